@@ -1,12 +1,18 @@
 package config
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/Dom-HTG/attendance-management-system/config/database"
+	adminRepo "github.com/Dom-HTG/attendance-management-system/internal/admin/repository"
+	adminSvc "github.com/Dom-HTG/attendance-management-system/internal/admin/service"
 	analyticsHandler "github.com/Dom-HTG/attendance-management-system/internal/analytics/handler"
 	analyticsRepo "github.com/Dom-HTG/attendance-management-system/internal/analytics/repository"
 	analyticsSvc "github.com/Dom-HTG/attendance-management-system/internal/analytics/service"
@@ -14,7 +20,21 @@ import (
 	attendanceSvc "github.com/Dom-HTG/attendance-management-system/internal/attendance/service"
 	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
 	authSvc "github.com/Dom-HTG/attendance-management-system/internal/auth/service"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
+	calendarSvc "github.com/Dom-HTG/attendance-management-system/internal/calendar/service"
+	courseRepo "github.com/Dom-HTG/attendance-management-system/internal/course/repository"
+	courseSvc "github.com/Dom-HTG/attendance-management-system/internal/course/service"
+	timetableRepo "github.com/Dom-HTG/attendance-management-system/internal/timetable/repository"
+	timetableSvc "github.com/Dom-HTG/attendance-management-system/internal/timetable/service"
+	venueRepo "github.com/Dom-HTG/attendance-management-system/internal/venue/repository"
+	venueSvc "github.com/Dom-HTG/attendance-management-system/internal/venue/service"
+	"github.com/Dom-HTG/attendance-management-system/pkg/lockout"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/mailer"
 	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/Dom-HTG/attendance-management-system/pkg/signing"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -23,6 +43,14 @@ import (
 type Application struct {
 	DB  database.DbConfig
 	App AppConfig
+
+	workers  []Worker // Background jobs started alongside the HTTP server.
+	startErr error    // Configuration error found while wiring; Start refuses to run.
+}
+
+// Worker is a background job that runs until its context is cancelled.
+type Worker interface {
+	Run(ctx context.Context)
 }
 
 type AppConfig struct {
@@ -33,12 +61,26 @@ type Handlers struct {
 	AuthHandler       *authSvc.AuthSvc
 	AttendanceHandler *attendanceSvc.AttendanceSvc
 	AnalyticsHandler  *analyticsHandler.AnalyticsHandler
+	CourseHandler     *courseSvc.CourseSvc
+	VenueHandler      *venueSvc.VenueSvc
+	AdminHandler      *adminSvc.AdminSvc
+	CalendarHandler   *calendarSvc.CalendarSvc
+	TimetableHandler  *timetableSvc.TimetableSvc
+	FeedHandler       *calendarSvc.FeedSvc
 }
 
 // Mount method mounts the application routes and midddlewares to the gin engine.
 func (app *Application) Mount(handler *Handlers) *gin.Engine {
 	router := gin.Default()
 
+	// Client IPs, used for per-IP login lockouts, are only taken from
+	// X-Forwarded-For when the request comes through a proxy listed in
+	// TRUSTED_PROXIES (comma-separated IPs or CIDRs). None are trusted by default.
+	if err := router.SetTrustedProxies(listFromEnv("TRUSTED_PROXIES")); err != nil {
+		logger.Errorf("invalid TRUSTED_PROXIES: %v", err)
+		app.startErr = fmt.Errorf("invalid TRUSTED_PROXIES: %w", err)
+	}
+
 	// CORS configuration.
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
@@ -53,104 +95,360 @@ func (app *Application) Mount(handler *Handlers) *gin.Engine {
 	router.Use(gin.Logger())
 	router.Use(gin.Recovery())
 
+	// Public keys for verifying access tokens.
+	router.GET("/.well-known/jwks.json", handler.AuthHandler.JWKS)
+
+	// iCalendar feeds; the token in the URL authenticates calendar apps.
+	router.GET("/api/calendar/feeds/:feed", handler.FeedHandler.ServeFeed)
+
 	// Auth routes.
 	authRoutes := router.Group("/api/auth")
 	{
-		authRoutes.POST("/register-student", handler.AuthHandler.RegisterStudent)   // Registers new student.
-		authRoutes.POST("/register-lecturer", handler.AuthHandler.RegisterLecturer) // Registers new lecturer.
-		authRoutes.POST("/login-student", handler.AuthHandler.LoginStudent)         // Logs in student.
-		authRoutes.POST("/login-lecturer", handler.AuthHandler.LoginLecturer)       // Logs in lecturer.
-		authRoutes.POST("/forgot-password")                                         // Sends reset password email.
-		authRoutes.POST("/logout")                                                  // Logs out user.
-		authRoutes.POST("/refresh-token")                                           // Refresh access token..
+		authRoutes.POST("/register-student", handler.AuthHandler.RegisterStudent)                  // Registers new student.
+		authRoutes.POST("/register-lecturer", handler.AuthHandler.RegisterLecturer)                // Registers new lecturer.
+		authRoutes.POST("/login", handler.AuthHandler.Login)                                       // Logs in student or lecturer.
+		authRoutes.POST("/login-student", handler.AuthHandler.LoginStudent)                        // Logs in student (alias of /login).
+		authRoutes.POST("/login-lecturer", handler.AuthHandler.LoginLecturer)                      // Logs in lecturer (alias of /login).
+		authRoutes.POST("/login-admin", handler.AuthHandler.LoginAdmin)                            // Logs in admin.
+		authRoutes.POST("/forgot-password", handler.AuthHandler.ForgotPassword)                    // Sends reset password email.
+		authRoutes.POST("/reset-password", handler.AuthHandler.ResetPassword)                      // Sets a new password using an emailed reset token.
+		authRoutes.POST("/verify-email", handler.AuthHandler.VerifyEmail)                          // Confirms an email address using an emailed token.
+		authRoutes.POST("/resend-verification", handler.AuthHandler.ResendVerification)            // Sends another verification email (throttled).
+		authRoutes.POST("/logout", middleware.AuthMiddleware(), handler.AuthHandler.Logout)        // Logs out of the current session.
+		authRoutes.POST("/logout-all", middleware.AuthMiddleware(), handler.AuthHandler.LogoutAll) // Logs out of every session.
+		authRoutes.POST("/refresh-token", handler.AuthHandler.RefreshToken)                        // Exchanges a refresh token for new tokens.
+
+		// Two-factor authentication; the login steps take the challenge token from a login response.
+		authRoutes.POST("/login/2fa", handler.AuthHandler.LoginSecondFactor)                                             // Completes a login with a TOTP or recovery code.
+		authRoutes.POST("/login/2fa/setup", handler.AuthHandler.LoginTwoFactorSetup)                                     // Starts the enrollment a login requires.
+		authRoutes.POST("/login/2fa/enable", handler.AuthHandler.LoginTwoFactorEnable)                                   // Confirms that enrollment and completes the login.
+		authRoutes.GET("/2fa", middleware.AuthMiddleware(), handler.AuthHandler.GetTwoFactorStatus)                      // Shows two-factor status.
+		authRoutes.POST("/2fa/setup", middleware.AuthMiddleware(), handler.AuthHandler.SetupTwoFactor)                   // Starts two-factor enrollment.
+		authRoutes.POST("/2fa/enable", middleware.AuthMiddleware(), handler.AuthHandler.EnableTwoFactor)                 // Confirms enrollment with a first code.
+		authRoutes.POST("/2fa/disable", middleware.AuthMiddleware(), handler.AuthHandler.DisableTwoFactor)               // Turns two-factor authentication off.
+		authRoutes.POST("/2fa/recovery-codes", middleware.AuthMiddleware(), handler.AuthHandler.RegenerateRecoveryCodes) // Replaces the recovery codes.
 	}
 
 	// Student routes.
 	studentRoutes := router.Group("/api/student")
+	studentRoutes.Use(middleware.AuthMiddleware())
 	{
-		studentRoutes.GET("/:id") // Retrieve student by id.
-		studentRoutes.PUT("/:id") // Update student data by id.
+		studentRoutes.GET("/:id", handler.AuthHandler.GetStudentProfile)                                                                    // Retrieve student by id (self or admin).
+		studentRoutes.PUT("/:id", handler.AuthHandler.UpdateStudentProfile)                                                                 // Update student data by id (self or admin).
+		studentRoutes.GET("/courses", middleware.RequirePermission(rbac.PermCoursesReadEnrolled), handler.CourseHandler.ListStudentCourses) // List courses the student is enrolled in.
 	}
 
 	// Lecturer routes.
 	lecturerRoutes := router.Group("/api/lecturer")
 	lecturerRoutes.Use(middleware.AuthMiddleware())
-	lecturerRoutes.Use(middleware.RoleMiddleware("lecturer"))
 	{
-		lecturerRoutes.GET("/:id")                                                        // Retrieve lecturer by id.
-		lecturerRoutes.PUT("/:id")                                                        // Update lecturer data by id.
-		lecturerRoutes.POST("/qrcode/generate", handler.AttendanceHandler.GenerateQRCode) // Generate new QR Code.
+		manageEvents := middleware.RequirePermission(rbac.PermEventsManage)
+		readAttendance := middleware.RequirePermission(rbac.PermAttendanceRead)
+		overrideAttendance := middleware.RequirePermission(rbac.PermAttendanceOverride)
+		manageCourses := middleware.RequirePermission(rbac.PermCoursesManage)
+		manageVenues := middleware.RequirePermission(rbac.PermVenuesManage)
+		readRosters := middleware.RequirePermission(rbac.PermRostersRead)
+
+		lecturerRoutes.GET("/:id", handler.AuthHandler.GetLecturerProfile)                              // Retrieve lecturer by id (self or admin).
+		lecturerRoutes.PUT("/:id", handler.AuthHandler.UpdateLecturerProfile)                           // Update lecturer data by id (self or admin).
+		lecturerRoutes.POST("/qrcode/generate", manageEvents, handler.AttendanceHandler.GenerateQRCode) // Generate new QR Code.
+
+		// Event management.
+		lecturerRoutes.GET("/events", manageEvents, handler.AttendanceHandler.ListLecturerEvents)                                      // List events the lecturer created or co-lectures.
+		lecturerRoutes.POST("/events/:event_id/co-lecturers", manageEvents, handler.AttendanceHandler.AddCoLecturer)                   // Grant a co-lecturer access (creator only).
+		lecturerRoutes.DELETE("/events/:event_id/co-lecturers/:lecturer_id", manageEvents, handler.AttendanceHandler.RemoveCoLecturer) // Revoke a co-lecturer (creator only).
+		lecturerRoutes.GET("/events/:event_id/qrcode", manageEvents, handler.AttendanceHandler.GetCurrentQRCode)                       // Current QR code to display; rotating events poll this.
+
+		// Event lifecycle, recorded in the event's history.
+		lecturerRoutes.PUT("/events/:event_id", manageEvents, handler.AttendanceHandler.UpdateEvent)               // Move an event or change its venue.
+		lecturerRoutes.POST("/events/:event_id/cancel", manageEvents, handler.AttendanceHandler.CancelEvent)       // Cancel an event with a reason.
+		lecturerRoutes.POST("/events/:event_id/close", manageEvents, handler.AttendanceHandler.CloseCheckIn)       // Close check-in early.
+		lecturerRoutes.POST("/events/:event_id/reopen", manageEvents, handler.AttendanceHandler.ReopenCheckIn)     // Reopen check-in for a few minutes.
+		lecturerRoutes.GET("/events/:event_id/history", readAttendance, handler.AttendanceHandler.GetEventHistory) // Changes made to the event.
+
+		// Manual attendance overrides (creator, co-lecturers and teaching assistants), recorded in an audit trail.
+		lecturerRoutes.PUT("/events/:event_id/attendance/:student_id", overrideAttendance, handler.AttendanceHandler.SetAttendanceStatus) // Create or change a student's record.
+		lecturerRoutes.DELETE("/events/:event_id/attendance/:student_id", overrideAttendance, handler.AttendanceHandler.VoidAttendance)   // Void a student's record.
+		lecturerRoutes.GET("/events/:event_id/attendance/:student_id/audit", readAttendance, handler.AttendanceHandler.GetRecordAudit)    // Audit trail for one record.
+		lecturerRoutes.GET("/events/:event_id/audit", readAttendance, handler.AttendanceHandler.GetEventAudit)                            // Audit trail for the event.
+
+		// Course management.
+		lecturerRoutes.POST("/courses", manageCourses, handler.CourseHandler.CreateCourse)              // Create a course owned by the lecturer.
+		lecturerRoutes.GET("/courses", manageCourses, handler.CourseHandler.ListCourses)                // List the lecturer's courses.
+		lecturerRoutes.GET("/courses/:course_id", manageCourses, handler.CourseHandler.GetCourse)       // Retrieve course by id.
+		lecturerRoutes.PUT("/courses/:course_id", manageCourses, handler.CourseHandler.UpdateCourse)    // Update course (owners only).
+		lecturerRoutes.DELETE("/courses/:course_id", manageCourses, handler.CourseHandler.DeleteCourse) // Delete course (owners only).
+
+		// Venue management.
+		lecturerRoutes.POST("/venues", manageVenues, handler.VenueHandler.CreateVenue)             // Register a venue with coordinates.
+		lecturerRoutes.GET("/venues", manageVenues, handler.VenueHandler.ListVenues)               // List all venues.
+		lecturerRoutes.GET("/venues/:venue_id", manageVenues, handler.VenueHandler.GetVenue)       // Retrieve venue by id.
+		lecturerRoutes.PUT("/venues/:venue_id", manageVenues, handler.VenueHandler.UpdateVenue)    // Update venue (registering lecturer only).
+		lecturerRoutes.DELETE("/venues/:venue_id", manageVenues, handler.VenueHandler.DeleteVenue) // Delete venue (registering lecturer only).
+
+		// Course enrollment (owners only, except reading the roster).
+		lecturerRoutes.GET("/courses/:course_id/enrollments", readRosters, handler.CourseHandler.ListEnrollments)                  // List enrolled students (owners and heads of department).
+		lecturerRoutes.POST("/courses/:course_id/enrollments", manageCourses, handler.CourseHandler.EnrollStudent)                 // Enroll one student.
+		lecturerRoutes.POST("/courses/:course_id/enrollments/bulk", manageCourses, handler.CourseHandler.BulkEnroll)               // Enroll students by matric number.
+		lecturerRoutes.DELETE("/courses/:course_id/enrollments/:student_id", manageCourses, handler.CourseHandler.UnenrollStudent) // Unenroll a student.
+
+		// Course timetable (course lecturers only); events are generated ahead of time.
+		lecturerRoutes.POST("/courses/:course_id/timetable", manageEvents, handler.TimetableHandler.CreateEntry)                       // Add a recurring class.
+		lecturerRoutes.GET("/courses/:course_id/timetable", manageEvents, handler.TimetableHandler.ListEntries)                        // List the course's timetable.
+		lecturerRoutes.PUT("/timetable/:entry_id", manageEvents, handler.TimetableHandler.UpdateEntry)                                 // Update a class; future events follow.
+		lecturerRoutes.DELETE("/timetable/:entry_id", manageEvents, handler.TimetableHandler.DeleteEntry)                              // Delete a class; future events are cancelled.
+		lecturerRoutes.POST("/timetable/:entry_id/exceptions", manageEvents, handler.TimetableHandler.CreateException)                 // Cancel or reschedule one occurrence.
+		lecturerRoutes.GET("/timetable/:entry_id/exceptions", manageEvents, handler.TimetableHandler.ListExceptions)                   // List cancellations and reschedules.
+		lecturerRoutes.DELETE("/timetable/:entry_id/exceptions/:exception_id", manageEvents, handler.TimetableHandler.DeleteException) // Undo a cancellation or reschedule.
+	}
+
+	// Admin routes.
+	adminRoutes := router.Group("/api/admin")
+	adminRoutes.Use(middleware.AuthMiddleware())
+	{
+		manageUsers := middleware.RequirePermission(rbac.PermUsersManage)
+		manageRoles := middleware.RequirePermission(rbac.PermRolesManage)
+
+		adminRoutes.POST("/admins", manageUsers, handler.AdminHandler.CreateAdmin) // Create another admin.
+		adminRoutes.GET("/admins", manageUsers, handler.AdminHandler.ListAdmins)   // List admins.
+
+		// Student and lecturer management; role is "student" or "lecturer".
+		adminRoutes.GET("/users", manageUsers, handler.AdminHandler.ListUsers)                                   // List or search users of one role.
+		adminRoutes.GET("/users/:role/:user_id", manageUsers, handler.AdminHandler.GetUser)                      // Retrieve a user.
+		adminRoutes.POST("/users/:role/:user_id/suspend", manageUsers, handler.AdminHandler.SuspendUser)         // Suspend a user and revoke their sessions.
+		adminRoutes.POST("/users/:role/:user_id/reactivate", manageUsers, handler.AdminHandler.ReactivateUser)   // Lift a suspension.
+		adminRoutes.DELETE("/users/:role/:user_id", manageUsers, handler.AdminHandler.DeleteUser)                // Delete a user.
+		adminRoutes.POST("/users/:role/:user_id/unlock", manageUsers, handler.AdminHandler.UnlockUser)           // Lift a failed login lockout.
+		adminRoutes.POST("/lockouts/ip/:ip/unlock", manageUsers, handler.AdminHandler.UnlockIP)                  // Lift a lockout on a client IP.
+		adminRoutes.DELETE("/users/:role/:user_id/two-factor", manageUsers, handler.AdminHandler.ResetTwoFactor) // Remove a user's two-factor enrollment.
+
+		// Bulk import of students and lecturers from CSV; role is "student" or "lecturer".
+		adminRoutes.POST("/imports/:role", manageUsers, handler.AdminHandler.ImportUsers)   // Import or validate (dry_run=true) a CSV file.
+		adminRoutes.GET("/imports", manageUsers, handler.AdminHandler.ListImportJobs)       // List recent import jobs.
+		adminRoutes.GET("/imports/:job_id", manageUsers, handler.AdminHandler.GetImportJob) // Poll an import job.
+
+		// Role assignments: extra roles such as head of department, limited to a department or course.
+		adminRoutes.GET("/roles", manageRoles, handler.AdminHandler.ListRoles)                                         // List roles and their permissions.
+		adminRoutes.GET("/role-assignments", manageRoles, handler.AdminHandler.ListRoleAssignments)                    // List role assignments.
+		adminRoutes.POST("/role-assignments", manageRoles, handler.AdminHandler.AssignRole)                            // Assign a role to a lecturer.
+		adminRoutes.DELETE("/role-assignments/:assignment_id", manageRoles, handler.AdminHandler.RevokeRoleAssignment) // Revoke a role assignment.
+	}
+
+	// Academic calendar routes; every signed-in user can read the calendar.
+	calendarRoutes := router.Group("/api/calendar")
+	calendarRoutes.Use(middleware.AuthMiddleware())
+	{
+		manageCalendar := middleware.RequirePermission(rbac.PermCalendarManage)
+
+		calendarRoutes.GET("/sessions", handler.CalendarHandler.ListSessions)                                          // List academic sessions with their semesters.
+		calendarRoutes.GET("/sessions/:session_id", handler.CalendarHandler.GetSession)                                // Retrieve an academic session.
+		calendarRoutes.POST("/sessions", manageCalendar, handler.CalendarHandler.CreateSession)                        // Create an academic session.
+		calendarRoutes.PUT("/sessions/:session_id", manageCalendar, handler.CalendarHandler.UpdateSession)             // Update an academic session.
+		calendarRoutes.DELETE("/sessions/:session_id", manageCalendar, handler.CalendarHandler.DeleteSession)          // Delete an academic session and its semesters.
+		calendarRoutes.POST("/sessions/:session_id/semesters", manageCalendar, handler.CalendarHandler.CreateSemester) // Add a semester; events in it are tagged.
+		calendarRoutes.PUT("/semesters/:semester_id", manageCalendar, handler.CalendarHandler.UpdateSemester)          // Update a semester; events are retagged.
+		calendarRoutes.DELETE("/semesters/:semester_id", manageCalendar, handler.CalendarHandler.DeleteSemester)       // Delete a semester; its events are untagged.
+		calendarRoutes.GET("/holidays", handler.CalendarHandler.ListHolidays)                                          // List public holidays and breaks.
+		calendarRoutes.POST("/holidays", manageCalendar, handler.CalendarHandler.CreateHoliday)                        // Add a holiday.
+		calendarRoutes.PUT("/holidays/:holiday_id", manageCalendar, handler.CalendarHandler.UpdateHoliday)             // Update a holiday.
+		calendarRoutes.DELETE("/holidays/:holiday_id", manageCalendar, handler.CalendarHandler.DeleteHoliday)          // Delete a holiday.
+		calendarRoutes.GET("/feed", handler.FeedHandler.GetFeed)                                                       // Check whether the caller's calendar feed is enabled.
+		calendarRoutes.POST("/feed", handler.FeedHandler.CreateFeed)                                                   // Create the caller's feed URL, replacing any earlier one.
+		calendarRoutes.DELETE("/feed", handler.FeedHandler.DeleteFeed)                                                 // Turn the caller's calendar feed off.
 	}
 
 	// Attendance routes.
 	attendanceRoutes := router.Group("/api/attendance")
 	{
-		attendanceRoutes.POST("/check-in", middleware.AuthMiddleware(), middleware.RoleMiddleware("student"), handler.AttendanceHandler.CheckIn)                    // Checks in user [marks user as present].
-		attendanceRoutes.GET("/:event_id", middleware.AuthMiddleware(), middleware.RoleMiddleware("lecturer"), handler.AttendanceHandler.GetEventAttendance)        // Retrieves attendance record for an event.
-		attendanceRoutes.GET("/student/records", middleware.AuthMiddleware(), middleware.RoleMiddleware("student"), handler.AttendanceHandler.GetStudentAttendance) // Retrieves student attendance history.
-		attendanceRoutes.POST("/report")                                                                                                                            // Generates detailed attendance report for individual user.
+		attendanceRoutes.POST("/check-in", middleware.AuthMiddleware(), middleware.RequirePermission(rbac.PermAttendanceCheckIn), handler.AttendanceHandler.CheckIn)                    // Checks in user [marks user as present].
+		attendanceRoutes.GET("/:event_id", middleware.AuthMiddleware(), middleware.RequirePermission(rbac.PermAttendanceRead), handler.AttendanceHandler.GetEventAttendance)            // Retrieves attendance record for an event.
+		attendanceRoutes.GET("/student/records", middleware.AuthMiddleware(), middleware.RequirePermission(rbac.PermAttendanceReadOwn), handler.AttendanceHandler.GetStudentAttendance) // Retrieves student attendance history.
+		attendanceRoutes.POST("/report")                                                                                                                                                // Generates detailed attendance report for individual user.
 	}
 
 	// Analytics routes - all require authentication
 	analyticsRoutes := router.Group("/api/analytics")
 	analyticsRoutes.Use(middleware.AuthMiddleware())
 	{
+		// Per-student and per-course analytics: students see their own record and
+		// lecturers the courses they teach; holders of department analytics see the
+		// students and courses of the departments their role covers.
+		targetAnalytics := middleware.RequireOwnerOrPermission(handler.AnalyticsHandler.OwnsTarget, rbac.PermAnalyticsDepartmentRead,
+			handler.AnalyticsHandler.StudentDepartment, handler.AnalyticsHandler.CourseScope, handler.AnalyticsHandler.CourseDepartment)
+
 		// Student analytics
-		analyticsRoutes.GET("/student/:student_id", handler.AnalyticsHandler.GetStudentMetrics)           // Get student metrics
-		analyticsRoutes.GET("/student/:student_id/insights", handler.AnalyticsHandler.GetStudentInsights) // Get student insights
+		analyticsRoutes.GET("/student/:student_id", targetAnalytics, handler.AnalyticsHandler.GetStudentMetrics)           // Get student metrics
+		analyticsRoutes.GET("/student/:student_id/insights", targetAnalytics, handler.AnalyticsHandler.GetStudentInsights) // Get student insights
 
-		// Lecturer analytics (lecturer role required)
+		// Lecturer analytics for the lecturer's own courses
 		lecturerAnalytics := analyticsRoutes.Group("")
-		lecturerAnalytics.Use(middleware.RoleMiddleware("lecturer"))
+		lecturerAnalytics.Use(middleware.RequirePermission(rbac.PermAnalyticsCourseRead))
 		{
 			lecturerAnalytics.GET("/lecturer/courses", handler.AnalyticsHandler.GetLecturerCourseMetrics)                 // Get lecturer course metrics
 			lecturerAnalytics.GET("/lecturer/course/:course_code", handler.AnalyticsHandler.GetLecturerCoursePerformance) // Get course performance
 			lecturerAnalytics.GET("/lecturer/insights", handler.AnalyticsHandler.GetLecturerInsights)                     // Get lecturer insights
 		}
 
-		// Admin analytics (admin/lecturer role required for now)
-		adminAnalytics := analyticsRoutes.Group("")
-		adminAnalytics.Use(middleware.RoleMiddleware("lecturer")) // Can be extended to "admin" role
-		{
-			adminAnalytics.GET("/admin/overview", handler.AnalyticsHandler.GetAdminOverview)                   // Get admin overview
-			adminAnalytics.GET("/admin/department/:department", handler.AnalyticsHandler.GetDepartmentMetrics) // Get department metrics
-			adminAnalytics.GET("/admin/realtime", handler.AnalyticsHandler.GetRealTimeDashboard)               // Get real-time dashboard
-		}
+		// Institution and department analytics (admins, deans, registry; heads of department for their own department)
+		institutionAnalytics := middleware.RequirePermission(rbac.PermAnalyticsInstitutionRead)
+		departmentAnalytics := middleware.RequirePermission(rbac.PermAnalyticsDepartmentRead, middleware.DepartmentParam("department"))
+		analyticsRoutes.GET("/admin/overview", institutionAnalytics, handler.AnalyticsHandler.GetAdminOverview)                                 // Get admin overview
+		analyticsRoutes.GET("/admin/department/:department", departmentAnalytics, handler.AnalyticsHandler.GetDepartmentMetrics)                // Get department metrics
+		analyticsRoutes.GET("/admin/department/:department/at-risk", departmentAnalytics, handler.AnalyticsHandler.GetDepartmentAtRiskStudents) // List at-risk students
+		analyticsRoutes.GET("/admin/realtime", institutionAnalytics, handler.AnalyticsHandler.GetRealTimeDashboard)                             // Get real-time dashboard
 
-		// Temporal, anomaly, prediction, benchmarking, and chart endpoints (all authenticated users)
-		analyticsRoutes.GET("/temporal", handler.AnalyticsHandler.GetTemporalAnalytics)                            // Get temporal analytics
-		analyticsRoutes.GET("/anomalies", handler.AnalyticsHandler.DetectAnomalies)                                // Detect anomalies
-		analyticsRoutes.GET("/predictions/student/:student_id", handler.AnalyticsHandler.PredictStudentAttendance) // Predict student attendance
-		analyticsRoutes.GET("/predictions/course/:course_code", handler.AnalyticsHandler.PredictCourseAttendance)  // Predict course attendance
-		analyticsRoutes.GET("/benchmark", handler.AnalyticsHandler.GetBenchmarkComparison)                         // Get benchmark comparison
-		analyticsRoutes.GET("/charts/:chart_type", handler.AnalyticsHandler.GetChartData)                          // Get chart data
+		// Institution-wide temporal and anomaly analytics
+		analyticsRoutes.GET("/temporal", institutionAnalytics, handler.AnalyticsHandler.GetTemporalAnalytics) // Get temporal analytics
+		analyticsRoutes.GET("/anomalies", institutionAnalytics, handler.AnalyticsHandler.DetectAnomalies)     // Detect anomalies
+
+		// Prediction, benchmarking, and chart endpoints for one student or course
+		analyticsRoutes.GET("/predictions/student/:student_id", targetAnalytics, handler.AnalyticsHandler.PredictStudentAttendance) // Predict student attendance
+		analyticsRoutes.GET("/predictions/course/:course_code", targetAnalytics, handler.AnalyticsHandler.PredictCourseAttendance)  // Predict course attendance
+		analyticsRoutes.GET("/benchmark", targetAnalytics, handler.AnalyticsHandler.GetBenchmarkComparison)                         // Get benchmark comparison
+		analyticsRoutes.GET("/charts/:chart_type", targetAnalytics, handler.AnalyticsHandler.GetChartData)                          // Get chart data
 	}
 
 	return router
 }
 
 func (app *Application) InjectDependencies(db *gorm.DB) *Handlers {
+	// token signing
+	accessTokenTTL := durationFromEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	challengeTTL := durationFromEnv("TWO_FACTOR_CHALLENGE_TTL", 5*time.Minute)
+	jwtSecret, err := jwtSecretFromEnv()
+	var signingKeys *signing.Manager
+	if err == nil {
+		signingKeys, err = signingKeysFromEnv(db, jwtSecret, max(accessTokenTTL, challengeTTL))
+	}
+	if err != nil {
+		logger.Errorf("token signing setup failed: %v", err)
+		app.startErr = fmt.Errorf("token signing setup failed: %w", err)
+	} else {
+		utils.UseSigningKeys(signingKeys)
+		utils.UseQRTokenSecret(stringFromEnv("QR_TOKEN_SECRET", jwtSecret))
+		if signingKeys.Rotates() {
+			app.workers = append(app.workers, signing.NewRotator(signingKeys, 10*time.Minute))
+		}
+	}
+
+	// two-factor secrets at rest
+	twoFactorKey, err := twoFactorKeyFromEnv()
+	if err != nil {
+		logger.Errorf("two-factor setup failed: %v", err)
+		app.startErr = fmt.Errorf("two-factor setup failed: %w", err)
+	} else {
+		utils.UseTOTPEncryptionKey(twoFactorKey)
+	}
+
 	// auth
 	authRepoInstance := authRepo.NewAuthRepo(db)
-	authSvcInstance := authSvc.NewAuthSvc(authRepoInstance)
+	tokenRepoInstance := authRepo.NewTokenRepo(db)
+	twoFactorRepoInstance := authRepo.NewTwoFactorRepo(db)
+	mailerInstance, err := mailerFromEnv()
+	if err != nil {
+		logger.Errorf("mailer setup failed: %v", err)
+		app.startErr = fmt.Errorf("mailer setup failed: %w", err)
+		mailerInstance = mailer.NewLogMailer()
+	}
+	lockoutGuard := lockout.NewGuard(lockoutStoreFromEnv(db), lockout.Policy{
+		AccountThreshold: intFromEnv("LOCKOUT_ACCOUNT_THRESHOLD", 5),
+		IPThreshold:      intFromEnv("LOCKOUT_IP_THRESHOLD", 50),
+		BaseLockout:      durationFromEnv("LOCKOUT_BASE_DURATION", time.Minute),
+		MaxLockout:       durationFromEnv("LOCKOUT_MAX_DURATION", time.Hour),
+		Window:           durationFromEnv("LOCKOUT_WINDOW", 15*time.Minute),
+	})
+	authSvcInstance := authSvc.NewAuthSvc(authRepoInstance, tokenRepoInstance, mailerInstance, authSvc.SessionConfig{
+		AccessTokenTTL:  accessTokenTTL,
+		RefreshTokenTTL: durationFromEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+	}, authSvc.PasswordResetConfig{
+		TokenTTL: durationFromEnv("PASSWORD_RESET_TTL", 30*time.Minute),
+		URL:      os.Getenv("PASSWORD_RESET_URL"),
+	}, authSvc.EmailVerificationConfig{
+		TokenTTL:          durationFromEnv("EMAIL_VERIFICATION_TTL", 24*time.Hour),
+		URL:               os.Getenv("EMAIL_VERIFICATION_URL"),
+		ResendInterval:    durationFromEnv("EMAIL_VERIFICATION_RESEND_INTERVAL", time.Minute),
+		MaxResendsPerHour: intFromEnv("EMAIL_VERIFICATION_MAX_PER_HOUR", 5),
+	}, authSvc.RegistrationConfig{
+		RequireEmailVerification: boolFromEnv("REQUIRE_EMAIL_VERIFICATION", true),
+		LecturerEmailDomains:     emailDomainsFromEnv("LECTURER_EMAIL_DOMAINS"),
+	}, authSvc.TwoFactorConfig{
+		Issuer:        stringFromEnv("TWO_FACTOR_ISSUER", "Attendance Management System"),
+		RequiredRoles: rolesFromEnv("TWO_FACTOR_REQUIRED_ROLES", "lecturer", "admin"),
+		ChallengeTTL:  challengeTTL,
+	}, lockoutGuard, twoFactorRepoInstance, signingKeys)
+	middleware.UseRevocationStore(tokenRepoInstance)
+	app.workers = append(app.workers, authSvc.NewTokenJanitor(tokenRepoInstance, time.Hour))
+	app.workers = append(app.workers, lockout.NewJanitor(lockoutGuard, time.Hour))
+
+	// admin
+	adminRepoInstance := adminRepo.NewAdminRepo(db)
+	if err := adminSvc.BootstrapAdmin(adminRepoInstance, os.Getenv("ADMIN_EMAIL"), os.Getenv("ADMIN_PASSWORD")); err != nil {
+		logger.Errorf("admin bootstrap failed: %v", err)
+	}
+	roleAssignmentRepoInstance := adminRepo.NewRoleAssignmentRepo(db)
+	middleware.UseGrantStore(roleAssignmentRepoInstance)
+	importRepoInstance := adminRepo.NewImportRepo(db)
+	importer := adminSvc.NewImporter(importRepoInstance, intFromEnv("IMPORT_SYNC_ROWS", 200))
+	app.workers = append(app.workers, importer)
+	adminSvcInstance := adminSvc.NewAdminSvc(adminRepoInstance, roleAssignmentRepoInstance, importRepoInstance, tokenRepoInstance, importer, lockoutGuard, twoFactorRepoInstance)
+
+	// course
+	courseRepoInstance := courseRepo.NewCourseRepo(db)
+	enrollmentRepoInstance := courseRepo.NewEnrollmentRepo(db)
+	courseSvcInstance := courseSvc.NewCourseSvc(courseRepoInstance, enrollmentRepoInstance)
+
+	// venue
+	venueRepoInstance := venueRepo.NewVenueRepo(db)
+	venueSvcInstance := venueSvc.NewVenueSvc(venueRepoInstance)
 
 	// attendance
 	attendanceRepoInstance := attendanceRepo.NewAttendanceRepo(db)
-	attendanceSvcInstance := attendanceSvc.NewAttendanceSvc(attendanceRepoInstance, authRepoInstance)
+	attendanceSvcInstance := attendanceSvc.NewAttendanceSvc(attendanceRepoInstance, authRepoInstance, courseRepoInstance, enrollmentRepoInstance, venueRepoInstance, durationFromEnv("REOPEN_CHECK_IN_LIMIT", time.Hour))
+	app.workers = append(app.workers, attendanceSvc.NewAbsenceFinalizer(attendanceRepoInstance, durationFromEnv("ABSENCE_FINALIZER_INTERVAL", 5*time.Minute)))
+
+	// calendar
+	calendarRepoInstance := calendarRepo.NewCalendarRepo(db)
+	calendarSvcInstance := calendarSvc.NewCalendarSvc(calendarRepoInstance)
+	feedSvcInstance := calendarSvc.NewFeedSvc(calendarRepo.NewFeedRepo(db), calendarSvc.FeedConfig{
+		BaseURL:  stringFromEnv("CALENDAR_FEED_BASE_URL", ""),
+		Lookback: durationFromEnv("CALENDAR_FEED_LOOKBACK", 90*24*time.Hour),
+		Refresh:  durationFromEnv("CALENDAR_FEED_REFRESH", time.Hour),
+	})
+
+	// timetable
+	timetableRepoInstance := timetableRepo.NewTimetableRepo(db)
+	timetableGenerator := timetableSvc.NewGenerator(timetableRepoInstance, calendarRepoInstance, locationFromEnv("TIMETABLE_TIMEZONE"),
+		intFromEnv("TIMETABLE_HORIZON_DAYS", 14), durationFromEnv("TIMETABLE_INTERVAL", time.Hour))
+	app.workers = append(app.workers, timetableGenerator)
+	timetableSvcInstance := timetableSvc.NewTimetableSvc(timetableRepoInstance, courseRepoInstance, venueRepoInstance, calendarRepoInstance, timetableGenerator)
 
 	// analytics
 	analyticsRepoInstance := analyticsRepo.NewAnalyticsRepo(db)
-	analyticsSvcInstance := analyticsSvc.NewAnalyticsService(analyticsRepoInstance)
+	analyticsSvcInstance := analyticsSvc.NewAnalyticsService(analyticsRepoInstance, calendarRepoInstance)
 	analyticsHandlerInstance := analyticsHandler.NewAnalyticsHandler(analyticsSvcInstance)
 
 	return &Handlers{
 		AuthHandler:       authSvcInstance,
 		AttendanceHandler: attendanceSvcInstance,
 		AnalyticsHandler:  analyticsHandlerInstance,
+		CourseHandler:     courseSvcInstance,
+		VenueHandler:      venueSvcInstance,
+		AdminHandler:      adminSvcInstance,
+		CalendarHandler:   calendarSvcInstance,
+		TimetableHandler:  timetableSvcInstance,
+		FeedHandler:       feedSvcInstance,
 	}
 }
 
 func (app *Application) Start(router *gin.Engine) error {
+	if app.startErr != nil {
+		return app.startErr
+	}
+
+	// Start background jobs.
+	for _, w := range app.workers {
+		go w.Run(context.Background())
+	}
 
 	port := os.Getenv("APP_PORT")
 	// default to :2754 if not provided
@@ -166,3 +464,219 @@ func (app *Application) Start(router *gin.Engine) error {
 
 	return nil
 }
+
+// durationFromEnv parses a time.Duration from the named environment variable,
+// falling back to def when it is unset or invalid.
+func durationFromEnv(key string, def time.Duration) time.Duration {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// intFromEnv parses an int from the named environment variable, falling back
+// to def when it is unset or invalid.
+func intFromEnv(key string, def int) int {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return def
+	}
+	return n
+}
+
+// boolFromEnv parses a bool from the named environment variable, falling back
+// to def when it is unset or invalid.
+func boolFromEnv(key string, def bool) bool {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(raw)
+	if err != nil {
+		return def
+	}
+	return b
+}
+
+// stringFromEnv returns the named environment variable, falling back to def
+// when it is unset.
+func stringFromEnv(key, def string) string {
+	if raw := strings.TrimSpace(os.Getenv(key)); raw != "" {
+		return raw
+	}
+	return def
+}
+
+// locationFromEnv loads the time zone named by the environment variable, such
+// as "Africa/Lagos", falling back to the server's local time zone when it is
+// unset or unknown.
+func locationFromEnv(key string) *time.Location {
+	name := strings.TrimSpace(os.Getenv(key))
+	if name == "" {
+		return time.Local
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		logger.Errorf("unknown time zone %q in %s, using the local time zone", name, key)
+		return time.Local
+	}
+	return location
+}
+
+// rolesFromEnv parses a comma-separated list of roles from the named
+// environment variable. Roles outside allowed are logged and ignored.
+func rolesFromEnv(key string, allowed ...string) []string {
+	var roles []string
+	for _, role := range strings.Split(os.Getenv(key), ",") {
+		role = strings.ToLower(strings.TrimSpace(role))
+		if role == "" {
+			continue
+		}
+		if !slices.Contains(allowed, role) {
+			logger.Errorf("ignoring role %q in %s; expected one of %s", role, key, strings.Join(allowed, ", "))
+			continue
+		}
+		roles = append(roles, role)
+	}
+	return roles
+}
+
+// jwtSecretFromEnv returns JWT_SECRET. Outside development
+// (APP_ENV=development) a JWT_SECRET other than the built-in development secret
+// is required.
+func jwtSecretFromEnv() (string, error) {
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" || secret == developmentJWTSecret {
+		if !isDevelopment() {
+			return "", errors.New("JWT_SECRET must be set; the development secret is only allowed with APP_ENV=development")
+		}
+		logger.Errorf("JWT_SECRET is not set; using the insecure development secret")
+		secret = developmentJWTSecret
+	}
+	return secret, nil
+}
+
+// signingKeysFromEnv builds the JWT key manager from secret, the validated
+// JWT_SECRET, and JWT_ALGORITHM and related variables. Replaced keys keep
+// verifying for at least minRetention, the longest token lifetime.
+func signingKeysFromEnv(db *gorm.DB, secret string, minRetention time.Duration) (*signing.Manager, error) {
+	algorithm := signing.AlgHS256
+	switch raw := strings.TrimSpace(os.Getenv("JWT_ALGORITHM")); strings.ToUpper(raw) {
+	case "", "HS256":
+	case "RS256":
+		algorithm = signing.AlgRS256
+	case "EDDSA":
+		algorithm = signing.AlgEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM %q; use HS256, RS256 or EdDSA", raw)
+	}
+
+	retention := durationFromEnv("JWT_KEY_RETENTION", 24*time.Hour)
+	if retention < minRetention {
+		retention = minRetention
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return signing.NewManager(ctx, signing.Config{
+		Algorithm:        algorithm,
+		Secret:           secret,
+		PreviousSecrets:  listFromEnv("JWT_PREVIOUS_SECRETS"),
+		Issuer:           stringFromEnv("JWT_ISSUER", "attendance-management-system"),
+		Audience:         stringFromEnv("JWT_AUDIENCE", "attendance-management-api"),
+		RotationInterval: durationFromEnv("JWT_ROTATION_INTERVAL", 30*24*time.Hour),
+		Retention:        retention,
+	}, authRepo.NewSigningKeyRepo(db))
+}
+
+// twoFactorKeyFromEnv returns the key TOTP secrets are encrypted with, from
+// TWO_FACTOR_ENCRYPTION_KEY. Outside development (APP_ENV=development) it must
+// be set and must not be the development JWT secret.
+func twoFactorKeyFromEnv() (string, error) {
+	key := os.Getenv("TWO_FACTOR_ENCRYPTION_KEY")
+	if key == "" || key == developmentJWTSecret {
+		if !isDevelopment() {
+			return "", errors.New("TWO_FACTOR_ENCRYPTION_KEY must be set outside development")
+		}
+		logger.Errorf("TWO_FACTOR_ENCRYPTION_KEY is not set; using the insecure development secret")
+		key = developmentJWTSecret
+	}
+	return key, nil
+}
+
+// mailerFromEnv returns the mailer selected by MAILER ("log" or "file", with
+// MAIL_DIR). Outside development (APP_ENV=development) MAILER must be set to a
+// known mailer; in development it defaults to "log".
+func mailerFromEnv() (mailer.Mailer, error) {
+	kind := strings.TrimSpace(os.Getenv("MAILER"))
+	if kind == "" {
+		if !isDevelopment() {
+			return nil, errors.New("MAILER must be set; use log or file")
+		}
+		kind = "log"
+	}
+	return mailer.New(kind, os.Getenv("MAIL_DIR"))
+}
+
+// developmentJWTSecret is the signing secret used when JWT_SECRET is unset in
+// development, and the two-factor key when TWO_FACTOR_ENCRYPTION_KEY is. It is
+// public, so it is refused everywhere else.
+const developmentJWTSecret = "your-super-secret-key-change-in-production"
+
+// isDevelopment reports whether APP_ENV names a development environment.
+func isDevelopment() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("APP_ENV"))) {
+	case "development", "dev", "local":
+		return true
+	}
+	return false
+}
+
+// listFromEnv parses a comma-separated list from the named environment
+// variable, dropping empty entries.
+func listFromEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// lockoutStoreFromEnv returns the failed login store selected by LOCKOUT_STORE:
+// "postgres" (the default) shares counts between replicas and "memory" keeps
+// them in this process.
+func lockoutStoreFromEnv(db *gorm.DB) lockout.Store {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv("LOCKOUT_STORE"))) {
+	case "", "postgres":
+		return authRepo.NewLoginAttemptRepo(db)
+	case "memory":
+		return lockout.NewMemoryStore()
+	default:
+		logger.Errorf("unknown LOCKOUT_STORE %q, using postgres", os.Getenv("LOCKOUT_STORE"))
+		return authRepo.NewLoginAttemptRepo(db)
+	}
+}
+
+// emailDomainsFromEnv parses a comma-separated list of email domains, such as
+// "school.edu,staff.school.edu", from the named environment variable.
+func emailDomainsFromEnv(key string) []string {
+	var domains []string
+	for _, domain := range strings.Split(os.Getenv(key), ",") {
+		domain = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(domain), "@"))
+		if domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+	return domains
+}
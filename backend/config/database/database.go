@@ -57,16 +57,45 @@ func (conf *DbConfig) Start() (*gorm.DB, error) {
 
 	// Migrate models and report outcome
 	if err := db.AutoMigrate(
+		&entities.Identity{},
 		&entities.Student{},
 		&entities.Lecturer{},
+		&entities.Admin{},
+		&entities.RoleAssignment{},
+		&entities.ImportJob{},
+		&entities.Course{},
+		&entities.Enrollment{},
+		&entities.Venue{},
+		&entities.AcademicSession{},
+		&entities.Semester{},
+		&entities.Holiday{},
 		&entities.Event{},
+		&entities.TimetableEntry{},
+		&entities.TimetableException{},
+		&entities.CalendarFeed{},
 		&entities.Attendance{},
 		&entities.UserAttendance{},
+		&entities.AttendanceAudit{},
+		&entities.EventAudit{},
+		&entities.RefreshToken{},
+		&entities.RevokedAccessToken{},
+		&entities.PasswordResetToken{},
+		&entities.EmailVerification{},
+		&entities.LoginAttempt{},
+		&entities.TwoFactor{},
+		&entities.RecoveryCode{},
+		&entities.SigningKey{},
+		&entities.SchemaMigration{},
 	); err != nil {
 		logger.Errorf("AutoMigrate failed: %v", err)
 		return nil, err
 	}
 
+	if err := runMigrations(db); err != nil {
+		logger.Errorf("migration failed: %v", err)
+		return nil, err
+	}
+
 	logger.Info("Database migrations applied successfully")
 
 	return db, nil
@@ -0,0 +1,277 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// migrationLockKey is the Postgres advisory lock held while migrations run, so
+// replicas starting together apply each migration once.
+const migrationLockKey = 7301
+
+// migration is a one-off change that AutoMigrate cannot make, such as dropping
+// an index it replaced or moving data between tables. Migrations run once each,
+// in order, after AutoMigrate, and are recorded in schema_migrations.
+type migration struct {
+	name string
+	up   func(tx *gorm.DB) error
+}
+
+// migrations lists every one-off migration. Append new ones; never reorder,
+// rename or edit one that has shipped.
+var migrations = []migration{
+	{
+		// Course codes are now unique among courses that are not deleted, so a
+		// deleted course's code can be reused.
+		name: "0001_drop_courses_code_index",
+		up: func(tx *gorm.DB) error {
+			return tx.Exec("DROP INDEX IF EXISTS idx_courses_code").Error
+		},
+	},
+	{
+		// Heads of department are now department-scoped hod role assignments.
+		// Lecturers flagged as head become an assignment for their department,
+		// and the flag is dropped.
+		name: "0002_head_of_department_to_role_assignments",
+		up: func(tx *gorm.DB) error {
+			if !tx.Migrator().HasColumn(&entities.Lecturer{}, "is_head_of_department") {
+				return nil
+			}
+			if err := tx.Exec(`
+				INSERT INTO role_assignments (created_at, updated_at, user_id, user_role, role, scope_type, scope_value, granted_by)
+				SELECT NOW(), NOW(), id, 'lecturer', 'hod', 'department', TRIM(department), 0
+				FROM lecturers
+				WHERE is_head_of_department AND TRIM(department) <> '' AND deleted_at IS NULL
+				ON CONFLICT DO NOTHING
+			`).Error; err != nil {
+				return err
+			}
+			return tx.Migrator().DropColumn(&entities.Lecturer{}, "is_head_of_department")
+		},
+	},
+	{
+		// Credentials now live only on identities. Every student and lecturer,
+		// deleted or not, is linked to the identity for its email before the
+		// profile email and password columns are dropped; see mergeIdentities
+		// for which password a shared identity keeps.
+		name: "0003_identity_only_credentials",
+		up: func(tx *gorm.DB) error {
+			if tx.Migrator().HasColumn(&entities.Student{}, "password") {
+				if err := mergeIdentities(tx); err != nil {
+					return err
+				}
+				for _, model := range []interface{}{&entities.Student{}, &entities.Lecturer{}} {
+					for _, column := range []string{"email", "password"} {
+						if err := tx.Migrator().DropColumn(model, column); err != nil {
+							return err
+						}
+					}
+				}
+			}
+			for _, table := range []string{"students", "lecturers"} {
+				if err := tx.Exec("ALTER TABLE " + table + " ALTER COLUMN identity_id SET NOT NULL").Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		// Matric numbers, staff IDs and emails are now unique among accounts that
+		// are not deleted, so a deleted student or lecturer can register or be
+		// imported again. Identities left without an active profile are deleted
+		// along with it.
+		name: "0004_release_deleted_account_identifiers",
+		up: func(tx *gorm.DB) error {
+			for _, index := range []string{
+				"idx_students_matric_number",
+				"idx_students_identity_id",
+				"idx_lecturers_staff_id",
+				"idx_lecturers_identity_id",
+				"idx_identities_email",
+			} {
+				if err := tx.Exec("DROP INDEX IF EXISTS " + index).Error; err != nil {
+					return err
+				}
+			}
+			return tx.Exec(`
+				UPDATE identities i SET deleted_at = NOW()
+				WHERE i.deleted_at IS NULL
+					AND NOT EXISTS (SELECT 1 FROM students WHERE identity_id = i.id AND deleted_at IS NULL)
+					AND NOT EXISTS (SELECT 1 FROM lecturers WHERE identity_id = i.id AND deleted_at IS NULL)
+			`).Error
+		},
+	},
+	{
+		// Events generated from the timetable now record the lecturer who added
+		// the class as their creator.
+		name: "0005_timetabled_event_creators",
+		up: func(tx *gorm.DB) error {
+			return tx.Exec(`
+				UPDATE events e SET created_by = t.created_by
+				FROM timetable_entries t
+				WHERE e.timetable_entry_id = t.id AND e.created_by IS NULL
+			`).Error
+		},
+	},
+	{
+		// A student now has at most one active attendance record per event.
+		// Where check-ins, overrides and the absence finalizer raced to create
+		// several, the earliest is kept, as analytics already counted it and
+		// overrides already changed it, and the rest are soft-deleted.
+		name: "0006_unique_active_attendance_records",
+		up: func(tx *gorm.DB) error {
+			res := tx.Exec(`
+				UPDATE user_attendances ua SET deleted_at = NOW()
+				WHERE ua.deleted_at IS NULL AND EXISTS (
+					SELECT 1 FROM user_attendances keep
+					WHERE keep.attendance_id = ua.attendance_id AND keep.student_id = ua.student_id
+						AND keep.deleted_at IS NULL AND (keep.marked_time, keep.id) < (ua.marked_time, ua.id)
+				)
+			`)
+			if res.Error != nil {
+				return errors.New("failed to remove duplicate attendance records: " + res.Error.Error())
+			}
+			if res.RowsAffected > 0 {
+				logger.Infof("removed %d duplicate attendance record(s)", res.RowsAffected)
+			}
+			return tx.Exec(`
+				CREATE UNIQUE INDEX IF NOT EXISTS idx_user_attendances_event_student_active
+				ON user_attendances (attendance_id, student_id) WHERE deleted_at IS NULL
+			`).Error
+		},
+	},
+}
+
+// legacyProfile is a student or lecturer row from before migration 0003, with
+// the email and password it held itself.
+type legacyProfile struct {
+	Role      string
+	ID        uint
+	Email     string
+	Password  string
+	UpdatedAt time.Time
+	Deleted   bool
+}
+
+// mergeIdentities links every student and lecturer to one identity per email.
+// When a student and a lecturer share an email their password hashes always
+// differ, since bcrypt salts each hash, so the identity keeps the password of
+// the profile most likely to be in use: the most recently updated profile
+// that is not deleted, or the most recently updated profile if both are, with
+// the lecturer winning a tie. The other profile then signs in with that
+// password; each such merge is logged.
+func mergeIdentities(tx *gorm.DB) error {
+	var profiles []legacyProfile
+	if err := tx.Raw(`
+		SELECT 'lecturer' AS role, id, email, password, updated_at, deleted_at IS NOT NULL AS deleted FROM lecturers
+		UNION ALL
+		SELECT 'student' AS role, id, email, password, updated_at, deleted_at IS NOT NULL AS deleted FROM students`).
+		Scan(&profiles).Error; err != nil {
+		return errors.New("failed to retrieve profiles: " + err.Error())
+	}
+
+	byEmail := map[string][]legacyProfile{}
+	var emails []string
+	for _, profile := range profiles {
+		if _, seen := byEmail[profile.Email]; !seen {
+			emails = append(emails, profile.Email)
+		}
+		byEmail[profile.Email] = append(byEmail[profile.Email], profile)
+	}
+
+	for _, email := range emails {
+		group := byEmail[email]
+		winner := credentialsWinner(group)
+
+		var identity entities.Identity
+		err := tx.Unscoped().Where("email = ?", email).First(&identity).Error
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			identity = entities.Identity{Email: email, Password: winner.Password}
+			if err := tx.Create(&identity).Error; err != nil {
+				return errors.New("failed to create identity: " + err.Error())
+			}
+		case err != nil:
+			return errors.New("failed to retrieve identity: " + err.Error())
+		default:
+			if err := tx.Unscoped().Model(&identity).
+				Updates(map[string]interface{}{"password": winner.Password, "deleted_at": nil}).Error; err != nil {
+				return errors.New("failed to update identity: " + err.Error())
+			}
+		}
+
+		for _, profile := range group {
+			if err := tx.Table(profile.Role+"s").Where("id = ?", profile.ID).
+				Update("identity_id", identity.ID).Error; err != nil {
+				return errors.New("failed to link identity: " + err.Error())
+			}
+			if profile.Password != winner.Password {
+				logger.Infof("merged %s %d into identity %d for %s; it now signs in with the password of %s %d",
+					profile.Role, profile.ID, identity.ID, email, winner.Role, winner.ID)
+			}
+		}
+	}
+	return nil
+}
+
+// credentialsWinner returns the profile in group whose password the shared
+// identity keeps.
+func credentialsWinner(group []legacyProfile) legacyProfile {
+	winner := group[0]
+	for _, profile := range group[1:] {
+		if preferredCredentials(profile, winner) {
+			winner = profile
+		}
+	}
+	return winner
+}
+
+// preferredCredentials reports whether a's password should win over b's for
+// their shared identity.
+func preferredCredentials(a, b legacyProfile) bool {
+	if a.Deleted != b.Deleted {
+		return !a.Deleted
+	}
+	if !a.UpdatedAt.Equal(b.UpdatedAt) {
+		return a.UpdatedAt.After(b.UpdatedAt)
+	}
+	return a.Role == "lecturer" && b.Role != "lecturer"
+}
+
+// runMigrations applies the migrations that have not been applied yet, each in
+// its own transaction.
+func runMigrations(db *gorm.DB) error {
+	for _, m := range migrations {
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockKey).Error; err != nil {
+				return errors.New("failed to lock migrations: " + err.Error())
+			}
+
+			var applied int64
+			if err := tx.Model(&entities.SchemaMigration{}).Where("name = ?", m.name).Count(&applied).Error; err != nil {
+				return errors.New("failed to check migration " + m.name + ": " + err.Error())
+			}
+			if applied > 0 {
+				return nil
+			}
+
+			if err := m.up(tx); err != nil {
+				return errors.New("migration " + m.name + " failed: " + err.Error())
+			}
+			if err := tx.Create(&entities.SchemaMigration{Name: m.name, AppliedAt: time.Now()}).Error; err != nil {
+				return errors.New("failed to record migration " + m.name + ": " + err.Error())
+			}
+			logger.Infof("applied migration %s", m.name)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCredentialsWinner(t *testing.T) {
+	older := time.Date(2025, 1, 10, 9, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	tests := []struct {
+		name  string
+		group []legacyProfile
+		want  string // Role of the profile whose password is kept
+	}{
+		{
+			name:  "single profile",
+			group: []legacyProfile{{Role: "student", UpdatedAt: older}},
+			want:  "student",
+		},
+		{
+			name:  "most recently updated",
+			group: []legacyProfile{{Role: "lecturer", UpdatedAt: older}, {Role: "student", UpdatedAt: newer}},
+			want:  "student",
+		},
+		{
+			name:  "active beats more recently updated deleted",
+			group: []legacyProfile{{Role: "lecturer", UpdatedAt: newer, Deleted: true}, {Role: "student", UpdatedAt: older}},
+			want:  "student",
+		},
+		{
+			name:  "most recently updated when both deleted",
+			group: []legacyProfile{{Role: "lecturer", UpdatedAt: older, Deleted: true}, {Role: "student", UpdatedAt: newer, Deleted: true}},
+			want:  "student",
+		},
+		{
+			name:  "lecturer wins a tie",
+			group: []legacyProfile{{Role: "student", UpdatedAt: older}, {Role: "lecturer", UpdatedAt: older}},
+			want:  "lecturer",
+		},
+		{
+			name:  "tie regardless of order",
+			group: []legacyProfile{{Role: "lecturer", UpdatedAt: older}, {Role: "student", UpdatedAt: older}},
+			want:  "lecturer",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := credentialsWinner(tt.group); got.Role != tt.want {
+				t.Errorf("credentialsWinner = %s, want %s", got.Role, tt.want)
+			}
+		})
+	}
+}
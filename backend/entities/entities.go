@@ -6,39 +6,180 @@ import (
 	"gorm.io/gorm"
 )
 
+// Identity is the login credential of a person. Student and lecturer profiles
+// link to it, so one person can hold both roles with a single email and
+// password. The identity is the only place the email and password hash are
+// stored.
+type Identity struct {
+	gorm.Model
+	Email    string `gorm:"column:email;uniqueIndex:idx_identities_email_active,where:deleted_at IS NULL;not null"` // Unique among identities that are not deleted
+	Password string `gorm:"column:password;not null"`
+}
+
 // Student represents a student in the system.
 type Student struct {
 	gorm.Model
-	FirstName    string `gorm:"column:first_name;not null"`
-	LastName     string `gorm:"column:last_name;not null"`
-	Email        string `gorm:"column:email;uniqueIndex;not null"`
-	Role         string `gorm:"column:role;default:'student'"`
-	Password     string `gorm:"column:password;not null"`
-	MatricNumber string `gorm:"uniqueIndex;not null;column:matric_number;type:varchar(50)"`
+	IdentityID   uint      `gorm:"uniqueIndex:idx_students_identity_id_active,where:deleted_at IS NULL;column:identity_id"` // Login email and password; NOT NULL is set by migration 0003
+	Identity     *Identity `gorm:"foreignKey:IdentityID"`                                                                   // Loaded with Joins("Identity") when the email or password is needed
+	FirstName    string    `gorm:"column:first_name;not null"`
+	LastName     string    `gorm:"column:last_name;not null"`
+	Role         string    `gorm:"column:role;default:'student'"`
+	MatricNumber string    `gorm:"uniqueIndex:idx_students_matric_number_active,where:deleted_at IS NULL;not null;column:matric_number;type:varchar(50)"` // Unique among students that are not deleted
+	Faculty      string    `gorm:"index;column:faculty"`
+	Department   string    `gorm:"index;column:department"`
+	Programme    string    `gorm:"column:programme"`                     // Degree programme, e.g. "B.Sc. Computer Science"
+	Level        int       `gorm:"index;column:level"`                   // Year of study as a level, e.g. 100, 200; 0 when unknown
+	EntrySession string    `gorm:"column:entry_session;type:varchar(9)"` // Academic session of admission, e.g. "2024/2025"
+
+	RequiresEmailVerification bool       `gorm:"column:requires_email_verification;default:false"` // Set on self-registered accounts; older accounts are trusted
+	EmailVerifiedAt           *time.Time `gorm:"column:email_verified_at"`
+
+	SuspendedAt     *time.Time `gorm:"column:suspended_at"` // Set while an admin has suspended the account
+	SuspendedReason string     `gorm:"column:suspended_reason"`
 }
 
 // Lecturer represents a lecturer in the system.
 type Lecturer struct {
 	gorm.Model
-	FirstName  string `gorm:"column:first_name;not null"`
-	LastName   string `gorm:"column:last_name;not null"`
-	Email      string `gorm:"column:email;uniqueIndex;not null"`
-	Role       string `gorm:"column:role;default:'lecturer'"`
-	Password   string `gorm:"column:password;not null"`
-	Department string `gorm:"column:department;not null"`
-	StaffID    string `gorm:"uniqueIndex;column:staff_id;not null;type:varchar(50)"`
+	IdentityID uint      `gorm:"uniqueIndex:idx_lecturers_identity_id_active,where:deleted_at IS NULL;column:identity_id"` // Login email and password; NOT NULL is set by migration 0003
+	Identity   *Identity `gorm:"foreignKey:IdentityID"`                                                                    // Loaded with Joins("Identity") when the email or password is needed
+	FirstName  string    `gorm:"column:first_name;not null"`
+	LastName   string    `gorm:"column:last_name;not null"`
+	Role       string    `gorm:"column:role;default:'lecturer'"`
+	Department string    `gorm:"column:department;not null"`
+	StaffID    string    `gorm:"uniqueIndex:idx_lecturers_staff_id_active,where:deleted_at IS NULL;column:staff_id;not null;type:varchar(50)"` // Unique among lecturers that are not deleted
+
+	RequiresEmailVerification bool       `gorm:"column:requires_email_verification;default:false"` // Set on self-registered accounts; older accounts are trusted
+	EmailVerifiedAt           *time.Time `gorm:"column:email_verified_at"`
+
+	SuspendedAt     *time.Time `gorm:"column:suspended_at"` // Set while an admin has suspended the account
+	SuspendedReason string     `gorm:"column:suspended_reason"`
+}
+
+// Admin represents a system administrator. Admins are created from the
+// ADMIN_EMAIL and ADMIN_PASSWORD environment variables or by another admin.
+type Admin struct {
+	gorm.Model
+	FirstName string `gorm:"column:first_name;not null"`
+	LastName  string `gorm:"column:last_name;not null"`
+	Email     string `gorm:"column:email;uniqueIndex;not null"`
+	Role      string `gorm:"column:role;default:'admin'"`
+	Password  string `gorm:"column:password;not null"`
+}
+
+// RoleAssignment grants a lecturer an additional role, such as head of
+// department or teaching assistant, limited to a scope. The role's permissions
+// are defined in pkg/rbac.
+type RoleAssignment struct {
+	gorm.Model
+	UserID     int    `gorm:"uniqueIndex:idx_role_assignments_unique;index:idx_role_assignments_user;column:user_id;not null"`
+	UserRole   string `gorm:"uniqueIndex:idx_role_assignments_unique;index:idx_role_assignments_user;column:user_role;not null"` // Account role of the grantee
+	Role       string `gorm:"uniqueIndex:idx_role_assignments_unique;column:role;not null"`
+	ScopeType  string `gorm:"uniqueIndex:idx_role_assignments_unique;column:scope_type;not null"`  // global, department or course
+	ScopeValue string `gorm:"uniqueIndex:idx_role_assignments_unique;column:scope_value;not null"` // Department name or course code; empty for global
+	GrantedBy  int    `gorm:"column:granted_by"`                                                   // Admin who made the assignment
+}
+
+// Course represents an academic course owned by one or more lecturers.
+type Course struct {
+	gorm.Model
+	Code        string     `gorm:"uniqueIndex:idx_courses_code_active,where:deleted_at IS NULL;column:code;not null;type:varchar(20)"` // Unique among courses that are not deleted
+	Title       string     `gorm:"column:title;not null"`
+	Department  string     `gorm:"column:department;not null"`
+	CreditUnits int        `gorm:"column:credit_units;default:0"`
+	Lecturers   []Lecturer `gorm:"many2many:course_lecturers;"` // Lecturers allowed to manage the course
+}
+
+// Enrollment links a student to a course they are expected to attend.
+type Enrollment struct {
+	gorm.Model
+	CourseID  int     `gorm:"uniqueIndex:idx_enrollments_course_student;column:course_id;not null"`
+	Course    Course  `gorm:"foreignKey:CourseID;references:ID"`
+	StudentID int     `gorm:"uniqueIndex:idx_enrollments_course_student;index;column:student_id;not null"`
+	Student   Student `gorm:"foreignKey:StudentID;references:ID"`
 }
 
 // Event represents a class session.
 type Event struct {
 	gorm.Model
-	EventName   string    `gorm:"column:event_name"`
-	StartTime   time.Time `gorm:"column:start_time"`
-	EndTime     time.Time `gorm:"column:end_time"`
-	Venue       string    `gorm:"column:venue"`
-	QRCodeToken string    `gorm:"column:qr_code_token"` // Unique token used to generate the QR code
+	CourseID    *int       `gorm:"index;column:course_id"` // Nullable for events created before courses existed
+	Course      *Course    `gorm:"foreignKey:CourseID;references:ID"`
+	EventName   string     `gorm:"column:event_name"`
+	StartTime   time.Time  `gorm:"column:start_time"`
+	EndTime     time.Time  `gorm:"column:end_time"`
+	Venue       string     `gorm:"column:venue"`
+	QRCodeToken string     `gorm:"column:qr_code_token"`            // Unique token used to generate the QR code
+	QRMode      string     `gorm:"column:qr_mode;default:'static'"` // [static, rotating]
+	QRRotation  int        `gorm:"column:qr_rotation_seconds"`      // Rotation period in seconds for rotating events
+	CreatedBy   *int       `gorm:"index;column:created_by"`         // Lecturer who created the event; nil for legacy rows
+	Creator     *Lecturer  `gorm:"foreignKey:CreatedBy;references:ID"`
+	CoLecturers []Lecturer `gorm:"many2many:event_co_lecturers;"` // Lecturers granted access by the creator
+
+	AbsencesFinalizedAt *time.Time `gorm:"index;column:absences_finalized_at"` // Set once absent rows have been written for the event
+
+	VenueID      *int   `gorm:"index;column:venue_id"` // Managed venue used for geofencing; Venue holds its display name
+	Location     *Venue `gorm:"foreignKey:VenueID;references:ID"`
+	GeofenceMode string `gorm:"column:geofence_mode;default:'off'"` // [off, lenient, strict]
+
+	GracePeriodMinutes int  `gorm:"column:grace_period_minutes"` // Check-ins after this many minutes are late
+	LateCutoffMinutes  *int `gorm:"column:late_cutoff_minutes"`  // Check-ins after this many minutes are rejected; nil accepts until EndTime
+
+	SemesterID *int `gorm:"index;column:semester_id"` // Semester whose dates contain StartTime; kept in sync with the calendar
+
+	Status       string     `gorm:"index;column:status;default:'scheduled'"` // [scheduled, cancelled]
+	CancelledAt  *time.Time `gorm:"column:cancelled_at"`                     // Set when a lecturer cancels the event; timetable cancellations leave it nil
+	CancelReason string     `gorm:"column:cancel_reason"`
+
+	// Lecturers can close check-in before the event ends, and reopen it for a
+	// few minutes once it has closed, e.g. for students held up elsewhere.
+	CheckInClosedAt *time.Time `gorm:"column:check_in_closed_at"` // Check-ins from this time on are rejected
+	ReopenedUntil   *time.Time `gorm:"column:reopened_until"`     // Check-ins are accepted until this time whatever the cutoff or end time
+
+	// Events generated from a timetable entry record it and the date of the
+	// occurrence, which stays the same when the occurrence is rescheduled.
+	TimetableEntryID *int       `gorm:"uniqueIndex:idx_events_timetable_occurrence;column:timetable_entry_id"`
+	OccurrenceDate   *time.Time `gorm:"uniqueIndex:idx_events_timetable_occurrence;column:occurrence_date;type:date"`
 }
 
+// Event statuses. Cancelled events accept no check-ins and are left out of
+// attendance rates.
+const (
+	EventScheduled = "scheduled"
+	EventCancelled = "cancelled"
+)
+
+// Geofence modes for events.
+const (
+	GeofenceOff     = "off"     // Location is ignored
+	GeofenceLenient = "lenient" // Check-ins outside the venue are accepted but flagged
+	GeofenceStrict  = "strict"  // Check-ins outside the venue are rejected
+)
+
+// Reasons a check-in is flagged for review under lenient geofencing.
+const (
+	FlagOutsideGeofence = "outside_geofence" // Reported location is farther from the venue than its radius
+	FlagLocationMissing = "location_missing" // The device did not report a location
+	FlagLowAccuracy     = "low_accuracy"     // The reported location is too imprecise to trust
+	FlagAccuracyMissing = "accuracy_missing" // The device reported a location without its accuracy
+)
+
+// Venue represents a physical location where events are held.
+type Venue struct {
+	gorm.Model
+	Name         string  `gorm:"uniqueIndex;column:name"`
+	Building     string  `gorm:"column:building"`
+	Latitude     float64 `gorm:"column:latitude"`
+	Longitude    float64 `gorm:"column:longitude"`
+	RadiusMeters float64 `gorm:"column:radius_meters"` // Check-ins within this distance of the coordinates are on site
+	CreatedBy    *int    `gorm:"index;column:created_by"`
+}
+
+// QR modes for events.
+const (
+	QRModeStatic   = "static"   // One UUID token valid for the whole event
+	QRModeRotating = "rotating" // Signed tokens that expire every QRRotation seconds
+)
+
 // Attendance represents the overall attendance record for an event.
 type Attendance struct {
 	gorm.Model
@@ -47,12 +188,310 @@ type Attendance struct {
 	Records []UserAttendance `gorm:"foreignKey:AttendanceID"`
 }
 
-// UserAttendance represents an individual attendance record.
+// Attendance statuses. Present and late count as attended; excused sessions are
+// left out of attendance rates entirely.
+const (
+	AttendanceStatusPresent = "present"
+	AttendanceStatusLate    = "late"
+	AttendanceStatusAbsent  = "absent"
+	AttendanceStatusExcused = "excused"
+)
+
+// Attendance record sources.
+const (
+	AttendanceSourceCheckIn = "check_in" // Created by a student scanning the QR code
+	AttendanceSourceSystem  = "system"   // Created by the absence finalizer after the event ended
+	AttendanceSourceManual  = "manual"   // Set by a lecturer
+)
+
+// UserAttendance represents an individual attendance record. A student has at
+// most one active record per event; the partial unique index enforcing this,
+// idx_user_attendances_event_student_active, is created by migration 0006 once
+// older duplicates are removed, so it is not declared here.
 type UserAttendance struct {
 	gorm.Model
 	AttendanceID int       `gorm:"index;column:attendance_id"`
 	StudentID    int       `gorm:"index;column:student_id"` // References the Student's ID
 	Student      Student   `gorm:"foreignKey:StudentID;references:ID"`
-	Status       string    `gorm:"column:status"`      // [student, lecturer]
-	MarkedTime   time.Time `gorm:"column:marked_time"` // The time when attendance was recorded
+	Status       string    `gorm:"column:status"`                          // [present, late, absent, excused]
+	MarkedTime   time.Time `gorm:"column:marked_time"`                     // The time when attendance was recorded
+	Source       string    `gorm:"column:source;default:'check_in';index"` // How the record was created [check_in, system, manual]
+
+	DistanceMeters *float64 `gorm:"column:distance_meters"` // Distance from the venue at check-in, when reported
+	Flagged        bool     `gorm:"column:flagged;index"`   // Accepted under lenient geofencing but needs review
+	FlagReason     string   `gorm:"column:flag_reason"`     // [outside_geofence, location_missing, low_accuracy, accuracy_missing]
+}
+
+// Attendance audit actions.
+const (
+	AuditActionCreate = "create" // A lecturer created a record for a student
+	AuditActionUpdate = "update" // A lecturer changed an existing record's status
+	AuditActionVoid   = "void"   // A lecturer removed a record
+)
+
+// AttendanceAudit records a lecturer's manual change to an attendance record.
+type AttendanceAudit struct {
+	gorm.Model
+	AttendanceRecordID uint      `gorm:"index;column:attendance_record_id"` // References UserAttendance.ID; the record may since be voided
+	EventID            int       `gorm:"index;column:event_id"`
+	StudentID          int       `gorm:"index;column:student_id"`
+	Student            Student   `gorm:"foreignKey:StudentID;references:ID"`
+	Action             string    `gorm:"column:action"`          // [create, update, void]
+	PreviousStatus     string    `gorm:"column:previous_status"` // Empty when the record was created
+	NewStatus          string    `gorm:"column:new_status"`      // Empty when the record was voided
+	Reason             string    `gorm:"column:reason"`
+	ChangedBy          int       `gorm:"index;column:changed_by"` // Lecturer who made the change
+	Lecturer           Lecturer  `gorm:"foreignKey:ChangedBy;references:ID"`
+	ChangedAt          time.Time `gorm:"column:changed_at"`
+}
+
+// Event audit actions.
+const (
+	EventAuditUpdate = "update" // A lecturer changed the event's times or venue
+	EventAuditCancel = "cancel" // A lecturer cancelled the event
+	EventAuditClose  = "close"  // A lecturer closed check-in early
+	EventAuditReopen = "reopen" // A lecturer reopened check-in for a short window
+)
+
+// EventAudit records a lecturer's change to an event's schedule or check-in.
+type EventAudit struct {
+	gorm.Model
+	EventID   int       `gorm:"index;column:event_id"`
+	Action    string    `gorm:"column:action"`  // [update, cancel, close, reopen]
+	Changes   string    `gorm:"column:changes"` // JSON object mapping each changed field to its "from" and "to" values
+	Reason    string    `gorm:"column:reason"`
+	ChangedBy int       `gorm:"index;column:changed_by"` // Lecturer who made the change
+	Lecturer  Lecturer  `gorm:"foreignKey:ChangedBy;references:ID"`
+	ChangedAt time.Time `gorm:"column:changed_at"`
+}
+
+// RefreshToken is one link in a login session's chain of refresh tokens. Each
+// refresh rotates the token: the old row is marked used and a new row joins the
+// same family. Presenting a used token again revokes the whole family.
+type RefreshToken struct {
+	gorm.Model
+	TokenHash       string     `gorm:"uniqueIndex;column:token_hash;not null"` // SHA-256 of the token; the token itself is never stored
+	FamilyID        string     `gorm:"index;column:family_id;not null"`        // Shared by every token issued from one login
+	UserID          int        `gorm:"index:idx_refresh_tokens_user;column:user_id;not null"`
+	Role            string     `gorm:"index:idx_refresh_tokens_user;column:role;not null"`
+	AccessJTI       string     `gorm:"index;column:access_jti"` // ID of the access token issued alongside this refresh token
+	AccessExpiresAt time.Time  `gorm:"column:access_expires_at"`
+	ExpiresAt       time.Time  `gorm:"index;column:expires_at"`
+	UsedAt          *time.Time `gorm:"column:used_at"`    // Set when the token is exchanged for a new one
+	RevokedAt       *time.Time `gorm:"column:revoked_at"` // Set on logout or reuse detection
+	RevokedReason   string     `gorm:"column:revoked_reason"`
+	UserAgent       string     `gorm:"column:user_agent"`
+	IPAddress       string     `gorm:"column:ip_address"`
+}
+
+// RevokedAccessToken denylists an access token by its jti until it expires.
+type RevokedAccessToken struct {
+	gorm.Model
+	JTI       string    `gorm:"uniqueIndex;column:jti;not null"`
+	UserID    int       `gorm:"column:user_id"`
+	Role      string    `gorm:"column:role"`
+	ExpiresAt time.Time `gorm:"index;column:expires_at"` // Rows can be purged after this time
+}
+
+// TwoFactor is a user's TOTP enrollment. The secret is encrypted at rest, and
+// the enrollment only takes effect once a code from it has been verified.
+type TwoFactor struct {
+	gorm.Model
+	UserID       int        `gorm:"uniqueIndex:idx_two_factors_user;column:user_id;not null"`
+	Role         string     `gorm:"uniqueIndex:idx_two_factors_user;column:role;not null"`
+	Secret       string     `gorm:"column:secret;not null"` // AES-GCM encrypted base32 secret
+	EnabledAt    *time.Time `gorm:"column:enabled_at"`      // Nil while enrollment is pending
+	LastUsedStep int64      `gorm:"column:last_used_step"`  // Time step of the last accepted code; codes at or before it are replays
+}
+
+// RecoveryCode is a single-use code that stands in for a TOTP code when the
+// user has lost their authenticator. Only its hash is stored.
+type RecoveryCode struct {
+	gorm.Model
+	UserID   int        `gorm:"index:idx_recovery_codes_user;column:user_id;not null"`
+	Role     string     `gorm:"index:idx_recovery_codes_user;column:role;not null"`
+	CodeHash string     `gorm:"column:code_hash;not null"`
+	UsedAt   *time.Time `gorm:"column:used_at"`
+}
+
+// LoginAttempt counts recent failed logins for one account or client IP so
+// password guessing can be locked out. Keys are built by pkg/lockout.
+type LoginAttempt struct {
+	Key           string     `gorm:"primaryKey;column:attempt_key;type:varchar(320)"` // e.g. account:student:<email> or ip:<address>
+	Failures      int        `gorm:"column:failures;not null;default:0"`
+	LastFailureAt time.Time  `gorm:"index;column:last_failure_at"`
+	LockedUntil   *time.Time `gorm:"column:locked_until"`
+	UpdatedAt     time.Time
+}
+
+// SigningKey is a generated JWT signing key shared by every replica. Keys
+// are created and rotated by pkg/signing; the private key is encrypted with
+// JWT_SECRET.
+type SigningKey struct {
+	Kid        string    `gorm:"primaryKey;column:kid;type:varchar(64)"`
+	Algorithm  string    `gorm:"column:algorithm;type:varchar(16);not null"`
+	PrivateKey string    `gorm:"column:private_key;type:text;not null"`
+	PublicKey  string    `gorm:"column:public_key;type:text;not null"`
+	CreatedAt  time.Time `gorm:"index;column:created_at"`
+}
+
+// PasswordResetToken is a single-use token emailed to a user who forgot their
+// password. Issuing a new token invalidates any earlier unused ones.
+type PasswordResetToken struct {
+	gorm.Model
+	TokenHash   string     `gorm:"uniqueIndex;column:token_hash;not null"` // SHA-256 of the token; the token itself is never stored
+	UserID      int        `gorm:"index:idx_password_reset_tokens_user;column:user_id;not null"`
+	Role        string     `gorm:"index:idx_password_reset_tokens_user;column:role;not null"`
+	ExpiresAt   time.Time  `gorm:"index;column:expires_at"`
+	UsedAt      *time.Time `gorm:"column:used_at"` // Set when the token is redeemed or superseded
+	RequestedIP string     `gorm:"column:requested_ip"`
+}
+
+// ImportJob records a bulk import of students or lecturers from a CSV file and
+// its progress. Large files are processed in the background and polled.
+type ImportJob struct {
+	gorm.Model
+	Role          string     `gorm:"column:role;not null"`                  // student or lecturer
+	Status        string     `gorm:"index;column:status;default:'pending'"` // [pending, running, completed, failed]
+	DryRun        bool       `gorm:"column:dry_run"`                        // Validate only; nothing is written
+	FileName      string     `gorm:"column:file_name"`
+	TotalRows     int        `gorm:"column:total_rows"`
+	ProcessedRows int        `gorm:"column:processed_rows"`
+	CreatedCount  int        `gorm:"column:created_count"` // Rows that created (or would create) an account
+	UpdatedCount  int        `gorm:"column:updated_count"` // Rows that updated (or would update) an account
+	FailedCount   int        `gorm:"column:failed_count"`
+	Errors        string     `gorm:"column:errors;type:text"` // JSON array of per-row errors
+	FailureReason string     `gorm:"column:failure_reason"`   // Set when the whole job failed
+	CreatedBy     int        `gorm:"column:created_by"`       // Admin who started the import
+	StartedAt     *time.Time `gorm:"column:started_at"`
+	FinishedAt    *time.Time `gorm:"column:finished_at"`
+}
+
+// Import job statuses.
+const (
+	ImportPending   = "pending"
+	ImportRunning   = "running"
+	ImportCompleted = "completed"
+	ImportFailed    = "failed"
+)
+
+// EmailVerification is a single-use token emailed to an address to prove the
+// user controls it. New accounts cannot log in until their address is
+// verified, and an email change only takes effect once the token sent to the
+// new address is redeemed.
+type EmailVerification struct {
+	gorm.Model
+	TokenHash string     `gorm:"uniqueIndex;column:token_hash;not null"` // SHA-256 of the token; the token itself is never stored
+	UserID    int        `gorm:"index:idx_email_verifications_user;column:user_id;not null"`
+	Role      string     `gorm:"index:idx_email_verifications_user;column:role;not null"`
+	Email     string     `gorm:"column:email;not null"`   // Address being verified
+	Purpose   string     `gorm:"column:purpose;not null"` // registration or email_change
+	ExpiresAt time.Time  `gorm:"index;column:expires_at"`
+	UsedAt    *time.Time `gorm:"column:used_at"` // Set when the token is redeemed or superseded
+}
+
+// Purposes of email verification tokens.
+const (
+	EmailVerificationRegistration = "registration" // Confirms the address a new account registered with
+	EmailVerificationChange       = "email_change" // Confirms a new address before it replaces the old one
+)
+
+// AcademicSession is an academic year such as "2024/2025". Sessions do not
+// overlap and are divided into semesters.
+type AcademicSession struct {
+	gorm.Model
+	Name      string     `gorm:"uniqueIndex;column:name;type:varchar(9)"` // e.g. 2024/2025
+	StartDate time.Time  `gorm:"column:start_date;type:date"`
+	EndDate   time.Time  `gorm:"column:end_date;type:date"` // Inclusive
+	Semesters []Semester `gorm:"foreignKey:SessionID;references:ID"`
+}
+
+// Semester is a term within an academic session. Semesters do not overlap, and
+// events are tagged with the semester whose dates contain their start time.
+type Semester struct {
+	gorm.Model
+	SessionID     int        `gorm:"index;column:session_id;not null"`
+	Name          string     `gorm:"column:name"` // e.g. First Semester
+	StartDate     time.Time  `gorm:"index;column:start_date;type:date"`
+	EndDate       time.Time  `gorm:"column:end_date;type:date"` // Inclusive; covers the exam period
+	TeachingWeeks int        `gorm:"column:teaching_weeks"`
+	ExamStartDate *time.Time `gorm:"column:exam_start_date;type:date"`
+	ExamEndDate   *time.Time `gorm:"column:exam_end_date;type:date"`
+}
+
+// Holiday is a public holiday or break during which classes are not expected.
+type Holiday struct {
+	gorm.Model
+	Name      string    `gorm:"column:name"`
+	StartDate time.Time `gorm:"index;column:start_date;type:date"`
+	EndDate   time.Time `gorm:"column:end_date;type:date"` // Inclusive
+}
+
+// TimetableEntry is a recurring class of a course within a semester, such as
+// every Monday at 10:00. Events are generated from it ahead of time.
+type TimetableEntry struct {
+	gorm.Model
+	CourseID        int       `gorm:"index;column:course_id;not null"`
+	Course          *Course   `gorm:"foreignKey:CourseID;references:ID"`
+	SemesterID      int       `gorm:"index;column:semester_id;not null"`
+	Semester        *Semester `gorm:"foreignKey:SemesterID;references:ID"`
+	Weekday         int       `gorm:"column:weekday"`                    // time.Weekday: 0 is Sunday
+	StartTime       string    `gorm:"column:start_time;type:varchar(5)"` // HH:MM in the timetable time zone
+	DurationMinutes int       `gorm:"column:duration_minutes"`
+
+	// The entry meets every IntervalWeeks weeks from FirstWeek to LastWeek,
+	// counted in teaching weeks of the semester from 1.
+	IntervalWeeks int `gorm:"column:interval_weeks;default:1"`
+	FirstWeek     int `gorm:"column:first_week"`
+	LastWeek      int `gorm:"column:last_week"`
+
+	// Settings copied to every generated event.
+	Venue              string `gorm:"column:venue"`
+	VenueID            *int   `gorm:"index;column:venue_id"`
+	GeofenceMode       string `gorm:"column:geofence_mode;default:'off'"`
+	QRMode             string `gorm:"column:qr_mode;default:'static'"`
+	QRRotation         int    `gorm:"column:qr_rotation_seconds"`
+	GracePeriodMinutes int    `gorm:"column:grace_period_minutes"`
+	LateCutoffMinutes  *int   `gorm:"column:late_cutoff_minutes"`
+
+	CreatedBy *int `gorm:"index;column:created_by"`
+}
+
+// TimetableException cancels or reschedules one occurrence of a timetable
+// entry, identified by the date it would have met on.
+type TimetableException struct {
+	gorm.Model
+	EntryID   int        `gorm:"uniqueIndex:idx_timetable_exceptions_entry_date;column:entry_id;not null"`
+	Date      time.Time  `gorm:"uniqueIndex:idx_timetable_exceptions_entry_date;column:date;type:date"`
+	Action    string     `gorm:"column:action;not null"` // cancel or reschedule
+	StartTime *time.Time `gorm:"column:start_time"`      // New start of a rescheduled occurrence
+	EndTime   *time.Time `gorm:"column:end_time"`
+	Venue     string     `gorm:"column:venue"` // New venue of a rescheduled occurrence; empty keeps the entry's
+	VenueID   *int       `gorm:"column:venue_id"`
+	Reason    string     `gorm:"column:reason"`
+	CreatedBy *int       `gorm:"index;column:created_by"`
+}
+
+// Timetable exception actions.
+const (
+	TimetableCancel     = "cancel"
+	TimetableReschedule = "reschedule"
+)
+
+// CalendarFeed holds the secret token of a student's or lecturer's iCalendar
+// feed. Calendar apps cannot send an Authorization header, so the token in the
+// feed URL is the only credential; issuing a new one retires the old URL.
+type CalendarFeed struct {
+	gorm.Model
+	UserID     int        `gorm:"uniqueIndex:idx_calendar_feeds_user;column:user_id;not null"`
+	Role       string     `gorm:"uniqueIndex:idx_calendar_feeds_user;column:role;not null"`
+	TokenHash  string     `gorm:"uniqueIndex;column:token_hash;not null"` // SHA-256 of the token; the token itself is never stored
+	LastUsedAt *time.Time `gorm:"column:last_used_at"`                    // Last time a calendar app fetched the feed
+}
+
+// SchemaMigration records a one-off migration that has been applied; see
+// config/database.
+type SchemaMigration struct {
+	Name      string    `gorm:"primaryKey;column:name;type:varchar(100)"`
+	AppliedAt time.Time `gorm:"column:applied_at"`
 }
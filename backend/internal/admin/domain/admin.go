@@ -0,0 +1,140 @@
+package admin
+
+import "github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+
+// Request DTOs
+
+// CreateAdminDTO represents the request to create another admin.
+type CreateAdminDTO struct {
+	FirstName string `json:"first_name" binding:"required"`
+	LastName  string `json:"last_name" binding:"required"`
+	Email     string `json:"email" binding:"required,email"`
+	Password  string `json:"password" binding:"required,min=8"`
+}
+
+// SuspendUserDTO represents the request to suspend a student or lecturer.
+type SuspendUserDTO struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// AssignRoleDTO represents the request to assign a role to a lecturer.
+type AssignRoleDTO struct {
+	LecturerID int    `json:"lecturer_id" binding:"required"`
+	Role       string `json:"role" binding:"required"`
+	ScopeType  string `json:"scope_type" binding:"required,oneof=global department course"`
+	ScopeValue string `json:"scope_value"` // Department name or course code; omitted for global
+}
+
+// UserFilter narrows a user listing.
+type UserFilter struct {
+	Search     string // Matches name, email, matric number or staff ID
+	Status     string // "active", "suspended" or empty for both
+	Department string
+	Faculty    string // Students only
+	Level      int    // Students only; 0 for any level
+	Offset     int
+	Limit      int
+}
+
+// StudentImportRow is one validated student row of an import file.
+type StudentImportRow struct {
+	Line         int // Line in the CSV file, for error reporting
+	FirstName    string
+	LastName     string
+	Email        string
+	MatricNumber string
+	Department   string
+	Level        int
+	Faculty      string // Optional; blank leaves an existing student's value
+	Programme    string // Optional; blank leaves an existing student's value
+	EntrySession string // Optional; blank leaves an existing student's value
+}
+
+// LecturerImportRow is one validated lecturer row of an import file.
+type LecturerImportRow struct {
+	Line       int // Line in the CSV file, for error reporting
+	FirstName  string
+	LastName   string
+	Email      string
+	StaffID    string
+	Department string
+}
+
+// Response DTOs
+
+// AdminResponse represents an admin account.
+type AdminResponse struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	CreatedAt string `json:"created_at"`
+}
+
+// UserResponse represents a student or lecturer as seen by an admin.
+type UserResponse struct {
+	ID              int     `json:"id"`
+	Role            string  `json:"role"`
+	FirstName       string  `json:"first_name"`
+	LastName        string  `json:"last_name"`
+	Email           string  `json:"email"`
+	MatricNumber    string  `json:"matric_number,omitempty"`
+	StaffID         string  `json:"staff_id,omitempty"`
+	Faculty         string  `json:"faculty,omitempty"`
+	Department      string  `json:"department,omitempty"`
+	Programme       string  `json:"programme,omitempty"`
+	Level           int     `json:"level,omitempty"`
+	EntrySession    string  `json:"entry_session,omitempty"`
+	EmailVerified   bool    `json:"email_verified"`
+	Suspended       bool    `json:"suspended"`
+	SuspendedAt     *string `json:"suspended_at,omitempty"`
+	SuspendedReason string  `json:"suspended_reason,omitempty"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+// UserListResponse is a page of users.
+type UserListResponse struct {
+	Users    []UserResponse `json:"users"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	PageSize int            `json:"page_size"`
+}
+
+// RoleAssignmentResponse represents a role assigned to a lecturer.
+type RoleAssignmentResponse struct {
+	ID          int               `json:"id"`
+	LecturerID  int               `json:"lecturer_id"`
+	Role        string            `json:"role"`
+	Scope       rbac.Scope        `json:"scope"`
+	Permissions []rbac.Permission `json:"permissions"`
+	GrantedByID int               `json:"granted_by_id"`
+	AssignedAt  string            `json:"assigned_at"`
+}
+
+// ImportRowError explains why one row of an import file was rejected.
+type ImportRowError struct {
+	Line    int    `json:"line"`
+	Key     string `json:"key,omitempty"` // Matric number or staff ID, when the row has one
+	Message string `json:"message"`
+}
+
+// ImportJobResponse represents a bulk import and its progress.
+type ImportJobResponse struct {
+	ID            int              `json:"id"`
+	Role          string           `json:"role"`
+	Status        string           `json:"status"`
+	DryRun        bool             `json:"dry_run"`
+	FileName      string           `json:"file_name,omitempty"`
+	TotalRows     int              `json:"total_rows"`
+	ProcessedRows int              `json:"processed_rows"`
+	Progress      float64          `json:"progress"` // Percentage of rows processed
+	Created       int              `json:"created"`
+	Updated       int              `json:"updated"`
+	Failed        int              `json:"failed"`
+	Errors        []ImportRowError `json:"errors,omitempty"`
+	FailureReason string           `json:"failure_reason,omitempty"`
+	CreatedByID   int              `json:"created_by_id"`
+	CreatedAt     string           `json:"created_at"`
+	StartedAt     *string          `json:"started_at,omitempty"`
+	FinishedAt    *string          `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	admin "github.com/Dom-HTG/attendance-management-system/internal/admin/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUserNotFound is returned when a student or lecturer lookup matches no rows.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrAdminNotFound is returned when an admin lookup matches no rows.
+	ErrAdminNotFound = errors.New("admin not found")
+)
+
+// AdminRepoInterface defines the repository interface for admin accounts and
+// administrative user management.
+type AdminRepoInterface interface {
+	CreateAdmin(admin *entities.Admin) error
+	GetAdminByEmail(email string) (*entities.Admin, error)
+	ListAdmins() ([]*entities.Admin, error)
+	ListStudents(filter admin.UserFilter) ([]*entities.Student, int64, error)
+	ListLecturers(filter admin.UserFilter) ([]*entities.Lecturer, int64, error)
+	GetStudentByID(studentID int) (*entities.Student, error)
+	GetLecturerByID(lecturerID int) (*entities.Lecturer, error)
+	SetSuspension(role string, userID int, suspendedAt *time.Time, reason string) error
+	DeleteUser(role string, userID int) error
+}
+
+// AdminRepo implements the AdminRepoInterface.
+type AdminRepo struct {
+	db *gorm.DB
+}
+
+// NewAdminRepo returns a new instance of AdminRepo.
+func NewAdminRepo(db *gorm.DB) *AdminRepo {
+	return &AdminRepo{
+		db: db,
+	}
+}
+
+// CreateAdmin creates a new admin account.
+func (ar *AdminRepo) CreateAdmin(admin *entities.Admin) error {
+	if err := ar.db.Create(admin).Error; err != nil {
+		return errors.New("failed to create admin: " + err.Error())
+	}
+	return nil
+}
+
+// GetAdminByEmail retrieves an admin by email.
+func (ar *AdminRepo) GetAdminByEmail(email string) (*entities.Admin, error) {
+	var found entities.Admin
+	if err := ar.db.Where("email = ?", email).First(&found).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAdminNotFound
+		}
+		return nil, errors.New("failed to retrieve admin: " + err.Error())
+	}
+	return &found, nil
+}
+
+// ListAdmins retrieves every admin ordered by email.
+func (ar *AdminRepo) ListAdmins() ([]*entities.Admin, error) {
+	var admins []*entities.Admin
+	if err := ar.db.Order("email ASC").Find(&admins).Error; err != nil {
+		return nil, errors.New("failed to retrieve admins: " + err.Error())
+	}
+	return admins, nil
+}
+
+// ListStudents retrieves a page of students matching the filter and the total
+// number of matches.
+func (ar *AdminRepo) ListStudents(filter admin.UserFilter) ([]*entities.Student, int64, error) {
+	query := applyUserFilter(ar.db.Model(&entities.Student{}).Joins("Identity"), filter, "matric_number")
+	if filter.Faculty != "" {
+		query = query.Where("LOWER(faculty) = ?", strings.ToLower(strings.TrimSpace(filter.Faculty)))
+	}
+	if filter.Level > 0 {
+		query = query.Where("level = ?", filter.Level)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count students: " + err.Error())
+	}
+
+	var students []*entities.Student
+	if err := query.Order("last_name ASC, first_name ASC, students.id ASC").Offset(filter.Offset).Limit(filter.Limit).Find(&students).Error; err != nil {
+		return nil, 0, errors.New("failed to retrieve students: " + err.Error())
+	}
+	return students, total, nil
+}
+
+// ListLecturers retrieves a page of lecturers matching the filter and the total
+// number of matches.
+func (ar *AdminRepo) ListLecturers(filter admin.UserFilter) ([]*entities.Lecturer, int64, error) {
+	query := applyUserFilter(ar.db.Model(&entities.Lecturer{}).Joins("Identity"), filter, "staff_id")
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, errors.New("failed to count lecturers: " + err.Error())
+	}
+
+	var lecturers []*entities.Lecturer
+	if err := query.Order("last_name ASC, first_name ASC, lecturers.id ASC").Offset(filter.Offset).Limit(filter.Limit).Find(&lecturers).Error; err != nil {
+		return nil, 0, errors.New("failed to retrieve lecturers: " + err.Error())
+	}
+	return lecturers, total, nil
+}
+
+// GetStudentByID retrieves a student by ID.
+func (ar *AdminRepo) GetStudentByID(studentID int) (*entities.Student, error) {
+	var student entities.Student
+	if err := ar.db.Joins("Identity").First(&student, studentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, errors.New("failed to retrieve student: " + err.Error())
+	}
+	return &student, nil
+}
+
+// GetLecturerByID retrieves a lecturer by ID.
+func (ar *AdminRepo) GetLecturerByID(lecturerID int) (*entities.Lecturer, error) {
+	var lecturer entities.Lecturer
+	if err := ar.db.Joins("Identity").First(&lecturer, lecturerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, errors.New("failed to retrieve lecturer: " + err.Error())
+	}
+	return &lecturer, nil
+}
+
+// SetSuspension suspends a student or lecturer, or reactivates them when
+// suspendedAt is nil.
+func (ar *AdminRepo) SetSuspension(role string, userID int, suspendedAt *time.Time, reason string) error {
+	model, err := userModel(role)
+	if err != nil {
+		return err
+	}
+
+	res := ar.db.Model(model).Where("id = ?", userID).Updates(map[string]interface{}{
+		"suspended_at":     suspendedAt,
+		"suspended_reason": reason,
+	})
+	if res.Error != nil {
+		return errors.New("failed to update suspension: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+// DeleteUser soft-deletes a student or lecturer. Their attendance history is
+// kept, but they can no longer log in. Their matric number or staff ID is
+// released, and so is their email unless their other role still uses it.
+func (ar *AdminRepo) DeleteUser(role string, userID int) error {
+	model, err := userModel(role)
+	if err != nil {
+		return err
+	}
+
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ?", userID).Delete(model)
+		if res.Error != nil {
+			return errors.New("failed to delete user: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return ErrUserNotFound
+		}
+		return authRepo.ReleaseIdentity(tx, role, userID)
+	})
+}
+
+// applyUserFilter adds the search, status and department conditions shared by
+// students and lecturers. idColumn is the role's institutional identifier column;
+// the query must join the profile's Identity for the email search.
+func applyUserFilter(query *gorm.DB, filter admin.UserFilter, idColumn string) *gorm.DB {
+	if search := strings.ToLower(strings.TrimSpace(filter.Search)); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		query = query.Where(
+			`LOWER(first_name || ' ' || last_name) LIKE ? OR LOWER("Identity".email) LIKE ? OR LOWER(`+idColumn+`) LIKE ?`,
+			pattern, pattern, pattern,
+		)
+	}
+
+	switch filter.Status {
+	case "active":
+		query = query.Where("suspended_at IS NULL")
+	case "suspended":
+		query = query.Where("suspended_at IS NOT NULL")
+	}
+
+	if filter.Department != "" {
+		query = query.Where("LOWER(department) = ?", strings.ToLower(strings.TrimSpace(filter.Department)))
+	}
+	return query
+}
+
+// escapeLike escapes LIKE wildcards so user input matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// userModel returns the entity model for a manageable role.
+func userModel(role string) (interface{}, error) {
+	switch role {
+	case "student":
+		return &entities.Student{}, nil
+	case "lecturer":
+		return &entities.Lecturer{}, nil
+	default:
+		return nil, ErrUserNotFound
+	}
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	admin "github.com/Dom-HTG/attendance-management-system/internal/admin/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrImportJobNotFound is returned when an import job lookup matches no rows.
+	ErrImportJobNotFound = errors.New("import job not found")
+	// ErrImportEmailTaken is returned when an import row's email belongs to a
+	// different account of the same role.
+	ErrImportEmailTaken = errors.New("email address belongs to another account")
+)
+
+// ImportRepoInterface defines the repository interface for bulk imports.
+type ImportRepoInterface interface {
+	CreateImportJob(job *entities.ImportJob) error
+	UpdateImportJob(job *entities.ImportJob) error
+	GetImportJob(jobID int) (*entities.ImportJob, error)
+	ListImportJobs(limit int) ([]*entities.ImportJob, error)
+	FailUnfinishedImportJobs(reason string) (int, error)
+	UpsertStudent(row admin.StudentImportRow, passwordHash string, dryRun bool) (bool, error)
+	UpsertLecturer(row admin.LecturerImportRow, passwordHash string, dryRun bool) (bool, error)
+}
+
+// ImportRepo implements the ImportRepoInterface.
+type ImportRepo struct {
+	db *gorm.DB
+}
+
+// NewImportRepo returns a new instance of ImportRepo.
+func NewImportRepo(db *gorm.DB) *ImportRepo {
+	return &ImportRepo{
+		db: db,
+	}
+}
+
+// CreateImportJob stores a new import job.
+func (ir *ImportRepo) CreateImportJob(job *entities.ImportJob) error {
+	if err := ir.db.Create(job).Error; err != nil {
+		return errors.New("failed to create import job: " + err.Error())
+	}
+	return nil
+}
+
+// UpdateImportJob saves an import job's status and progress.
+func (ir *ImportRepo) UpdateImportJob(job *entities.ImportJob) error {
+	if err := ir.db.Save(job).Error; err != nil {
+		return errors.New("failed to update import job: " + err.Error())
+	}
+	return nil
+}
+
+// GetImportJob retrieves an import job by ID.
+func (ir *ImportRepo) GetImportJob(jobID int) (*entities.ImportJob, error) {
+	var job entities.ImportJob
+	if err := ir.db.First(&job, jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, errors.New("failed to retrieve import job: " + err.Error())
+	}
+	return &job, nil
+}
+
+// ListImportJobs retrieves the most recent import jobs, newest first.
+func (ir *ImportRepo) ListImportJobs(limit int) ([]*entities.ImportJob, error) {
+	var jobs []*entities.ImportJob
+	if err := ir.db.Order("created_at DESC").Limit(limit).Find(&jobs).Error; err != nil {
+		return nil, errors.New("failed to retrieve import jobs: " + err.Error())
+	}
+	return jobs, nil
+}
+
+// FailUnfinishedImportJobs marks pending and running jobs as failed and returns
+// how many were changed. It is called at startup, since jobs are queued in
+// memory and do not survive a restart.
+func (ir *ImportRepo) FailUnfinishedImportJobs(reason string) (int, error) {
+	res := ir.db.Model(&entities.ImportJob{}).
+		Where("status IN ?", []string{entities.ImportPending, entities.ImportRunning}).
+		Updates(map[string]interface{}{
+			"status":         entities.ImportFailed,
+			"failure_reason": reason,
+			"finished_at":    time.Now(),
+		})
+	if res.Error != nil {
+		return 0, errors.New("failed to update unfinished import jobs: " + res.Error.Error())
+	}
+	return int(res.RowsAffected), nil
+}
+
+// UpsertStudent creates the student in row, or updates the student with the
+// same matric number, and reports whether an account was created. New
+// accounts get passwordHash, unless they join an identity that already has a
+// password. With dryRun the checks run but nothing is written.
+func (ir *ImportRepo) UpsertStudent(row admin.StudentImportRow, passwordHash string, dryRun bool) (bool, error) {
+	var created bool
+	err := ir.db.Transaction(func(tx *gorm.DB) error {
+		var existing entities.Student
+		err := tx.Where("matric_number = ?", row.MatricNumber).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("failed to retrieve student: " + err.Error())
+		}
+		created = err != nil
+
+		if err := emailAvailable(tx, &entities.Student{}, "student", row.Email, int(existing.ID), created); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+
+		if created {
+			student := &entities.Student{
+				FirstName:    row.FirstName,
+				LastName:     row.LastName,
+				MatricNumber: row.MatricNumber,
+				Faculty:      row.Faculty,
+				Department:   row.Department,
+				Programme:    row.Programme,
+				Level:        row.Level,
+				EntrySession: row.EntrySession,
+			}
+			identity, err := authRepo.LinkIdentity(tx, row.Email, passwordHash)
+			if err != nil {
+				return err
+			}
+			student.IdentityID = identity.ID
+			if err := tx.Create(student).Error; err != nil {
+				return errors.New("failed to create student: " + err.Error())
+			}
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"first_name": row.FirstName,
+			"last_name":  row.LastName,
+			"department": row.Department,
+			"level":      row.Level,
+		}
+		for column, value := range map[string]string{"faculty": row.Faculty, "programme": row.Programme, "entry_session": row.EntrySession} {
+			if value != "" {
+				updates[column] = value
+			}
+		}
+		if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+			return errors.New("failed to update student: " + err.Error())
+		}
+		return authRepo.UpdateCredentials(tx, "student", int(existing.ID), map[string]interface{}{"email": row.Email})
+	})
+	return created, err
+}
+
+// UpsertLecturer creates the lecturer in row, or updates the lecturer with the
+// same staff ID, and reports whether an account was created. New accounts get
+// passwordHash, unless they join an identity that already has a password.
+// With dryRun the checks run but nothing is written.
+func (ir *ImportRepo) UpsertLecturer(row admin.LecturerImportRow, passwordHash string, dryRun bool) (bool, error) {
+	var created bool
+	err := ir.db.Transaction(func(tx *gorm.DB) error {
+		var existing entities.Lecturer
+		err := tx.Where("staff_id = ?", row.StaffID).First(&existing).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("failed to retrieve lecturer: " + err.Error())
+		}
+		created = err != nil
+
+		if err := emailAvailable(tx, &entities.Lecturer{}, "lecturer", row.Email, int(existing.ID), created); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+
+		if created {
+			lecturer := &entities.Lecturer{
+				FirstName:  row.FirstName,
+				LastName:   row.LastName,
+				StaffID:    row.StaffID,
+				Department: row.Department,
+			}
+			identity, err := authRepo.LinkIdentity(tx, row.Email, passwordHash)
+			if err != nil {
+				return err
+			}
+			lecturer.IdentityID = identity.ID
+			if err := tx.Create(lecturer).Error; err != nil {
+				return errors.New("failed to create lecturer: " + err.Error())
+			}
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"first_name": row.FirstName,
+			"last_name":  row.LastName,
+			"department": row.Department,
+		}
+		if err := tx.Model(&existing).Updates(updates).Error; err != nil {
+			return errors.New("failed to update lecturer: " + err.Error())
+		}
+		return authRepo.UpdateCredentials(tx, "lecturer", int(existing.ID), map[string]interface{}{"email": row.Email})
+	})
+	return created, err
+}
+
+// emailAvailable returns ErrImportEmailTaken if email cannot be used by the
+// account of role being imported. Deleted accounts no longer hold their address.
+// A new account may share its email with a profile of the other role, since
+// it joins that person's identity, but not with one of its own role; an
+// existing account may only move to an address no one outside its own
+// identity uses.
+func emailAvailable(tx *gorm.DB, model interface{}, role, email string, exceptID int, created bool) error {
+	if !created {
+		taken, err := authRepo.EmailInUse(tx, email, role, exceptID)
+		if err != nil {
+			return err
+		}
+		if taken {
+			return ErrImportEmailTaken
+		}
+		return nil
+	}
+
+	var count int64
+	if err := tx.Model(model).
+		Where("identity_id IN (?)", tx.Model(&entities.Identity{}).Select("id").Where("LOWER(email) = ?", strings.ToLower(email))).
+		Count(&count).Error; err != nil {
+		return errors.New("failed to check email: " + err.Error())
+	}
+	if count > 0 {
+		return ErrImportEmailTaken
+	}
+	return nil
+}
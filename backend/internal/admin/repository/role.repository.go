@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRoleAssignmentNotFound is returned when a role assignment lookup matches no rows.
+	ErrRoleAssignmentNotFound = errors.New("role assignment not found")
+	// ErrRoleAssignmentExists is returned when the same role and scope are assigned twice.
+	ErrRoleAssignmentExists = errors.New("role assignment already exists")
+)
+
+// RoleAssignmentRepoInterface defines the repository interface for assigned roles.
+type RoleAssignmentRepoInterface interface {
+	CreateRoleAssignment(assignment *entities.RoleAssignment) error
+	GetRoleAssignment(assignmentID int) (*entities.RoleAssignment, error)
+	ListRoleAssignments(userID *int, role string) ([]*entities.RoleAssignment, error)
+	DeleteRoleAssignment(assignmentID int) error
+	CourseExists(code string) (bool, error)
+	GrantsFor(accountRole string, userID int) ([]rbac.Grant, error)
+}
+
+// RoleAssignmentRepo implements the RoleAssignmentRepoInterface.
+type RoleAssignmentRepo struct {
+	db *gorm.DB
+}
+
+// NewRoleAssignmentRepo returns a new instance of RoleAssignmentRepo.
+func NewRoleAssignmentRepo(db *gorm.DB) *RoleAssignmentRepo {
+	return &RoleAssignmentRepo{
+		db: db,
+	}
+}
+
+// CreateRoleAssignment stores a role assignment. It returns
+// ErrRoleAssignmentExists if the user already has the role at that scope.
+func (rr *RoleAssignmentRepo) CreateRoleAssignment(assignment *entities.RoleAssignment) error {
+	res := rr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(assignment)
+	if res.Error != nil {
+		return errors.New("failed to create role assignment: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return ErrRoleAssignmentExists
+	}
+	return nil
+}
+
+// GetRoleAssignment retrieves a role assignment by ID.
+func (rr *RoleAssignmentRepo) GetRoleAssignment(assignmentID int) (*entities.RoleAssignment, error) {
+	var assignment entities.RoleAssignment
+	if err := rr.db.First(&assignment, assignmentID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoleAssignmentNotFound
+		}
+		return nil, errors.New("failed to retrieve role assignment: " + err.Error())
+	}
+	return &assignment, nil
+}
+
+// ListRoleAssignments retrieves role assignments, optionally narrowed to a
+// lecturer and a role.
+func (rr *RoleAssignmentRepo) ListRoleAssignments(userID *int, role string) ([]*entities.RoleAssignment, error) {
+	query := rr.db.Model(&entities.RoleAssignment{})
+	if userID != nil {
+		query = query.Where("user_role = ? AND user_id = ?", rbac.RoleLecturer, *userID)
+	}
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+
+	var assignments []*entities.RoleAssignment
+	if err := query.Order("user_id ASC, role ASC, scope_value ASC").Find(&assignments).Error; err != nil {
+		return nil, errors.New("failed to retrieve role assignments: " + err.Error())
+	}
+	return assignments, nil
+}
+
+// DeleteRoleAssignment permanently deletes a role assignment so the same role
+// and scope can be assigned again later.
+func (rr *RoleAssignmentRepo) DeleteRoleAssignment(assignmentID int) error {
+	res := rr.db.Unscoped().Delete(&entities.RoleAssignment{}, assignmentID)
+	if res.Error != nil {
+		return errors.New("failed to delete role assignment: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return ErrRoleAssignmentNotFound
+	}
+	return nil
+}
+
+// CourseExists reports whether a course with the given code exists.
+func (rr *RoleAssignmentRepo) CourseExists(code string) (bool, error) {
+	var count int64
+	if err := rr.db.Model(&entities.Course{}).Where("UPPER(code) = ?", strings.ToUpper(strings.TrimSpace(code))).Count(&count).Error; err != nil {
+		return false, errors.New("failed to check course: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// GrantsFor returns the roles assigned to an account as grants.
+// It implements middleware.GrantStore.
+func (rr *RoleAssignmentRepo) GrantsFor(accountRole string, userID int) ([]rbac.Grant, error) {
+	var assignments []entities.RoleAssignment
+	if err := rr.db.Where("user_role = ? AND user_id = ?", accountRole, userID).Find(&assignments).Error; err != nil {
+		return nil, errors.New("failed to retrieve role assignments: " + err.Error())
+	}
+
+	grants := make([]rbac.Grant, 0, len(assignments))
+	for _, a := range assignments {
+		grants = append(grants, rbac.Grant{
+			Role:  a.Role,
+			Scope: rbac.Scope{Type: rbac.ScopeType(a.ScopeType), Value: a.ScopeValue},
+		})
+	}
+	return grants, nil
+}
@@ -0,0 +1,461 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	admin "github.com/Dom-HTG/attendance-management-system/internal/admin/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/admin/repository"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/lockout"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// AdminSvcInterface defines the service interface for administrative operations.
+type AdminSvcInterface interface {
+	CreateAdmin(ctx *gin.Context)
+	ListAdmins(ctx *gin.Context)
+	ListUsers(ctx *gin.Context)
+	GetUser(ctx *gin.Context)
+	SuspendUser(ctx *gin.Context)
+	ReactivateUser(ctx *gin.Context)
+	DeleteUser(ctx *gin.Context)
+	ListRoles(ctx *gin.Context)
+	ListRoleAssignments(ctx *gin.Context)
+	AssignRole(ctx *gin.Context)
+	RevokeRoleAssignment(ctx *gin.Context)
+	ImportUsers(ctx *gin.Context)
+	ListImportJobs(ctx *gin.Context)
+	GetImportJob(ctx *gin.Context)
+	UnlockUser(ctx *gin.Context)
+	UnlockIP(ctx *gin.Context)
+	ResetTwoFactor(ctx *gin.Context)
+}
+
+// AdminSvc implements the AdminSvcInterface.
+type AdminSvc struct {
+	adminRepo  repository.AdminRepoInterface
+	roleRepo   repository.RoleAssignmentRepoInterface
+	importRepo repository.ImportRepoInterface
+	tokens     authRepo.TokenRepoInterface
+	importer   *Importer
+	lockout    *lockout.Guard
+	twoFactors authRepo.TwoFactorRepoInterface
+}
+
+// NewAdminSvc returns a new instance of AdminSvc.
+func NewAdminSvc(adminRepo repository.AdminRepoInterface, roleRepo repository.RoleAssignmentRepoInterface, importRepo repository.ImportRepoInterface, tokens authRepo.TokenRepoInterface, importer *Importer, guard *lockout.Guard, twoFactors authRepo.TwoFactorRepoInterface) *AdminSvc {
+	return &AdminSvc{
+		adminRepo:  adminRepo,
+		roleRepo:   roleRepo,
+		importRepo: importRepo,
+		tokens:     tokens,
+		importer:   importer,
+		lockout:    guard,
+		twoFactors: twoFactors,
+	}
+}
+
+// BootstrapAdmin creates the first admin from configuration when no admin with
+// that email exists yet. An existing admin's password is never overwritten.
+func BootstrapAdmin(adminRepo repository.AdminRepoInterface, email, password string) error {
+	email = strings.TrimSpace(email)
+	if email == "" || password == "" {
+		return nil
+	}
+
+	if _, err := adminRepo.GetAdminByEmail(email); err == nil {
+		return nil
+	} else if !errors.Is(err, repository.ErrAdminNotFound) {
+		return err
+	}
+
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return err
+	}
+
+	if err := adminRepo.CreateAdmin(&entities.Admin{
+		FirstName: "System",
+		LastName:  "Administrator",
+		Email:     email,
+		Role:      "admin",
+		Password:  string(hash),
+	}); err != nil {
+		return err
+	}
+
+	logger.Infof("bootstrap admin %s created", email)
+	return nil
+}
+
+// CreateAdmin handles POST /api/admin/admins.
+func (as *AdminSvc) CreateAdmin(ctx *gin.Context) {
+	var req admin.CreateAdminDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := as.adminRepo.GetAdminByEmail(req.Email); err == nil {
+		responses.ApiFailure(ctx, "An admin with this email already exists", http.StatusConflict, nil)
+		return
+	} else if !errors.Is(err, repository.ErrAdminNotFound) {
+		responses.ApiFailure(ctx, "Failed to check admin email", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	hash, err := utils.HashPassword(req.Password)
+	if err != nil {
+		responses.ApiFailure(ctx, "Unable to hash password", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	entity := &entities.Admin{
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		Email:     req.Email,
+		Role:      "admin",
+		Password:  string(hash),
+	}
+	if err := as.adminRepo.CreateAdmin(entity); err != nil {
+		responses.ApiFailure(ctx, "Failed to create admin", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Admin created successfully", toAdminResponse(entity))
+}
+
+// ListAdmins handles GET /api/admin/admins.
+func (as *AdminSvc) ListAdmins(ctx *gin.Context) {
+	admins, err := as.adminRepo.ListAdmins()
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve admins", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []admin.AdminResponse{}
+	for _, a := range admins {
+		result = append(result, toAdminResponse(a))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Admins retrieved successfully", result)
+}
+
+// ListUsers handles GET /api/admin/users?role=student|lecturer.
+// Results can be narrowed with search, status, department and (for students)
+// faculty and level, and are paginated with page and page_size.
+func (as *AdminSvc) ListUsers(ctx *gin.Context) {
+	role := ctx.Query("role")
+	if !isManagedRole(role) {
+		responses.ApiFailure(ctx, "role must be student or lecturer", http.StatusBadRequest, nil)
+		return
+	}
+
+	status := ctx.Query("status")
+	if status != "" && status != "active" && status != "suspended" {
+		responses.ApiFailure(ctx, "status must be active or suspended", http.StatusBadRequest, nil)
+		return
+	}
+
+	page, err := positiveIntQuery(ctx, "page", 1)
+	if err != nil {
+		responses.ApiFailure(ctx, "page must be a positive integer", http.StatusBadRequest, nil)
+		return
+	}
+	pageSize, err := positiveIntQuery(ctx, "page_size", defaultPageSize)
+	if err != nil {
+		responses.ApiFailure(ctx, "page_size must be a positive integer", http.StatusBadRequest, nil)
+		return
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	level, err := positiveIntQuery(ctx, "level", 0)
+	if err != nil {
+		responses.ApiFailure(ctx, "level must be a positive integer", http.StatusBadRequest, nil)
+		return
+	}
+
+	filter := admin.UserFilter{
+		Search:     ctx.Query("search"),
+		Status:     status,
+		Department: ctx.Query("department"),
+		Faculty:    ctx.Query("faculty"),
+		Level:      level,
+		Offset:     (page - 1) * pageSize,
+		Limit:      pageSize,
+	}
+
+	result := admin.UserListResponse{Users: []admin.UserResponse{}, Page: page, PageSize: pageSize}
+	if role == "student" {
+		students, total, err := as.adminRepo.ListStudents(filter)
+		if err != nil {
+			responses.ApiFailure(ctx, "Failed to retrieve students", http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, s := range students {
+			result.Users = append(result.Users, studentResponse(s))
+		}
+		result.Total = total
+	} else {
+		lecturers, total, err := as.adminRepo.ListLecturers(filter)
+		if err != nil {
+			responses.ApiFailure(ctx, "Failed to retrieve lecturers", http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, l := range lecturers {
+			result.Users = append(result.Users, lecturerResponse(l))
+		}
+		result.Total = total
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Users retrieved successfully", result)
+}
+
+// GetUser handles GET /api/admin/users/{role}/{user_id}.
+func (as *AdminSvc) GetUser(ctx *gin.Context) {
+	user, ok := as.loadUser(ctx)
+	if !ok {
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "User retrieved successfully", user)
+}
+
+// SuspendUser handles POST /api/admin/users/{role}/{user_id}/suspend.
+// A suspended user cannot log in or refresh tokens, and their current sessions
+// are revoked immediately.
+func (as *AdminSvc) SuspendUser(ctx *gin.Context) {
+	user, ok := as.loadUser(ctx)
+	if !ok {
+		return
+	}
+
+	var req admin.SuspendUserDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		responses.ApiFailure(ctx, "reason is required", http.StatusBadRequest, nil)
+		return
+	}
+
+	if user.Suspended {
+		responses.ApiFailure(ctx, "User is already suspended", http.StatusConflict, nil)
+		return
+	}
+
+	now := time.Now()
+	if err := as.adminRepo.SetSuspension(user.Role, user.ID, &now, reason); err != nil {
+		responses.ApiFailure(ctx, "Failed to suspend user", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sessions, err := as.tokens.RevokeAllForUser(user.Role, user.ID, authRepo.RevokeReasonSuspended)
+	if err != nil {
+		responses.ApiFailure(ctx, "User suspended but sessions could not be revoked", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	logger.Infof("admin %d suspended %s %d: %s", adminID, user.Role, user.ID, reason)
+
+	suspendedAt := now.Format(time.RFC3339)
+	user.Suspended = true
+	user.SuspendedAt = &suspendedAt
+	user.SuspendedReason = reason
+	responses.ApiSuccess(ctx, http.StatusOK, "User suspended successfully", map[string]interface{}{
+		"user":             user,
+		"sessions_revoked": sessions,
+	})
+}
+
+// ReactivateUser handles POST /api/admin/users/{role}/{user_id}/reactivate.
+func (as *AdminSvc) ReactivateUser(ctx *gin.Context) {
+	user, ok := as.loadUser(ctx)
+	if !ok {
+		return
+	}
+
+	if !user.Suspended {
+		responses.ApiFailure(ctx, "User is not suspended", http.StatusConflict, nil)
+		return
+	}
+
+	if err := as.adminRepo.SetSuspension(user.Role, user.ID, nil, ""); err != nil {
+		responses.ApiFailure(ctx, "Failed to reactivate user", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	logger.Infof("admin %d reactivated %s %d", adminID, user.Role, user.ID)
+
+	user.Suspended = false
+	user.SuspendedAt = nil
+	user.SuspendedReason = ""
+	responses.ApiSuccess(ctx, http.StatusOK, "User reactivated successfully", user)
+}
+
+// DeleteUser handles DELETE /api/admin/users/{role}/{user_id}.
+// The account is soft-deleted so attendance history is kept, and its sessions
+// are revoked.
+func (as *AdminSvc) DeleteUser(ctx *gin.Context) {
+	user, ok := as.loadUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := as.adminRepo.DeleteUser(user.Role, user.ID); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete user", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if _, err := as.tokens.RevokeAllForUser(user.Role, user.ID, authRepo.RevokeReasonDeleted); err != nil {
+		logger.Errorf("failed to revoke sessions of deleted %s %d: %v", user.Role, user.ID, err)
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	logger.Infof("admin %d deleted %s %d", adminID, user.Role, user.ID)
+
+	responses.ApiSuccess(ctx, http.StatusOK, "User deleted successfully", nil)
+}
+
+// loadUser loads the student or lecturer named by the role and user_id URL parameters.
+// It writes the failure response itself and returns false when the request should stop.
+func (as *AdminSvc) loadUser(ctx *gin.Context) (*admin.UserResponse, bool) {
+	role := ctx.Param("role")
+	if !isManagedRole(role) {
+		responses.ApiFailure(ctx, "role must be student or lecturer", http.StatusBadRequest, nil)
+		return nil, false
+	}
+
+	userID, err := strconv.Atoi(ctx.Param("user_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	var user admin.UserResponse
+	if role == "student" {
+		student, err := as.adminRepo.GetStudentByID(userID)
+		if err != nil {
+			respondUserLookupError(ctx, err)
+			return nil, false
+		}
+		user = studentResponse(student)
+	} else {
+		lecturer, err := as.adminRepo.GetLecturerByID(userID)
+		if err != nil {
+			respondUserLookupError(ctx, err)
+			return nil, false
+		}
+		user = lecturerResponse(lecturer)
+	}
+
+	return &user, true
+}
+
+// respondUserLookupError maps user lookup errors to HTTP responses.
+func respondUserLookupError(ctx *gin.Context, err error) {
+	if errors.Is(err, repository.ErrUserNotFound) {
+		responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+		return
+	}
+	responses.ApiFailure(ctx, "Failed to retrieve user", http.StatusInternalServerError, err.Error())
+}
+
+// isManagedRole reports whether admins manage accounts of the given role.
+func isManagedRole(role string) bool {
+	return role == "student" || role == "lecturer"
+}
+
+// positiveIntQuery parses an optional positive integer query parameter.
+func positiveIntQuery(ctx *gin.Context, key string, def int) (int, error) {
+	raw := ctx.Query(key)
+	if raw == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, errors.New(key + " must be a positive integer")
+	}
+	return n, nil
+}
+
+// toAdminResponse maps an admin entity to its response DTO.
+func toAdminResponse(a *entities.Admin) admin.AdminResponse {
+	return admin.AdminResponse{
+		ID:        int(a.ID),
+		FirstName: a.FirstName,
+		LastName:  a.LastName,
+		Email:     a.Email,
+		CreatedAt: a.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// studentResponse maps a student entity to the admin user DTO.
+func studentResponse(s *entities.Student) admin.UserResponse {
+	user := admin.UserResponse{
+		ID:              int(s.ID),
+		Role:            "student",
+		FirstName:       s.FirstName,
+		LastName:        s.LastName,
+		Email:           s.Identity.Email,
+		MatricNumber:    s.MatricNumber,
+		Faculty:         s.Faculty,
+		Department:      s.Department,
+		Programme:       s.Programme,
+		Level:           s.Level,
+		EntrySession:    s.EntrySession,
+		EmailVerified:   !s.RequiresEmailVerification || s.EmailVerifiedAt != nil,
+		SuspendedReason: s.SuspendedReason,
+		CreatedAt:       s.CreatedAt.Format(time.RFC3339),
+	}
+	setSuspension(&user, s.SuspendedAt)
+	return user
+}
+
+// lecturerResponse maps a lecturer entity to the admin user DTO.
+func lecturerResponse(l *entities.Lecturer) admin.UserResponse {
+	user := admin.UserResponse{
+		ID:              int(l.ID),
+		Role:            "lecturer",
+		FirstName:       l.FirstName,
+		LastName:        l.LastName,
+		Email:           l.Identity.Email,
+		StaffID:         l.StaffID,
+		Department:      l.Department,
+		EmailVerified:   !l.RequiresEmailVerification || l.EmailVerifiedAt != nil,
+		SuspendedReason: l.SuspendedReason,
+		CreatedAt:       l.CreatedAt.Format(time.RFC3339),
+	}
+	setSuspension(&user, l.SuspendedAt)
+	return user
+}
+
+// setSuspension fills the suspension fields of a user DTO.
+func setSuspension(user *admin.UserResponse, suspendedAt *time.Time) {
+	if suspendedAt == nil {
+		return
+	}
+	formatted := suspendedAt.Format(time.RFC3339)
+	user.Suspended = true
+	user.SuspendedAt = &formatted
+}
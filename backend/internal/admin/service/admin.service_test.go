@@ -0,0 +1,245 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	admin "github.com/Dom-HTG/attendance-management-system/internal/admin/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/admin/repository"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeAdminRepo holds one student and one lecturer and records suspension
+// changes and deletions.
+type fakeAdminRepo struct {
+	repository.AdminRepoInterface
+	student     *entities.Student
+	lecturer    *entities.Lecturer
+	filter      admin.UserFilter
+	suspensions []*time.Time
+	deleted     []string
+}
+
+func (r *fakeAdminRepo) GetStudentByID(studentID int) (*entities.Student, error) {
+	if r.student == nil || int(r.student.ID) != studentID {
+		return nil, repository.ErrUserNotFound
+	}
+	return r.student, nil
+}
+
+func (r *fakeAdminRepo) GetLecturerByID(lecturerID int) (*entities.Lecturer, error) {
+	if r.lecturer == nil || int(r.lecturer.ID) != lecturerID {
+		return nil, repository.ErrUserNotFound
+	}
+	return r.lecturer, nil
+}
+
+func (r *fakeAdminRepo) ListStudents(filter admin.UserFilter) ([]*entities.Student, int64, error) {
+	r.filter = filter
+	return []*entities.Student{r.student}, 1, nil
+}
+
+func (r *fakeAdminRepo) SetSuspension(role string, userID int, suspendedAt *time.Time, reason string) error {
+	r.suspensions = append(r.suspensions, suspendedAt)
+	return nil
+}
+
+func (r *fakeAdminRepo) DeleteUser(role string, userID int) error {
+	r.deleted = append(r.deleted, role)
+	return nil
+}
+
+// fakeSessions reports two active sessions for every user and records why
+// they were revoked.
+type fakeSessions struct {
+	authRepo.TokenRepoInterface
+	reasons []string
+}
+
+func (s *fakeSessions) RevokeAllForUser(role string, userID int, reason string) (int, error) {
+	s.reasons = append(s.reasons, reason)
+	return 2, nil
+}
+
+func newTestAdminSvc(suspendedAt *time.Time) (*AdminSvc, *fakeAdminRepo, *fakeSessions) {
+	repo := &fakeAdminRepo{
+		student:  &entities.Student{Model: gorm.Model{ID: 7}, Identity: &entities.Identity{Email: "ada@uni.edu"}, FirstName: "Ada", MatricNumber: "CSC/2024/001", SuspendedAt: suspendedAt},
+		lecturer: &entities.Lecturer{Model: gorm.Model{ID: 3}, Identity: &entities.Identity{Email: "grace@uni.edu"}, FirstName: "Grace", StaffID: "STF-19", SuspendedAt: suspendedAt},
+	}
+	sessions := &fakeSessions{}
+	return &AdminSvc{adminRepo: repo, tokens: sessions}, repo, sessions
+}
+
+// serveAdmin calls handler as admin 1 with the given URL parameters, query
+// string and JSON body.
+func serveAdmin(t *testing.T, handler gin.HandlerFunc, params gin.Params, query string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/?"+query, bytes.NewReader(payload))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = params
+	ctx.Set("user_id", 1)
+	handler(ctx)
+	return w
+}
+
+func userParams(role, userID string) gin.Params {
+	return gin.Params{{Key: "role", Value: role}, {Key: "user_id", Value: userID}}
+}
+
+func TestGetUser(t *testing.T) {
+	tests := []struct {
+		name   string
+		params gin.Params
+		want   int
+	}{
+		{"student", userParams("student", "7"), http.StatusOK},
+		{"lecturer", userParams("lecturer", "3"), http.StatusOK},
+		{"admin role", userParams("admin", "1"), http.StatusBadRequest},
+		{"malformed user ID", userParams("student", "abc"), http.StatusBadRequest},
+		{"unknown student", userParams("student", "3"), http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newTestAdminSvc(nil)
+			if w := serveAdmin(t, svc.GetUser, tt.params, "", nil); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestSuspendUser(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		suspendedAt *time.Time
+		reason      string
+		want        int
+	}{
+		{"active user", nil, "Exam malpractice", http.StatusOK},
+		{"blank reason", nil, "  ", http.StatusBadRequest},
+		{"already suspended", &earlier, "Exam malpractice", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, sessions := newTestAdminSvc(tt.suspendedAt)
+			w := serveAdmin(t, svc.SuspendUser, userParams("student", "7"), "", admin.SuspendUserDTO{Reason: tt.reason})
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusOK {
+				if len(repo.suspensions) != 0 || len(sessions.reasons) != 0 {
+					t.Errorf("suspensions %v and revocations %v, want none", repo.suspensions, sessions.reasons)
+				}
+				return
+			}
+
+			if len(repo.suspensions) != 1 || repo.suspensions[0] == nil {
+				t.Errorf("suspensions = %v, want one suspension", repo.suspensions)
+			}
+			if len(sessions.reasons) != 1 || sessions.reasons[0] != authRepo.RevokeReasonSuspended {
+				t.Errorf("revocations = %v, want %q", sessions.reasons, authRepo.RevokeReasonSuspended)
+			}
+			var resp struct {
+				Data struct {
+					User            admin.UserResponse `json:"user"`
+					SessionsRevoked int                `json:"sessions_revoked"`
+				} `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !resp.Data.User.Suspended || resp.Data.User.SuspendedReason != tt.reason || resp.Data.SessionsRevoked != 2 {
+				t.Errorf("response = %+v, want suspended for %q with 2 sessions revoked", resp.Data, tt.reason)
+			}
+		})
+	}
+}
+
+func TestReactivateUser(t *testing.T) {
+	earlier := time.Now().Add(-time.Hour)
+
+	tests := []struct {
+		name        string
+		suspendedAt *time.Time
+		want        int
+	}{
+		{"suspended user", &earlier, http.StatusOK},
+		{"active user", nil, http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestAdminSvc(tt.suspendedAt)
+			w := serveAdmin(t, svc.ReactivateUser, userParams("lecturer", "3"), "", nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			cleared := len(repo.suspensions) == 1 && repo.suspensions[0] == nil
+			if cleared != (tt.want == http.StatusOK) {
+				t.Errorf("suspensions = %v", repo.suspensions)
+			}
+		})
+	}
+}
+
+func TestDeleteUserRevokesSessions(t *testing.T) {
+	svc, repo, sessions := newTestAdminSvc(nil)
+	if w := serveAdmin(t, svc.DeleteUser, userParams("student", "7"), "", nil); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != "student" {
+		t.Errorf("deleted = %v, want the student", repo.deleted)
+	}
+	if len(sessions.reasons) != 1 || sessions.reasons[0] != authRepo.RevokeReasonDeleted {
+		t.Errorf("revocations = %v, want %q", sessions.reasons, authRepo.RevokeReasonDeleted)
+	}
+}
+
+func TestListUsersQuery(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		want       int
+		wantFilter admin.UserFilter
+	}{
+		{"defaults", "role=student", http.StatusOK, admin.UserFilter{Limit: defaultPageSize}},
+		{"filters and page", "role=student&status=suspended&search=ada&department=CS&level=200&page=3&page_size=10", http.StatusOK,
+			admin.UserFilter{Search: "ada", Status: "suspended", Department: "CS", Level: 200, Offset: 20, Limit: 10}},
+		{"page size capped", "role=student&page_size=500", http.StatusOK, admin.UserFilter{Limit: maxPageSize}},
+		{"missing role", "", http.StatusBadRequest, admin.UserFilter{}},
+		{"unknown status", "role=student&status=deleted", http.StatusBadRequest, admin.UserFilter{}},
+		{"page zero", "role=student&page=0", http.StatusBadRequest, admin.UserFilter{}},
+		{"level not a number", "role=student&level=two", http.StatusBadRequest, admin.UserFilter{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, repo, _ := newTestAdminSvc(nil)
+			w := serveAdmin(t, svc.ListUsers, nil, tt.query, nil)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if repo.filter != tt.wantFilter {
+				t.Errorf("filter = %+v, want %+v", repo.filter, tt.wantFilter)
+			}
+		})
+	}
+}
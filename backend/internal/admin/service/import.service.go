@@ -0,0 +1,536 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	admin "github.com/Dom-HTG/attendance-management-system/internal/admin/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/admin/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxImportFileSize      = 10 << 20 // 10 MB
+	maxImportErrors        = 1000     // Row errors kept per job; the failed count is always exact
+	importProgressInterval = 100      // Rows between progress saves
+	importQueueSize        = 16
+	importJobListLimit     = 50
+)
+
+// importColumns are the columns each role's import file must have. A single
+// name column may be given instead of first_name and last_name. Student files
+// may also have faculty, programme and entry_session columns.
+var importColumns = map[string][]string{
+	"student":  {"first_name", "last_name", "email", "matric_number", "department", "level"},
+	"lecturer": {"first_name", "last_name", "email", "staff_id", "department"},
+}
+
+// importRow is one validated row of an import file. Exactly one of student
+// and lecturer is set.
+type importRow struct {
+	line     int
+	key      string // Matric number or staff ID
+	student  *admin.StudentImportRow
+	lecturer *admin.LecturerImportRow
+}
+
+// importTask is a parsed import file waiting to be applied.
+type importTask struct {
+	job    *entities.ImportJob
+	rows   []importRow
+	errors []admin.ImportRowError // Rows rejected while parsing
+}
+
+// Importer applies import files to the database. Small files are applied while
+// the admin waits; larger ones are queued and applied by Run in the background.
+type Importer struct {
+	importRepo repository.ImportRepoInterface
+	syncLimit  int
+	queue      chan *importTask
+}
+
+// NewImporter returns a new Importer. Files with at most syncLimit rows are
+// applied synchronously.
+func NewImporter(importRepo repository.ImportRepoInterface, syncLimit int) *Importer {
+	return &Importer{
+		importRepo: importRepo,
+		syncLimit:  syncLimit,
+		queue:      make(chan *importTask, importQueueSize),
+	}
+}
+
+// Run applies queued imports one at a time until ctx is cancelled. Jobs left
+// unfinished by a previous run are marked failed first, since the queue is
+// held in memory.
+func (im *Importer) Run(ctx context.Context) {
+	if n, err := im.importRepo.FailUnfinishedImportJobs("server restarted before the import finished"); err != nil {
+		logger.Errorf("importer failed to clean up unfinished jobs: %v", err)
+	} else if n > 0 {
+		logger.Infof("importer marked %d unfinished job(s) as failed", n)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-im.queue:
+			im.process(ctx, task)
+		}
+	}
+}
+
+// enqueue queues a task for Run and reports false if the queue is full.
+func (im *Importer) enqueue(task *importTask) bool {
+	select {
+	case im.queue <- task:
+		return true
+	default:
+		return false
+	}
+}
+
+// process applies every row of a task and records the outcome on its job.
+// New accounts get an unusable random password; imported users set their own
+// through the forgot-password flow.
+func (im *Importer) process(ctx context.Context, task *importTask) {
+	job := task.job
+	now := time.Now()
+	job.Status = entities.ImportRunning
+	job.StartedAt = &now
+	job.ProcessedRows = len(task.errors)
+	job.FailedCount = len(task.errors)
+	im.save(job)
+
+	var passwordHash string
+	if !job.DryRun {
+		password, err := utils.GenerateOpaqueToken()
+		if err == nil {
+			var hash []byte
+			hash, err = utils.HashPassword(password)
+			passwordHash = string(hash)
+		}
+		if err != nil {
+			im.fail(job, "failed to generate initial passwords: "+err.Error())
+			return
+		}
+	}
+
+	rowErrors := task.errors
+	for i, row := range task.rows {
+		if ctx.Err() != nil {
+			im.fail(job, "server shut down before the import finished")
+			return
+		}
+
+		created, err := im.upsert(row, passwordHash, job.DryRun)
+		switch {
+		case err != nil:
+			job.FailedCount++
+			rowErrors = append(rowErrors, admin.ImportRowError{Line: row.line, Key: row.key, Message: err.Error()})
+		case created:
+			job.CreatedCount++
+		default:
+			job.UpdatedCount++
+		}
+
+		job.ProcessedRows++
+		if (i+1)%importProgressInterval == 0 {
+			im.save(job)
+		}
+	}
+
+	sort.SliceStable(rowErrors, func(i, j int) bool { return rowErrors[i].Line < rowErrors[j].Line })
+	if len(rowErrors) > maxImportErrors {
+		rowErrors = rowErrors[:maxImportErrors]
+	}
+	if len(rowErrors) > 0 {
+		encoded, err := json.Marshal(rowErrors)
+		if err != nil {
+			logger.Errorf("failed to encode errors of import job %d: %v", job.ID, err)
+		}
+		job.Errors = string(encoded)
+	}
+
+	finished := time.Now()
+	job.Status = entities.ImportCompleted
+	job.FinishedAt = &finished
+	im.save(job)
+
+	logger.Infof("import job %d (%s, dry run %t) finished: %d created, %d updated, %d failed",
+		job.ID, job.Role, job.DryRun, job.CreatedCount, job.UpdatedCount, job.FailedCount)
+}
+
+// upsert applies one row.
+func (im *Importer) upsert(row importRow, passwordHash string, dryRun bool) (bool, error) {
+	if row.student != nil {
+		return im.importRepo.UpsertStudent(*row.student, passwordHash, dryRun)
+	}
+	return im.importRepo.UpsertLecturer(*row.lecturer, passwordHash, dryRun)
+}
+
+// fail marks a job as failed as a whole.
+func (im *Importer) fail(job *entities.ImportJob, reason string) {
+	finished := time.Now()
+	job.Status = entities.ImportFailed
+	job.FailureReason = reason
+	job.FinishedAt = &finished
+	im.save(job)
+	logger.Errorf("import job %d failed: %s", job.ID, reason)
+}
+
+// save stores a job's progress. Failures are logged rather than returned so a
+// transient error does not abandon an import halfway.
+func (im *Importer) save(job *entities.ImportJob) {
+	if err := im.importRepo.UpdateImportJob(job); err != nil {
+		logger.Errorf("failed to save import job %d: %v", job.ID, err)
+	}
+}
+
+// ImportUsers handles POST /api/admin/imports/{role}?dry_run=true|false.
+// The CSV file is sent as the multipart field "file" or as a text/csv body.
+// Files with up to the configured number of rows are applied immediately and
+// the finished job is returned; larger files are queued and return 202 with a
+// job to poll. Rows are matched on matric number or staff ID: existing accounts
+// are updated, unknown ones are created.
+func (as *AdminSvc) ImportUsers(ctx *gin.Context) {
+	role := ctx.Param("role")
+	if !isManagedRole(role) {
+		responses.ApiFailure(ctx, "role must be student or lecturer", http.StatusBadRequest, nil)
+		return
+	}
+
+	dryRun, err := strconv.ParseBool(ctx.DefaultQuery("dry_run", "false"))
+	if err != nil {
+		responses.ApiFailure(ctx, "dry_run must be true or false", http.StatusBadRequest, nil)
+		return
+	}
+
+	file, fileName, ok := importFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	rows, rowErrors, err := parseImportFile(role, file)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			responses.ApiFailure(ctx, fmt.Sprintf("File is larger than %d MB", maxImportFileSize>>20), http.StatusRequestEntityTooLarge, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Invalid import file", http.StatusBadRequest, err.Error())
+		return
+	}
+	if len(rows)+len(rowErrors) == 0 {
+		responses.ApiFailure(ctx, "Import file has no rows", http.StatusBadRequest, nil)
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	job := &entities.ImportJob{
+		Role:      role,
+		Status:    entities.ImportPending,
+		DryRun:    dryRun,
+		FileName:  fileName,
+		TotalRows: len(rows) + len(rowErrors),
+		CreatedBy: adminID,
+	}
+	if err := as.importRepo.CreateImportJob(job); err != nil {
+		responses.ApiFailure(ctx, "Failed to create import job", http.StatusInternalServerError, err.Error())
+		return
+	}
+	logger.Infof("admin %d started import job %d: %d %s row(s), dry run %t", adminID, job.ID, job.TotalRows, role, dryRun)
+
+	task := &importTask{job: job, rows: rows, errors: rowErrors}
+	if job.TotalRows <= as.importer.syncLimit {
+		as.importer.process(context.Background(), task)
+		responses.ApiSuccess(ctx, http.StatusOK, "Import completed", importJobResponse(job, true))
+		return
+	}
+
+	if !as.importer.enqueue(task) {
+		as.importer.fail(job, "import queue is full")
+		responses.ApiFailure(ctx, "Too many imports are in progress. Please try again later", http.StatusServiceUnavailable, nil)
+		return
+	}
+	responses.ApiSuccess(ctx, http.StatusAccepted, "Import queued", importJobResponse(job, false))
+}
+
+// ListImportJobs handles GET /api/admin/imports.
+// It returns the most recent import jobs without their row errors.
+func (as *AdminSvc) ListImportJobs(ctx *gin.Context) {
+	jobs, err := as.importRepo.ListImportJobs(importJobListLimit)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve import jobs", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []admin.ImportJobResponse{}
+	for _, job := range jobs {
+		result = append(result, importJobResponse(job, false))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Import jobs retrieved successfully", result)
+}
+
+// GetImportJob handles GET /api/admin/imports/{job_id}.
+// Poll it to follow a background import; row errors are included.
+func (as *AdminSvc) GetImportJob(ctx *gin.Context) {
+	jobID, err := strconv.Atoi(ctx.Param("job_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid job ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, err := as.importRepo.GetImportJob(jobID)
+	if err != nil {
+		if errors.Is(err, repository.ErrImportJobNotFound) {
+			responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve import job", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Import job retrieved successfully", importJobResponse(job, true))
+}
+
+// importFile opens the uploaded CSV, limited to maxImportFileSize.
+// It writes the failure response itself and returns false when the request should stop.
+func importFile(ctx *gin.Context) (io.ReadCloser, string, bool) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+
+	mediaType, _, _ := mime.ParseMediaType(ctx.GetHeader("Content-Type"))
+	if mediaType != "multipart/form-data" {
+		return ctx.Request.Body, "", true
+	}
+
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			responses.ApiFailure(ctx, fmt.Sprintf("File is larger than %d MB", maxImportFileSize>>20), http.StatusRequestEntityTooLarge, nil)
+			return nil, "", false
+		}
+		responses.ApiFailure(ctx, "file is required", http.StatusBadRequest, err.Error())
+		return nil, "", false
+	}
+	file, err := header.Open()
+	if err != nil {
+		responses.ApiFailure(ctx, "Unable to read file", http.StatusBadRequest, err.Error())
+		return nil, "", false
+	}
+	return file, header.Filename, true
+}
+
+// parseImportFile reads a CSV import file for role. Rows that fail validation
+// are returned as row errors; an error is only returned when the file as a
+// whole cannot be used.
+func parseImportFile(role string, r io.Reader) ([]importRow, []admin.ImportRowError, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+		columns[name] = i
+	}
+	_, hasName := columns["name"]
+	var missing []string
+	for _, name := range importColumns[role] {
+		if _, ok := columns[name]; ok {
+			continue
+		}
+		if hasName && (name == "first_name" || name == "last_name") {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	if len(missing) > 0 {
+		return nil, nil, errors.New("missing column(s): " + strings.Join(missing, ", "))
+	}
+
+	var rows []importRow
+	var rowErrors []admin.ImportRowError
+	seenKeys := map[string]int{}
+	seenEmails := map[string]int{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		line, _ := reader.FieldPos(0)
+
+		field := func(name string) string {
+			i, ok := columns[name]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row, err := parseImportRow(role, line, field)
+		if err == nil {
+			email := strings.ToLower(field("email"))
+			if first, ok := seenKeys[row.key]; ok {
+				err = fmt.Errorf("duplicate of line %d", first)
+			} else if first, ok := seenEmails[email]; ok {
+				err = fmt.Errorf("email is also used on line %d", first)
+			} else {
+				seenKeys[row.key] = line
+				seenEmails[email] = line
+			}
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, admin.ImportRowError{Line: line, Key: row.key, Message: err.Error()})
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, rowErrors, nil
+}
+
+// parseImportRow validates one record. field returns a trimmed column value.
+// The returned row carries its key even when validation fails.
+func parseImportRow(role string, line int, field func(string) string) (importRow, error) {
+	firstName, lastName := field("first_name"), field("last_name")
+	if firstName == "" && lastName == "" {
+		if name := field("name"); name != "" {
+			if i := strings.LastIndex(name, " "); i > 0 {
+				firstName, lastName = strings.TrimSpace(name[:i]), name[i+1:]
+			} else {
+				firstName = name
+			}
+		}
+	}
+
+	row := importRow{line: line}
+	if role == "student" {
+		row.key = field("matric_number")
+	} else {
+		row.key = field("staff_id")
+	}
+
+	email := field("email")
+	department := field("department")
+	switch {
+	case firstName == "" || lastName == "":
+		return row, errors.New("first and last name are required")
+	case email == "":
+		return row, errors.New("email is required")
+	case row.key == "" && role == "student":
+		return row, errors.New("matric_number is required")
+	case row.key == "":
+		return row, errors.New("staff_id is required")
+	case department == "":
+		return row, errors.New("department is required")
+	}
+	if addr, err := mail.ParseAddress(email); err != nil || addr.Address != email {
+		return row, errors.New("email is not a valid address")
+	}
+
+	if role == "lecturer" {
+		row.lecturer = &admin.LecturerImportRow{
+			Line:       line,
+			FirstName:  firstName,
+			LastName:   lastName,
+			Email:      email,
+			StaffID:    row.key,
+			Department: department,
+		}
+		return row, nil
+	}
+
+	level, err := strconv.Atoi(field("level"))
+	if err != nil || level < 1 {
+		return row, errors.New("level must be a positive integer")
+	}
+	entrySession := field("entry_session")
+	if entrySession != "" && !utils.ValidAcademicSession(entrySession) {
+		return row, errors.New("entry_session must look like 2024/2025")
+	}
+	row.student = &admin.StudentImportRow{
+		Line:         line,
+		FirstName:    firstName,
+		LastName:     lastName,
+		Email:        email,
+		MatricNumber: row.key,
+		Department:   department,
+		Level:        level,
+		Faculty:      field("faculty"),
+		Programme:    field("programme"),
+		EntrySession: entrySession,
+	}
+	return row, nil
+}
+
+// importJobResponse maps an import job to its response DTO. Row errors are only
+// included when withErrors is set.
+func importJobResponse(job *entities.ImportJob, withErrors bool) admin.ImportJobResponse {
+	result := admin.ImportJobResponse{
+		ID:            int(job.ID),
+		Role:          job.Role,
+		Status:        job.Status,
+		DryRun:        job.DryRun,
+		FileName:      job.FileName,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		Created:       job.CreatedCount,
+		Updated:       job.UpdatedCount,
+		Failed:        job.FailedCount,
+		FailureReason: job.FailureReason,
+		CreatedByID:   job.CreatedBy,
+		CreatedAt:     job.CreatedAt.Format(time.RFC3339),
+	}
+	if job.TotalRows > 0 {
+		result.Progress = math.Round(float64(job.ProcessedRows)/float64(job.TotalRows)*1000) / 10
+	}
+	if job.StartedAt != nil {
+		startedAt := job.StartedAt.Format(time.RFC3339)
+		result.StartedAt = &startedAt
+	}
+	if job.FinishedAt != nil {
+		finishedAt := job.FinishedAt.Format(time.RFC3339)
+		result.FinishedAt = &finishedAt
+	}
+	if withErrors && job.Errors != "" {
+		if err := json.Unmarshal([]byte(job.Errors), &result.Errors); err != nil {
+			logger.Errorf("failed to decode errors of import job %d: %v", job.ID, err)
+		}
+	}
+	return result
+}
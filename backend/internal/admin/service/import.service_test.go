@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	admin "github.com/Dom-HTG/attendance-management-system/internal/admin/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/admin/repository"
+)
+
+func TestParseImportRow(t *testing.T) {
+	student := map[string]string{
+		"first_name": "Ada", "last_name": "Obi", "email": "ada@uni.edu",
+		"matric_number": "CSC/2024/001", "department": "Computer Science", "level": "200",
+	}
+	lecturer := map[string]string{
+		"first_name": "Grace", "last_name": "Eze", "email": "grace@uni.edu",
+		"staff_id": "STF-19", "department": "Computer Science",
+	}
+	with := func(base map[string]string, changes ...string) map[string]string {
+		record := map[string]string{}
+		for k, v := range base {
+			record[k] = v
+		}
+		for i := 0; i < len(changes); i += 2 {
+			record[changes[i]] = changes[i+1]
+		}
+		return record
+	}
+
+	tests := []struct {
+		name    string
+		role    string
+		record  map[string]string
+		wantErr string
+		wantKey string
+	}{
+		{"valid student", "student", student, "", "CSC/2024/001"},
+		{"valid lecturer", "lecturer", lecturer, "", "STF-19"},
+		{"single name column", "student", with(student, "first_name", "", "last_name", "", "name", "Ada Ngozi Obi"), "", "CSC/2024/001"},
+		{"single word name", "student", with(student, "first_name", "", "last_name", "", "name", "Ada"), "first and last name are required", "CSC/2024/001"},
+		{"missing last name", "lecturer", with(lecturer, "last_name", ""), "first and last name are required", "STF-19"},
+		{"missing email", "student", with(student, "email", ""), "email is required", "CSC/2024/001"},
+		{"missing matric number", "student", with(student, "matric_number", ""), "matric_number is required", ""},
+		{"missing staff ID", "lecturer", with(lecturer, "staff_id", ""), "staff_id is required", ""},
+		{"missing department", "student", with(student, "department", ""), "department is required", "CSC/2024/001"},
+		{"invalid email", "student", with(student, "email", "ada@"), "email is not a valid address", "CSC/2024/001"},
+		{"email with display name", "lecturer", with(lecturer, "email", "Grace <grace@uni.edu>"), "email is not a valid address", "STF-19"},
+		{"level not a number", "student", with(student, "level", "two"), "level must be a positive integer", "CSC/2024/001"},
+		{"level zero", "student", with(student, "level", "0"), "level must be a positive integer", "CSC/2024/001"},
+		{"lecturer ignores level", "lecturer", with(lecturer, "level", "x"), "", "STF-19"},
+		{"valid entry session", "student", with(student, "entry_session", "2024/2025"), "", "CSC/2024/001"},
+		{"invalid entry session", "student", with(student, "entry_session", "2024"), "entry_session must look like 2024/2025", "CSC/2024/001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row, err := parseImportRow(tt.role, 4, func(name string) string { return tt.record[name] })
+			if row.key != tt.wantKey || row.line != 4 {
+				t.Errorf("row key %q line %d, want %q line 4", row.key, row.line, tt.wantKey)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if (row.student != nil) != (tt.role == "student") || (row.lecturer != nil) != (tt.role == "lecturer") {
+				t.Errorf("row = %+v, want only the %s set", row, tt.role)
+			}
+		})
+	}
+
+	row, _ := parseImportRow("student", 2, func(name string) string {
+		return with(student, "first_name", "", "last_name", "", "name", "Ada Ngozi Obi", "faculty", "Science")[name]
+	})
+	want := admin.StudentImportRow{
+		Line: 2, FirstName: "Ada Ngozi", LastName: "Obi", Email: "ada@uni.edu", MatricNumber: "CSC/2024/001",
+		Department: "Computer Science", Level: 200, Faculty: "Science",
+	}
+	if row.student == nil || *row.student != want {
+		t.Errorf("student row = %+v, want %+v", row.student, want)
+	}
+}
+
+func TestParseImportFile(t *testing.T) {
+	tests := []struct {
+		name       string
+		role       string
+		file       string
+		wantErr    string
+		wantKeys   []string
+		wantErrors []admin.ImportRowError
+	}{
+		{
+			name:     "header names are normalised",
+			role:     "student",
+			file:     "\ufeffFirst Name,last-name,EMAIL,Matric Number,Department,Level\nAda,Obi,ada@uni.edu,M1,CS,100\n",
+			wantKeys: []string{"M1"},
+		},
+		{
+			name:     "name column replaces first and last name",
+			role:     "lecturer",
+			file:     "name,email,staff_id,department\nGrace Eze,grace@uni.edu,S1,CS\n",
+			wantKeys: []string{"S1"},
+		},
+		{
+			name:    "missing columns",
+			role:    "student",
+			file:    "first_name,last_name,email\nAda,Obi,ada@uni.edu\n",
+			wantErr: "missing column(s): matric_number, department, level",
+		},
+		{
+			name:     "empty file",
+			role:     "student",
+			file:     "",
+			wantKeys: nil,
+		},
+		{
+			name: "row errors keep line numbers",
+			role: "student",
+			file: "first_name,last_name,email,matric_number,department,level\n" +
+				"Ada,Obi,ada@uni.edu,M1,CS,100\n" +
+				",,,,,\n" +
+				"Bola,Ade,bola@uni.edu,M2,CS,abc\n" +
+				"Chi,Oko,chi@uni.edu,M1,CS,100\n" +
+				"Dayo,Ola,ADA@uni.edu,M3,CS,100\n" +
+				"Eke,Uzo,eke@uni.edu,M4,CS,300\n",
+			wantKeys: []string{"M1", "M4"},
+			wantErrors: []admin.ImportRowError{
+				{Line: 4, Key: "M2", Message: "level must be a positive integer"},
+				{Line: 5, Key: "M1", Message: "duplicate of line 2"},
+				{Line: 6, Key: "M3", Message: "email is also used on line 2"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, rowErrors, err := parseImportFile(tt.role, strings.NewReader(tt.file))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("err = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			var keys []string
+			for _, row := range rows {
+				keys = append(keys, row.key)
+			}
+			if !reflect.DeepEqual(keys, tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", keys, tt.wantKeys)
+			}
+			if !reflect.DeepEqual(rowErrors, tt.wantErrors) {
+				t.Errorf("row errors = %+v, want %+v", rowErrors, tt.wantErrors)
+			}
+		})
+	}
+}
+
+// fakeImportRepo creates students whose matric number it has not seen and
+// updates the rest.
+type fakeImportRepo struct {
+	repository.ImportRepoInterface
+	known        map[string]bool
+	failOn       string
+	passwordHash string
+	dryRun       bool
+}
+
+func (r *fakeImportRepo) UpdateImportJob(job *entities.ImportJob) error {
+	return nil
+}
+
+func (r *fakeImportRepo) UpsertStudent(row admin.StudentImportRow, passwordHash string, dryRun bool) (bool, error) {
+	r.passwordHash, r.dryRun = passwordHash, dryRun
+	if row.MatricNumber == r.failOn {
+		return false, errors.New("email is already used by another account")
+	}
+	return !r.known[row.MatricNumber], nil
+}
+
+func TestImporterProcess(t *testing.T) {
+	file := "first_name,last_name,email,matric_number,department,level\n" +
+		"Ada,Obi,ada@uni.edu,M1,CS,100\n" +
+		"Bola,Ade,bola@uni.edu,M2,CS,0\n" +
+		"Chi,Oko,chi@uni.edu,M3,CS,100\n" +
+		"Dayo,Ola,dayo@uni.edu,M4,CS,100\n"
+
+	for _, dryRun := range []bool{false, true} {
+		rows, rowErrors, err := parseImportFile("student", strings.NewReader(file))
+		if err != nil {
+			t.Fatalf("parseImportFile: %v", err)
+		}
+		repo := &fakeImportRepo{known: map[string]bool{"M3": true}, failOn: "M4"}
+		job := &entities.ImportJob{Role: "student", DryRun: dryRun, TotalRows: 4}
+		NewImporter(repo, 0).process(context.Background(), &importTask{job: job, rows: rows, errors: rowErrors})
+
+		if job.Status != entities.ImportCompleted || job.StartedAt == nil || job.FinishedAt == nil {
+			t.Errorf("dry run %t: job = %+v, want completed", dryRun, job)
+		}
+		if job.ProcessedRows != 4 || job.CreatedCount != 1 || job.UpdatedCount != 1 || job.FailedCount != 2 {
+			t.Errorf("dry run %t: processed %d, created %d, updated %d, failed %d; want 4, 1, 1, 2",
+				dryRun, job.ProcessedRows, job.CreatedCount, job.UpdatedCount, job.FailedCount)
+		}
+		if repo.dryRun != dryRun || (repo.passwordHash == "") != dryRun {
+			t.Errorf("dry run %t: repository got dry run %t and password hash %q", dryRun, repo.dryRun, repo.passwordHash)
+		}
+
+		var got []admin.ImportRowError
+		if err := json.Unmarshal([]byte(job.Errors), &got); err != nil {
+			t.Fatalf("errors %q: %v", job.Errors, err)
+		}
+		want := []admin.ImportRowError{
+			{Line: 3, Key: "M2", Message: "level must be a positive integer"},
+			{Line: 5, Key: "M4", Message: "email is already used by another account"},
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("dry run %t: errors = %+v, want %+v", dryRun, got, want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/pkg/lockout"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// UnlockUser handles POST /api/admin/users/{role}/{user_id}/unlock.
+// It clears the user's failed login attempts and lifts any lockout so they can
+// log in again immediately.
+func (as *AdminSvc) UnlockUser(ctx *gin.Context) {
+	user, ok := as.loadUser(ctx)
+	if !ok {
+		return
+	}
+
+	key := lockout.AccountKey(user.Role, user.Email)
+	state, ok := as.unlock(ctx, key)
+	if !ok {
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	logger.Security("login_unlocked", map[string]interface{}{
+		"admin_id": adminID,
+		"role":     user.Role,
+		"user_id":  user.ID,
+		"failures": state.Failures,
+	})
+
+	responses.ApiSuccess(ctx, http.StatusOK, "User unlocked successfully", map[string]interface{}{
+		"user":            user,
+		"failed_attempts": state.Failures,
+		"was_locked":      state.LockedUntil.After(time.Now()),
+	})
+}
+
+// UnlockIP handles POST /api/admin/lockouts/ip/{ip}/unlock.
+// It lifts a lockout on a client IP, for example a campus network address
+// shared by many users.
+func (as *AdminSvc) UnlockIP(ctx *gin.Context) {
+	ip := net.ParseIP(ctx.Param("ip"))
+	if ip == nil {
+		responses.ApiFailure(ctx, "ip must be a valid IP address", http.StatusBadRequest, nil)
+		return
+	}
+
+	state, ok := as.unlock(ctx, lockout.IPKey(ip.String()))
+	if !ok {
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	logger.Security("login_unlocked", map[string]interface{}{
+		"admin_id": adminID,
+		"ip":       ip.String(),
+		"failures": state.Failures,
+	})
+
+	responses.ApiSuccess(ctx, http.StatusOK, "IP address unlocked successfully", map[string]interface{}{
+		"ip":              ip.String(),
+		"failed_attempts": state.Failures,
+		"was_locked":      state.LockedUntil.After(time.Now()),
+	})
+}
+
+// unlock clears a lockout key and returns its state from before the unlock.
+// It writes the failure response itself and returns false when the request should stop.
+func (as *AdminSvc) unlock(ctx *gin.Context, key string) (lockout.State, bool) {
+	state, err := as.lockout.Status(ctx.Request.Context(), key)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve login attempts", http.StatusInternalServerError, err.Error())
+		return lockout.State{}, false
+	}
+	if err := as.lockout.Unlock(ctx.Request.Context(), key); err != nil {
+		responses.ApiFailure(ctx, "Failed to unlock", http.StatusInternalServerError, err.Error())
+		return lockout.State{}, false
+	}
+	return state, true
+}
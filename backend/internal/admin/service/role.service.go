@@ -0,0 +1,175 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	admin "github.com/Dom-HTG/attendance-management-system/internal/admin/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/admin/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// ListRoles handles GET /api/admin/roles.
+// It lists every role with its permissions and the scopes it can be assigned at.
+func (as *AdminSvc) ListRoles(ctx *gin.Context) {
+	responses.ApiSuccess(ctx, http.StatusOK, "Roles retrieved successfully", rbac.Roles())
+}
+
+// ListRoleAssignments handles GET /api/admin/role-assignments.
+// Results can be narrowed with lecturer_id and role.
+func (as *AdminSvc) ListRoleAssignments(ctx *gin.Context) {
+	var lecturerID *int
+	if raw := ctx.Query("lecturer_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			responses.ApiFailure(ctx, "Invalid lecturer ID", http.StatusBadRequest, err.Error())
+			return
+		}
+		lecturerID = &id
+	}
+
+	assignments, err := as.roleRepo.ListRoleAssignments(lecturerID, ctx.Query("role"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve role assignments", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []admin.RoleAssignmentResponse{}
+	for _, a := range assignments {
+		result = append(result, toRoleAssignmentResponse(a))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Role assignments retrieved successfully", result)
+}
+
+// AssignRole handles POST /api/admin/role-assignments.
+// Only lecturer accounts can be given additional roles. The new permissions
+// apply from the lecturer's next request.
+func (as *AdminSvc) AssignRole(ctx *gin.Context) {
+	adminID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return
+	}
+
+	var req admin.AssignRoleDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	def, ok := rbac.LookupRole(req.Role)
+	if !ok || !def.Assignable {
+		responses.ApiFailure(ctx, "Unknown or unassignable role "+req.Role, http.StatusBadRequest, nil)
+		return
+	}
+
+	scope := rbac.Scope{Type: rbac.ScopeType(req.ScopeType), Value: strings.TrimSpace(req.ScopeValue)}
+	if !allowsScopeType(def, scope.Type) {
+		responses.ApiFailure(ctx, "Role "+def.Name+" cannot be assigned at "+req.ScopeType+" scope", http.StatusBadRequest, nil)
+		return
+	}
+
+	switch scope.Type {
+	case rbac.ScopeGlobal:
+		scope.Value = ""
+	case rbac.ScopeDepartment:
+		if scope.Value == "" {
+			responses.ApiFailure(ctx, "scope_value must name a department", http.StatusBadRequest, nil)
+			return
+		}
+	case rbac.ScopeCourse:
+		scope.Value = strings.ToUpper(scope.Value)
+		exists, err := as.roleRepo.CourseExists(scope.Value)
+		if err != nil {
+			responses.ApiFailure(ctx, "Failed to check course", http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !exists {
+			responses.ApiFailure(ctx, "Course "+scope.Value+" not found", http.StatusNotFound, nil)
+			return
+		}
+	}
+
+	if _, err := as.adminRepo.GetLecturerByID(req.LecturerID); err != nil {
+		respondUserLookupError(ctx, err)
+		return
+	}
+
+	assignment := &entities.RoleAssignment{
+		UserID:     req.LecturerID,
+		UserRole:   rbac.RoleLecturer,
+		Role:       def.Name,
+		ScopeType:  string(scope.Type),
+		ScopeValue: scope.Value,
+		GrantedBy:  adminID,
+	}
+	if err := as.roleRepo.CreateRoleAssignment(assignment); err != nil {
+		if errors.Is(err, repository.ErrRoleAssignmentExists) {
+			responses.ApiFailure(ctx, err.Error(), http.StatusConflict, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to assign role", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.Infof("admin %d assigned role %s (%s %s) to lecturer %d", adminID, def.Name, scope.Type, scope.Value, req.LecturerID)
+	responses.ApiSuccess(ctx, http.StatusCreated, "Role assigned successfully", toRoleAssignmentResponse(assignment))
+}
+
+// RevokeRoleAssignment handles DELETE /api/admin/role-assignments/{assignment_id}.
+func (as *AdminSvc) RevokeRoleAssignment(ctx *gin.Context) {
+	assignmentID, err := strconv.Atoi(ctx.Param("assignment_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid assignment ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := as.roleRepo.DeleteRoleAssignment(assignmentID); err != nil {
+		if errors.Is(err, repository.ErrRoleAssignmentNotFound) {
+			responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to revoke role assignment", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	logger.Infof("admin %d revoked role assignment %d", adminID, assignmentID)
+	responses.ApiSuccess(ctx, http.StatusOK, "Role assignment revoked successfully", nil)
+}
+
+// allowsScopeType reports whether a role can be assigned at the given scope type.
+func allowsScopeType(def rbac.RoleDefinition, scopeType rbac.ScopeType) bool {
+	for _, t := range def.ScopeTypes {
+		if t == scopeType {
+			return true
+		}
+	}
+	return false
+}
+
+// toRoleAssignmentResponse maps a role assignment entity to its response DTO.
+func toRoleAssignmentResponse(a *entities.RoleAssignment) admin.RoleAssignmentResponse {
+	permissions := []rbac.Permission{}
+	if def, ok := rbac.LookupRole(a.Role); ok {
+		permissions = def.Permissions
+	}
+	return admin.RoleAssignmentResponse{
+		ID:          int(a.ID),
+		LecturerID:  a.UserID,
+		Role:        a.Role,
+		Scope:       rbac.Scope{Type: rbac.ScopeType(a.ScopeType), Value: a.ScopeValue},
+		Permissions: permissions,
+		GrantedByID: a.GrantedBy,
+		AssignedAt:  a.CreatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// ResetTwoFactor handles DELETE /api/admin/users/{role}/{user_id}/two-factor.
+// It removes the user's TOTP enrollment and recovery codes, for users who lost
+// both their authenticator and their codes. If their role requires two-factor
+// authentication they enroll again at their next login.
+func (as *AdminSvc) ResetTwoFactor(ctx *gin.Context) {
+	user, ok := as.loadUser(ctx)
+	if !ok {
+		return
+	}
+
+	if err := as.twoFactors.DeleteTwoFactor(user.Role, user.ID); err != nil {
+		if errors.Is(err, authRepo.ErrTwoFactorNotFound) {
+			responses.ApiFailure(ctx, "User has no two-factor authentication set up", http.StatusNotFound, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to reset two-factor authentication", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	adminID, _ := middleware.GetUserIDFromContext(ctx)
+	logger.Security("two_factor_reset", map[string]interface{}{
+		"admin_id": adminID,
+		"role":     user.Role,
+		"user_id":  user.ID,
+	})
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Two-factor authentication reset successfully", map[string]interface{}{
+		"user": user,
+	})
+}
@@ -2,6 +2,28 @@ package domain
 
 import "time"
 
+// ===== Reporting Period =====
+
+// Period limits analytics to sessions held from Start to End, both dates
+// inclusive. It comes from the session or semester query parameter; the zero
+// Period covers every session held so far.
+type Period struct {
+	Session  string    // Academic session name, e.g. 2024/2025
+	Semester string    // Semester name when the period is a single semester
+	Start    time.Time // First day
+	End      time.Time // Last day
+}
+
+// IsZero reports whether the period is unbounded.
+func (p Period) IsZero() bool {
+	return p.Start.IsZero() && p.End.IsZero()
+}
+
+// Bounds returns the first and last instants of the period.
+func (p Period) Bounds() (time.Time, time.Time) {
+	return p.Start, p.End.AddDate(0, 0, 1).Add(-time.Nanosecond)
+}
+
 // ===== Student Analytics =====
 
 // StudentMetricsResponse represents overall student attendance metrics
@@ -164,6 +186,27 @@ type DepartmentDeepDiveResponse struct {
 	GeneratedAt                  time.Time               `json:"generated_at"`
 }
 
+// DepartmentAtRiskResponse lists students falling below the attendance threshold in a department's courses
+type DepartmentAtRiskResponse struct {
+	DepartmentName string          `json:"department_name"`
+	Threshold      float64         `json:"threshold"`
+	TotalAtRisk    int             `json:"total_at_risk"`
+	Students       []AtRiskStudent `json:"students"`
+	GeneratedAt    time.Time       `json:"generated_at"`
+}
+
+// AtRiskStudent represents one student's standing in one course
+type AtRiskStudent struct {
+	StudentID        int     `json:"student_id"`
+	StudentName      string  `json:"student_name"`
+	MatricNumber     string  `json:"matric_number"`
+	CourseCode       string  `json:"course_code"`
+	CourseName       string  `json:"course_name"`
+	SessionsHeld     int     `json:"sessions_held"`
+	SessionsAttended int     `json:"sessions_attended"`
+	AttendanceRate   float64 `json:"attendance_rate"`
+}
+
 // CourseEnrollmentData for enrollment vs attendance analysis
 type CourseEnrollmentData struct {
 	CourseCode     string  `json:"course_code"`
@@ -182,9 +225,10 @@ type LecturerEfficiency struct {
 	EfficiencyRate    float64 `json:"efficiency_rate"`
 }
 
-// StudentEngagementYear for student engagement by year
+// StudentEngagementYear for student engagement by year. Averages cover the
+// students of the level who have had at least one session.
 type StudentEngagementYear struct {
-	Year              string  `json:"year"` // e.g., "100L", "200L"
+	Year              string  `json:"year"` // e.g., "100L", "200L"; "Unknown" for students without a level
 	StudentCount      int     `json:"student_count"`
 	AverageAttendance float64 `json:"average_attendance"`
 	EngagementScore   float64 `json:"engagement_score"`
@@ -324,7 +368,7 @@ type AnomalyResponse struct {
 // Anomaly represents a detected anomaly
 type Anomaly struct {
 	ID                int       `json:"id"`
-	Type              string    `json:"type"`     // "unusual_pattern", "fraud_suspected", "duplicate_checkin", "timing_anomaly"
+	Type              string    `json:"type"`     // "unusual_pattern", "fraud_suspected", "duplicate_checkin", "timing_anomaly", "geofence_flagged"
 	Severity          string    `json:"severity"` // "low", "medium", "high", "critical"
 	Description       string    `json:"description"`
 	StudentID         int       `json:"student_id,omitempty"`
@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPeriodBounds(t *testing.T) {
+	period := Period{Start: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC)}
+	start, end := period.Bounds()
+
+	if !start.Equal(period.Start) {
+		t.Errorf("start = %v, want %v", start, period.Start)
+	}
+	// The last day is inclusive, so an event late on 28 March is inside
+	lastEvent := time.Date(2025, 3, 28, 23, 59, 59, 0, time.UTC)
+	if end.Before(lastEvent) || !end.Before(time.Date(2025, 3, 29, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("end = %v, want the last instant of 28 March", end)
+	}
+
+	if period.IsZero() {
+		t.Error("bounded period reports IsZero")
+	}
+	if !(Period{Session: "2024/2025"}).IsZero() {
+		t.Error("period without dates is not IsZero")
+	}
+}
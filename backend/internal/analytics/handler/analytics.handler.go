@@ -1,12 +1,18 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/Dom-HTG/attendance-management-system/internal/analytics/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/analytics/repository"
 	"github.com/Dom-HTG/attendance-management-system/internal/analytics/service"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
 	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
 	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
 	"github.com/gin-gonic/gin"
 )
@@ -24,6 +30,8 @@ func NewAnalyticsHandler(svc service.AnalyticsServiceInterface) *AnalyticsHandle
 // ===== Student Analytics Endpoints =====
 
 // GetStudentMetrics handles GET /api/analytics/student/{student_id}
+// The route admits the student themselves and holders of department analytics
+// for the student's department; see OwnsTarget and StudentDepartment.
 func (ah *AnalyticsHandler) GetStudentMetrics(ctx *gin.Context) {
 	studentID, err := strconv.Atoi(ctx.Param("student_id"))
 	if err != nil {
@@ -31,21 +39,12 @@ func (ah *AnalyticsHandler) GetStudentMetrics(ctx *gin.Context) {
 		return
 	}
 
-	// Get current user's ID for authorization
-	currentUserID, ok := middleware.GetUserIDFromContext(ctx)
+	period, ok := ah.periodFromQuery(ctx)
 	if !ok {
-		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
-		return
-	}
-
-	// Students can only view their own metrics; lecturers/admins can view any
-	userRole, _ := middleware.GetUserRoleFromContext(ctx)
-	if userRole == "student" && currentUserID != studentID {
-		responses.ApiFailure(ctx, "Unauthorized: Students can only view their own metrics", http.StatusForbidden, nil)
 		return
 	}
 
-	metrics, err := ah.service.GetStudentMetrics(studentID)
+	metrics, err := ah.service.GetStudentMetrics(studentID, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to retrieve student metrics", http.StatusInternalServerError, err)
 		return
@@ -62,7 +61,12 @@ func (ah *AnalyticsHandler) GetStudentInsights(ctx *gin.Context) {
 		return
 	}
 
-	insights, err := ah.service.GetStudentInsights(studentID)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	insights, err := ah.service.GetStudentInsights(studentID, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to generate student insights", http.StatusInternalServerError, err)
 		return
@@ -81,7 +85,12 @@ func (ah *AnalyticsHandler) GetLecturerCourseMetrics(ctx *gin.Context) {
 		return
 	}
 
-	metrics, err := ah.service.GetLecturerCourseMetrics(lecturerID)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	metrics, err := ah.service.GetLecturerCourseMetrics(lecturerID, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to retrieve lecturer metrics", http.StatusInternalServerError, err)
 		return
@@ -91,6 +100,8 @@ func (ah *AnalyticsHandler) GetLecturerCourseMetrics(ctx *gin.Context) {
 }
 
 // GetLecturerCoursePerformance handles GET /api/analytics/lecturer/course/{course_code}
+// Lecturers see the courses they teach; heads of department and other holders of
+// department analytics see any course in the departments their role covers.
 func (ah *AnalyticsHandler) GetLecturerCoursePerformance(ctx *gin.Context) {
 	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
 	if !ok {
@@ -104,8 +115,32 @@ func (ah *AnalyticsHandler) GetLecturerCoursePerformance(ctx *gin.Context) {
 		return
 	}
 
-	performance, err := ah.service.GetLecturerCoursePerformance(lecturerID, courseCode)
+	department, err := ah.service.GetCourseDepartment(courseCode)
+	if err != nil {
+		if errors.Is(err, repository.ErrCourseNotFound) {
+			responses.ApiFailure(ctx, "Course not found", http.StatusNotFound, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve course performance", http.StatusInternalServerError, err)
+		return
+	}
+
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	var performance *domain.CoursePerformanceResponse
+	if middleware.HasPermission(ctx, rbac.PermAnalyticsDepartmentRead, rbac.Course(courseCode), rbac.Department(department)) {
+		performance, err = ah.service.GetCoursePerformance(courseCode, period)
+	} else {
+		performance, err = ah.service.GetLecturerCoursePerformance(lecturerID, courseCode, period)
+	}
 	if err != nil {
+		if errors.Is(err, repository.ErrCourseNotTaught) {
+			responses.ApiFailure(ctx, "Access denied. You do not teach this course or manage its department", http.StatusForbidden, nil)
+			return
+		}
 		responses.ApiFailure(ctx, "Failed to retrieve course performance", http.StatusInternalServerError, err)
 		return
 	}
@@ -121,7 +156,12 @@ func (ah *AnalyticsHandler) GetLecturerInsights(ctx *gin.Context) {
 		return
 	}
 
-	insights, err := ah.service.GetLecturerInsights(lecturerID)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	insights, err := ah.service.GetLecturerInsights(lecturerID, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to generate insights", http.StatusInternalServerError, err)
 		return
@@ -134,7 +174,12 @@ func (ah *AnalyticsHandler) GetLecturerInsights(ctx *gin.Context) {
 
 // GetAdminOverview handles GET /api/analytics/admin/overview
 func (ah *AnalyticsHandler) GetAdminOverview(ctx *gin.Context) {
-	overview, err := ah.service.GetAdminOverview()
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	overview, err := ah.service.GetAdminOverview(period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to retrieve admin overview", http.StatusInternalServerError, err)
 		return
@@ -151,7 +196,12 @@ func (ah *AnalyticsHandler) GetDepartmentMetrics(ctx *gin.Context) {
 		return
 	}
 
-	metrics, err := ah.service.GetDepartmentMetrics(department)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	metrics, err := ah.service.GetDepartmentMetrics(department, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to retrieve department metrics", http.StatusInternalServerError, err)
 		return
@@ -160,6 +210,40 @@ func (ah *AnalyticsHandler) GetDepartmentMetrics(ctx *gin.Context) {
 	responses.ApiSuccess(ctx, http.StatusOK, "Department metrics retrieved successfully", metrics)
 }
 
+// GetDepartmentAtRiskStudents handles GET /api/analytics/admin/department/{department}/at-risk
+// The optional threshold query parameter sets the attendance rate below which a
+// student is at risk (default 75).
+func (ah *AnalyticsHandler) GetDepartmentAtRiskStudents(ctx *gin.Context) {
+	department := ctx.Param("department")
+	if department == "" {
+		responses.ApiFailure(ctx, "Department name is required", http.StatusBadRequest, nil)
+		return
+	}
+
+	threshold := 75.0
+	if raw := ctx.Query("threshold"); raw != "" {
+		parsed, err := strconv.ParseFloat(raw, 64)
+		if err != nil || parsed <= 0 || parsed > 100 {
+			responses.ApiFailure(ctx, "threshold must be a number between 0 and 100", http.StatusBadRequest, nil)
+			return
+		}
+		threshold = parsed
+	}
+
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	atRisk, err := ah.service.GetDepartmentAtRiskStudents(department, threshold, period)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve at-risk students", http.StatusInternalServerError, err)
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "At-risk students retrieved successfully", atRisk)
+}
+
 // GetRealTimeDashboard handles GET /api/analytics/admin/realtime
 func (ah *AnalyticsHandler) GetRealTimeDashboard(ctx *gin.Context) {
 	dashboard, err := ah.service.GetRealTimeDashboard()
@@ -174,13 +258,15 @@ func (ah *AnalyticsHandler) GetRealTimeDashboard(ctx *gin.Context) {
 // ===== Temporal Analytics Endpoint =====
 
 // GetTemporalAnalytics handles GET /api/analytics/temporal
+// The range is given either by start_date and end_date or by a session or
+// semester filter.
 func (ah *AnalyticsHandler) GetTemporalAnalytics(ctx *gin.Context) {
 	startDateStr := ctx.Query("start_date")
 	endDateStr := ctx.Query("end_date")
 	granularity := ctx.Query("granularity") // daily, weekly, monthly
 
-	if startDateStr == "" || endDateStr == "" {
-		responses.ApiFailure(ctx, "start_date and end_date query parameters are required (RFC3339 format)", http.StatusBadRequest, nil)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
 		return
 	}
 
@@ -188,16 +274,29 @@ func (ah *AnalyticsHandler) GetTemporalAnalytics(ctx *gin.Context) {
 		granularity = "weekly"
 	}
 
-	startDate, err := time.Parse(time.RFC3339, startDateStr)
-	if err != nil {
-		responses.ApiFailure(ctx, "Invalid start_date format", http.StatusBadRequest, err)
+	var startDate, endDate time.Time
+	switch {
+	case startDateStr == "" && endDateStr == "" && !period.IsZero():
+		startDate, endDate = period.Bounds()
+	case !period.IsZero():
+		responses.ApiFailure(ctx, "Use either start_date and end_date or a session or semester filter", http.StatusBadRequest, nil)
 		return
-	}
-
-	endDate, err := time.Parse(time.RFC3339, endDateStr)
-	if err != nil {
-		responses.ApiFailure(ctx, "Invalid end_date format", http.StatusBadRequest, err)
+	case startDateStr == "" || endDateStr == "":
+		responses.ApiFailure(ctx, "start_date and end_date query parameters are required (RFC3339 format) unless session or semester is given", http.StatusBadRequest, nil)
 		return
+	default:
+		var err error
+		startDate, err = time.Parse(time.RFC3339, startDateStr)
+		if err != nil {
+			responses.ApiFailure(ctx, "Invalid start_date format", http.StatusBadRequest, err)
+			return
+		}
+
+		endDate, err = time.Parse(time.RFC3339, endDateStr)
+		if err != nil {
+			responses.ApiFailure(ctx, "Invalid end_date format", http.StatusBadRequest, err)
+			return
+		}
 	}
 
 	temporal, err := ah.service.GetTemporalAnalytics(startDate, endDate, granularity)
@@ -232,7 +331,12 @@ func (ah *AnalyticsHandler) PredictStudentAttendance(ctx *gin.Context) {
 		return
 	}
 
-	prediction, err := ah.service.PredictStudentAttendance(studentID)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	prediction, err := ah.service.PredictStudentAttendance(studentID, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to generate prediction", http.StatusInternalServerError, err)
 		return
@@ -276,7 +380,12 @@ func (ah *AnalyticsHandler) GetBenchmarkComparison(ctx *gin.Context) {
 		return
 	}
 
-	comparison, err := ah.service.GetBenchmarkComparison(entityType, entityID)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	comparison, err := ah.service.GetBenchmarkComparison(entityType, entityID, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to retrieve benchmark comparison", http.StatusInternalServerError, err)
 		return
@@ -297,6 +406,10 @@ func (ah *AnalyticsHandler) GetChartData(ctx *gin.Context) {
 		responses.ApiFailure(ctx, "chart_type, entity_type, and entity_id are required", http.StatusBadRequest, nil)
 		return
 	}
+	if entityType != "student" {
+		responses.ApiFailure(ctx, "Charts are only available for entity_type student", http.StatusBadRequest, nil)
+		return
+	}
 
 	entityID, err := strconv.Atoi(entityIDStr)
 	if err != nil {
@@ -304,7 +417,12 @@ func (ah *AnalyticsHandler) GetChartData(ctx *gin.Context) {
 		return
 	}
 
-	chartData, err := ah.service.GetChartData(chartType, entityType, entityID)
+	period, ok := ah.periodFromQuery(ctx)
+	if !ok {
+		return
+	}
+
+	chartData, err := ah.service.GetChartData(chartType, entityType, entityID, period)
 	if err != nil {
 		responses.ApiFailure(ctx, "Failed to retrieve chart data", http.StatusInternalServerError, err)
 		return
@@ -312,3 +430,124 @@ func (ah *AnalyticsHandler) GetChartData(ctx *gin.Context) {
 
 	responses.ApiSuccess(ctx, http.StatusOK, "Chart data retrieved successfully", chartData)
 }
+
+// ===== Access Control =====
+
+// OwnsTarget reports whether the caller owns what a request targets: a student
+// their own record, or a lecturer a course they teach. Routes pass it to
+// middleware.RequireOwnerOrPermission.
+func (ah *AnalyticsHandler) OwnsTarget(ctx *gin.Context) bool {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return false
+	}
+	role, _ := middleware.GetUserRoleFromContext(ctx)
+	switch role {
+	case rbac.RoleStudent:
+		studentID, ok := targetStudent(ctx)
+		return ok && studentID == userID
+	case rbac.RoleLecturer:
+		courseCode, ok := ah.targetCourse(ctx)
+		if !ok {
+			return false
+		}
+		teaches, err := ah.service.LecturerTeachesCourse(userID, courseCode)
+		return err == nil && teaches
+	default:
+		return false
+	}
+}
+
+// StudentDepartment resolves the department of the student a request targets.
+func (ah *AnalyticsHandler) StudentDepartment(ctx *gin.Context) (rbac.Scope, bool) {
+	studentID, ok := targetStudent(ctx)
+	if !ok {
+		return rbac.Scope{}, false
+	}
+	department, err := ah.service.GetStudentDepartment(studentID)
+	if err != nil || department == "" {
+		return rbac.Scope{}, false
+	}
+	return rbac.Department(department), true
+}
+
+// CourseScope resolves the course a request targets.
+func (ah *AnalyticsHandler) CourseScope(ctx *gin.Context) (rbac.Scope, bool) {
+	courseCode, ok := ah.targetCourse(ctx)
+	if !ok {
+		return rbac.Scope{}, false
+	}
+	return rbac.Course(courseCode), true
+}
+
+// CourseDepartment resolves the department that owns the course a request targets.
+func (ah *AnalyticsHandler) CourseDepartment(ctx *gin.Context) (rbac.Scope, bool) {
+	courseCode, ok := ah.targetCourse(ctx)
+	if !ok {
+		return rbac.Scope{}, false
+	}
+	department, err := ah.service.GetCourseDepartment(courseCode)
+	if err != nil || department == "" {
+		return rbac.Scope{}, false
+	}
+	return rbac.Department(department), true
+}
+
+// targetStudent returns the student a request names: the student_id URL
+// parameter, or entity_id when entity_type is student.
+func targetStudent(ctx *gin.Context) (int, bool) {
+	raw := ctx.Param("student_id")
+	if raw == "" && ctx.Query("entity_type") == "student" {
+		raw = ctx.Query("entity_id")
+	}
+	studentID, err := strconv.Atoi(raw)
+	return studentID, err == nil
+}
+
+// targetCourse returns the code of the course a request names: the
+// course_code URL parameter, or the course whose ID is entity_id when
+// entity_type is course.
+func (ah *AnalyticsHandler) targetCourse(ctx *gin.Context) (string, bool) {
+	if courseCode := strings.TrimSpace(ctx.Param("course_code")); courseCode != "" {
+		return courseCode, true
+	}
+	if ctx.Query("entity_type") != "course" {
+		return "", false
+	}
+	courseID, err := strconv.Atoi(ctx.Query("entity_id"))
+	if err != nil {
+		return "", false
+	}
+	courseCode, err := ah.service.GetCourseCode(courseID)
+	return courseCode, err == nil
+}
+
+// ===== Helpers =====
+
+// periodFromQuery resolves the optional session (ID or name, e.g. 2024/2025)
+// and semester (ID) query parameters into a reporting period.
+// It writes the failure response itself and returns false when the request should stop.
+func (ah *AnalyticsHandler) periodFromQuery(ctx *gin.Context) (domain.Period, bool) {
+	semesterID := 0
+	if raw := ctx.Query("semester"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			responses.ApiFailure(ctx, "semester must be a semester ID", http.StatusBadRequest, nil)
+			return domain.Period{}, false
+		}
+		semesterID = id
+	}
+
+	period, err := ah.service.ResolvePeriod(strings.TrimSpace(ctx.Query("session")), semesterID)
+	switch {
+	case err == nil:
+		return period, true
+	case errors.Is(err, calendarRepo.ErrSessionNotFound), errors.Is(err, calendarRepo.ErrSemesterNotFound):
+		responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+	case errors.Is(err, service.ErrSemesterNotInSession):
+		responses.ApiFailure(ctx, err.Error(), http.StatusBadRequest, nil)
+	default:
+		responses.ApiFailure(ctx, "Failed to resolve reporting period", http.StatusInternalServerError, err)
+	}
+	return domain.Period{}, false
+}
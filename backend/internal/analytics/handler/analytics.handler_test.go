@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Dom-HTG/attendance-management-system/internal/analytics/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/analytics/repository"
+	"github.com/Dom-HTG/attendance-management-system/internal/analytics/service"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeAnalytics knows one Computer Science course, taught by lecturer 5, and
+// one Computer Science student. It records which performance query ran.
+type fakeAnalytics struct {
+	service.AnalyticsServiceInterface
+	queried string
+}
+
+func (f *fakeAnalytics) ResolvePeriod(session string, semesterID int) (domain.Period, error) {
+	return domain.Period{}, nil
+}
+
+func (f *fakeAnalytics) GetCourseDepartment(courseCode string) (string, error) {
+	if courseCode != "CSC301" {
+		return "", repository.ErrCourseNotFound
+	}
+	return "Computer Science", nil
+}
+
+func (f *fakeAnalytics) GetStudentDepartment(studentID int) (string, error) {
+	return "Computer Science", nil
+}
+
+func (f *fakeAnalytics) GetStudentMetrics(studentID int, period domain.Period) (*domain.StudentMetricsResponse, error) {
+	return &domain.StudentMetricsResponse{}, nil
+}
+
+func (f *fakeAnalytics) GetCoursePerformance(courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error) {
+	f.queried = "department"
+	return &domain.CoursePerformanceResponse{}, nil
+}
+
+func (f *fakeAnalytics) GetLecturerCoursePerformance(lecturerID int, courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error) {
+	f.queried = "lecturer"
+	if lecturerID != 5 {
+		return nil, repository.ErrCourseNotTaught
+	}
+	return &domain.CoursePerformanceResponse{}, nil
+}
+
+func (f *fakeAnalytics) GetDepartmentMetrics(department string, period domain.Period) (*domain.DepartmentDeepDiveResponse, error) {
+	return &domain.DepartmentDeepDiveResponse{}, nil
+}
+
+// grantsOf returns the grants of a lecturer who is also head of department,
+// or of a plain lecturer when department is empty.
+func grantsOf(department string) []rbac.Grant {
+	lecturer, _ := rbac.AccountGrant(rbac.RoleLecturer)
+	if department == "" {
+		return []rbac.Grant{lecturer}
+	}
+	return []rbac.Grant{lecturer, {Role: rbac.RoleHeadOfDepartment, Scope: rbac.Department(department)}}
+}
+
+// serveRoute registers route with handlers and requests path as userID with
+// the given grants.
+func serveRoute(userID int, grants []rbac.Grant, route, path string, handlers ...gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	authenticate := func(ctx *gin.Context) {
+		ctx.Set("user_id", userID)
+		ctx.Set("grants", grants)
+	}
+	router.GET(route, append([]gin.HandlerFunc{authenticate}, handlers...)...)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w
+}
+
+func TestGetLecturerCoursePerformanceScope(t *testing.T) {
+	tests := []struct {
+		name        string
+		userID      int
+		department  string
+		path        string
+		want        int
+		wantQueried string
+	}{
+		{"teaching lecturer", 5, "", "/course/CSC301", http.StatusOK, "lecturer"},
+		{"other lecturer", 6, "", "/course/CSC301", http.StatusForbidden, "lecturer"},
+		{"head of the course's department", 6, "Computer Science", "/course/CSC301", http.StatusOK, "department"},
+		{"head of another department", 6, "Mathematics", "/course/CSC301", http.StatusForbidden, "lecturer"},
+		{"unknown course", 6, "Computer Science", "/course/MTH101", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &fakeAnalytics{}
+			ah := NewAnalyticsHandler(svc)
+			w := serveRoute(tt.userID, grantsOf(tt.department), "/course/:course_code", tt.path, ah.GetLecturerCoursePerformance)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if svc.queried != tt.wantQueried {
+				t.Errorf("queried %q performance, want %q", svc.queried, tt.wantQueried)
+			}
+		})
+	}
+}
+
+func TestDepartmentAnalyticsScope(t *testing.T) {
+	admin, _ := rbac.AccountGrant(rbac.RoleAdmin)
+
+	tests := []struct {
+		name   string
+		grants []rbac.Grant
+		path   string
+		want   int
+	}{
+		{"head of the department", grantsOf("Computer Science"), "/department/Computer%20Science", http.StatusOK},
+		{"head of another department", grantsOf("Mathematics"), "/department/Computer%20Science", http.StatusForbidden},
+		{"lecturer", grantsOf(""), "/department/Computer%20Science", http.StatusForbidden},
+		{"admin", []rbac.Grant{admin}, "/department/Mathematics", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ah := NewAnalyticsHandler(&fakeAnalytics{})
+			require := middleware.RequirePermission(rbac.PermAnalyticsDepartmentRead, middleware.DepartmentParam("department"))
+			w := serveRoute(6, tt.grants, "/department/:department", tt.path, require, ah.GetDepartmentMetrics)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestStudentAnalyticsScope(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     int
+		department string
+		want       int
+	}{
+		{"head of the student's department", 6, "Computer Science", http.StatusOK},
+		{"head of another department", 6, "Mathematics", http.StatusForbidden},
+		{"lecturer", 6, "", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ah := NewAnalyticsHandler(&fakeAnalytics{})
+			require := middleware.RequirePermission(rbac.PermAnalyticsDepartmentRead, ah.StudentDepartment)
+			w := serveRoute(tt.userID, grantsOf(tt.department), "/student/:student_id", "/student/12", require, ah.GetStudentMetrics)
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	"github.com/Dom-HTG/attendance-management-system/entities"
@@ -13,19 +14,25 @@ import (
 // AnalyticsRepoInterface defines analytics repository operations
 type AnalyticsRepoInterface interface {
 	// Student analytics
-	GetStudentMetrics(studentID int) (*domain.StudentMetricsResponse, error)
-	GetStudentPerCourseRates(studentID int) ([]domain.CourseAttendanceRate, error)
+	GetStudentMetrics(studentID int, period domain.Period) (*domain.StudentMetricsResponse, error)
+	GetStudentPerCourseRates(studentID int, period domain.Period) ([]domain.CourseAttendanceRate, error)
 	GetStudentAttendanceTrend(studentID int, startDate, endDate time.Time) ([]domain.TrendDataPoint, error)
-	GetStudentEngagementScore(studentID int) (float64, error)
-	IsStudentAtRisk(studentID int, threshold float64) (bool, error)
+	GetStudentEngagementScore(studentID int, period domain.Period) (float64, error)
+	IsStudentAtRisk(studentID int, threshold float64, period domain.Period) (bool, error)
 
 	// Lecturer analytics
-	GetLecturerCourseMetrics(lecturerID int) (*domain.LecturerCourseMetricsResponse, error)
-	GetLecturerCoursePerformance(lecturerID int, courseCode string) (*domain.CoursePerformanceResponse, error)
+	GetLecturerCourseMetrics(lecturerID int, period domain.Period) (*domain.LecturerCourseMetricsResponse, error)
+	GetLecturerCoursePerformance(lecturerID int, courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error)
+	GetCoursePerformance(courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error)
+	GetCourseDepartment(courseCode string) (string, error)
+	GetCourseCode(courseID int) (string, error)
+	GetStudentDepartment(studentID int) (string, error)
+	LecturerTeachesCourse(lecturerID int, courseCode string) (bool, error)
 
 	// Admin analytics
-	GetAdminOverview() (*domain.AdminOverviewResponse, error)
-	GetDepartmentMetrics(department string) (*domain.DepartmentDeepDiveResponse, error)
+	GetAdminOverview(period domain.Period) (*domain.AdminOverviewResponse, error)
+	GetDepartmentMetrics(department string, period domain.Period) (*domain.DepartmentDeepDiveResponse, error)
+	GetDepartmentAtRiskStudents(department string, threshold float64, period domain.Period) ([]domain.AtRiskStudent, error)
 	GetRealTimeDashboard() (*domain.RealTimeDashboardResponse, error)
 
 	// Temporal analytics
@@ -36,18 +43,83 @@ type AnalyticsRepoInterface interface {
 	GetAnomaliesByStudent(studentID int) ([]domain.Anomaly, error)
 
 	// Predictions
-	PredictStudentAttendance(studentID int) (*domain.PredictionResponse, error)
+	PredictStudentAttendance(studentID int, period domain.Period) (*domain.PredictionResponse, error)
 	PredictCourseAttendance(courseCode string) (*domain.PredictionResponse, error)
 
 	// Benchmarking
-	GetBenchmarkComparison(entityType string, entityID int) (*domain.BenchmarkResponse, error)
+	GetBenchmarkComparison(entityType string, entityID int, period domain.Period) (*domain.BenchmarkResponse, error)
 
 	// Utility methods
 	GetAttendanceRateForEntity(entityType string, entityID int, startDate, endDate time.Time) (float64, error)
 	GetLateCheckInCount(studentID int, startDate, endDate time.Time) (int, error)
 	GetAttendanceStreak(studentID int) (int, error)
+	GetCurrentSemester() (domain.Period, error)
 }
 
+var (
+	// ErrCourseNotFound is returned when no course matches a course code.
+	ErrCourseNotFound = errors.New("course not found")
+	// ErrCourseNotTaught is returned when a lecturer asks for a course they do not teach.
+	ErrCourseNotTaught = errors.New("course not found for this lecturer")
+	// ErrStudentNotFound is returned when no student matches a student ID.
+	ErrStudentNotFound = errors.New("student not found")
+)
+
+// expectedSessionsCTE expands enrollments into one row per session a student
+// was expected to attend: every started event of every course they are enrolled
+// in that was not cancelled, joined to the earliest attendance row they
+// produced for it (if any).
+// Attendance rates are computed as attended rows over expected rows, so
+// sessions a student missed count against them. Present and late both count as
+// attended, and sessions a student was excused from are left out entirely.
+// The %s verb takes the period filter built by eventsIn; use expectedSessions.
+const expectedSessionsCTE = `
+	WITH expected AS (
+		SELECT
+			en.student_id,
+			en.course_id,
+			e.id AS event_id,
+			e.event_name,
+			e.venue,
+			e.start_time,
+			e.end_time,
+			ua.status,
+			ua.marked_time,
+			COALESCE(ua.status IN ('present', 'late'), FALSE) AS attended,
+			COALESCE(ua.status = 'late', FALSE) AS late
+		FROM enrollments en
+		JOIN events e ON e.course_id = en.course_id AND e.deleted_at IS NULL AND e.status <> 'cancelled' AND e.start_time <= NOW()%s
+		LEFT JOIN LATERAL (
+			SELECT u.status, u.marked_time
+			FROM user_attendances u
+			WHERE u.attendance_id = e.id AND u.student_id = en.student_id AND u.deleted_at IS NULL
+			ORDER BY u.marked_time ASC
+			LIMIT 1
+		) ua ON TRUE
+		WHERE en.deleted_at IS NULL AND ua.status IS DISTINCT FROM 'excused'
+	)
+`
+
+// expectedSessions returns expectedSessionsCTE limited to events held within period.
+func expectedSessions(period domain.Period) string {
+	return fmt.Sprintf(expectedSessionsCTE, eventsIn("e", period))
+}
+
+// eventsIn returns an SQL condition, starting with AND, that keeps the events
+// aliased alias whose start date falls within period. The zero period keeps
+// every event. Dates are compared in the database's time zone, as when events
+// are tagged with their semester.
+func eventsIn(alias string, period domain.Period) string {
+	if period.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" AND %s.start_time::date BETWEEN '%s' AND '%s'",
+		alias, period.Start.Format(dateLayout), period.End.Format(dateLayout))
+}
+
+// dateLayout formats period dates for SQL.
+const dateLayout = "2006-01-02"
+
 // AnalyticsRepo implements AnalyticsRepoInterface
 type AnalyticsRepo struct {
 	db *gorm.DB
@@ -60,8 +132,10 @@ func NewAnalyticsRepo(db *gorm.DB) AnalyticsRepoInterface {
 
 // ===== Student Analytics =====
 
-// GetStudentMetrics returns comprehensive metrics for a student
-func (ar *AnalyticsRepo) GetStudentMetrics(studentID int) (*domain.StudentMetricsResponse, error) {
+// GetStudentMetrics returns comprehensive metrics for a student over period.
+// The attendance trend covers the period, else the current semester so far,
+// else the last 3 months.
+func (ar *AnalyticsRepo) GetStudentMetrics(studentID int, period domain.Period) (*domain.StudentMetricsResponse, error) {
 	var response domain.StudentMetricsResponse
 
 	// Get student info
@@ -70,17 +144,17 @@ func (ar *AnalyticsRepo) GetStudentMetrics(studentID int) (*domain.StudentMetric
 		return nil, errors.New("student not found")
 	}
 
-	// Get overall attendance rate
+	// Get overall attendance rate over sessions held for enrolled courses
 	var result struct {
 		TotalSessions int
 		TotalPresent  int
 	}
 
-	query := `
-		SELECT COUNT(ua.id) as total_sessions,
-		       SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) as total_present
-		FROM user_attendances ua
-		WHERE ua.student_id = ?
+	query := expectedSessions(period) + `
+		SELECT COUNT(*) as total_sessions,
+		       COUNT(*) FILTER (WHERE attended) as total_present
+		FROM expected
+		WHERE student_id = ?
 	`
 	if err := ar.db.Raw(query, studentID).Scan(&result).Error; err != nil {
 		return nil, err
@@ -98,25 +172,33 @@ func (ar *AnalyticsRepo) GetStudentMetrics(studentID int) (*domain.StudentMetric
 	}
 
 	// Get per-course rates
-	perCourseRates, err := ar.GetStudentPerCourseRates(studentID)
+	perCourseRates, err := ar.GetStudentPerCourseRates(studentID, period)
 	if err == nil {
 		response.PerCourseRates = perCourseRates
 	}
 
 	// Get attendance trend
-	trend, err := ar.GetStudentAttendanceTrend(studentID, time.Now().AddDate(0, -3, 0), time.Now())
+	trendStart, trendEnd := time.Now().AddDate(0, -3, 0), time.Now()
+	if window := ar.periodOrCurrentSemester(period); !window.IsZero() {
+		trendStart, trendEnd = window.Bounds()
+	}
+	trend, err := ar.GetStudentAttendanceTrend(studentID, trendStart, trendEnd)
 	if err == nil {
 		response.AttendanceTrend = trend
 	}
 
 	// Get engagement score
-	engScore, err := ar.GetStudentEngagementScore(studentID)
+	engScore, err := ar.GetStudentEngagementScore(studentID, period)
 	if err == nil {
 		response.EngagementScore = engScore
 	}
 
 	// Get late check-ins
-	lateCount, err := ar.GetLateCheckInCount(studentID, time.Time{}, time.Now())
+	lateStart, lateEnd := time.Time{}, time.Now()
+	if !period.IsZero() {
+		lateStart, lateEnd = period.Bounds()
+	}
+	lateCount, err := ar.GetLateCheckInCount(studentID, lateStart, lateEnd)
 	if err == nil {
 		response.TotalLate = lateCount
 		response.LateCheckInFrequency = lateCount
@@ -129,7 +211,7 @@ func (ar *AnalyticsRepo) GetStudentMetrics(studentID int) (*domain.StudentMetric
 	}
 
 	// Check if at risk
-	atRisk, err := ar.IsStudentAtRisk(studentID, 75)
+	atRisk, err := ar.IsStudentAtRisk(studentID, 75, period)
 	if err == nil {
 		response.AtRiskStatus = atRisk
 	}
@@ -138,21 +220,22 @@ func (ar *AnalyticsRepo) GetStudentMetrics(studentID int) (*domain.StudentMetric
 	return &response, nil
 }
 
-// GetStudentPerCourseRates returns attendance rate per course
-func (ar *AnalyticsRepo) GetStudentPerCourseRates(studentID int) ([]domain.CourseAttendanceRate, error) {
+// GetStudentPerCourseRates returns attendance rate per enrolled course
+func (ar *AnalyticsRepo) GetStudentPerCourseRates(studentID int, period domain.Period) ([]domain.CourseAttendanceRate, error) {
 	var rates []domain.CourseAttendanceRate
 
-	query := `
-		SELECT DISTINCT
-			COALESCE(e.event_name, 'Unknown') as course_code,
-			COALESCE(e.event_name, 'Unknown') as course_name,
-			COUNT(ua.id) as total_sessions,
-			SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) as sessions_attended,
-			ROUND(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / COUNT(ua.id), 2) as attendance_rate
-		FROM user_attendances ua
-		JOIN events e ON ua.attendance_id = e.id
-		WHERE ua.student_id = ?
-		GROUP BY e.id, e.event_name
+	query := expectedSessions(period) + `
+		SELECT
+			c.code as course_code,
+			c.title as course_name,
+			c.department as department,
+			COUNT(*) as total_sessions,
+			COUNT(*) FILTER (WHERE x.attended) as sessions_attended,
+			ROUND(AVG(CASE WHEN x.attended THEN 100.0 ELSE 0 END), 2) as attendance_rate
+		FROM expected x
+		JOIN courses c ON x.course_id = c.id
+		WHERE x.student_id = ?
+		GROUP BY c.id, c.code, c.title, c.department
 		ORDER BY attendance_rate DESC
 	`
 
@@ -167,16 +250,15 @@ func (ar *AnalyticsRepo) GetStudentPerCourseRates(studentID int) ([]domain.Cours
 func (ar *AnalyticsRepo) GetStudentAttendanceTrend(studentID int, startDate, endDate time.Time) ([]domain.TrendDataPoint, error) {
 	var trends []domain.TrendDataPoint
 
-	query := `
+	query := expectedSessions(domain.Period{}) + `
 		SELECT 
-			to_char(ua.marked_time, 'YYYY-IW') as period,
-			COUNT(ua.id) as total_sessions,
-			SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) as sessions_attended,
-			ROUND(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / COUNT(ua.id), 2) as attendance_rate,
-			ROUND(CAST(EXTRACT(EPOCH FROM AVG(ua.marked_time - e.start_time)) AS INT) / 60) as average_checkin_time
-		FROM user_attendances ua
-		JOIN events e ON ua.attendance_id = e.id
-		WHERE ua.student_id = ? AND ua.marked_time >= ? AND ua.marked_time <= ?
+			to_char(start_time, 'IYYY-IW') as period,
+			COUNT(*) as total_sessions,
+			COUNT(*) FILTER (WHERE attended) as sessions_attended,
+			ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 2) as attendance_rate,
+			COALESCE(ROUND(EXTRACT(EPOCH FROM AVG(marked_time - start_time) FILTER (WHERE attended)) / 60), 0) as average_checkin_time
+		FROM expected
+		WHERE student_id = ? AND start_time >= ? AND start_time <= ?
 		GROUP BY period
 		ORDER BY period
 	`
@@ -189,22 +271,21 @@ func (ar *AnalyticsRepo) GetStudentAttendanceTrend(studentID int, startDate, end
 }
 
 // GetStudentEngagementScore calculates engagement score (0-100)
-func (ar *AnalyticsRepo) GetStudentEngagementScore(studentID int) (float64, error) {
+func (ar *AnalyticsRepo) GetStudentEngagementScore(studentID int, period domain.Period) (float64, error) {
 	var result struct {
 		Score float64
 	}
 
-	// Engagement is based on consistency + punctuality
-	query := `
+	// Engagement is based on consistency (attended / expected sessions) + punctuality
+	query := expectedSessions(period) + `
 		SELECT 
 			ROUND((attendance_rate * 0.7) + (punctuality_score * 0.3), 2) as score
 		FROM (
 			SELECT
-				COALESCE(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(ua.id), 0), 0) as attendance_rate,
-				COALESCE(100 - (COUNT(CASE WHEN (ua.marked_time - e.start_time) > INTERVAL '5 minutes' THEN 1 END) * 100 / NULLIF(COUNT(ua.id), 0)), 100) as punctuality_score
-			FROM user_attendances ua
-			LEFT JOIN events e ON ua.attendance_id = e.id
-			WHERE ua.student_id = ?
+				COALESCE(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 0) as attendance_rate,
+				COALESCE(100 - (COUNT(*) FILTER (WHERE late) * 100.0 / NULLIF(COUNT(*) FILTER (WHERE attended), 0)), 100) as punctuality_score
+			FROM expected
+			WHERE student_id = ?
 		) sub
 	`
 
@@ -215,29 +296,32 @@ func (ar *AnalyticsRepo) GetStudentEngagementScore(studentID int) (float64, erro
 	return result.Score, nil
 }
 
-// IsStudentAtRisk checks if student is below attendance threshold
-func (ar *AnalyticsRepo) IsStudentAtRisk(studentID int, threshold float64) (bool, error) {
+// IsStudentAtRisk checks if student is below attendance threshold.
+// Students with no sessions held yet are never at risk.
+func (ar *AnalyticsRepo) IsStudentAtRisk(studentID int, threshold float64, period domain.Period) (bool, error) {
 	var result struct {
+		TotalSessions  int
 		AttendanceRate float64
 	}
 
-	query := `
-		SELECT COALESCE(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(ua.id), 0), 0) as attendance_rate
-		FROM user_attendances ua
-		WHERE ua.student_id = ?
+	query := expectedSessions(period) + `
+		SELECT COUNT(*) as total_sessions,
+		       COALESCE(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 0) as attendance_rate
+		FROM expected
+		WHERE student_id = ?
 	`
 
 	if err := ar.db.Raw(query, studentID).Scan(&result).Error; err != nil {
 		return false, err
 	}
 
-	return result.AttendanceRate < threshold, nil
+	return result.TotalSessions > 0 && result.AttendanceRate < threshold, nil
 }
 
 // ===== Lecturer Analytics =====
 
 // GetLecturerCourseMetrics returns all course metrics for a lecturer
-func (ar *AnalyticsRepo) GetLecturerCourseMetrics(lecturerID int) (*domain.LecturerCourseMetricsResponse, error) {
+func (ar *AnalyticsRepo) GetLecturerCourseMetrics(lecturerID int, period domain.Period) (*domain.LecturerCourseMetricsResponse, error) {
 	var response domain.LecturerCourseMetricsResponse
 
 	var lecturer entities.Lecturer
@@ -249,42 +333,178 @@ func (ar *AnalyticsRepo) GetLecturerCourseMetrics(lecturerID int) (*domain.Lectu
 	response.LecturerName = lecturer.FirstName + " " + lecturer.LastName
 	response.Department = lecturer.Department
 
-	// Get all courses for lecturer (inferred from events they created)
-	query := `
-		SELECT COUNT(DISTINCT e.id) as total_courses
-		FROM events e
-		WHERE e.created_by = ? OR e.id IN (
-			SELECT DISTINCT e2.id FROM events e2
-		)
+	// Per-course sessions held, enrolled students and attendance for every course the lecturer owns
+	query := expectedSessions(period) + `
+		SELECT
+			c.code as course_code,
+			c.title as course_name,
+			(SELECT COUNT(*) FROM events e WHERE e.course_id = c.id AND e.deleted_at IS NULL AND e.status <> 'cancelled' AND e.start_time <= NOW()` + eventsIn("e", period) + `) as session_count,
+			(SELECT COUNT(*) FROM enrollments en WHERE en.course_id = c.id AND en.deleted_at IS NULL) as student_count,
+			COALESCE(ROUND(AVG(CASE WHEN x.attended THEN 100.0 ELSE 0 END), 2), 0) as attendance_average
+		FROM courses c
+		JOIN course_lecturers cl ON cl.course_id = c.id
+		LEFT JOIN expected x ON x.course_id = c.id
+		WHERE cl.lecturer_id = ? AND c.deleted_at IS NULL
+		GROUP BY c.id, c.code, c.title
+		ORDER BY c.code
 	`
 
-	var courseCount int
-	ar.db.Raw(query, lecturerID).Scan(&courseCount)
-	response.TotalCourses = courseCount
+	var courseMetrics []domain.CourseMetrics
+	if err := ar.db.Raw(query, lecturerID).Scan(&courseMetrics).Error; err != nil {
+		return nil, err
+	}
+	response.CourseMetrics = courseMetrics
+	response.TotalCourses = len(courseMetrics)
+
+	// Average attendance across courses that have held sessions
+	heldCourses := 0
+	for _, cm := range courseMetrics {
+		if cm.SessionCount > 0 && cm.StudentCount > 0 {
+			response.AverageAttendance += cm.AttendanceAverage
+			heldCourses++
+		}
+	}
+	if heldCourses > 0 {
+		response.AverageAttendance /= float64(heldCourses)
+	}
+
+	// QR codes generated = events created by this lecturer
+	var qrCount int64
+	ar.db.Table("events e").Where("e.deleted_at IS NULL AND e.created_by = ?"+eventsIn("e", period), lecturerID).Count(&qrCount)
+	response.QRGeneratedCount = int(qrCount)
 
 	response.GeneratedAt = time.Now()
 	return &response, nil
 }
 
-// GetLecturerCoursePerformance returns detailed performance for a course
-func (ar *AnalyticsRepo) GetLecturerCoursePerformance(lecturerID int, courseCode string) (*domain.CoursePerformanceResponse, error) {
+// GetLecturerCoursePerformance returns detailed performance for a course the lecturer teaches
+func (ar *AnalyticsRepo) GetLecturerCoursePerformance(lecturerID int, courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error) {
+	var course entities.Course
+	if err := ar.db.
+		Joins("JOIN course_lecturers cl ON cl.course_id = courses.id").
+		Where("courses.code = ? AND cl.lecturer_id = ?", courseCode, lecturerID).
+		First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCourseNotTaught
+		}
+		return nil, err
+	}
+
+	return ar.coursePerformance(&course, period)
+}
+
+// GetCoursePerformance returns detailed performance for any course. Callers
+// are responsible for checking the requester may see it.
+func (ar *AnalyticsRepo) GetCoursePerformance(courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error) {
+	var course entities.Course
+	if err := ar.db.Where("code = ?", courseCode).First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCourseNotFound
+		}
+		return nil, err
+	}
+
+	return ar.coursePerformance(&course, period)
+}
+
+// GetCourseDepartment returns the department that owns a course
+func (ar *AnalyticsRepo) GetCourseDepartment(courseCode string) (string, error) {
+	var course entities.Course
+	if err := ar.db.Select("department").Where("code = ?", courseCode).First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrCourseNotFound
+		}
+		return "", err
+	}
+	return course.Department, nil
+}
+
+// GetCourseCode returns the code of a course
+func (ar *AnalyticsRepo) GetCourseCode(courseID int) (string, error) {
+	var course entities.Course
+	if err := ar.db.Select("code").Where("id = ?", courseID).First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrCourseNotFound
+		}
+		return "", err
+	}
+	return course.Code, nil
+}
+
+// GetStudentDepartment returns the department a student belongs to
+func (ar *AnalyticsRepo) GetStudentDepartment(studentID int) (string, error) {
+	var student entities.Student
+	if err := ar.db.Select("department").Where("id = ?", studentID).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", ErrStudentNotFound
+		}
+		return "", err
+	}
+	return student.Department, nil
+}
+
+// LecturerTeachesCourse reports whether a lecturer is one of a course's lecturers
+func (ar *AnalyticsRepo) LecturerTeachesCourse(lecturerID int, courseCode string) (bool, error) {
+	var count int64
+	if err := ar.db.Model(&entities.Course{}).
+		Joins("JOIN course_lecturers cl ON cl.course_id = courses.id").
+		Where("courses.code = ? AND cl.lecturer_id = ?", courseCode, lecturerID).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// coursePerformance computes the performance breakdown for a loaded course
+func (ar *AnalyticsRepo) coursePerformance(course *entities.Course, period domain.Period) (*domain.CoursePerformanceResponse, error) {
 	var response domain.CoursePerformanceResponse
 
-	query := `
-		SELECT 
-			COUNT(DISTINCT e.id) as session_count,
-			COUNT(DISTINCT ua.student_id) as student_count,
-			ROUND(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(ua.id), 0), 2) as overall_rate
-		FROM events e
-		LEFT JOIN user_attendances ua ON e.id = ua.attendance_id
-		WHERE e.event_name LIKE ?
+	// Per-student attendance rate over the sessions held for this course
+	query := expectedSessions(period) + `
+		SELECT student_id, AVG(CASE WHEN attended THEN 100.0 ELSE 0 END) as rate
+		FROM expected
+		WHERE course_id = ?
+		GROUP BY student_id
 	`
 
-	if err := ar.db.Raw(query, "%"+courseCode+"%").Scan(&response).Error; err != nil {
+	var studentRates []struct {
+		StudentID int
+		Rate      float64
+	}
+	if err := ar.db.Raw(query, course.ID).Scan(&studentRates).Error; err != nil {
 		return nil, err
 	}
 
-	response.CourseCode = courseCode
+	var enrolled int64
+	ar.db.Model(&entities.Enrollment{}).Where("course_id = ?", course.ID).Count(&enrolled)
+	response.StudentCount = int(enrolled)
+
+	total := 0.0
+	for _, sr := range studentRates {
+		total += sr.Rate
+		if sr.Rate < 75 {
+			response.StudentsAtRisk++
+		}
+		switch {
+		case sr.Rate < 20:
+			response.AttendanceDistribution.Range0To20++
+		case sr.Rate < 40:
+			response.AttendanceDistribution.Range20To40++
+		case sr.Rate < 60:
+			response.AttendanceDistribution.Range40To60++
+		case sr.Rate < 80:
+			response.AttendanceDistribution.Range60To80++
+		default:
+			response.AttendanceDistribution.Range80To100++
+		}
+	}
+	if len(studentRates) > 0 {
+		response.OverallAttendanceRate = total / float64(len(studentRates))
+	}
+
+	response.CourseCode = course.Code
+	response.CourseName = course.Title
+	response.Department = course.Department
 	response.GeneratedAt = time.Now()
 	return &response, nil
 }
@@ -292,19 +512,19 @@ func (ar *AnalyticsRepo) GetLecturerCoursePerformance(lecturerID int, courseCode
 // ===== Admin Analytics =====
 
 // GetAdminOverview returns university-wide metrics
-func (ar *AnalyticsRepo) GetAdminOverview() (*domain.AdminOverviewResponse, error) {
+func (ar *AnalyticsRepo) GetAdminOverview(period domain.Period) (*domain.AdminOverviewResponse, error) {
 	var response domain.AdminOverviewResponse
 
-	// Overall attendance rate
-	query := `
-		SELECT ROUND(CAST(SUM(CASE WHEN status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(*), 0), 2)
-		FROM user_attendances
+	// Overall attendance rate over every expected (enrolled) session
+	query := expectedSessions(period) + `
+		SELECT COALESCE(ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 2), 0)
+		FROM expected
 	`
 	ar.db.Raw(query).Scan(&response.OverallAttendanceRate)
 
 	// Active sessions
 	query = `
-		SELECT COUNT(*) FROM events WHERE start_time <= NOW() AND end_time >= NOW()
+		SELECT COUNT(*) FROM events WHERE deleted_at IS NULL AND status <> 'cancelled' AND start_time <= NOW() AND end_time >= NOW()
 	`
 	ar.db.Raw(query).Scan(&response.TotalActiveSessions)
 
@@ -320,52 +540,150 @@ func (ar *AnalyticsRepo) GetAdminOverview() (*domain.AdminOverviewResponse, erro
 }
 
 // GetDepartmentMetrics returns metrics for a specific department
-func (ar *AnalyticsRepo) GetDepartmentMetrics(department string) (*domain.DepartmentDeepDiveResponse, error) {
+func (ar *AnalyticsRepo) GetDepartmentMetrics(department string, period domain.Period) (*domain.DepartmentDeepDiveResponse, error) {
 	var response domain.DepartmentDeepDiveResponse
 
 	response.DepartmentName = department
 	response.GeneratedAt = time.Now()
 
-	// Get department-level attendance rate
-	query := `
-		SELECT ROUND(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(ua.id), 0), 2)
-		FROM user_attendances ua
-		JOIN students s ON ua.student_id = s.id
-		WHERE s.department = ?
+	// Get department-level attendance rate over sessions of the department's courses
+	query := expectedSessions(period) + `
+		SELECT COALESCE(ROUND(AVG(CASE WHEN x.attended THEN 100.0 ELSE 0 END), 2), 0)
+		FROM expected x
+		JOIN courses c ON x.course_id = c.id
+		WHERE LOWER(c.department) = LOWER(?)
 	`
 	ar.db.Raw(query, department).Scan(&response.OverallAttendanceRate)
 
+	// Enrollment vs attendance for each course in the department
+	query = expectedSessions(period) + `
+		SELECT
+			c.code as course_code,
+			c.title as course_name,
+			(SELECT COUNT(*) FROM enrollments en WHERE en.course_id = c.id AND en.deleted_at IS NULL) as enrolled,
+			COUNT(x.event_id) FILTER (WHERE x.attended) as actual_attended,
+			COALESCE(ROUND(AVG(CASE WHEN x.attended THEN 100.0 ELSE 0 END), 2), 0) as attendance_rate
+		FROM courses c
+		LEFT JOIN expected x ON x.course_id = c.id
+		WHERE LOWER(c.department) = LOWER(?) AND c.deleted_at IS NULL
+		GROUP BY c.id, c.code, c.title
+		ORDER BY c.code
+	`
+	var courseEnrollment []domain.CourseEnrollmentData
+	if err := ar.db.Raw(query, department).Scan(&courseEnrollment).Error; err == nil {
+		response.CourseEnrollmentVsAttendance = courseEnrollment
+		response.CourseCount = len(courseEnrollment)
+	}
+
 	// Count students, lecturers, courses
 	var studentCount, lecturerCount int64
-	ar.db.Model(&entities.Student{}).Where("department = ?", department).Count(&studentCount)
-	ar.db.Model(&entities.Lecturer{}).Where("department = ?", department).Count(&lecturerCount)
+	ar.db.Model(&entities.Student{}).Where("LOWER(department) = LOWER(?)", department).Count(&studentCount)
+	ar.db.Model(&entities.Lecturer{}).Where("LOWER(department) = LOWER(?)", department).Count(&lecturerCount)
 	response.StudentCount = int(studentCount)
 	response.LecturerCount = int(lecturerCount)
 
+	// Engagement of the department's students by level, over all their courses
+	query = expectedSessions(period) + `,
+		per_student AS (
+			SELECT
+				student_id,
+				AVG(CASE WHEN attended THEN 100.0 ELSE 0 END) as attendance_rate,
+				COALESCE(100 - (COUNT(*) FILTER (WHERE late) * 100.0 / NULLIF(COUNT(*) FILTER (WHERE attended), 0)), 100) as punctuality_score
+			FROM expected
+			GROUP BY student_id
+		)
+		SELECT
+			s.level as level,
+			COUNT(*) as student_count,
+			COALESCE(ROUND(AVG(p.attendance_rate), 2), 0) as average_attendance,
+			COALESCE(ROUND(AVG((p.attendance_rate * 0.7) + (p.punctuality_score * 0.3)), 2), 0) as engagement_score
+		FROM students s
+		LEFT JOIN per_student p ON p.student_id = s.id
+		WHERE LOWER(s.department) = LOWER(?) AND s.deleted_at IS NULL
+		GROUP BY s.level
+		ORDER BY s.level
+	`
+	var byLevel []struct {
+		Level             int
+		StudentCount      int
+		AverageAttendance float64
+		EngagementScore   float64
+	}
+	response.StudentEngagementByYear = []domain.StudentEngagementYear{}
+	if err := ar.db.Raw(query, department).Scan(&byLevel).Error; err == nil {
+		for _, row := range byLevel {
+			response.StudentEngagementByYear = append(response.StudentEngagementByYear, domain.StudentEngagementYear{
+				Year:              levelLabel(row.Level),
+				StudentCount:      row.StudentCount,
+				AverageAttendance: row.AverageAttendance,
+				EngagementScore:   row.EngagementScore,
+			})
+		}
+	}
+
 	return &response, nil
 }
 
+// levelLabel names a student level the way departments do, e.g. "200L".
+func levelLabel(level int) string {
+	if level <= 0 {
+		return "Unknown"
+	}
+	return fmt.Sprintf("%dL", level)
+}
+
+// GetDepartmentAtRiskStudents lists, for every course in a department, the
+// enrolled students whose attendance rate is below threshold, lowest first
+func (ar *AnalyticsRepo) GetDepartmentAtRiskStudents(department string, threshold float64, period domain.Period) ([]domain.AtRiskStudent, error) {
+	query := expectedSessions(period) + `
+		SELECT
+			s.id as student_id,
+			CONCAT(s.first_name, ' ', s.last_name) as student_name,
+			s.matric_number as matric_number,
+			c.code as course_code,
+			c.title as course_name,
+			COUNT(*) as sessions_held,
+			COUNT(*) FILTER (WHERE x.attended) as sessions_attended,
+			ROUND(AVG(CASE WHEN x.attended THEN 100.0 ELSE 0 END), 2) as attendance_rate
+		FROM expected x
+		JOIN courses c ON x.course_id = c.id AND c.deleted_at IS NULL
+		JOIN students s ON x.student_id = s.id AND s.deleted_at IS NULL
+		WHERE LOWER(c.department) = LOWER(?)
+		GROUP BY s.id, s.first_name, s.last_name, s.matric_number, c.id, c.code, c.title
+		HAVING AVG(CASE WHEN x.attended THEN 100.0 ELSE 0 END) < ?
+		ORDER BY attendance_rate ASC, c.code, s.matric_number
+	`
+
+	students := []domain.AtRiskStudent{}
+	if err := ar.db.Raw(query, department, threshold).Scan(&students).Error; err != nil {
+		return nil, errors.New("failed to retrieve at-risk students: " + err.Error())
+	}
+	return students, nil
+}
+
 // GetRealTimeDashboard returns live dashboard data
 func (ar *AnalyticsRepo) GetRealTimeDashboard() (*domain.RealTimeDashboardResponse, error) {
 	var response domain.RealTimeDashboardResponse
 
 	// Active sessions right now
 	query := `
-		SELECT COUNT(*) FROM events WHERE start_time <= NOW() AND end_time >= NOW()
+		SELECT COUNT(*) FROM events WHERE deleted_at IS NULL AND status <> 'cancelled' AND start_time <= NOW() AND end_time >= NOW()
 	`
 	ar.db.Raw(query).Scan(&response.ActiveSessionsNow)
 
 	// Total check-ins today
 	query = `
-		SELECT COUNT(*) FROM user_attendances WHERE DATE(marked_time) = CURRENT_DATE
+		SELECT COUNT(*) FROM user_attendances
+		WHERE DATE(marked_time) = CURRENT_DATE AND status IN ('present', 'late') AND deleted_at IS NULL
+		AND attendance_id NOT IN (SELECT id FROM events WHERE status = 'cancelled')
 	`
 	ar.db.Raw(query).Scan(&response.TotalCheckInsToday)
 
-	// Average attendance today
-	query = `
-		SELECT ROUND(CAST(SUM(CASE WHEN status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(*), 0), 2)
-		FROM user_attendances
-		WHERE DATE(marked_time) = CURRENT_DATE
+	// Average attendance across sessions held today
+	query = expectedSessions(domain.Period{}) + `
+		SELECT COALESCE(ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 2), 0)
+		FROM expected
+		WHERE DATE(start_time) = CURRENT_DATE
 	`
 	ar.db.Raw(query).Scan(&response.AverageAttendanceToday)
 
@@ -385,14 +703,16 @@ func (ar *AnalyticsRepo) GetTemporalAnalytics(startDate, endDate time.Time, gran
 	response.GeneratedAt = time.Now()
 
 	// Get day-of-week analysis
-	query := `
+	query := expectedSessions(domain.Period{}) + `
 		SELECT 
-			to_char(ua.marked_time, 'Day') as day_of_week,
-			ROUND(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(ua.id), 0), 2) as attendance_rate,
-			COUNT(ua.id) as session_count
-		FROM user_attendances ua
-		WHERE ua.marked_time >= ? AND ua.marked_time <= ?
-		GROUP BY to_char(ua.marked_time, 'Day')
+			trim(to_char(start_time, 'Day')) as day_of_week,
+			ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 2) as attendance_rate,
+			COUNT(DISTINCT event_id) as session_count,
+			COUNT(*) FILTER (WHERE attended) / NULLIF(COUNT(DISTINCT event_id), 0) as average_present
+		FROM expected
+		WHERE start_time >= ? AND start_time <= ?
+		GROUP BY trim(to_char(start_time, 'Day')), EXTRACT(ISODOW FROM start_time)
+		ORDER BY EXTRACT(ISODOW FROM start_time)
 	`
 
 	var dayMetrics []domain.DayOfWeekMetrics
@@ -400,9 +720,90 @@ func (ar *AnalyticsRepo) GetTemporalAnalytics(startDate, endDate time.Time, gran
 		response.DayOfWeekAnalysis = dayMetrics
 	}
 
+	holidayImpact, err := ar.getHolidayImpact(startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	response.HolidayImpact = holidayImpact
+
 	return &response, nil
 }
 
+// holidayImpactWindow is how far before and after a holiday attendance is
+// compared.
+const holidayImpactWindow = 7 // days
+
+// getHolidayImpact compares attendance around each public holiday and exam
+// period overlapping startDate to endDate. AttendanceRate covers the period
+// and the week after it, when classes resume; BeforePeriodRate covers the
+// week before.
+func (ar *AnalyticsRepo) getHolidayImpact(startDate, endDate time.Time) ([]domain.HolidayImpactData, error) {
+	var periods []struct {
+		Name      string
+		StartDate time.Time
+		EndDate   time.Time
+	}
+	query := `
+		SELECT name, start_date, end_date FROM holidays
+		WHERE deleted_at IS NULL AND start_date <= ?::date AND end_date >= ?::date
+		UNION ALL
+		SELECT name || ' exams', exam_start_date, exam_end_date FROM semesters
+		WHERE deleted_at IS NULL AND exam_start_date IS NOT NULL
+			AND exam_start_date <= ?::date AND exam_end_date >= ?::date
+		ORDER BY start_date
+	`
+	to, from := endDate.Format(dateLayout), startDate.Format(dateLayout)
+	if err := ar.db.Raw(query, to, from, to, from).Scan(&periods).Error; err != nil {
+		return nil, errors.New("failed to retrieve holidays: " + err.Error())
+	}
+
+	impact := []domain.HolidayImpactData{}
+	for _, p := range periods {
+		during, before := holidayImpactPeriods(p.StartDate, p.EndDate)
+
+		var rates struct {
+			AttendanceRate   float64
+			BeforePeriodRate float64
+		}
+		query := expectedSessions(domain.Period{Start: before.Start, End: during.End}) + `
+			SELECT
+				COALESCE(ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END) FILTER (WHERE start_time::date >= ?::date), 2), 0) as attendance_rate,
+				COALESCE(ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END) FILTER (WHERE start_time::date <= ?::date), 2), 0) as before_period_rate
+			FROM expected
+		`
+		if err := ar.db.Raw(query, during.Start.Format(dateLayout), before.End.Format(dateLayout)).Scan(&rates).Error; err != nil {
+			return nil, errors.New("failed to compute holiday impact: " + err.Error())
+		}
+
+		impact = append(impact, domain.HolidayImpactData{
+			PeriodName:       p.Name,
+			StartDate:        p.StartDate,
+			EndDate:          p.EndDate,
+			AttendanceRate:   rates.AttendanceRate,
+			BeforePeriodRate: rates.BeforePeriodRate,
+			ImpactPercent:    impactPercent(rates.AttendanceRate, rates.BeforePeriodRate),
+		})
+	}
+	return impact, nil
+}
+
+// holidayImpactPeriods returns the days compared for a holiday from start to
+// end: the holiday and the week after it, and the week before it.
+func holidayImpactPeriods(start, end time.Time) (during, before domain.Period) {
+	during = domain.Period{Start: start, End: end.AddDate(0, 0, holidayImpactWindow)}
+	before = domain.Period{Start: start.AddDate(0, 0, -holidayImpactWindow), End: start.AddDate(0, 0, -1)}
+	return during, before
+}
+
+// impactPercent returns the relative change from beforeRate to rate as a
+// percentage rounded to two decimals, or 0 when there is nothing to compare.
+func impactPercent(rate, beforeRate float64) float64 {
+	if beforeRate <= 0 {
+		return 0
+	}
+	return math.Round((rate-beforeRate)/beforeRate*10000) / 100
+}
+
 // ===== Anomalies =====
 
 // DetectAnomalies identifies unusual attendance patterns
@@ -446,6 +847,64 @@ func (ar *AnalyticsRepo) DetectAnomalies() (*domain.AnomalyResponse, error) {
 		}
 	}
 
+	// Check-ins accepted under lenient geofencing but flagged for review
+	flaggedQuery := `
+		SELECT
+			ua.student_id,
+			ua.attendance_id,
+			CONCAT(s.first_name, ' ', s.last_name) as student_name,
+			e.event_name,
+			ua.flag_reason,
+			ua.distance_meters,
+			ua.marked_time
+		FROM user_attendances ua
+		JOIN students s ON s.id = ua.student_id
+		JOIN events e ON e.id = ua.attendance_id AND e.status <> 'cancelled'
+		WHERE ua.flagged = TRUE AND ua.deleted_at IS NULL
+		ORDER BY ua.marked_time DESC
+		LIMIT 500
+	`
+
+	var flagged []struct {
+		StudentID      int
+		AttendanceID   int
+		StudentName    string
+		EventName      string
+		FlagReason     string
+		DistanceMeters *float64
+		MarkedTime     time.Time
+	}
+
+	if err := ar.db.Raw(flaggedQuery).Scan(&flagged).Error; err == nil {
+		for _, f := range flagged {
+			anomaly := domain.Anomaly{
+				Type:              "geofence_flagged",
+				Severity:          "low",
+				StudentID:         f.StudentID,
+				StudentName:       f.StudentName,
+				EventID:           f.AttendanceID,
+				CourseName:        f.EventName,
+				DetectionTime:     f.MarkedTime,
+				RecommendedAction: "Confirm the student was present before relying on this record",
+			}
+			switch f.FlagReason {
+			case entities.FlagOutsideGeofence:
+				anomaly.Severity = "medium"
+				anomaly.Description = "Check-in reported from outside the venue"
+				if f.DistanceMeters != nil {
+					anomaly.Description = fmt.Sprintf("Check-in reported %.0fm from the venue", *f.DistanceMeters)
+				}
+			case entities.FlagLowAccuracy:
+				anomaly.Description = "Check-in location was too imprecise to verify"
+			case entities.FlagAccuracyMissing:
+				anomaly.Description = "Check-in location did not include its accuracy"
+			default:
+				anomaly.Description = "Check-in did not include a device location"
+			}
+			response.Anomalies = append(response.Anomalies, anomaly)
+		}
+	}
+
 	response.AnomalyCount = len(response.Anomalies)
 	response.GeneratedAt = time.Now()
 	return &response, nil
@@ -460,20 +919,25 @@ func (ar *AnalyticsRepo) GetAnomaliesByStudent(studentID int) ([]domain.Anomaly,
 
 // ===== Predictions =====
 
-// PredictStudentAttendance predicts future attendance for a student
-func (ar *AnalyticsRepo) PredictStudentAttendance(studentID int) (*domain.PredictionResponse, error) {
+// PredictStudentAttendance predicts future attendance for a student from the
+// sessions held in period, else in the current semester, else in the last 4 weeks
+func (ar *AnalyticsRepo) PredictStudentAttendance(studentID int, period domain.Period) (*domain.PredictionResponse, error) {
 	var response domain.PredictionResponse
 
 	response.EntityType = "student"
 	response.EntityID = studentID
 	response.GeneratedAt = time.Now()
 
-	// Simple prediction: average of last 4 weeks
-	query := `
-		SELECT ROUND(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(ua.id), 0), 2)
-		FROM user_attendances ua
-		WHERE ua.student_id = ? AND ua.marked_time >= NOW() - INTERVAL '4 weeks'
+	// Simple prediction: current attendance over the window
+	window := ar.periodOrCurrentSemester(period)
+	query := expectedSessions(window) + `
+		SELECT COALESCE(ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 2), 0)
+		FROM expected
+		WHERE student_id = ?
 	`
+	if window.IsZero() {
+		query += ` AND start_time >= NOW() - INTERVAL '4 weeks'`
+	}
 	ar.db.Raw(query, studentID).Scan(&response.CurrentAttendance)
 
 	// Forecast (same as current for basic model)
@@ -502,7 +966,7 @@ func (ar *AnalyticsRepo) PredictCourseAttendance(courseCode string) (*domain.Pre
 // ===== Benchmarking =====
 
 // GetBenchmarkComparison returns peer comparison data
-func (ar *AnalyticsRepo) GetBenchmarkComparison(entityType string, entityID int) (*domain.BenchmarkResponse, error) {
+func (ar *AnalyticsRepo) GetBenchmarkComparison(entityType string, entityID int, period domain.Period) (*domain.BenchmarkResponse, error) {
 	var response domain.BenchmarkResponse
 
 	response.EntityType = entityType
@@ -511,18 +975,22 @@ func (ar *AnalyticsRepo) GetBenchmarkComparison(entityType string, entityID int)
 
 	switch entityType {
 	case "student":
-		// Get student's attendance
-		query := `
-			SELECT ROUND(CAST(SUM(CASE WHEN status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(*), 0), 2)
-			FROM user_attendances
+		// Get student's attendance over expected sessions
+		query := expectedSessions(period) + `
+			SELECT COALESCE(ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 2), 0)
+			FROM expected
 			WHERE student_id = ?
 		`
 		ar.db.Raw(query, entityID).Scan(&response.PerformanceValue)
 
-		// Get peer average
-		query = `
-			SELECT ROUND(CAST(SUM(CASE WHEN status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(*), 0), 2)
-			FROM user_attendances
+		// Get peer average (mean of per-student rates)
+		query = expectedSessions(period) + `
+			SELECT COALESCE(ROUND(AVG(rate), 2), 0)
+			FROM (
+				SELECT AVG(CASE WHEN attended THEN 100.0 ELSE 0 END) as rate
+				FROM expected
+				GROUP BY student_id
+			) per_student
 		`
 		ar.db.Raw(query).Scan(&response.PeerAverage)
 
@@ -538,7 +1006,9 @@ func (ar *AnalyticsRepo) GetBenchmarkComparison(entityType string, entityID int)
 		response.PerformanceVsPeers = "average"
 	}
 
-	response.PercentileRank = (response.PerformanceValue / response.PeerAverage) * 100
+	if response.PeerAverage > 0 {
+		response.PercentileRank = (response.PerformanceValue / response.PeerAverage) * 100
+	}
 
 	return &response, nil
 }
@@ -546,18 +1016,19 @@ func (ar *AnalyticsRepo) GetBenchmarkComparison(entityType string, entityID int)
 // ===== Utility Methods =====
 
 // GetAttendanceRateForEntity returns attendance rate for any entity
+// over the sessions it was expected to attend
 func (ar *AnalyticsRepo) GetAttendanceRateForEntity(entityType string, entityID int, startDate, endDate time.Time) (float64, error) {
 	var rate float64
 
-	query := `
-		SELECT ROUND(CAST(SUM(CASE WHEN ua.status = 'present' THEN 1 ELSE 0 END) AS FLOAT) * 100 / NULLIF(COUNT(ua.id), 0), 2)
-		FROM user_attendances ua
-		WHERE ua.%s = ? AND ua.marked_time >= ? AND ua.marked_time <= ?
+	query := expectedSessions(domain.Period{}) + `
+		SELECT COALESCE(ROUND(AVG(CASE WHEN attended THEN 100.0 ELSE 0 END), 2), 0)
+		FROM expected
+		WHERE %s = ? AND start_time >= ? AND start_time <= ?
 	`
 
 	field := "student_id"
 	if entityType == "course" {
-		field = "attendance_id"
+		field = "course_id"
 	}
 
 	query = fmt.Sprintf(query, field)
@@ -569,15 +1040,15 @@ func (ar *AnalyticsRepo) GetAttendanceRateForEntity(entityType string, entityID
 	return rate, nil
 }
 
-// GetLateCheckInCount returns count of late check-ins
+// GetLateCheckInCount returns count of check-ins recorded as late
 func (ar *AnalyticsRepo) GetLateCheckInCount(studentID int, startDate, endDate time.Time) (int, error) {
 	var count int
 
 	query := `
 		SELECT COUNT(ua.id)
 		FROM user_attendances ua
-		JOIN events e ON ua.attendance_id = e.id
-		WHERE ua.student_id = ? AND (ua.marked_time - e.start_time) > INTERVAL '5 minutes'
+		WHERE ua.student_id = ? AND ua.status = 'late' AND ua.deleted_at IS NULL
+		AND ua.attendance_id NOT IN (SELECT id FROM events WHERE status = 'cancelled')
 	`
 
 	if !startDate.IsZero() && !endDate.IsZero() {
@@ -594,28 +1065,69 @@ func (ar *AnalyticsRepo) GetLateCheckInCount(studentID int, startDate, endDate t
 	return count, nil
 }
 
-// GetAttendanceStreak returns current attendance streak
+// GetCurrentSemester returns the semester containing today as a period, or the
+// zero period when today falls outside every semester
+func (ar *AnalyticsRepo) GetCurrentSemester() (domain.Period, error) {
+	var rows []struct {
+		SessionName  string
+		SemesterName string
+		StartDate    time.Time
+		EndDate      time.Time
+	}
+	query := `
+		SELECT a.name as session_name, s.name as semester_name, s.start_date, s.end_date
+		FROM semesters s
+		JOIN academic_sessions a ON a.id = s.session_id AND a.deleted_at IS NULL
+		WHERE s.deleted_at IS NULL AND CURRENT_DATE BETWEEN s.start_date AND s.end_date
+		ORDER BY s.start_date
+		LIMIT 1
+	`
+	if err := ar.db.Raw(query).Scan(&rows).Error; err != nil {
+		return domain.Period{}, err
+	}
+	if len(rows) == 0 {
+		return domain.Period{}, nil
+	}
+	return domain.Period{
+		Session:  rows[0].SessionName,
+		Semester: rows[0].SemesterName,
+		Start:    rows[0].StartDate,
+		End:      rows[0].EndDate,
+	}, nil
+}
+
+// periodOrCurrentSemester returns period, or the current semester up to today
+// when period is zero. The result is zero when there is no current semester.
+func (ar *AnalyticsRepo) periodOrCurrentSemester(period domain.Period) domain.Period {
+	if !period.IsZero() {
+		return period
+	}
+	current, err := ar.GetCurrentSemester()
+	if err != nil || current.IsZero() {
+		return domain.Period{}
+	}
+	today := time.Now()
+	current.End = time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+	return current
+}
+
+// GetAttendanceStreak returns the number of consecutive expected sessions,
+// counting back from the most recent, that the student attended
 func (ar *AnalyticsRepo) GetAttendanceStreak(studentID int) (int, error) {
 	var streak int
 
-	query := `
-		WITH RECURSIVE streak_calc AS (
-			SELECT 
-				DATE(marked_time) as check_date,
-				ROW_NUMBER() OVER (ORDER BY DATE(marked_time) DESC) as rn
-			FROM user_attendances
-			WHERE student_id = ? AND status = 'present'
-			ORDER BY check_date DESC
-		)
+	query := expectedSessions(domain.Period{}) + `
 		SELECT COUNT(*) as streak
-		FROM streak_calc
-		WHERE rn <= DENSE_RANK() OVER (ORDER BY (DATE(check_date) - (rn || ' days')::INTERVAL))
-		LIMIT 1
+		FROM (
+			SELECT SUM(CASE WHEN attended THEN 0 ELSE 1 END) OVER (ORDER BY start_time DESC) as misses
+			FROM expected
+			WHERE student_id = ?
+		) s
+		WHERE misses = 0
 	`
 
 	if err := ar.db.Raw(query, studentID).Scan(&streak).Error; err != nil {
-		// Return 0 if query fails (different DB syntax)
-		return 0, nil
+		return 0, err
 	}
 
 	return streak, nil
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	domain "github.com/Dom-HTG/attendance-management-system/internal/analytics/domain"
+)
+
+func TestEventsIn(t *testing.T) {
+	tests := []struct {
+		name   string
+		alias  string
+		period domain.Period
+		want   string
+	}{
+		{"unbounded", "e", domain.Period{}, ""},
+		{
+			"semester",
+			"e",
+			domain.Period{Start: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC)},
+			" AND e.start_time::date BETWEEN '2025-01-06' AND '2025-03-28'",
+		},
+		{
+			"dates ignore the time of day",
+			"ev",
+			domain.Period{Start: time.Date(2025, 1, 6, 23, 30, 0, 0, time.UTC), End: time.Date(2025, 1, 6, 1, 0, 0, 0, time.UTC)},
+			" AND ev.start_time::date BETWEEN '2025-01-06' AND '2025-01-06'",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := eventsIn(tt.alias, tt.period); got != tt.want {
+				t.Errorf("eventsIn = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpectedSessions(t *testing.T) {
+	period := domain.Period{Start: time.Date(2025, 1, 6, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 28, 0, 0, 0, 0, time.UTC)}
+	query := expectedSessions(period)
+
+	// Sessions are expected from enrollments, not from check-ins, so a missed
+	// session still counts against the student
+	for _, want := range []string{
+		"FROM enrollments en",
+		"JOIN events e ON e.course_id = en.course_id",
+		"e.status <> 'cancelled'",
+		"e.start_time <= NOW()" + eventsIn("e", period),
+		"LEFT JOIN LATERAL",
+		"ua.status IS DISTINCT FROM 'excused'",
+		"COALESCE(ua.status IN ('present', 'late'), FALSE) AS attended",
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("expected sessions query lacks %q", want)
+		}
+	}
+	if strings.Contains(expectedSessions(domain.Period{}), "BETWEEN") {
+		t.Error("unbounded period filters events by date")
+	}
+}
+
+func TestHolidayImpactPeriods(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2025, 4, d, 0, 0, 0, 0, time.UTC) }
+
+	// Easter break from 18 to 21 April
+	during, before := holidayImpactPeriods(day(18), day(21))
+	if !during.Start.Equal(day(18)) || !during.End.Equal(day(28)) {
+		t.Errorf("during = %s to %s, want the break and the week after it", during.Start.Format(dateLayout), during.End.Format(dateLayout))
+	}
+	if !before.Start.Equal(day(11)) || !before.End.Equal(day(17)) {
+		t.Errorf("before = %s to %s, want the week before the break", before.Start.Format(dateLayout), before.End.Format(dateLayout))
+	}
+}
+
+func TestImpactPercent(t *testing.T) {
+	tests := []struct {
+		name             string
+		rate, beforeRate float64
+		want             float64
+	}{
+		{"drop", 60, 80, -25},
+		{"rise", 90, 80, 12.5},
+		{"unchanged", 75, 75, 0},
+		{"rounded to two decimals", 70, 90, -22.22},
+		{"nothing before", 50, 0, 0},
+		{"nothing during", 0, 80, -100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := impactPercent(tt.rate, tt.beforeRate); got != tt.want {
+				t.Errorf("impactPercent(%v, %v) = %v, want %v", tt.rate, tt.beforeRate, got, tt.want)
+			}
+		})
+	}
+}
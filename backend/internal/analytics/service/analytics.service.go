@@ -1,27 +1,44 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/Dom-HTG/attendance-management-system/entities"
 	"github.com/Dom-HTG/attendance-management-system/internal/analytics/domain"
 	"github.com/Dom-HTG/attendance-management-system/internal/analytics/repository"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
 )
 
+// ErrSemesterNotInSession is returned when the semester filter names a
+// semester of another academic session than the session filter.
+var ErrSemesterNotInSession = errors.New("semester does not belong to the academic session")
+
 // AnalyticsServiceInterface defines analytics service operations
 type AnalyticsServiceInterface interface {
+	// Reporting period
+	ResolvePeriod(session string, semesterID int) (domain.Period, error)
+
 	// Student analytics
-	GetStudentMetrics(studentID int) (*domain.StudentMetricsResponse, error)
-	GetStudentInsights(studentID int) (*domain.InsightResponse, error)
+	GetStudentMetrics(studentID int, period domain.Period) (*domain.StudentMetricsResponse, error)
+	GetStudentInsights(studentID int, period domain.Period) (*domain.InsightResponse, error)
 
 	// Lecturer analytics
-	GetLecturerCourseMetrics(lecturerID int) (*domain.LecturerCourseMetricsResponse, error)
-	GetLecturerCoursePerformance(lecturerID int, courseCode string) (*domain.CoursePerformanceResponse, error)
-	GetLecturerInsights(lecturerID int) (*domain.InsightResponse, error)
+	GetLecturerCourseMetrics(lecturerID int, period domain.Period) (*domain.LecturerCourseMetricsResponse, error)
+	GetLecturerCoursePerformance(lecturerID int, courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error)
+	GetCoursePerformance(courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error)
+	GetCourseDepartment(courseCode string) (string, error)
+	GetCourseCode(courseID int) (string, error)
+	GetStudentDepartment(studentID int) (string, error)
+	LecturerTeachesCourse(lecturerID int, courseCode string) (bool, error)
+	GetLecturerInsights(lecturerID int, period domain.Period) (*domain.InsightResponse, error)
 
 	// Admin analytics
-	GetAdminOverview() (*domain.AdminOverviewResponse, error)
-	GetDepartmentMetrics(department string) (*domain.DepartmentDeepDiveResponse, error)
+	GetAdminOverview(period domain.Period) (*domain.AdminOverviewResponse, error)
+	GetDepartmentMetrics(department string, period domain.Period) (*domain.DepartmentDeepDiveResponse, error)
+	GetDepartmentAtRiskStudents(department string, threshold float64, period domain.Period) (*domain.DepartmentAtRiskResponse, error)
 	GetRealTimeDashboard() (*domain.RealTimeDashboardResponse, error)
 
 	// Temporal analytics
@@ -31,36 +48,78 @@ type AnalyticsServiceInterface interface {
 	DetectAnomalies() (*domain.AnomalyResponse, error)
 
 	// Predictions
-	PredictStudentAttendance(studentID int) (*domain.PredictionResponse, error)
+	PredictStudentAttendance(studentID int, period domain.Period) (*domain.PredictionResponse, error)
 	PredictCourseAttendance(courseCode string) (*domain.PredictionResponse, error)
 
 	// Benchmarking
-	GetBenchmarkComparison(entityType string, entityID int) (*domain.BenchmarkResponse, error)
+	GetBenchmarkComparison(entityType string, entityID int, period domain.Period) (*domain.BenchmarkResponse, error)
 
 	// Chart data
-	GetChartData(chartType string, entityType string, entityID int) (*domain.ChartDataResponse, error)
+	GetChartData(chartType string, entityType string, entityID int, period domain.Period) (*domain.ChartDataResponse, error)
 }
 
 // AnalyticsService implements AnalyticsServiceInterface
 type AnalyticsService struct {
-	repo repository.AnalyticsRepoInterface
+	repo         repository.AnalyticsRepoInterface
+	calendarRepo calendarRepo.CalendarRepoInterface
 }
 
 // NewAnalyticsService creates a new analytics service
-func NewAnalyticsService(repo repository.AnalyticsRepoInterface) AnalyticsServiceInterface {
-	return &AnalyticsService{repo: repo}
+func NewAnalyticsService(repo repository.AnalyticsRepoInterface, calendar calendarRepo.CalendarRepoInterface) AnalyticsServiceInterface {
+	return &AnalyticsService{repo: repo, calendarRepo: calendar}
+}
+
+// ===== Reporting Period =====
+
+// ResolvePeriod returns the period selected by the session and semester
+// filters. session is an academic session ID or name such as "2024/2025" and
+// semesterID a semester ID; empty and zero values leave the filter out. Unknown
+// sessions and semesters return the calendar repository's not-found errors.
+func (as *AnalyticsService) ResolvePeriod(session string, semesterID int) (domain.Period, error) {
+	var period domain.Period
+	if session != "" {
+		found, err := as.lookupSession(session)
+		if err != nil {
+			return domain.Period{}, err
+		}
+		period = domain.Period{Session: found.Name, Start: found.StartDate, End: found.EndDate}
+	}
+	if semesterID == 0 {
+		return period, nil
+	}
+
+	semester, err := as.calendarRepo.GetSemesterByID(semesterID)
+	if err != nil {
+		return domain.Period{}, err
+	}
+	parent, err := as.calendarRepo.GetSessionByID(semester.SessionID)
+	if err != nil {
+		return domain.Period{}, err
+	}
+	if period.Session != "" && period.Session != parent.Name {
+		return domain.Period{}, ErrSemesterNotInSession
+	}
+	return domain.Period{Session: parent.Name, Semester: semester.Name, Start: semester.StartDate, End: semester.EndDate}, nil
+}
+
+// lookupSession finds an academic session by ID or name.
+func (as *AnalyticsService) lookupSession(session string) (*entities.AcademicSession, error) {
+	if id, err := strconv.Atoi(session); err == nil {
+		return as.calendarRepo.GetSessionByID(id)
+	}
+	return as.calendarRepo.GetSessionByName(session)
 }
 
 // ===== Student Analytics =====
 
 // GetStudentMetrics returns comprehensive metrics for a student
-func (as *AnalyticsService) GetStudentMetrics(studentID int) (*domain.StudentMetricsResponse, error) {
-	return as.repo.GetStudentMetrics(studentID)
+func (as *AnalyticsService) GetStudentMetrics(studentID int, period domain.Period) (*domain.StudentMetricsResponse, error) {
+	return as.repo.GetStudentMetrics(studentID, period)
 }
 
 // GetStudentInsights generates natural language insights for a student
-func (as *AnalyticsService) GetStudentInsights(studentID int) (*domain.InsightResponse, error) {
-	metrics, err := as.repo.GetStudentMetrics(studentID)
+func (as *AnalyticsService) GetStudentInsights(studentID int, period domain.Period) (*domain.InsightResponse, error) {
+	metrics, err := as.repo.GetStudentMetrics(studentID, period)
 	if err != nil {
 		return nil, err
 	}
@@ -143,18 +202,43 @@ func (as *AnalyticsService) GetStudentInsights(studentID int) (*domain.InsightRe
 // ===== Lecturer Analytics =====
 
 // GetLecturerCourseMetrics returns course metrics for a lecturer
-func (as *AnalyticsService) GetLecturerCourseMetrics(lecturerID int) (*domain.LecturerCourseMetricsResponse, error) {
-	return as.repo.GetLecturerCourseMetrics(lecturerID)
+func (as *AnalyticsService) GetLecturerCourseMetrics(lecturerID int, period domain.Period) (*domain.LecturerCourseMetricsResponse, error) {
+	return as.repo.GetLecturerCourseMetrics(lecturerID, period)
 }
 
 // GetLecturerCoursePerformance returns detailed performance for a lecturer's course
-func (as *AnalyticsService) GetLecturerCoursePerformance(lecturerID int, courseCode string) (*domain.CoursePerformanceResponse, error) {
-	return as.repo.GetLecturerCoursePerformance(lecturerID, courseCode)
+func (as *AnalyticsService) GetLecturerCoursePerformance(lecturerID int, courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error) {
+	return as.repo.GetLecturerCoursePerformance(lecturerID, courseCode, period)
+}
+
+// GetCoursePerformance returns detailed performance for any course
+func (as *AnalyticsService) GetCoursePerformance(courseCode string, period domain.Period) (*domain.CoursePerformanceResponse, error) {
+	return as.repo.GetCoursePerformance(courseCode, period)
+}
+
+// GetCourseDepartment returns the department that owns a course
+func (as *AnalyticsService) GetCourseDepartment(courseCode string) (string, error) {
+	return as.repo.GetCourseDepartment(courseCode)
+}
+
+// GetCourseCode returns the code of a course
+func (as *AnalyticsService) GetCourseCode(courseID int) (string, error) {
+	return as.repo.GetCourseCode(courseID)
+}
+
+// GetStudentDepartment returns the department a student belongs to
+func (as *AnalyticsService) GetStudentDepartment(studentID int) (string, error) {
+	return as.repo.GetStudentDepartment(studentID)
+}
+
+// LecturerTeachesCourse reports whether a lecturer is one of a course's lecturers
+func (as *AnalyticsService) LecturerTeachesCourse(lecturerID int, courseCode string) (bool, error) {
+	return as.repo.LecturerTeachesCourse(lecturerID, courseCode)
 }
 
 // GetLecturerInsights generates insights for a lecturer
-func (as *AnalyticsService) GetLecturerInsights(lecturerID int) (*domain.InsightResponse, error) {
-	metrics, err := as.repo.GetLecturerCourseMetrics(lecturerID)
+func (as *AnalyticsService) GetLecturerInsights(lecturerID int, period domain.Period) (*domain.InsightResponse, error) {
+	metrics, err := as.repo.GetLecturerCourseMetrics(lecturerID, period)
 	if err != nil {
 		return nil, err
 	}
@@ -206,13 +290,29 @@ func (as *AnalyticsService) GetLecturerInsights(lecturerID int) (*domain.Insight
 // ===== Admin Analytics =====
 
 // GetAdminOverview returns university-wide overview
-func (as *AnalyticsService) GetAdminOverview() (*domain.AdminOverviewResponse, error) {
-	return as.repo.GetAdminOverview()
+func (as *AnalyticsService) GetAdminOverview(period domain.Period) (*domain.AdminOverviewResponse, error) {
+	return as.repo.GetAdminOverview(period)
 }
 
 // GetDepartmentMetrics returns department-level metrics
-func (as *AnalyticsService) GetDepartmentMetrics(department string) (*domain.DepartmentDeepDiveResponse, error) {
-	return as.repo.GetDepartmentMetrics(department)
+func (as *AnalyticsService) GetDepartmentMetrics(department string, period domain.Period) (*domain.DepartmentDeepDiveResponse, error) {
+	return as.repo.GetDepartmentMetrics(department, period)
+}
+
+// GetDepartmentAtRiskStudents returns the students below threshold in a department's courses
+func (as *AnalyticsService) GetDepartmentAtRiskStudents(department string, threshold float64, period domain.Period) (*domain.DepartmentAtRiskResponse, error) {
+	students, err := as.repo.GetDepartmentAtRiskStudents(department, threshold, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.DepartmentAtRiskResponse{
+		DepartmentName: department,
+		Threshold:      threshold,
+		TotalAtRisk:    len(students),
+		Students:       students,
+		GeneratedAt:    time.Now(),
+	}, nil
 }
 
 // GetRealTimeDashboard returns real-time dashboard data
@@ -237,8 +337,8 @@ func (as *AnalyticsService) DetectAnomalies() (*domain.AnomalyResponse, error) {
 // ===== Predictions =====
 
 // PredictStudentAttendance predicts future student attendance
-func (as *AnalyticsService) PredictStudentAttendance(studentID int) (*domain.PredictionResponse, error) {
-	pred, err := as.repo.PredictStudentAttendance(studentID)
+func (as *AnalyticsService) PredictStudentAttendance(studentID int, period domain.Period) (*domain.PredictionResponse, error) {
+	pred, err := as.repo.PredictStudentAttendance(studentID, period)
 	if err != nil {
 		return nil, err
 	}
@@ -265,14 +365,15 @@ func (as *AnalyticsService) PredictCourseAttendance(courseCode string) (*domain.
 // ===== Benchmarking =====
 
 // GetBenchmarkComparison returns peer comparison data
-func (as *AnalyticsService) GetBenchmarkComparison(entityType string, entityID int) (*domain.BenchmarkResponse, error) {
-	return as.repo.GetBenchmarkComparison(entityType, entityID)
+func (as *AnalyticsService) GetBenchmarkComparison(entityType string, entityID int, period domain.Period) (*domain.BenchmarkResponse, error) {
+	return as.repo.GetBenchmarkComparison(entityType, entityID, period)
 }
 
 // ===== Chart Data =====
 
-// GetChartData returns data formatted for charts
-func (as *AnalyticsService) GetChartData(chartType string, entityType string, entityID int) (*domain.ChartDataResponse, error) {
+// GetChartData returns data formatted for charts. Trends cover period, else
+// the current semester so far, else the last 3 months.
+func (as *AnalyticsService) GetChartData(chartType string, entityType string, entityID int, period domain.Period) (*domain.ChartDataResponse, error) {
 	response := &domain.ChartDataResponse{
 		ChartType:   chartType,
 		GeneratedAt: time.Now(),
@@ -286,6 +387,11 @@ func (as *AnalyticsService) GetChartData(chartType string, entityType string, en
 		// Get trend data
 		startDate := time.Now().AddDate(0, -3, 0)
 		endDate := time.Now()
+		if !period.IsZero() {
+			startDate, endDate = period.Bounds()
+		} else if current, err := as.repo.GetCurrentSemester(); err == nil && !current.IsZero() {
+			startDate, _ = current.Bounds()
+		}
 		trend, err := as.repo.GetStudentAttendanceTrend(entityID, startDate, endDate)
 		if err != nil {
 			return nil, err
@@ -311,7 +417,7 @@ func (as *AnalyticsService) GetChartData(chartType string, entityType string, en
 		response.Title = "Course Comparison"
 		response.Description = "Attendance rates across courses"
 
-		rates, err := as.repo.GetStudentPerCourseRates(entityID)
+		rates, err := as.repo.GetStudentPerCourseRates(entityID, period)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"github.com/Dom-HTG/attendance-management-system/internal/analytics/domain"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
+	"gorm.io/gorm"
+)
+
+// fakeCalendar holds two academic sessions with one semester each.
+type fakeCalendar struct {
+	calendarRepo.CalendarRepoInterface
+	sessions  []entities.AcademicSession
+	semesters []entities.Semester
+}
+
+func (c *fakeCalendar) GetSessionByID(sessionID int) (*entities.AcademicSession, error) {
+	for i := range c.sessions {
+		if int(c.sessions[i].ID) == sessionID {
+			return &c.sessions[i], nil
+		}
+	}
+	return nil, calendarRepo.ErrSessionNotFound
+}
+
+func (c *fakeCalendar) GetSessionByName(name string) (*entities.AcademicSession, error) {
+	for i := range c.sessions {
+		if c.sessions[i].Name == name {
+			return &c.sessions[i], nil
+		}
+	}
+	return nil, calendarRepo.ErrSessionNotFound
+}
+
+func (c *fakeCalendar) GetSemesterByID(semesterID int) (*entities.Semester, error) {
+	for i := range c.semesters {
+		if int(c.semesters[i].ID) == semesterID {
+			return &c.semesters[i], nil
+		}
+	}
+	return nil, calendarRepo.ErrSemesterNotFound
+}
+
+func TestResolvePeriod(t *testing.T) {
+	date := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	calendar := &fakeCalendar{
+		sessions: []entities.AcademicSession{
+			{Model: gorm.Model{ID: 1}, Name: "2023/2024", StartDate: date(2023, 9, 4), EndDate: date(2024, 7, 26)},
+			{Model: gorm.Model{ID: 2}, Name: "2024/2025", StartDate: date(2024, 9, 2), EndDate: date(2025, 7, 25)},
+		},
+		semesters: []entities.Semester{
+			{Model: gorm.Model{ID: 10}, SessionID: 1, Name: "Second Semester", StartDate: date(2024, 1, 8), EndDate: date(2024, 4, 26)},
+			{Model: gorm.Model{ID: 20}, SessionID: 2, Name: "First Semester", StartDate: date(2024, 9, 2), EndDate: date(2024, 12, 20)},
+		},
+	}
+	svc := &AnalyticsService{calendarRepo: calendar}
+
+	tests := []struct {
+		name       string
+		session    string
+		semesterID int
+		want       domain.Period
+		wantErr    error
+	}{
+		{"no filters", "", 0, domain.Period{}, nil},
+		{"session by name", "2024/2025", 0, domain.Period{Session: "2024/2025", Start: date(2024, 9, 2), End: date(2025, 7, 25)}, nil},
+		{"session by ID", "1", 0, domain.Period{Session: "2023/2024", Start: date(2023, 9, 4), End: date(2024, 7, 26)}, nil},
+		{"semester alone", "", 20, domain.Period{Session: "2024/2025", Semester: "First Semester", Start: date(2024, 9, 2), End: date(2024, 12, 20)}, nil},
+		{"semester of the session", "2023/2024", 10, domain.Period{Session: "2023/2024", Semester: "Second Semester", Start: date(2024, 1, 8), End: date(2024, 4, 26)}, nil},
+		{"semester of another session", "2024/2025", 10, domain.Period{}, ErrSemesterNotInSession},
+		{"unknown session", "2030/2031", 0, domain.Period{}, calendarRepo.ErrSessionNotFound},
+		{"unknown session ID", "9", 20, domain.Period{}, calendarRepo.ErrSessionNotFound},
+		{"unknown semester", "", 99, domain.Period{}, calendarRepo.ErrSemesterNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := svc.ResolvePeriod(tt.session, tt.semesterID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ResolvePeriod(%q, %d) = %+v, want %+v", tt.session, tt.semesterID, got, tt.want)
+			}
+		})
+	}
+}
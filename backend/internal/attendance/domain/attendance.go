@@ -3,18 +3,81 @@ package attendance
 // Request DTOs
 
 // GenerateQRCodeDTO represents the request to generate a QR code for an event.
+// The course must already exist; course name and department are taken from it.
 type GenerateQRCodeDTO struct {
-	CourseName string `json:"course_name" binding:"required"`
 	CourseCode string `json:"course_code" binding:"required"`
 	StartTime  string `json:"start_time" binding:"required"` // ISO 8601 format: 2025-11-27T10:00:00Z
 	EndTime    string `json:"end_time" binding:"required"`   // ISO 8601 format: 2025-11-27T11:00:00Z
-	Venue      string `json:"venue" binding:"required"`
-	Department string `json:"department" binding:"required"`
+	Venue      string `json:"venue"`                         // Free-text venue; required unless venue_id is given
+
+	// VenueID links the event to a managed venue. GeofenceMode ("off", "lenient"
+	// or "strict") controls how check-ins far from that venue are handled.
+	VenueID      *int   `json:"venue_id"`
+	GeofenceMode string `json:"geofence_mode" binding:"omitempty,oneof=off lenient strict"`
+
+	// Minutes after start_time before check-ins are marked late (default 5), and
+	// after which they are rejected (default: accepted until end_time).
+	GracePeriodMinutes *int `json:"grace_period_minutes" binding:"omitempty,gte=0"`
+	LateCutoffMinutes  *int `json:"late_cutoff_minutes" binding:"omitempty,gte=0"`
+
+	// QRMode is "static" (default) or "rotating". Rotating events show a signed
+	// token that changes every RotationSeconds (default 30).
+	QRMode          string `json:"qr_mode" binding:"omitempty,oneof=static rotating"`
+	RotationSeconds int    `json:"rotation_seconds" binding:"omitempty,min=10,max=300"`
 }
 
 // ScanQRCodeDTO represents the request when a student scans a QR code.
 type ScanQRCodeDTO struct {
 	QRToken string `json:"qr_token" binding:"required"`
+
+	// Device location, required for events with strict geofencing.
+	Latitude  *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	Accuracy  *float64 `json:"accuracy" binding:"omitempty,gte=0"` // Reported accuracy radius in meters
+}
+
+// SetAttendanceStatusDTO represents a lecturer setting a student's status by hand.
+type SetAttendanceStatusDTO struct {
+	Status string `json:"status" binding:"required,oneof=present late absent excused"`
+	Reason string `json:"reason" binding:"required"` // Recorded in the audit trail
+}
+
+// VoidAttendanceDTO represents a lecturer removing a student's attendance record.
+type VoidAttendanceDTO struct {
+	Reason string `json:"reason" binding:"required"` // Recorded in the audit trail
+}
+
+// AddCoLecturerDTO represents the request to grant another lecturer access to an event.
+type AddCoLecturerDTO struct {
+	LecturerID int `json:"lecturer_id" binding:"required"`
+}
+
+// UpdateEventDTO represents a lecturer changing an event's times or venue.
+// Only fields that are provided are changed; a venue_id of 0 detaches the
+// managed venue. Once the event has started only end_time and the venue may change.
+type UpdateEventDTO struct {
+	StartTime    *string `json:"start_time"` // ISO 8601 format: 2025-11-27T10:00:00Z
+	EndTime      *string `json:"end_time"`
+	Venue        *string `json:"venue"`
+	VenueID      *int    `json:"venue_id"`
+	GeofenceMode *string `json:"geofence_mode" binding:"omitempty,oneof=off lenient strict"`
+	Reason       string  `json:"reason"` // Recorded in the event's history
+}
+
+// CancelEventDTO represents a lecturer cancelling an event.
+type CancelEventDTO struct {
+	Reason string `json:"reason" binding:"required"` // Shown on the event and recorded in its history
+}
+
+// CloseCheckInDTO represents a lecturer closing check-in before the event ends.
+type CloseCheckInDTO struct {
+	Reason string `json:"reason"` // Recorded in the event's history
+}
+
+// ReopenCheckInDTO represents a lecturer reopening check-in for a short window.
+type ReopenCheckInDTO struct {
+	Minutes int    `json:"minutes" binding:"omitempty,min=1,max=30"` // Length of the window (default 10)
+	Reason  string `json:"reason"`                                   // Recorded in the event's history
 }
 
 // Response DTOs
@@ -34,18 +97,42 @@ type GenerateQRCodeResponse struct {
 	CreatedBy  string `json:"created_by"` // Lecturer name
 	CreatedAt  string `json:"created_at"`
 	ExpiresAt  string `json:"expires_at"` // When QR code expires
+	QRMode     string `json:"qr_mode"`
+	// RotationSeconds is only set for rotating events; fetch a fresh code from
+	// GET /api/lecturer/events/{event_id}/qrcode before ExpiresAt.
+	RotationSeconds int `json:"rotation_seconds,omitempty"`
+
+	GracePeriodMinutes int  `json:"grace_period_minutes"`
+	LateCutoffMinutes  *int `json:"late_cutoff_minutes"` // Null when check-ins are accepted until end_time
+
+	SemesterID *int `json:"semester_id"` // Null when no semester contains start_time
+}
+
+// CurrentQRCodeResponse represents the QR code currently shown for an event.
+type CurrentQRCodeResponse struct {
+	Message         string `json:"message"`
+	EventID         int    `json:"event_id"`
+	QRMode          string `json:"qr_mode"`
+	QRToken         string `json:"qr_token"`
+	QRCodeData      string `json:"qr_code"` // Base64 encoded PNG image
+	RotationSeconds int    `json:"rotation_seconds,omitempty"`
+	ExpiresAt       string `json:"expires_at"` // When this token stops being accepted for new scans
 }
 
 // CheckInResponse represents the response when a student checks in.
 type CheckInResponse struct {
 	Message      string `json:"message"`
-	Status       string `json:"status"` // "present"
+	Status       string `json:"status"` // "present" or "late"
 	StudentID    int    `json:"student_id"`
 	StudentName  string `json:"student_name"`
 	MatricNumber string `json:"matric_number"`
 	CourseName   string `json:"course_name"`
 	CourseCode   string `json:"course_code"`
 	MarkedTime   string `json:"marked_time"`
+
+	DistanceMeters *float64 `json:"distance_meters,omitempty"` // Distance from the venue, when location was checked
+	Flagged        bool     `json:"flagged"`                   // Accepted but queued for review (lenient geofencing)
+	FlagReason     string   `json:"flag_reason,omitempty"`
 }
 
 // AttendanceRecordResponse represents a single attendance record.
@@ -54,8 +141,11 @@ type AttendanceRecordResponse struct {
 	StudentID    int    `json:"student_id"`
 	StudentName  string `json:"student_name"`
 	MatricNumber string `json:"matric_number"`
-	Status       string `json:"status"` // "present"
+	Status       string `json:"status"` // "present", "late", "absent" or "excused"
+	Source       string `json:"source"` // "check_in", "system" (written by the absence finalizer) or "manual"
 	MarkedTime   string `json:"marked_time"`
+	Flagged      bool   `json:"flagged"`
+	FlagReason   string `json:"flag_reason,omitempty"`
 }
 
 // EventAttendanceResponse represents attendance records for an entire event.
@@ -70,10 +160,53 @@ type EventAttendanceResponse struct {
 	Venue             string                     `json:"venue"`
 	CreatedBy         string                     `json:"created_by"` // Lecturer name
 	TotalPresent      int                        `json:"total_present"`
+	TotalLate         int                        `json:"total_late"`
+	TotalAbsent       int                        `json:"total_absent"`
+	TotalExcused      int                        `json:"total_excused"`
+	AbsencesFinalized bool                       `json:"absences_finalized"` // True once the roster includes absent students
 	AttendanceRecords []AttendanceRecordResponse `json:"attendance_records"`
 	GeneratedAt       string                     `json:"generated_at"`
 }
 
+// EventCoLecturerResponse represents a lecturer with access to an event.
+type EventCoLecturerResponse struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// EventSummaryResponse represents an event in a lecturer's event list.
+type EventSummaryResponse struct {
+	EventID     int                       `json:"event_id"`
+	CourseName  string                    `json:"course_name"`
+	CourseCode  string                    `json:"course_code"`
+	StartTime   string                    `json:"start_time"`
+	EndTime     string                    `json:"end_time"`
+	Venue       string                    `json:"venue"`
+	CreatedBy   string                    `json:"created_by"` // Lecturer name
+	QRMode      string                    `json:"qr_mode"`
+	IsCreator   bool                      `json:"is_creator"`
+	CoLecturers []EventCoLecturerResponse `json:"co_lecturers"`
+	SemesterID  *int                      `json:"semester_id"`
+	Status      string                    `json:"status"` // scheduled or cancelled
+
+	CancelledAt     *string `json:"cancelled_at"`
+	CancelReason    string  `json:"cancel_reason,omitempty"`
+	CheckInOpen     bool    `json:"check_in_open"`      // Whether a check-in right now would be accepted
+	CheckInClosedAt *string `json:"check_in_closed_at"` // Set when a lecturer closed check-in early
+	ReopenedUntil   *string `json:"reopened_until"`     // Set while or after a lecturer reopened check-in
+
+	// TimetableEntryID is set on events generated from the course timetable.
+	TimetableEntryID *int `json:"timetable_entry_id"`
+}
+
+// LecturerEventsResponse represents the events a lecturer can access.
+type LecturerEventsResponse struct {
+	Message     string                 `json:"message"`
+	TotalEvents int                    `json:"total_events"`
+	Events      []EventSummaryResponse `json:"events"`
+	GeneratedAt string                 `json:"generated_at"`
+}
+
 // StudentAttendanceResponse represents attendance history for a student.
 type StudentAttendanceResponse struct {
 	Message           string                     `json:"message"`
@@ -82,10 +215,65 @@ type StudentAttendanceResponse struct {
 	MatricNumber      string                     `json:"matric_number"`
 	TotalEvents       int                        `json:"total_events"`
 	TotalPresent      int                        `json:"total_present"`
+	TotalLate         int                        `json:"total_late"`
+	TotalAbsent       int                        `json:"total_absent"`
+	TotalExcused      int                        `json:"total_excused"`
 	AttendanceRecords []AttendanceRecordResponse `json:"attendance_records"`
 	GeneratedAt       string                     `json:"generated_at"`
 }
 
+// AttendanceAuditEntryResponse represents one manual change to an attendance record.
+type AttendanceAuditEntryResponse struct {
+	ID                 int    `json:"id"`
+	AttendanceRecordID int    `json:"attendance_record_id"`
+	StudentID          int    `json:"student_id"`
+	StudentName        string `json:"student_name"`
+	MatricNumber       string `json:"matric_number"`
+	Action             string `json:"action"`          // "create", "update" or "void"
+	PreviousStatus     string `json:"previous_status"` // Empty for "create"
+	NewStatus          string `json:"new_status"`      // Empty for "void"
+	Reason             string `json:"reason"`
+	ChangedByID        int    `json:"changed_by_id"`
+	ChangedBy          string `json:"changed_by"` // Lecturer name
+	ChangedAt          string `json:"changed_at"`
+}
+
+// AttendanceAuditResponse represents the audit trail for an event or a single student's record.
+type AttendanceAuditResponse struct {
+	Message      string                         `json:"message"`
+	EventID      int                            `json:"event_id"`
+	StudentID    *int                           `json:"student_id,omitempty"` // Set when the trail is for one student's record
+	TotalEntries int                            `json:"total_entries"`
+	Entries      []AttendanceAuditEntryResponse `json:"entries"`
+	GeneratedAt  string                         `json:"generated_at"`
+}
+
+// EventFieldChange represents one field changed on an event.
+type EventFieldChange struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// EventHistoryEntryResponse represents one change to an event's schedule or check-in.
+type EventHistoryEntryResponse struct {
+	ID          int                         `json:"id"`
+	Action      string                      `json:"action"` // "update", "cancel", "close" or "reopen"
+	Changes     map[string]EventFieldChange `json:"changes"`
+	Reason      string                      `json:"reason"`
+	ChangedByID int                         `json:"changed_by_id"`
+	ChangedBy   string                      `json:"changed_by"` // Lecturer name
+	ChangedAt   string                      `json:"changed_at"`
+}
+
+// EventHistoryResponse represents the changes made to an event.
+type EventHistoryResponse struct {
+	Message      string                      `json:"message"`
+	EventID      int                         `json:"event_id"`
+	TotalEntries int                         `json:"total_entries"`
+	Entries      []EventHistoryEntryResponse `json:"entries"`
+	GeneratedAt  string                      `json:"generated_at"`
+}
+
 // Error Response
 type ErrorResponse struct {
 	Error      string `json:"error"`
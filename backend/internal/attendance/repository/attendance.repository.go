@@ -2,17 +2,50 @@ package repository
 
 import (
 	"errors"
+	"time"
 
 	"github.com/Dom-HTG/attendance-management-system/entities"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+var (
+	// ErrLecturerNotFound is returned when a co-lecturer being added does not exist.
+	ErrLecturerNotFound = errors.New("lecturer not found")
+	// ErrAttendanceRecordNotFound is returned when a student has no record for an event.
+	ErrAttendanceRecordNotFound = errors.New("attendance record not found")
+	// ErrEventChanged is returned when an event changed between being read and updated.
+	ErrEventChanged = errors.New("event was changed by someone else. reload it and try again")
+	// ErrAttendanceAlreadyRecorded is returned when a student already has a record for an event.
+	ErrAttendanceAlreadyRecorded = errors.New("attendance already recorded for this student")
+)
+
+// activeRecordConflict makes an insert into user_attendances do nothing when
+// the student already has an active record for the event, going by the partial
+// unique index idx_user_attendances_event_student_active.
+var activeRecordConflict = clause.OnConflict{
+	Columns:     []clause.Column{{Name: "attendance_id"}, {Name: "student_id"}},
+	TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+	DoNothing:   true,
+}
+
 // AttendanceRepoInterface defines the repository interface for attendance operations.
 type AttendanceRepoInterface interface {
 	// Event operations
 	CreateEvent(event *entities.Event) error
 	GetEventByQRToken(qrToken string) (*entities.Event, error)
 	GetEventByID(eventID int) (*entities.Event, error)
+	ListEventsForLecturer(lecturerID int) ([]*entities.Event, error)
+
+	// Event lifecycle
+	UpdateEvent(event *entities.Event, columns map[string]interface{}, audit *entities.EventAudit) error
+	ListEventAudit(eventID int) ([]*entities.EventAudit, error)
+
+	// Event ownership operations
+	CanLecturerAccessEvent(event *entities.Event, lecturerID int) (bool, error)
+	AddCoLecturer(eventID, lecturerID int) error
+	RemoveCoLecturer(eventID, lecturerID int) error
 
 	// Attendance operations
 	CreateAttendanceRecord(attendanceRecord *entities.UserAttendance) error
@@ -20,6 +53,14 @@ type AttendanceRepoInterface interface {
 	GetStudentAttendance(studentID int) ([]*entities.UserAttendance, error)
 	CheckIfStudentMarkedAttendance(eventID, studentID int) (bool, error)
 	GetEventWithAttendanceRecords(eventID int) (*entities.Event, []*entities.UserAttendance, error)
+
+	// Manual overrides
+	OverrideAttendance(eventID, studentID int, status, reason string, lecturerID int, at time.Time) (*entities.UserAttendance, *entities.AttendanceAudit, error)
+	VoidAttendanceRecord(eventID, studentID int, reason string, lecturerID int, at time.Time) (*entities.AttendanceAudit, error)
+	ListAttendanceAudit(eventID int, studentID *int) ([]*entities.AttendanceAudit, error)
+
+	// Absence finalization
+	FinalizeAbsences(endedBefore time.Time, limit int) (events int, absences int, err error)
 }
 
 // AttendanceRepo implements the AttendanceRepoInterface.
@@ -34,18 +75,21 @@ func NewAttendanceRepo(db *gorm.DB) *AttendanceRepo {
 	}
 }
 
-// CreateEvent creates a new event in the database.
+// CreateEvent creates a new event in the database, tagged with the semester
+// containing its start time.
 func (ar *AttendanceRepo) CreateEvent(event *entities.Event) error {
-	if err := ar.db.Create(event).Error; err != nil {
-		return errors.New("failed to create event: " + err.Error())
-	}
-	return nil
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(event).Error; err != nil {
+			return errors.New("failed to create event: " + err.Error())
+		}
+		return calendarRepo.TagEvent(tx, event)
+	})
 }
 
 // GetEventByQRToken retrieves an event by its QR token.
 func (ar *AttendanceRepo) GetEventByQRToken(qrToken string) (*entities.Event, error) {
 	var event *entities.Event
-	if err := ar.db.Where("qr_code_token = ?", qrToken).First(&event).Error; err != nil {
+	if err := ar.db.Preload("Course").Preload("Location").Where("qr_code_token = ?", qrToken).First(&event).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("qr code not found or invalid")
 		}
@@ -57,7 +101,11 @@ func (ar *AttendanceRepo) GetEventByQRToken(qrToken string) (*entities.Event, er
 // GetEventByID retrieves an event by its ID.
 func (ar *AttendanceRepo) GetEventByID(eventID int) (*entities.Event, error) {
 	var event *entities.Event
-	if err := ar.db.Where("id = ?", eventID).First(&event).Error; err != nil {
+	if err := ar.db.Preload("Course").
+		Preload("Location").
+		Preload("Creator").
+		Preload("CoLecturers").
+		Where("id = ?", eventID).First(&event).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("event not found")
 		}
@@ -66,10 +114,143 @@ func (ar *AttendanceRepo) GetEventByID(eventID int) (*entities.Event, error) {
 	return event, nil
 }
 
-// CreateAttendanceRecord creates a new attendance record for a student.
+// ListEventsForLecturer retrieves every event the lecturer created or co-lectures,
+// and the timetabled events of courses they lecture, newest first.
+func (ar *AttendanceRepo) ListEventsForLecturer(lecturerID int) ([]*entities.Event, error) {
+	var events []*entities.Event
+	if err := ar.db.Preload("Course").
+		Preload("Location").
+		Preload("Creator").
+		Preload("CoLecturers").
+		Where("created_by = ? OR id IN (SELECT event_id FROM event_co_lecturers WHERE lecturer_id = ?)"+
+			" OR (timetable_entry_id IS NOT NULL AND course_id IN (SELECT course_id FROM course_lecturers WHERE lecturer_id = ?))",
+			lecturerID, lecturerID, lecturerID).
+		Order("start_time DESC").
+		Find(&events).Error; err != nil {
+		return nil, errors.New("failed to retrieve events: " + err.Error())
+	}
+	return events, nil
+}
+
+// UpdateEvent writes columns to an event and records the audit entry in the
+// same transaction, retagging the event's semester when its start time moves.
+// The update only applies if the event is unchanged since it was read, going by
+// its updated_at; otherwise ErrEventChanged is returned so that transitions
+// validated against stale state are not applied. Clearing absences_finalized_at
+// also removes the absences the finalizer recorded, so it can record them again.
+func (ar *AttendanceRepo) UpdateEvent(event *entities.Event, columns map[string]interface{}, audit *entities.EventAudit) error {
+	return ar.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entities.Event{}).
+			Where("id = ? AND updated_at = ?", event.ID, event.UpdatedAt).
+			Updates(columns)
+		if res.Error != nil {
+			return errors.New("failed to update event: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return ErrEventChanged
+		}
+
+		if _, moved := columns["start_time"]; moved {
+			if err := calendarRepo.TagEvent(tx, event); err != nil {
+				return err
+			}
+		}
+
+		if finalized, ok := columns["absences_finalized_at"]; ok && finalized == nil {
+			if err := tx.Where("attendance_id = ? AND source = ? AND status = ?",
+				event.ID, entities.AttendanceSourceSystem, entities.AttendanceStatusAbsent).
+				Delete(&entities.UserAttendance{}).Error; err != nil {
+				return errors.New("failed to remove recorded absences: " + err.Error())
+			}
+		}
+
+		if err := tx.Create(audit).Error; err != nil {
+			return errors.New("failed to record audit entry: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// ListEventAudit retrieves the changes made to an event, newest first.
+func (ar *AttendanceRepo) ListEventAudit(eventID int) ([]*entities.EventAudit, error) {
+	var entries []*entities.EventAudit
+	if err := ar.db.Preload("Lecturer").
+		Where("event_id = ?", eventID).
+		Order("changed_at DESC").
+		Order("id DESC").
+		Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to retrieve event history: " + err.Error())
+	}
+	return entries, nil
+}
+
+// CanLecturerAccessEvent reports whether a lecturer may read or manage an event.
+// Access is granted to the creator and to explicitly added co-lecturers. Legacy
+// events without a creator, and events generated from the timetable, are also
+// open to the lecturers of the event's course.
+func (ar *AttendanceRepo) CanLecturerAccessEvent(event *entities.Event, lecturerID int) (bool, error) {
+	if event.CreatedBy != nil && *event.CreatedBy == lecturerID {
+		return true, nil
+	}
+
+	var count int64
+	if err := ar.db.Table("event_co_lecturers").
+		Where("event_id = ? AND lecturer_id = ?", event.ID, lecturerID).
+		Count(&count).Error; err != nil {
+		return false, errors.New("failed to check event access: " + err.Error())
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if (event.CreatedBy == nil || event.TimetableEntryID != nil) && event.CourseID != nil {
+		if err := ar.db.Table("course_lecturers").
+			Where("course_id = ? AND lecturer_id = ?", *event.CourseID, lecturerID).
+			Count(&count).Error; err != nil {
+			return false, errors.New("failed to check event access: " + err.Error())
+		}
+		return count > 0, nil
+	}
+
+	return false, nil
+}
+
+// AddCoLecturer grants a lecturer access to an event.
+func (ar *AttendanceRepo) AddCoLecturer(eventID, lecturerID int) error {
+	var lecturer entities.Lecturer
+	if err := ar.db.First(&lecturer, lecturerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLecturerNotFound
+		}
+		return errors.New("failed to retrieve lecturer: " + err.Error())
+	}
+
+	event := &entities.Event{}
+	event.ID = uint(eventID)
+	if err := ar.db.Model(event).Association("CoLecturers").Append(&lecturer); err != nil {
+		return errors.New("failed to add co-lecturer: " + err.Error())
+	}
+	return nil
+}
+
+// RemoveCoLecturer revokes a co-lecturer's access to an event.
+func (ar *AttendanceRepo) RemoveCoLecturer(eventID, lecturerID int) error {
+	if err := ar.db.Exec("DELETE FROM event_co_lecturers WHERE event_id = ? AND lecturer_id = ?", eventID, lecturerID).Error; err != nil {
+		return errors.New("failed to remove co-lecturer: " + err.Error())
+	}
+	return nil
+}
+
+// CreateAttendanceRecord creates a new attendance record for a student. It
+// returns ErrAttendanceAlreadyRecorded if the student already has one for the
+// event, for example from a concurrent check-in or a lecturer's override.
 func (ar *AttendanceRepo) CreateAttendanceRecord(attendanceRecord *entities.UserAttendance) error {
-	if err := ar.db.Create(attendanceRecord).Error; err != nil {
-		return errors.New("failed to create attendance record: " + err.Error())
+	res := ar.db.Clauses(activeRecordConflict).Create(attendanceRecord)
+	if res.Error != nil {
+		return errors.New("failed to create attendance record: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return ErrAttendanceAlreadyRecorded
 	}
 	return nil
 }
@@ -86,10 +267,12 @@ func (ar *AttendanceRepo) GetAttendanceByEventID(eventID int) ([]*entities.UserA
 	return records, nil
 }
 
-// GetStudentAttendance retrieves all attendance records for a specific student.
+// GetStudentAttendance retrieves all attendance records for a specific student,
+// leaving out records for cancelled events.
 func (ar *AttendanceRepo) GetStudentAttendance(studentID int) ([]*entities.UserAttendance, error) {
 	var records []*entities.UserAttendance
 	if err := ar.db.Where("student_id = ?", studentID).
+		Where("attendance_id NOT IN (SELECT id FROM events WHERE status = ?)", entities.EventCancelled).
 		Order("marked_time DESC").
 		Find(&records).Error; err != nil {
 		return nil, errors.New("failed to retrieve student attendance: " + err.Error())
@@ -109,6 +292,140 @@ func (ar *AttendanceRepo) CheckIfStudentMarkedAttendance(eventID, studentID int)
 	return count > 0, nil
 }
 
+// OverrideAttendance sets a student's status for an event on behalf of a
+// lecturer, updating the student's existing record or creating one, and writes
+// an audit entry in the same transaction. If the status is unchanged nothing is
+// written and the returned audit entry is nil. A record created by a check-in
+// while the override runs is updated rather than duplicated.
+func (ar *AttendanceRepo) OverrideAttendance(eventID, studentID int, status, reason string, lecturerID int, at time.Time) (*entities.UserAttendance, *entities.AttendanceAudit, error) {
+	var record entities.UserAttendance
+	var audit *entities.AttendanceAudit
+
+	err := ar.db.Transaction(func(tx *gorm.DB) error {
+		err := lockAttendanceRecord(tx, eventID, studentID, &record)
+
+		action, previous := entities.AuditActionUpdate, ""
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			record = entities.UserAttendance{
+				AttendanceID: eventID,
+				StudentID:    studentID,
+				Status:       status,
+				MarkedTime:   at,
+				Source:       entities.AttendanceSourceManual,
+			}
+			res := tx.Clauses(activeRecordConflict).Create(&record)
+			if res.Error != nil {
+				return errors.New("failed to create attendance record: " + res.Error.Error())
+			}
+			if res.RowsAffected > 0 {
+				action = entities.AuditActionCreate
+			} else {
+				// Another request created the record since it was looked up
+				record = entities.UserAttendance{}
+				err = lockAttendanceRecord(tx, eventID, studentID, &record)
+			}
+		}
+
+		switch {
+		case action == entities.AuditActionCreate:
+		case err != nil:
+			return errors.New("failed to retrieve attendance record: " + err.Error())
+		case record.Status == status:
+			return nil
+		default:
+			previous = record.Status
+			record.Status = status
+			record.Source = entities.AttendanceSourceManual
+			if err := tx.Save(&record).Error; err != nil {
+				return errors.New("failed to update attendance record: " + err.Error())
+			}
+		}
+
+		audit = &entities.AttendanceAudit{
+			AttendanceRecordID: record.ID,
+			EventID:            eventID,
+			StudentID:          studentID,
+			Action:             action,
+			PreviousStatus:     previous,
+			NewStatus:          status,
+			Reason:             reason,
+			ChangedBy:          lecturerID,
+			ChangedAt:          at,
+		}
+		if err := tx.Create(audit).Error; err != nil {
+			return errors.New("failed to record audit entry: " + err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return &record, audit, nil
+}
+
+// VoidAttendanceRecord removes a student's attendance record for an event on
+// behalf of a lecturer and writes an audit entry in the same transaction.
+// The record is soft-deleted so the audit trail can still refer to it.
+func (ar *AttendanceRepo) VoidAttendanceRecord(eventID, studentID int, reason string, lecturerID int, at time.Time) (*entities.AttendanceAudit, error) {
+	var audit *entities.AttendanceAudit
+
+	err := ar.db.Transaction(func(tx *gorm.DB) error {
+		var record entities.UserAttendance
+		if err := lockAttendanceRecord(tx, eventID, studentID, &record); err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrAttendanceRecordNotFound
+			}
+			return errors.New("failed to retrieve attendance record: " + err.Error())
+		}
+
+		if err := tx.Delete(&record).Error; err != nil {
+			return errors.New("failed to void attendance record: " + err.Error())
+		}
+
+		audit = &entities.AttendanceAudit{
+			AttendanceRecordID: record.ID,
+			EventID:            eventID,
+			StudentID:          studentID,
+			Action:             entities.AuditActionVoid,
+			PreviousStatus:     record.Status,
+			Reason:             reason,
+			ChangedBy:          lecturerID,
+			ChangedAt:          at,
+		}
+		if err := tx.Create(audit).Error; err != nil {
+			return errors.New("failed to record audit entry: " + err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return audit, nil
+}
+
+// lockAttendanceRecord loads a student's active record for an event into
+// record and locks it for the rest of the transaction.
+func lockAttendanceRecord(tx *gorm.DB, eventID, studentID int, record *entities.UserAttendance) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("attendance_id = ? AND student_id = ?", eventID, studentID).
+		First(record).Error
+}
+
+// ListAttendanceAudit retrieves the audit trail for an event, newest first.
+// When studentID is set only that student's entries are returned.
+func (ar *AttendanceRepo) ListAttendanceAudit(eventID int, studentID *int) ([]*entities.AttendanceAudit, error) {
+	query := ar.db.Preload("Student").Preload("Lecturer").Where("event_id = ?", eventID)
+	if studentID != nil {
+		query = query.Where("student_id = ?", *studentID)
+	}
+
+	var entries []*entities.AttendanceAudit
+	if err := query.Order("changed_at DESC").Order("id DESC").Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to retrieve audit trail: " + err.Error())
+	}
+	return entries, nil
+}
+
 // GetEventWithAttendanceRecords retrieves an event along with all its attendance records.
 func (ar *AttendanceRepo) GetEventWithAttendanceRecords(eventID int) (*entities.Event, []*entities.UserAttendance, error) {
 	event, err := ar.GetEventByID(eventID)
@@ -123,3 +440,62 @@ func (ar *AttendanceRepo) GetEventWithAttendanceRecords(eventID int) (*entities.
 
 	return event, records, nil
 }
+
+// FinalizeAbsences writes an "absent" record for every enrolled student who did
+// not check in to an event that ended before endedBefore, processing at most
+// limit events. Each event is finalized once: its absences_finalized_at column
+// is set in the same transaction. Events are claimed with FOR UPDATE SKIP LOCKED
+// so several replicas can run the job concurrently without double-processing.
+// Cancelled events are finalized without recording absences, and events whose
+// check-in a lecturer has reopened wait until the reopen window has passed.
+func (ar *AttendanceRepo) FinalizeAbsences(endedBefore time.Time, limit int) (int, int, error) {
+	var eventCount, absenceCount int
+
+	err := ar.db.Transaction(func(tx *gorm.DB) error {
+		var events []entities.Event
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("id").
+			Where("end_time < ? AND absences_finalized_at IS NULL", endedBefore).
+			Where("reopened_until IS NULL OR reopened_until < ?", endedBefore).
+			Order("end_time ASC").
+			Limit(limit).
+			Find(&events).Error; err != nil {
+			return errors.New("failed to claim ended events: " + err.Error())
+		}
+
+		now := time.Now()
+		for _, event := range events {
+			res := tx.Exec(`
+				INSERT INTO user_attendances (created_at, updated_at, attendance_id, student_id, status, marked_time, source)
+				SELECT ?, ?, e.id, en.student_id, ?, e.end_time, ?
+				FROM events e
+				JOIN enrollments en ON en.course_id = e.course_id AND en.deleted_at IS NULL
+				WHERE e.id = ? AND e.status <> ?
+				ON CONFLICT (attendance_id, student_id) WHERE deleted_at IS NULL DO NOTHING
+			`, now, now, entities.AttendanceStatusAbsent, entities.AttendanceSourceSystem, event.ID, entities.EventCancelled)
+			if res.Error != nil {
+				return errors.New("failed to record absences: " + res.Error.Error())
+			}
+
+			if err := markAbsencesFinalized(tx, event.ID, now).Error; err != nil {
+				return errors.New("failed to mark event finalized: " + err.Error())
+			}
+
+			eventCount++
+			absenceCount += int(res.RowsAffected)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return eventCount, absenceCount, nil
+}
+
+// markAbsencesFinalized stamps an event's absences_finalized_at. It leaves
+// updated_at alone: UpdateEvent compares it to detect concurrent edits, and the
+// job finalizing an event is not an edit a lecturer could have seen.
+func markAbsencesFinalized(tx *gorm.DB, eventID uint, at time.Time) *gorm.DB {
+	return tx.Model(&entities.Event{}).Where("id = ?", eventID).UpdateColumn("absences_finalized_at", at)
+}
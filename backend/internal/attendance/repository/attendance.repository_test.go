@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB returns a Postgres handle that builds statements without running
+// them, so tests can inspect the SQL a repository method would send.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db
+}
+
+func TestMarkAbsencesFinalizedKeepsUpdatedAt(t *testing.T) {
+	stmt := markAbsencesFinalized(dryRunDB(t), 42, time.Now()).Statement
+	sql := stmt.SQL.String()
+
+	if !strings.Contains(sql, `SET "absences_finalized_at"=`) {
+		t.Errorf("statement does not set absences_finalized_at: %s", sql)
+	}
+	if strings.Contains(sql, "updated_at") {
+		t.Errorf("statement touches updated_at, which UpdateEvent compares: %s", sql)
+	}
+	if len(stmt.Vars) != 2 || stmt.Vars[1] != uint(42) {
+		t.Errorf("vars = %v, want the timestamp and event 42", stmt.Vars)
+	}
+}
+
+func TestActiveRecordConflict(t *testing.T) {
+	record := entities.UserAttendance{AttendanceID: 42, StudentID: 7, Status: entities.AttendanceStatusPresent}
+	sql := strings.Join(strings.Fields(dryRunDB(t).Clauses(activeRecordConflict).Create(&record).Statement.SQL.String()), " ")
+
+	// The conflict target must name the partial index's columns and predicate,
+	// or Postgres cannot match it to idx_user_attendances_event_student_active
+	want := `ON CONFLICT ("attendance_id","student_id") WHERE deleted_at IS NULL DO NOTHING`
+	if !strings.Contains(sql, want) {
+		t.Errorf("insert = %s, want it to contain %s", sql, want)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/internal/attendance/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+)
+
+// absenceFinalizerBatchSize caps how many events are claimed per transaction.
+const absenceFinalizerBatchSize = 50
+
+// AbsenceFinalizer is a background job that records explicit "absent" rows for
+// enrolled students who did not check in once an event has ended. It is safe to
+// run on several replicas at once; see AttendanceRepo.FinalizeAbsences.
+type AbsenceFinalizer struct {
+	attendanceRepo repository.AttendanceRepoInterface
+	interval       time.Duration
+}
+
+// NewAbsenceFinalizer returns a new AbsenceFinalizer that runs every interval.
+func NewAbsenceFinalizer(attendanceRepo repository.AttendanceRepoInterface, interval time.Duration) *AbsenceFinalizer {
+	return &AbsenceFinalizer{
+		attendanceRepo: attendanceRepo,
+		interval:       interval,
+	}
+}
+
+// Run finalizes absences immediately and then on every tick until ctx is cancelled.
+func (af *AbsenceFinalizer) Run(ctx context.Context) {
+	ticker := time.NewTicker(af.interval)
+	defer ticker.Stop()
+
+	for {
+		af.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce finalizes every event that has ended, one batch at a time.
+func (af *AbsenceFinalizer) RunOnce() {
+	for {
+		events, absences, err := af.attendanceRepo.FinalizeAbsences(time.Now(), absenceFinalizerBatchSize)
+		if err != nil {
+			logger.Errorf("absence finalizer failed: %v", err)
+			return
+		}
+		if events > 0 {
+			logger.Infof("absence finalizer closed %d event(s) and recorded %d absence(s)", events, absences)
+		}
+		if events < absenceFinalizerBatchSize {
+			return
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/internal/attendance/repository"
+)
+
+// batchFinalizer finalizes the given number of events per call, then none.
+type batchFinalizer struct {
+	repository.AttendanceRepoInterface
+	batches []int
+	err     error
+	calls   int
+	limits  []int
+}
+
+func (r *batchFinalizer) FinalizeAbsences(endedBefore time.Time, limit int) (int, int, error) {
+	r.calls++
+	r.limits = append(r.limits, limit)
+	if r.err != nil {
+		return 0, 0, r.err
+	}
+	if len(r.batches) == 0 {
+		return 0, 0, nil
+	}
+	events := r.batches[0]
+	r.batches = r.batches[1:]
+	return events, events * 30, nil
+}
+
+func TestAbsenceFinalizerRunOnce(t *testing.T) {
+	full := absenceFinalizerBatchSize
+
+	tests := []struct {
+		name      string
+		batches   []int
+		err       error
+		wantCalls int
+	}{
+		{"nothing ended", nil, nil, 1},
+		{"partial batch", []int{3}, nil, 1},
+		{"full batches continue", []int{full, full, 7}, nil, 3},
+		{"full batch then empty", []int{full}, nil, 2},
+		{"error stops the run", []int{full}, errors.New("connection refused"), 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &batchFinalizer{batches: tt.batches, err: tt.err}
+			NewAbsenceFinalizer(repo, time.Minute).RunOnce()
+
+			if repo.calls != tt.wantCalls {
+				t.Errorf("FinalizeAbsences called %d time(s), want %d", repo.calls, tt.wantCalls)
+			}
+			for _, limit := range repo.limits {
+				if limit != absenceFinalizerBatchSize {
+					t.Errorf("limit = %d, want %d", limit, absenceFinalizerBatchSize)
+				}
+			}
+		})
+	}
+}
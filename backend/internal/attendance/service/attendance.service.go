@@ -1,8 +1,10 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/Dom-HTG/attendance-management-system/entities"
@@ -10,10 +12,23 @@ import (
 	"github.com/Dom-HTG/attendance-management-system/internal/attendance/repository"
 	authDomain "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
 	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	courseRepo "github.com/Dom-HTG/attendance-management-system/internal/course/repository"
+	venueRepo "github.com/Dom-HTG/attendance-management-system/internal/venue/repository"
 	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
 	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// defaultQRRotationSeconds is used for rotating events created without an explicit period.
+	defaultQRRotationSeconds = 30
+	// maxLocationAccuracyMeters is the worst reported accuracy trusted for geofencing.
+	maxLocationAccuracyMeters = 100.0
+	// defaultGracePeriodMinutes is how long after the start a check-in still counts as on time.
+	defaultGracePeriodMinutes = 5
 )
 
 // AttendanceSvcInterface defines the service interface for attendance operations.
@@ -22,19 +37,45 @@ type AttendanceSvcInterface interface {
 	CheckIn(ctx *gin.Context)
 	GetEventAttendance(ctx *gin.Context)
 	GetStudentAttendance(ctx *gin.Context)
+	ListLecturerEvents(ctx *gin.Context)
+	AddCoLecturer(ctx *gin.Context)
+	RemoveCoLecturer(ctx *gin.Context)
+	GetCurrentQRCode(ctx *gin.Context)
+
+	// Event lifecycle
+	UpdateEvent(ctx *gin.Context)
+	CancelEvent(ctx *gin.Context)
+	CloseCheckIn(ctx *gin.Context)
+	ReopenCheckIn(ctx *gin.Context)
+	GetEventHistory(ctx *gin.Context)
+
+	// Manual overrides
+	SetAttendanceStatus(ctx *gin.Context)
+	VoidAttendance(ctx *gin.Context)
+	GetRecordAudit(ctx *gin.Context)
+	GetEventAudit(ctx *gin.Context)
 }
 
 // AttendanceSvc implements the AttendanceSvcInterface.
 type AttendanceSvc struct {
 	attendanceRepo repository.AttendanceRepoInterface
 	authRepo       authRepo.AuthRepoInterface
+	courseRepo     courseRepo.CourseRepoInterface
+	enrollmentRepo courseRepo.EnrollmentRepoInterface
+	venueRepo      venueRepo.VenueRepoInterface
+	reopenLimit    time.Duration // How long after an event ends check-in can still be reopened
 }
 
-// NewAttendanceSvc returns a new instance of AttendanceSvc.
-func NewAttendanceSvc(attendanceRepo repository.AttendanceRepoInterface, authRepo authRepo.AuthRepoInterface) *AttendanceSvc {
+// NewAttendanceSvc returns a new instance of AttendanceSvc. Check-in can be
+// reopened until reopenLimit after an event ends.
+func NewAttendanceSvc(attendanceRepo repository.AttendanceRepoInterface, authRepo authRepo.AuthRepoInterface, courseRepo courseRepo.CourseRepoInterface, enrollmentRepo courseRepo.EnrollmentRepoInterface, venueRepo venueRepo.VenueRepoInterface, reopenLimit time.Duration) *AttendanceSvc {
 	return &AttendanceSvc{
 		attendanceRepo: attendanceRepo,
 		authRepo:       authRepo,
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+		venueRepo:      venueRepo,
+		reopenLimit:    reopenLimit,
 	}
 }
 
@@ -42,7 +83,7 @@ func NewAttendanceSvc(attendanceRepo repository.AttendanceRepoInterface, authRep
 // Only lecturers can generate QR codes for events.
 func (as *AttendanceSvc) GenerateQRCode(ctx *gin.Context) {
 	// Get user ID and role from context (set by AuthMiddleware and RoleMiddleware)
-	_, ok := middleware.GetUserIDFromContext(ctx)
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
 	if !ok {
 		ctx.JSON(http.StatusUnauthorized, gin.H{
 			"error": "user id not found in context",
@@ -85,16 +126,123 @@ func (as *AttendanceSvc) GenerateQRCode(ctx *gin.Context) {
 		return
 	}
 
-	// Generate a unique QR token
+	// Resolve the late and cutoff windows
+	gracePeriod := defaultGracePeriodMinutes
+	if req.GracePeriodMinutes != nil {
+		gracePeriod = *req.GracePeriodMinutes
+	}
+	if req.LateCutoffMinutes != nil && *req.LateCutoffMinutes < gracePeriod {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "late_cutoff_minutes must not be less than grace_period_minutes",
+		})
+		return
+	}
+
+	// Resolve the course; only its lecturers may open sessions for it
+	course, err := as.courseRepo.GetCourseByCode(req.CourseCode)
+	if err != nil {
+		if errors.Is(err, courseRepo.ErrCourseNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": "course " + req.CourseCode + " not found. create the course first",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve course",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	isLecturer, err := as.courseRepo.IsCourseLecturer(int(course.ID), lecturerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to check course ownership",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !isLecturer {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "you are not a lecturer of course " + course.Code,
+		})
+		return
+	}
+
+	// Resolve the venue; geofencing needs a managed venue with coordinates
+	venueName := strings.TrimSpace(req.Venue)
+	var venue *entities.Venue
+	if req.VenueID != nil {
+		venue, err = as.venueRepo.GetVenueByID(*req.VenueID)
+		if err != nil {
+			if errors.Is(err, venueRepo.ErrVenueNotFound) {
+				ctx.JSON(http.StatusNotFound, gin.H{
+					"error": err.Error(),
+				})
+				return
+			}
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "failed to retrieve venue",
+				"details": err.Error(),
+			})
+			return
+		}
+		venueName = venue.Name
+	}
+	if venueName == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "venue or venue_id is required",
+		})
+		return
+	}
+
+	geofenceMode := req.GeofenceMode
+	if geofenceMode == "" {
+		geofenceMode = entities.GeofenceOff
+	}
+	if geofenceMode != entities.GeofenceOff && venue == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "geofence_mode requires a venue_id",
+		})
+		return
+	}
+
+	// Generate a unique QR token. Rotating events keep one too, but it is never
+	// shown and CheckIn refuses it.
 	qrToken := uuid.New().String()
 
+	qrMode := req.QRMode
+	if qrMode == "" {
+		qrMode = entities.QRModeStatic
+	}
+	rotationSeconds := 0
+	if qrMode == entities.QRModeRotating {
+		rotationSeconds = req.RotationSeconds
+		if rotationSeconds == 0 {
+			rotationSeconds = defaultQRRotationSeconds
+		}
+	}
+
 	// Create the event
+	courseID := int(course.ID)
 	event := &entities.Event{
-		EventName:   fmt.Sprintf("%s (%s)", req.CourseName, req.CourseCode),
+		CourseID:    &courseID,
+		Course:      course,
+		EventName:   fmt.Sprintf("%s (%s)", course.Title, course.Code),
 		StartTime:   startTime,
 		EndTime:     endTime,
-		Venue:       req.Venue,
+		Venue:       venueName,
 		QRCodeToken: qrToken,
+		QRMode:      qrMode,
+		QRRotation:  rotationSeconds,
+		CreatedBy:   &lecturerID,
+
+		VenueID:      req.VenueID,
+		Location:     venue,
+		GeofenceMode: geofenceMode,
+
+		GracePeriodMinutes: gracePeriod,
+		LateCutoffMinutes:  req.LateCutoffMinutes,
 	}
 
 	if err := as.attendanceRepo.CreateEvent(event); err != nil {
@@ -106,7 +254,8 @@ func (as *AttendanceSvc) GenerateQRCode(ctx *gin.Context) {
 	}
 
 	// Generate QR code with the token as data
-	qrCodeData, err := utils.GenerateQRCodePNG(qrToken, 256)
+	displayToken, expiresAt := currentQRToken(event, time.Now())
+	qrCodeData, err := utils.GenerateQRCodePNG(displayToken, 256)
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "failed to generate QR code",
@@ -136,17 +285,25 @@ func (as *AttendanceSvc) GenerateQRCode(ctx *gin.Context) {
 	response := attendance.GenerateQRCodeResponse{
 		Message:    "QR code generated successfully",
 		EventID:    int(event.ID),
-		QRToken:    qrToken,
+		QRToken:    displayToken,
 		QRCodeData: qrCodeData,
-		CourseName: req.CourseName,
-		CourseCode: req.CourseCode,
+		CourseName: course.Title,
+		CourseCode: course.Code,
 		StartTime:  req.StartTime,
 		EndTime:    req.EndTime,
-		Venue:      req.Venue,
-		Department: req.Department,
+		Venue:      venueName,
+		Department: course.Department,
 		CreatedBy:  lecturerName,
 		CreatedAt:  event.CreatedAt.Format(time.RFC3339),
-		ExpiresAt:  endTime.Format(time.RFC3339),
+		ExpiresAt:  expiresAt.Format(time.RFC3339),
+		QRMode:     qrMode,
+
+		RotationSeconds: rotationSeconds,
+
+		GracePeriodMinutes: gracePeriod,
+		LateCutoffMinutes:  req.LateCutoffMinutes,
+
+		SemesterID: event.SemesterID,
 	}
 
 	ctx.JSON(http.StatusCreated, response)
@@ -173,6 +330,21 @@ func (as *AttendanceSvc) CheckIn(ctx *gin.Context) {
 		return
 	}
 
+	student, err := as.authRepo.GetStudentByID(studentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": "student not found",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve student",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// Validate QR token
 	if !utils.ValidateQRCodeToken(req.QRToken) {
 		ctx.JSON(http.StatusBadRequest, gin.H{
@@ -181,17 +353,40 @@ func (as *AttendanceSvc) CheckIn(ctx *gin.Context) {
 		return
 	}
 
-	// Get the event by QR token
-	event, err := as.attendanceRepo.GetEventByQRToken(req.QRToken)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": err.Error(),
+	// Resolve the event from either a signed rotating token or a static UUID
+	now := time.Now()
+	event, ok := as.resolveQRToken(ctx, req.QRToken, now)
+	if !ok {
+		return
+	}
+
+	if event.Status == entities.EventCancelled {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "this event has been cancelled",
 		})
 		return
 	}
 
+	// Only students enrolled in the event's course are expected at it, and
+	// attendance rates only count enrolled sessions
+	if event.CourseID != nil {
+		enrolled, err := as.enrollmentRepo.IsStudentEnrolled(*event.CourseID, studentID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "failed to check enrollment",
+				"details": err.Error(),
+			})
+			return
+		}
+		if !enrolled {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": "you are not enrolled in this event's course",
+			})
+			return
+		}
+	}
+
 	// Check if the event is still active (within time range)
-	now := time.Now()
 	if now.Before(event.StartTime) {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error":      "event has not started yet",
@@ -200,7 +395,15 @@ func (as *AttendanceSvc) CheckIn(ctx *gin.Context) {
 		return
 	}
 
-	if now.After(event.EndTime) {
+	if checkInClosedEarly(event, now) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  "check-in for this event was closed by the lecturer",
+			"closed": event.CheckInClosedAt.Format(time.RFC3339),
+		})
+		return
+	}
+
+	if now.After(event.EndTime) && !checkInReopened(event, now) {
 		ctx.JSON(http.StatusBadRequest, gin.H{
 			"error":    "event has ended",
 			"end_time": event.EndTime.Format(time.RFC3339),
@@ -208,6 +411,16 @@ func (as *AttendanceSvc) CheckIn(ctx *gin.Context) {
 		return
 	}
 
+	// Decide between present and late, and refuse check-ins after the cutoff
+	status, ok := checkInStatus(event, now)
+	if !ok {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  "check-in for this event has closed",
+			"closed": event.StartTime.Add(time.Duration(*event.LateCutoffMinutes) * time.Minute).Format(time.RFC3339),
+		})
+		return
+	}
+
 	// Check if the student has already marked attendance for this event
 	alreadyMarked, err := as.attendanceRepo.CheckIfStudentMarkedAttendance(int(event.ID), studentID)
 	if err != nil {
@@ -225,15 +438,32 @@ func (as *AttendanceSvc) CheckIn(ctx *gin.Context) {
 		return
 	}
 
+	// Check the reported device location against the venue geofence
+	geo, ok := checkGeofence(ctx, event, &req)
+	if !ok {
+		return
+	}
+
 	// Create attendance record
 	attendanceRecord := &entities.UserAttendance{
 		AttendanceID: int(event.ID),
 		StudentID:    studentID,
-		Status:       "present",
+		Status:       status,
 		MarkedTime:   now,
+		Source:       entities.AttendanceSourceCheckIn,
+
+		DistanceMeters: geo.distanceMeters,
+		Flagged:        geo.flagReason != "",
+		FlagReason:     geo.flagReason,
 	}
 
 	if err := as.attendanceRepo.CreateAttendanceRecord(attendanceRecord); err != nil {
+		if errors.Is(err, repository.ErrAttendanceAlreadyRecorded) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": "you have already checked in for this event",
+			})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "failed to record attendance",
 			"details": err.Error(),
@@ -241,78 +471,70 @@ func (as *AttendanceSvc) CheckIn(ctx *gin.Context) {
 		return
 	}
 
-	// Get student information
-	// For now, we'll fetch from context or use placeholder
-	userEmail, _ := middleware.GetUserEmailFromContext(ctx)
+	courseName, courseCode, _ := eventCourseDetails(event)
+
+	message := "Check-in successful"
+	if attendanceRecord.Flagged {
+		message = "Check-in recorded and flagged for review"
+	}
 
 	response := attendance.CheckInResponse{
-		Message:      "Check-in successful",
-		Status:       "present",
+		Message:      message,
+		Status:       status,
 		StudentID:    studentID,
-		StudentName:  userEmail, // In a real app, fetch full name from database
-		MatricNumber: "",        // Would need to fetch from student record
-		CourseName:   event.EventName,
-		CourseCode:   "", // Would need to extract or store separately
+		StudentName:  studentName(student),
+		MatricNumber: student.MatricNumber,
+		CourseName:   courseName,
+		CourseCode:   courseCode,
 		MarkedTime:   now.Format(time.RFC3339),
+
+		DistanceMeters: geo.distanceMeters,
+		Flagged:        attendanceRecord.Flagged,
+		FlagReason:     geo.flagReason,
 	}
 
 	ctx.JSON(http.StatusOK, response)
 }
 
 // GetEventAttendance retrieves attendance records for a specific event.
-// Only lecturers can access this endpoint.
+// The lecturer who created the event, its co-lecturers and lecturers with an
+// assigned role covering it (such as the head of department) can access this endpoint.
 func (as *AttendanceSvc) GetEventAttendance(ctx *gin.Context) {
-	// Get event ID from URL parameter
-	eventID, ok := ctx.Params.Get("event_id")
-	if !ok || eventID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "event_id parameter is required",
-		})
-		return
-	}
-
-	// Convert to integer
-	var eventIDInt int
-	if _, err := fmt.Sscanf(eventID, "%d", &eventIDInt); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{
-			"error": "event_id must be a valid integer",
-		})
+	// Load the event and make sure the lecturer may read it
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermAttendanceRead)
+	if !ok {
 		return
 	}
 
-	// Get event and attendance records
-	event, records, err := as.attendanceRepo.GetEventWithAttendanceRecords(eventIDInt)
+	records, err := as.attendanceRepo.GetAttendanceByEventID(int(event.ID))
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"error": err.Error(),
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve attendance records",
+			"details": err.Error(),
 		})
 		return
 	}
 
 	// Build attendance records response
-	attendanceRecords := []attendance.AttendanceRecordResponse{}
-	for _, record := range records {
-		attendanceRecords = append(attendanceRecords, attendance.AttendanceRecordResponse{
-			ID:           int(record.ID),
-			StudentID:    record.StudentID,
-			StudentName:  fmt.Sprintf("%s %s", record.Student.FirstName, record.Student.LastName),
-			MatricNumber: record.Student.MatricNumber,
-			Status:       record.Status,
-			MarkedTime:   record.MarkedTime.Format(time.RFC3339),
-		})
-	}
+	attendanceRecords, tally := toAttendanceRecords(records)
+
+	courseName, courseCode, department := eventCourseDetails(event)
 
 	response := attendance.EventAttendanceResponse{
 		Message:           "Attendance records retrieved successfully",
 		EventID:           int(event.ID),
-		CourseName:        event.EventName,
-		CourseCode:        "", // Would need to parse from EventName or store separately
-		Department:        "",
+		CourseName:        courseName,
+		CourseCode:        courseCode,
+		Department:        department,
 		StartTime:         event.StartTime.Format(time.RFC3339),
 		EndTime:           event.EndTime.Format(time.RFC3339),
 		Venue:             event.Venue,
-		CreatedBy:         "",
-		TotalPresent:      len(attendanceRecords),
+		CreatedBy:         lecturerName(event.Creator),
+		TotalPresent:      tally.present,
+		TotalLate:         tally.late,
+		TotalAbsent:       tally.absent,
+		TotalExcused:      tally.excused,
+		AbsencesFinalized: event.AbsencesFinalizedAt != nil,
 		AttendanceRecords: attendanceRecords,
 		GeneratedAt:       time.Now().Format(time.RFC3339),
 	}
@@ -331,6 +553,21 @@ func (as *AttendanceSvc) GetStudentAttendance(ctx *gin.Context) {
 		return
 	}
 
+	student, err := as.authRepo.GetStudentByID(studentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": "student not found",
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve student",
+			"details": err.Error(),
+		})
+		return
+	}
+
 	// Get student attendance records
 	records, err := as.attendanceRepo.GetStudentAttendance(studentID)
 	if err != nil {
@@ -342,28 +579,571 @@ func (as *AttendanceSvc) GetStudentAttendance(ctx *gin.Context) {
 	}
 
 	// Build attendance records response
-	attendanceRecords := []attendance.AttendanceRecordResponse{}
+	attendanceRecords, tally := toAttendanceRecords(records)
+
+	response := attendance.StudentAttendanceResponse{
+		Message:           "Student attendance records retrieved successfully",
+		StudentID:         studentID,
+		StudentName:       studentName(student),
+		MatricNumber:      student.MatricNumber,
+		TotalEvents:       len(attendanceRecords),
+		TotalPresent:      tally.present,
+		TotalLate:         tally.late,
+		TotalAbsent:       tally.absent,
+		TotalExcused:      tally.excused,
+		AttendanceRecords: attendanceRecords,
+		GeneratedAt:       time.Now().Format(time.RFC3339),
+	}
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+// ListLecturerEvents retrieves the events the lecturer created or co-lectures.
+func (as *AttendanceSvc) ListLecturerEvents(ctx *gin.Context) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "user id not found in context",
+		})
+		return
+	}
+
+	events, err := as.attendanceRepo.ListEventsForLecturer(lecturerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve events",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	summaries := []attendance.EventSummaryResponse{}
+	for _, event := range events {
+		summaries = append(summaries, toEventSummary(event, lecturerID))
+	}
+
+	ctx.JSON(http.StatusOK, attendance.LecturerEventsResponse{
+		Message:     "Events retrieved successfully",
+		TotalEvents: len(summaries),
+		Events:      summaries,
+		GeneratedAt: time.Now().Format(time.RFC3339),
+	})
+}
+
+// AddCoLecturer grants another lecturer access to an event.
+// Only the lecturer who created the event can add co-lecturers.
+func (as *AttendanceSvc) AddCoLecturer(ctx *gin.Context) {
+	event, ok := as.loadCreatedEvent(ctx)
+	if !ok {
+		return
+	}
+
+	var req attendance.AddCoLecturerDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.LecturerID == *event.CreatedBy {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "the event creator already has access to this event",
+		})
+		return
+	}
+
+	if err := as.attendanceRepo.AddCoLecturer(int(event.ID), req.LecturerID); err != nil {
+		if errors.Is(err, repository.ErrLecturerNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to add co-lecturer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "co-lecturer added successfully",
+		"event_id":    int(event.ID),
+		"lecturer_id": req.LecturerID,
+	})
+}
+
+// RemoveCoLecturer revokes a co-lecturer's access to an event.
+// Only the lecturer who created the event can remove co-lecturers.
+func (as *AttendanceSvc) RemoveCoLecturer(ctx *gin.Context) {
+	event, ok := as.loadCreatedEvent(ctx)
+	if !ok {
+		return
+	}
+
+	var lecturerID int
+	if _, err := fmt.Sscanf(ctx.Param("lecturer_id"), "%d", &lecturerID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "lecturer_id must be a valid integer",
+		})
+		return
+	}
+
+	if err := as.attendanceRepo.RemoveCoLecturer(int(event.ID), lecturerID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to remove co-lecturer",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     "co-lecturer removed successfully",
+		"event_id":    int(event.ID),
+		"lecturer_id": lecturerID,
+	})
+}
+
+// GetCurrentQRCode returns the QR code to display for an event right now.
+// Screens showing a rotating event poll this endpoint before expires_at.
+func (as *AttendanceSvc) GetCurrentQRCode(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermEventsManage)
+	if !ok {
+		return
+	}
+
+	if event.Status == entities.EventCancelled {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "this event has been cancelled",
+		})
+		return
+	}
+
+	now := time.Now()
+	if checkInClosedEarly(event, now) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":  "check-in for this event has been closed",
+			"closed": event.CheckInClosedAt.Format(time.RFC3339),
+		})
+		return
+	}
+	if now.After(event.EndTime) && !checkInReopened(event, now) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":    "event has ended",
+			"end_time": event.EndTime.Format(time.RFC3339),
+		})
+		return
+	}
+
+	token, expiresAt := currentQRToken(event, now)
+	qrCodeData, err := utils.GenerateQRCodePNG(token, 256)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to generate QR code",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, attendance.CurrentQRCodeResponse{
+		Message:         "QR code retrieved successfully",
+		EventID:         int(event.ID),
+		QRMode:          qrMode(event),
+		QRToken:         token,
+		QRCodeData:      qrCodeData,
+		RotationSeconds: event.QRRotation,
+		ExpiresAt:       expiresAt.Format(time.RFC3339),
+	})
+}
+
+// resolveQRToken finds the event a scanned token belongs to. Signed tokens are
+// only accepted for rotating events within their window, and static UUIDs only
+// for static events, so a leaked static token cannot bypass rotation.
+// It writes the error response itself and returns false when the request should stop.
+func (as *AttendanceSvc) resolveQRToken(ctx *gin.Context, token string, now time.Time) (*entities.Event, bool) {
+	if !utils.IsRotatingQRToken(token) {
+		event, err := as.attendanceRepo.GetEventByQRToken(token)
+		if err != nil {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return nil, false
+		}
+		if qrMode(event) == entities.QRModeRotating {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "this event uses a rotating QR code. scan the code currently on screen",
+			})
+			return nil, false
+		}
+		return event, true
+	}
+
+	parsed, err := utils.ParseRotatingQRToken(token)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid QR token",
+		})
+		return nil, false
+	}
+
+	event, err := as.attendanceRepo.GetEventByID(parsed.EventID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": "qr code not found or invalid",
+		})
+		return nil, false
+	}
+	if qrMode(event) != entities.QRModeRotating {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "invalid QR token",
+		})
+		return nil, false
+	}
+	if !parsed.ValidAt(qrRotationPeriod(event), now) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "QR code has expired. scan the code currently on screen",
+		})
+		return nil, false
+	}
+
+	return event, true
+}
+
+// loadAccessibleEvent loads the event named by the event_id URL parameter and
+// checks that the authenticated lecturer created it, is a co-lecturer, or holds
+// perm through an assigned role covering the event's course or department.
+// It writes the error response itself and returns false when the request should stop.
+func (as *AttendanceSvc) loadAccessibleEvent(ctx *gin.Context, perm rbac.Permission) (*entities.Event, bool) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{
+			"error": "user id not found in context",
+		})
+		return nil, false
+	}
+
+	var eventID int
+	if _, err := fmt.Sscanf(ctx.Param("event_id"), "%d", &eventID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "event_id must be a valid integer",
+		})
+		return nil, false
+	}
+
+	event, err := as.attendanceRepo.GetEventByID(eventID)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error(),
+		})
+		return nil, false
+	}
+
+	allowed, err := as.attendanceRepo.CanLecturerAccessEvent(event, lecturerID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to check event access",
+			"details": err.Error(),
+		})
+		return nil, false
+	}
+	if !allowed && !middleware.HasPermission(ctx, perm, eventScopes(event)...) {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "you do not have access to this event",
+		})
+		return nil, false
+	}
+
+	return event, true
+}
+
+// loadCreatedEvent is like loadAccessibleEvent but only admits the event's creator.
+func (as *AttendanceSvc) loadCreatedEvent(ctx *gin.Context) (*entities.Event, bool) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermEventsManage)
+	if !ok {
+		return nil, false
+	}
+
+	lecturerID, _ := middleware.GetUserIDFromContext(ctx)
+	if event.CreatedBy == nil || *event.CreatedBy != lecturerID {
+		ctx.JSON(http.StatusForbidden, gin.H{
+			"error": "only the lecturer who created this event can do this",
+		})
+		return nil, false
+	}
+
+	return event, true
+}
+
+// eventScopes returns the scopes an event falls under for role-based access:
+// its course and department, or only the global scope when it has no course.
+func eventScopes(event *entities.Event) []rbac.Scope {
+	scopes := []rbac.Scope{{Type: rbac.ScopeGlobal}}
+	if event.Course != nil {
+		scopes = append(scopes, rbac.Course(event.Course.Code), rbac.Department(event.Course.Department))
+	}
+	return scopes
+}
+
+// geofenceResult is the outcome of checking a check-in location against a venue.
+type geofenceResult struct {
+	distanceMeters *float64
+	flagReason     string // Empty unless the check-in should be flagged for review
+}
+
+// checkGeofence compares the reported device location with the event's venue.
+// Strict events reject check-ins that cannot be placed inside the venue, lenient
+// events accept them with a flag, and events without geofencing skip the check.
+// It writes the error response itself and returns false when the request should stop.
+func checkGeofence(ctx *gin.Context, event *entities.Event, req *attendance.ScanQRCodeDTO) (geofenceResult, bool) {
+	var result geofenceResult
+	if event.GeofenceMode == "" || event.GeofenceMode == entities.GeofenceOff || event.Location == nil {
+		return result, true
+	}
+	strict := event.GeofenceMode == entities.GeofenceStrict
+
+	if req.Latitude == nil || req.Longitude == nil {
+		if strict {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "location is required to check in to this event",
+			})
+			return result, false
+		}
+		result.flagReason = entities.FlagLocationMissing
+		return result, true
+	}
+
+	distance := utils.HaversineMeters(*req.Latitude, *req.Longitude, event.Location.Latitude, event.Location.Longitude)
+	result.distanceMeters = &distance
+
+	// Without an accuracy the location cannot be trusted; a spoofed coordinate
+	// would otherwise count as perfectly precise
+	if req.Accuracy == nil {
+		if strict {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "location accuracy is required to check in to this event",
+			})
+			return result, false
+		}
+		result.flagReason = entities.FlagAccuracyMissing
+		return result, true
+	}
+	accuracy := *req.Accuracy
+	if accuracy > maxLocationAccuracyMeters {
+		if strict {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error":           "location accuracy is too low. enable precise location and try again",
+				"accuracy_meters": accuracy,
+			})
+			return result, false
+		}
+		result.flagReason = entities.FlagLowAccuracy
+		return result, true
+	}
+
+	// Give the device the benefit of its reported accuracy
+	if distance > event.Location.RadiusMeters+accuracy {
+		if strict {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error":           "you are too far from " + event.Location.Name + " to check in",
+				"distance_meters": distance,
+				"radius_meters":   event.Location.RadiusMeters,
+			})
+			return result, false
+		}
+		result.flagReason = entities.FlagOutsideGeofence
+	}
+
+	return result, true
+}
+
+// currentQRToken returns the token to encode in the event's QR code at now and
+// when it stops being valid. Static events always show their UUID.
+func currentQRToken(event *entities.Event, now time.Time) (string, time.Time) {
+	endsAt := checkInEndsAt(event)
+	if qrMode(event) != entities.QRModeRotating {
+		return event.QRCodeToken, endsAt
+	}
+	token, expiresAt := utils.GenerateRotatingQRToken(int(event.ID), qrRotationPeriod(event), now)
+	if expiresAt.After(endsAt) {
+		expiresAt = endsAt
+	}
+	return token, expiresAt
+}
+
+// checkInEndsAt returns when check-in for the event finally closes: its end
+// time, or the end of a reopen window running past it.
+func checkInEndsAt(event *entities.Event) time.Time {
+	if event.ReopenedUntil != nil && event.ReopenedUntil.After(event.EndTime) {
+		return *event.ReopenedUntil
+	}
+	return event.EndTime
+}
+
+// checkInReopened reports whether a lecturer's reopen window is running at now.
+func checkInReopened(event *entities.Event, now time.Time) bool {
+	return event.ReopenedUntil != nil && !now.After(*event.ReopenedUntil)
+}
+
+// checkInClosedEarly reports whether a lecturer closed check-in at or before
+// now and has not reopened it since.
+func checkInClosedEarly(event *entities.Event, now time.Time) bool {
+	return event.CheckInClosedAt != nil && !now.Before(*event.CheckInClosedAt) && !checkInReopened(event, now)
+}
+
+// checkInOpen reports whether a check-in at now would be accepted, going by
+// the event's status, times and any early close or reopen window.
+func checkInOpen(event *entities.Event, now time.Time) bool {
+	if eventStatus(event) == entities.EventCancelled || now.Before(event.StartTime) {
+		return false
+	}
+	if checkInReopened(event, now) {
+		return true
+	}
+	if checkInClosedEarly(event, now) || now.After(event.EndTime) {
+		return false
+	}
+	_, ok := checkInStatus(event, now)
+	return ok
+}
+
+// qrMode returns the event's QR mode, treating legacy rows as static.
+func qrMode(event *entities.Event) string {
+	if event.QRMode == "" {
+		return entities.QRModeStatic
+	}
+	return event.QRMode
+}
+
+// eventStatus returns the event's status, treating legacy rows as scheduled.
+func eventStatus(event *entities.Event) string {
+	if event.Status == "" {
+		return entities.EventScheduled
+	}
+	return event.Status
+}
+
+// qrRotationPeriod returns how long each rotating token is shown for.
+func qrRotationPeriod(event *entities.Event) time.Duration {
+	if event.QRRotation <= 0 {
+		return defaultQRRotationSeconds * time.Second
+	}
+	return time.Duration(event.QRRotation) * time.Second
+}
+
+// toEventSummary maps an event to the summary shown in a lecturer's event list.
+func toEventSummary(event *entities.Event, lecturerID int) attendance.EventSummaryResponse {
+	courseName, courseCode, _ := eventCourseDetails(event)
+
+	coLecturers := []attendance.EventCoLecturerResponse{}
+	for i := range event.CoLecturers {
+		coLecturers = append(coLecturers, attendance.EventCoLecturerResponse{
+			ID:   int(event.CoLecturers[i].ID),
+			Name: lecturerName(&event.CoLecturers[i]),
+		})
+	}
+
+	return attendance.EventSummaryResponse{
+		EventID:     int(event.ID),
+		CourseName:  courseName,
+		CourseCode:  courseCode,
+		StartTime:   event.StartTime.Format(time.RFC3339),
+		EndTime:     event.EndTime.Format(time.RFC3339),
+		Venue:       event.Venue,
+		CreatedBy:   lecturerName(event.Creator),
+		QRMode:      qrMode(event),
+		IsCreator:   event.CreatedBy != nil && *event.CreatedBy == lecturerID,
+		CoLecturers: coLecturers,
+		SemesterID:  event.SemesterID,
+		Status:      eventStatus(event),
+
+		CancelledAt:     formatOptionalTime(event.CancelledAt),
+		CancelReason:    event.CancelReason,
+		CheckInOpen:     checkInOpen(event, time.Now()),
+		CheckInClosedAt: formatOptionalTime(event.CheckInClosedAt),
+		ReopenedUntil:   formatOptionalTime(event.ReopenedUntil),
+
+		TimetableEntryID: event.TimetableEntryID,
+	}
+}
+
+// attendanceTally counts attendance records by status.
+type attendanceTally struct {
+	present, late, absent, excused int
+}
+
+// toAttendanceRecords maps attendance rows to response DTOs and tallies them by status.
+func toAttendanceRecords(records []*entities.UserAttendance) ([]attendance.AttendanceRecordResponse, attendanceTally) {
+	var tally attendanceTally
+	result := []attendance.AttendanceRecordResponse{}
 	for _, record := range records {
-		attendanceRecords = append(attendanceRecords, attendance.AttendanceRecordResponse{
+		switch record.Status {
+		case entities.AttendanceStatusPresent:
+			tally.present++
+		case entities.AttendanceStatusLate:
+			tally.late++
+		case entities.AttendanceStatusAbsent:
+			tally.absent++
+		case entities.AttendanceStatusExcused:
+			tally.excused++
+		}
+		result = append(result, attendance.AttendanceRecordResponse{
 			ID:           int(record.ID),
 			StudentID:    record.StudentID,
-			StudentName:  fmt.Sprintf("%s %s", record.Student.FirstName, record.Student.LastName),
+			StudentName:  studentName(&record.Student),
 			MatricNumber: record.Student.MatricNumber,
 			Status:       record.Status,
+			Source:       record.Source,
 			MarkedTime:   record.MarkedTime.Format(time.RFC3339),
+			Flagged:      record.Flagged,
+			FlagReason:   record.FlagReason,
 		})
 	}
+	return result, tally
+}
 
-	response := attendance.StudentAttendanceResponse{
-		Message:           "Student attendance records retrieved successfully",
-		StudentID:         studentID,
-		StudentName:       "", // Would need to fetch from database
-		MatricNumber:      "", // Would need to fetch from database
-		TotalEvents:       len(attendanceRecords),
-		TotalPresent:      len(attendanceRecords), // Assuming all records are "present"
-		AttendanceRecords: attendanceRecords,
-		GeneratedAt:       time.Now().Format(time.RFC3339),
+// checkInStatus returns the status for a check-in at now: present within the
+// grace period, late until the cutoff, and false once the cutoff has passed.
+// The cutoff does not apply while a lecturer has reopened check-in.
+func checkInStatus(event *entities.Event, now time.Time) (string, bool) {
+	elapsed := now.Sub(event.StartTime)
+	if event.LateCutoffMinutes != nil && elapsed > time.Duration(*event.LateCutoffMinutes)*time.Minute && !checkInReopened(event, now) {
+		return "", false
+	}
+	if elapsed > time.Duration(event.GracePeriodMinutes)*time.Minute {
+		return entities.AttendanceStatusLate, true
 	}
+	return entities.AttendanceStatusPresent, true
+}
 
-	ctx.JSON(http.StatusOK, response)
+// formatOptionalTime formats a nullable timestamp as RFC3339, or nil.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	formatted := t.Format(time.RFC3339)
+	return &formatted
+}
+
+// lecturerName returns the lecturer's full name, or an empty string when unknown.
+func lecturerName(lecturer *entities.Lecturer) string {
+	if lecturer == nil {
+		return ""
+	}
+	return fmt.Sprintf("%s %s", lecturer.FirstName, lecturer.LastName)
+}
+
+// studentName returns the student's full name.
+func studentName(student *entities.Student) string {
+	return fmt.Sprintf("%s %s", student.FirstName, student.LastName)
+}
+
+// eventCourseDetails returns the course name, code and department for an event.
+// Events created before courses existed fall back to the stored event name.
+func eventCourseDetails(event *entities.Event) (name, code, department string) {
+	if event.Course == nil {
+		return event.EventName, "", ""
+	}
+	return event.Course.Title, event.Course.Code, event.Course.Department
 }
@@ -0,0 +1,442 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	attendance "github.com/Dom-HTG/attendance-management-system/internal/attendance/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/attendance/repository"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	courseRepo "github.com/Dom-HTG/attendance-management-system/internal/course/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeAttendanceRepo serves one event and keeps the records created for it.
+type fakeAttendanceRepo struct {
+	repository.AttendanceRepoInterface
+	event     *entities.Event
+	records   []*entities.UserAttendance
+	audits    []*entities.AttendanceAudit
+	createErr error // Returned by CreateAttendanceRecord when set
+}
+
+func (r *fakeAttendanceRepo) GetEventByQRToken(qrToken string) (*entities.Event, error) {
+	if r.event == nil || r.event.QRCodeToken != qrToken {
+		return nil, errors.New("qr code not found or invalid")
+	}
+	return r.event, nil
+}
+
+func (r *fakeAttendanceRepo) GetEventByID(eventID int) (*entities.Event, error) {
+	if r.event == nil || int(r.event.ID) != eventID {
+		return nil, errors.New("event not found")
+	}
+	return r.event, nil
+}
+
+func (r *fakeAttendanceRepo) CheckIfStudentMarkedAttendance(eventID, studentID int) (bool, error) {
+	for _, record := range r.records {
+		if record.AttendanceID == eventID && record.StudentID == studentID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeAttendanceRepo) CreateAttendanceRecord(record *entities.UserAttendance) error {
+	if r.createErr != nil {
+		return r.createErr
+	}
+	r.records = append(r.records, record)
+	return nil
+}
+
+// fakeStudents serves students by ID.
+type fakeStudents struct {
+	authRepo.AuthRepoInterface
+	students map[int]*entities.Student
+}
+
+func (r *fakeStudents) GetStudentByID(studentID int) (*entities.Student, error) {
+	student, ok := r.students[studentID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return student, nil
+}
+
+// fakeEnrollments enrolls the listed students in every course.
+type fakeEnrollments struct {
+	courseRepo.EnrollmentRepoInterface
+	students map[int]bool
+}
+
+func (r *fakeEnrollments) IsStudentEnrolled(courseID, studentID int) (bool, error) {
+	return r.students[studentID], nil
+}
+
+// testEvent returns a static, ungeofenced event that started ten minutes ago.
+func testEvent(now time.Time) *entities.Event {
+	courseID := 3
+	event := &entities.Event{
+		CourseID:           &courseID,
+		Course:             &entities.Course{Code: "CSC301", Title: "Operating Systems"},
+		StartTime:          now.Add(-10 * time.Minute),
+		EndTime:            now.Add(50 * time.Minute),
+		QRCodeToken:        "550e8400-e29b-41d4-a716-446655440000",
+		QRMode:             entities.QRModeStatic,
+		GracePeriodMinutes: 15,
+	}
+	event.ID = 42
+	return event
+}
+
+// serve runs handler for an authenticated user with the URL params and body as
+// the JSON request, and returns the recorded response.
+func serve(t *testing.T, handler gin.HandlerFunc, userID int, params gin.Params, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = params
+	ctx.Set("user_id", userID)
+	handler(ctx)
+	return w
+}
+
+func TestCheckInReturnsStudentDetails(t *testing.T) {
+	event := testEvent(time.Now())
+	repo := &fakeAttendanceRepo{event: event}
+	svc := &AttendanceSvc{
+		attendanceRepo: repo,
+		authRepo: &fakeStudents{students: map[int]*entities.Student{
+			7: {FirstName: "Ada", LastName: "Obi", MatricNumber: "STU-2024-007"},
+		}},
+		enrollmentRepo: &fakeEnrollments{students: map[int]bool{7: true}},
+	}
+
+	w := serve(t, svc.CheckIn, 7, nil, attendance.ScanQRCodeDTO{QRToken: event.QRCodeToken})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body)
+	}
+	var resp attendance.CheckInResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if resp.StudentName != "Ada Obi" || resp.MatricNumber != "STU-2024-007" || resp.CourseCode != "CSC301" {
+		t.Errorf("response = %+v, want Ada Obi, STU-2024-007 and CSC301", resp)
+	}
+	if len(repo.records) != 1 || repo.records[0].StudentID != 7 || repo.records[0].Status != entities.AttendanceStatusPresent {
+		t.Errorf("records = %+v, want one present record for student 7", repo.records)
+	}
+
+	if w := serve(t, svc.CheckIn, 8, nil, attendance.ScanQRCodeDTO{QRToken: event.QRCodeToken}); w.Code != http.StatusNotFound {
+		t.Errorf("unknown student: status = %d, want %d", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestCheckInRequiresEnrollment(t *testing.T) {
+	students := &fakeStudents{students: map[int]*entities.Student{
+		7: {FirstName: "Ada", LastName: "Obi"},
+		8: {FirstName: "Bola", LastName: "Ade"},
+	}}
+	enrollments := &fakeEnrollments{students: map[int]bool{7: true}}
+
+	tests := []struct {
+		name      string
+		studentID int
+		noCourse  bool
+		want      int
+	}{
+		{"enrolled", 7, false, http.StatusOK},
+		{"not enrolled", 8, false, http.StatusForbidden},
+		{"event without a course", 8, true, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testEvent(time.Now())
+			if tt.noCourse {
+				event.CourseID, event.Course = nil, nil
+			}
+			repo := &fakeAttendanceRepo{event: event}
+			svc := &AttendanceSvc{attendanceRepo: repo, authRepo: students, enrollmentRepo: enrollments}
+
+			w := serve(t, svc.CheckIn, tt.studentID, nil, attendance.ScanQRCodeDTO{QRToken: event.QRCodeToken})
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if created := len(repo.records) > 0; created != (tt.want == http.StatusOK) {
+				t.Errorf("records = %+v", repo.records)
+			}
+		})
+	}
+}
+
+func TestResolveQRToken(t *testing.T) {
+	utils.UseQRTokenSecret("qr-test-secret")
+	now := time.Now()
+	period := 30 * time.Second
+
+	static := testEvent(now)
+	rotating := testEvent(now)
+	rotating.QRMode = entities.QRModeRotating
+	rotating.QRRotation = int(period.Seconds())
+
+	current, _ := utils.GenerateRotatingQRToken(int(rotating.ID), period, now)
+	previous, _ := utils.GenerateRotatingQRToken(int(rotating.ID), period, now.Add(-period))
+	stale, _ := utils.GenerateRotatingQRToken(int(rotating.ID), period, now.Add(-2*period))
+	otherEvent, _ := utils.GenerateRotatingQRToken(99, period, now)
+
+	tests := []struct {
+		name  string
+		event *entities.Event
+		token string
+		want  int // Zero when the token resolves
+	}{
+		{"static token", static, static.QRCodeToken, 0},
+		{"unknown static token", static, "00000000-0000-0000-0000-000000000000", http.StatusNotFound},
+		{"static token of a rotating event", rotating, rotating.QRCodeToken, http.StatusBadRequest},
+		{"current window", rotating, current, 0},
+		{"previous window", rotating, previous, 0},
+		{"expired window", rotating, stale, http.StatusBadRequest},
+		{"rotating token of a static event", static, current, http.StatusBadRequest},
+		{"tampered signature", rotating, current[:len(current)-2] + "xx", http.StatusBadRequest},
+		{"malformed", rotating, "v1.42", http.StatusBadRequest},
+		{"unknown event", rotating, otherEvent, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &AttendanceSvc{attendanceRepo: &fakeAttendanceRepo{event: tt.event}}
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			event, ok := svc.resolveQRToken(ctx, tt.token, now)
+			if tt.want == 0 {
+				if !ok || event != tt.event {
+					t.Errorf("token not resolved: %d %s", w.Code, w.Body)
+				}
+				return
+			}
+			if ok || w.Code != tt.want {
+				t.Errorf("resolved = %v, status = %d, want %d", ok, w.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckGeofence(t *testing.T) {
+	venue := &entities.Venue{Name: "LT1", Latitude: 5.5663, Longitude: 5.7926, RadiusMeters: 50}
+	f := func(v float64) *float64 { return &v }
+	at := func(lat, accuracy *float64) attendance.ScanQRCodeDTO {
+		req := attendance.ScanQRCodeDTO{Accuracy: accuracy}
+		if lat != nil {
+			req.Latitude, req.Longitude = lat, f(venue.Longitude)
+		}
+		return req
+	}
+	inside := f(venue.Latitude)
+	away := f(venue.Latitude + 0.001) // About 111m north of the venue
+
+	tests := []struct {
+		name       string
+		mode       string
+		noVenue    bool
+		req        attendance.ScanQRCodeDTO
+		wantStatus int // Zero when the check-in goes ahead
+		wantFlag   string
+	}{
+		{"off ignores location", entities.GeofenceOff, false, at(nil, nil), 0, ""},
+		{"no venue skips the check", entities.GeofenceStrict, true, at(nil, nil), 0, ""},
+		{"strict inside", entities.GeofenceStrict, false, at(inside, f(10)), 0, ""},
+		{"strict outside", entities.GeofenceStrict, false, at(away, f(10)), http.StatusForbidden, ""},
+		{"strict within accuracy of the radius", entities.GeofenceStrict, false, at(away, f(70)), 0, ""},
+		{"strict without location", entities.GeofenceStrict, false, at(nil, f(10)), http.StatusBadRequest, ""},
+		{"strict without accuracy", entities.GeofenceStrict, false, at(inside, nil), http.StatusBadRequest, ""},
+		{"strict with low accuracy", entities.GeofenceStrict, false, at(inside, f(150)), http.StatusBadRequest, ""},
+		{"lenient inside", entities.GeofenceLenient, false, at(inside, f(10)), 0, ""},
+		{"lenient outside", entities.GeofenceLenient, false, at(away, f(10)), 0, entities.FlagOutsideGeofence},
+		{"lenient without location", entities.GeofenceLenient, false, at(nil, nil), 0, entities.FlagLocationMissing},
+		{"lenient without accuracy", entities.GeofenceLenient, false, at(inside, nil), 0, entities.FlagAccuracyMissing},
+		{"lenient with low accuracy", entities.GeofenceLenient, false, at(away, f(150)), 0, entities.FlagLowAccuracy},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testEvent(time.Now())
+			event.GeofenceMode = tt.mode
+			if !tt.noVenue {
+				event.Location = venue
+			}
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			result, ok := checkGeofence(ctx, event, &tt.req)
+			if tt.wantStatus != 0 {
+				if ok || w.Code != tt.wantStatus {
+					t.Errorf("accepted = %v, status = %d, want %d", ok, w.Code, tt.wantStatus)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("rejected: %d %s", w.Code, w.Body)
+			}
+			if result.flagReason != tt.wantFlag {
+				t.Errorf("flag = %q, want %q", result.flagReason, tt.wantFlag)
+			}
+			if wantDistance := tt.req.Latitude != nil && event.Location != nil && tt.mode != entities.GeofenceOff; (result.distanceMeters != nil) != wantDistance {
+				t.Errorf("distance = %v, want one reported: %v", result.distanceMeters, wantDistance)
+			}
+		})
+	}
+}
+
+func TestCheckInStatus(t *testing.T) {
+	start := time.Date(2025, 11, 28, 10, 0, 0, 0, time.UTC)
+	cutoff := 20
+	reopenedUntil := start.Add(40 * time.Minute)
+
+	tests := []struct {
+		name       string
+		cutoff     *int
+		reopened   bool
+		elapsed    time.Duration
+		wantStatus string // Empty when the check-in is refused
+	}{
+		{"at the start", &cutoff, false, 0, entities.AttendanceStatusPresent},
+		{"end of the grace period", &cutoff, false, 5 * time.Minute, entities.AttendanceStatusPresent},
+		{"just after the grace period", &cutoff, false, 5*time.Minute + time.Second, entities.AttendanceStatusLate},
+		{"at the cutoff", &cutoff, false, 20 * time.Minute, entities.AttendanceStatusLate},
+		{"after the cutoff", &cutoff, false, 20*time.Minute + time.Second, ""},
+		{"no cutoff", nil, false, 2 * time.Hour, entities.AttendanceStatusLate},
+		{"reopened after the cutoff", &cutoff, true, 30 * time.Minute, entities.AttendanceStatusLate},
+		{"reopen window over", &cutoff, true, 41 * time.Minute, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &entities.Event{StartTime: start, EndTime: start.Add(time.Hour), GracePeriodMinutes: 5, LateCutoffMinutes: tt.cutoff}
+			if tt.reopened {
+				event.ReopenedUntil = &reopenedUntil
+			}
+
+			status, ok := checkInStatus(event, start.Add(tt.elapsed))
+			if ok != (tt.wantStatus != "") || status != tt.wantStatus {
+				t.Errorf("checkInStatus = (%q, %v), want %q", status, ok, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestCheckInOpen(t *testing.T) {
+	start := time.Date(2025, 11, 28, 10, 0, 0, 0, time.UTC)
+	closedAt := start.Add(15 * time.Minute)
+	reopenedUntil := start.Add(70 * time.Minute)
+
+	tests := []struct {
+		name    string
+		event   entities.Event
+		elapsed time.Duration
+		want    bool
+	}{
+		{"before the start", entities.Event{}, -time.Minute, false},
+		{"during the event", entities.Event{}, 30 * time.Minute, true},
+		{"after the end", entities.Event{}, 61 * time.Minute, false},
+		{"cancelled", entities.Event{Status: entities.EventCancelled}, 30 * time.Minute, false},
+		{"closed early", entities.Event{CheckInClosedAt: &closedAt}, 30 * time.Minute, false},
+		{"reopened after closing", entities.Event{CheckInClosedAt: &closedAt, ReopenedUntil: &reopenedUntil}, 30 * time.Minute, true},
+		{"reopened past the end", entities.Event{ReopenedUntil: &reopenedUntil}, 65 * time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := tt.event
+			event.StartTime, event.EndTime, event.GracePeriodMinutes = start, start.Add(time.Hour), 5
+			if got := checkInOpen(&event, start.Add(tt.elapsed)); got != tt.want {
+				t.Errorf("checkInOpen = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckInRacingAnotherRecord(t *testing.T) {
+	event := testEvent(time.Now())
+	svc := &AttendanceSvc{
+		attendanceRepo: &fakeAttendanceRepo{event: event, createErr: repository.ErrAttendanceAlreadyRecorded},
+		authRepo:       &fakeStudents{students: map[int]*entities.Student{7: {}}},
+		enrollmentRepo: &fakeEnrollments{students: map[int]bool{7: true}},
+	}
+
+	w := serve(t, svc.CheckIn, 7, nil, attendance.ScanQRCodeDTO{QRToken: event.QRCodeToken})
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, want %d: %s", w.Code, http.StatusConflict, w.Body)
+	}
+}
+
+func TestLoadAccessibleEvent(t *testing.T) {
+	creator := 5
+	lecturer, _ := rbac.AccountGrant(rbac.RoleLecturer)
+	hod := func(department string) rbac.Grant {
+		return rbac.Grant{Role: rbac.RoleHeadOfDepartment, Scope: rbac.Department(department)}
+	}
+	assistant := rbac.Grant{Role: rbac.RoleTeachingAssistant, Scope: rbac.Course("CSC301")}
+
+	tests := []struct {
+		name    string
+		userID  int
+		grants  []rbac.Grant
+		perm    rbac.Permission
+		eventID string
+		want    int
+	}{
+		{"creator manages", creator, []rbac.Grant{lecturer}, rbac.PermEventsManage, "42", http.StatusOK},
+		{"other lecturer", 6, []rbac.Grant{lecturer}, rbac.PermAttendanceRead, "42", http.StatusForbidden},
+		{"head of the course's department reads", 6, []rbac.Grant{lecturer, hod("Computer Science")}, rbac.PermAttendanceRead, "42", http.StatusOK},
+		{"head of the department cannot manage", 6, []rbac.Grant{lecturer, hod("Computer Science")}, rbac.PermEventsManage, "42", http.StatusForbidden},
+		{"head of another department", 6, []rbac.Grant{lecturer, hod("Mathematics")}, rbac.PermAttendanceRead, "42", http.StatusForbidden},
+		{"assistant on the course reads", 6, []rbac.Grant{lecturer, assistant}, rbac.PermAttendanceRead, "42", http.StatusOK},
+		{"unknown event", creator, []rbac.Grant{lecturer}, rbac.PermEventsManage, "43", http.StatusNotFound},
+		{"malformed event ID", creator, []rbac.Grant{lecturer}, rbac.PermEventsManage, "abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := testEvent(time.Now())
+			event.CreatedBy = &creator
+			event.Course.Department = "Computer Science"
+			svc := &AttendanceSvc{attendanceRepo: &fakeAttendanceRepo{event: event}}
+
+			gin.SetMode(gin.TestMode)
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+			ctx.Params = gin.Params{{Key: "event_id", Value: tt.eventID}}
+			ctx.Set("user_id", tt.userID)
+			ctx.Set("grants", tt.grants)
+
+			got, ok := svc.loadAccessibleEvent(ctx, tt.perm)
+			if ok != (tt.want == http.StatusOK) || (ok && got != event) {
+				t.Errorf("loadAccessibleEvent = %v, %v", got, ok)
+			}
+			if !ok && w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,530 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	attendance "github.com/Dom-HTG/attendance-management-system/internal/attendance/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/attendance/repository"
+	venueRepo "github.com/Dom-HTG/attendance-management-system/internal/venue/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultReopenMinutes is how long check-in stays reopened when no length is given.
+const defaultReopenMinutes = 10
+
+// eventChange is a validated transition of an event: the columns to write and
+// the audit entry describing them.
+type eventChange struct {
+	action  string
+	reason  string
+	columns map[string]interface{}
+	changes map[string]attendance.EventFieldChange
+}
+
+// UpdateEvent lets a lecturer move an event or change its venue. Events that
+// have started keep their start time, and events that have ended or were
+// cancelled cannot be changed. Every change is recorded in the event's history.
+func (as *AttendanceSvc) UpdateEvent(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermEventsManage)
+	if !ok {
+		return
+	}
+
+	var req attendance.UpdateEventDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	if eventStatus(event) == entities.EventCancelled {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "cancelled events cannot be changed",
+		})
+		return
+	}
+	if now.After(event.EndTime) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "events that have ended cannot be changed",
+		})
+		return
+	}
+	if !checkTimetableEvent(ctx, event, now) {
+		return
+	}
+
+	// Resolve the new times; a started event only moves its end
+	startTime, endTime := event.StartTime, event.EndTime
+	if req.StartTime != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.StartTime)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid start_time format. expected RFC3339 format (e.g., 2025-11-27T10:00:00Z)",
+			})
+			return
+		}
+		startTime = parsed
+	}
+	if req.EndTime != nil {
+		parsed, err := time.Parse(time.RFC3339, *req.EndTime)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid end_time format. expected RFC3339 format (e.g., 2025-11-27T11:00:00Z)",
+			})
+			return
+		}
+		endTime = parsed
+	}
+
+	started := !now.Before(event.StartTime)
+	if !startTime.Equal(event.StartTime) {
+		if started {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "start_time cannot change once the event has started",
+			})
+			return
+		}
+		if !startTime.After(now) {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"error": "start_time must be in the future",
+			})
+			return
+		}
+	}
+	if !endTime.After(startTime) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "end_time must be after start_time",
+		})
+		return
+	}
+	if !endTime.After(now) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "end_time must be in the future",
+		})
+		return
+	}
+
+	// Resolve the venue; geofencing needs a managed venue with coordinates
+	venueName, venueID, location := event.Venue, event.VenueID, event.Location
+	if req.VenueID != nil {
+		if *req.VenueID == 0 {
+			venueID, location = nil, nil
+		} else {
+			venue, err := as.venueRepo.GetVenueByID(*req.VenueID)
+			if err != nil {
+				if errors.Is(err, venueRepo.ErrVenueNotFound) {
+					ctx.JSON(http.StatusNotFound, gin.H{
+						"error": err.Error(),
+					})
+					return
+				}
+				ctx.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "failed to retrieve venue",
+					"details": err.Error(),
+				})
+				return
+			}
+			venueID, location, venueName = req.VenueID, venue, venue.Name
+		}
+	}
+	if req.Venue != nil && venueID == nil {
+		venueName = strings.TrimSpace(*req.Venue)
+	}
+	if venueName == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "venue or venue_id is required",
+		})
+		return
+	}
+
+	geofenceMode := event.GeofenceMode
+	if req.GeofenceMode != nil {
+		geofenceMode = *req.GeofenceMode
+	}
+	if geofenceMode == "" {
+		geofenceMode = entities.GeofenceOff
+	}
+	if geofenceMode != entities.GeofenceOff && location == nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "geofence_mode requires a venue_id",
+		})
+		return
+	}
+
+	change := eventChange{
+		action:  entities.EventAuditUpdate,
+		reason:  req.Reason,
+		columns: map[string]interface{}{},
+		changes: map[string]attendance.EventFieldChange{},
+	}
+	if !startTime.Equal(event.StartTime) {
+		change.set("start_time", startTime, formatTime(event.StartTime), formatTime(startTime))
+	}
+	if !endTime.Equal(event.EndTime) {
+		change.set("end_time", endTime, formatTime(event.EndTime), formatTime(endTime))
+	}
+	if venueName != event.Venue {
+		change.set("venue", venueName, event.Venue, venueName)
+	}
+	if !sameID(venueID, event.VenueID) {
+		change.set("venue_id", venueID, formatOptionalID(event.VenueID), formatOptionalID(venueID))
+	}
+	if geofenceMode != event.GeofenceMode {
+		change.set("geofence_mode", geofenceMode, event.GeofenceMode, geofenceMode)
+	}
+
+	if len(change.columns) == 0 {
+		lecturerID, _ := middleware.GetUserIDFromContext(ctx)
+		ctx.JSON(http.StatusOK, gin.H{
+			"message": "event unchanged",
+			"event":   toEventSummary(event, lecturerID),
+		})
+		return
+	}
+
+	as.applyEventChange(ctx, event, change, now, "event updated successfully")
+}
+
+// CancelEvent cancels an event with a reason. Cancelled events refuse
+// check-ins, record no absences and are left out of attendance rates.
+func (as *AttendanceSvc) CancelEvent(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermEventsManage)
+	if !ok {
+		return
+	}
+
+	var req attendance.CancelEventDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "reason is required",
+		})
+		return
+	}
+
+	now := time.Now()
+	if eventStatus(event) == entities.EventCancelled {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "event is already cancelled",
+		})
+		return
+	}
+	if !checkTimetableEvent(ctx, event, now) {
+		return
+	}
+
+	change := eventChange{
+		action: entities.EventAuditCancel,
+		reason: reason,
+		columns: map[string]interface{}{
+			"cancelled_at":  now,
+			"cancel_reason": reason,
+		},
+		changes: map[string]attendance.EventFieldChange{},
+	}
+	change.set("status", entities.EventCancelled, eventStatus(event), entities.EventCancelled)
+
+	as.applyEventChange(ctx, event, change, now, "event cancelled successfully")
+}
+
+// CloseCheckIn stops an event accepting check-ins before it ends.
+func (as *AttendanceSvc) CloseCheckIn(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermEventsManage)
+	if !ok {
+		return
+	}
+
+	var req attendance.CloseCheckInDTO
+	if err := bindOptionalJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	if !checkTransition(ctx, event, now) {
+		return
+	}
+	if !checkInOpen(event, now) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "check-in for this event is already closed",
+		})
+		return
+	}
+
+	change := eventChange{
+		action:  entities.EventAuditClose,
+		reason:  req.Reason,
+		columns: map[string]interface{}{},
+		changes: map[string]attendance.EventFieldChange{},
+	}
+	change.set("check_in_closed_at", now, formatOptionalTimeValue(event.CheckInClosedAt), formatTime(now))
+	if checkInReopened(event, now) {
+		// Closing a reopened event ends the reopen window now
+		change.set("reopened_until", now, formatOptionalTimeValue(event.ReopenedUntil), formatTime(now))
+	}
+
+	as.applyEventChange(ctx, event, change, now, "check-in closed successfully")
+}
+
+// ReopenCheckIn accepts check-ins again for a few minutes once they have
+// closed, whether early, at the late cutoff or at the end of the event, up to
+// the reopen limit after the event ends. If absences were already finalized
+// they are removed and recorded again after the window.
+func (as *AttendanceSvc) ReopenCheckIn(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermEventsManage)
+	if !ok {
+		return
+	}
+
+	var req attendance.ReopenCheckInDTO
+	if err := bindOptionalJSON(ctx, &req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	now := time.Now()
+	if !checkTransition(ctx, event, now) || !checkReopenLimit(ctx, event, now, as.reopenLimit) {
+		return
+	}
+	if checkInOpen(event, now) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "check-in for this event is already open",
+		})
+		return
+	}
+
+	minutes := req.Minutes
+	if minutes == 0 {
+		minutes = defaultReopenMinutes
+	}
+	until := now.Add(time.Duration(minutes) * time.Minute)
+
+	change := eventChange{
+		action:  entities.EventAuditReopen,
+		reason:  req.Reason,
+		columns: map[string]interface{}{},
+		changes: map[string]attendance.EventFieldChange{},
+	}
+	change.set("reopened_until", until, formatOptionalTimeValue(event.ReopenedUntil), formatTime(until))
+	if event.AbsencesFinalizedAt != nil {
+		// The finalizer records absences again once the window has passed
+		change.set("absences_finalized_at", nil, formatOptionalTimeValue(event.AbsencesFinalizedAt), "")
+	}
+
+	as.applyEventChange(ctx, event, change, now, "check-in reopened successfully")
+}
+
+// GetEventHistory returns the changes lecturers made to an event's schedule
+// and check-in, newest first.
+func (as *AttendanceSvc) GetEventHistory(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermAttendanceRead)
+	if !ok {
+		return
+	}
+
+	entries, err := as.attendanceRepo.ListEventAudit(int(event.ID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve event history",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := []attendance.EventHistoryEntryResponse{}
+	for _, entry := range entries {
+		changes := map[string]attendance.EventFieldChange{}
+		if entry.Changes != "" {
+			_ = json.Unmarshal([]byte(entry.Changes), &changes)
+		}
+		result = append(result, attendance.EventHistoryEntryResponse{
+			ID:          int(entry.ID),
+			Action:      entry.Action,
+			Changes:     changes,
+			Reason:      entry.Reason,
+			ChangedByID: entry.ChangedBy,
+			ChangedBy:   lecturerName(&entry.Lecturer),
+			ChangedAt:   entry.ChangedAt.Format(time.RFC3339),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, attendance.EventHistoryResponse{
+		Message:      "Event history retrieved successfully",
+		EventID:      int(event.ID),
+		TotalEntries: len(result),
+		Entries:      result,
+		GeneratedAt:  time.Now().Format(time.RFC3339),
+	})
+}
+
+// applyEventChange writes a validated transition and its audit entry, then
+// responds with the event as it now stands.
+func (as *AttendanceSvc) applyEventChange(ctx *gin.Context, event *entities.Event, change eventChange, now time.Time, message string) {
+	lecturerID, _ := middleware.GetUserIDFromContext(ctx)
+
+	changes, err := json.Marshal(change.changes)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to record event history",
+			"details": err.Error(),
+		})
+		return
+	}
+	audit := &entities.EventAudit{
+		EventID:   int(event.ID),
+		Action:    change.action,
+		Changes:   string(changes),
+		Reason:    strings.TrimSpace(change.reason),
+		ChangedBy: lecturerID,
+		ChangedAt: now,
+	}
+
+	if err := as.attendanceRepo.UpdateEvent(event, change.columns, audit); err != nil {
+		if errors.Is(err, repository.ErrEventChanged) {
+			ctx.JSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to update event",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	updated, err := as.attendanceRepo.GetEventByID(int(event.ID))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve event",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"event":   toEventSummary(updated, lecturerID),
+	})
+}
+
+// set records a column to write and how it appears in the event's history.
+func (c *eventChange) set(column string, value interface{}, from, to string) {
+	c.columns[column] = value
+	c.changes[column] = attendance.EventFieldChange{From: from, To: to}
+}
+
+// checkTimetableEvent refuses direct changes to timetabled events that have
+// not started, since the timetable generator would undo them; those are
+// cancelled or rescheduled with a timetable exception instead.
+// It writes the error response itself and returns false when the request should stop.
+func checkTimetableEvent(ctx *gin.Context, event *entities.Event, now time.Time) bool {
+	if event.TimetableEntryID != nil && now.Before(event.StartTime) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":              "this event comes from the course timetable. cancel or reschedule it with a timetable exception",
+			"timetable_entry_id": *event.TimetableEntryID,
+		})
+		return false
+	}
+	return true
+}
+
+// checkTransition checks that check-in for an event can be closed or reopened
+// at now: the event must not be cancelled and must have started.
+// It writes the error response itself and returns false when the request should stop.
+func checkTransition(ctx *gin.Context, event *entities.Event, now time.Time) bool {
+	if eventStatus(event) == entities.EventCancelled {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error": "this event has been cancelled",
+		})
+		return false
+	}
+	if now.Before(event.StartTime) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":      "event has not started yet",
+			"start_time": event.StartTime.Format(time.RFC3339),
+		})
+		return false
+	}
+	return true
+}
+
+// checkReopenLimit checks that check-in for an event can still be reopened at
+// now: no later than limit after the event ends.
+// It writes the error response itself and returns false when the request should stop.
+func checkReopenLimit(ctx *gin.Context, event *entities.Event, now time.Time, limit time.Duration) bool {
+	if deadline := event.EndTime.Add(limit); now.After(deadline) {
+		ctx.JSON(http.StatusConflict, gin.H{
+			"error":     fmt.Sprintf("check-in can only be reopened up to %d minutes after the event ends", int(limit.Minutes())),
+			"end_time":  event.EndTime.Format(time.RFC3339),
+			"reopen_by": deadline.Format(time.RFC3339),
+		})
+		return false
+	}
+	return true
+}
+
+// bindOptionalJSON binds the request body when there is one, so that requests
+// whose fields are all optional may be sent without a body.
+func bindOptionalJSON(ctx *gin.Context, obj interface{}) error {
+	if ctx.Request.ContentLength == 0 {
+		return nil
+	}
+	return ctx.ShouldBindJSON(obj)
+}
+
+// sameID reports whether two nullable IDs are equal.
+func sameID(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// formatTime formats a timestamp for the event's history.
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// formatOptionalTimeValue formats a nullable timestamp for the event's
+// history, as an empty string when unset.
+func formatOptionalTimeValue(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return formatTime(*t)
+}
+
+// formatOptionalID formats a nullable ID for the event's history, as an empty
+// string when unset.
+func formatOptionalID(id *int) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.Itoa(*id)
+}
@@ -0,0 +1,171 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	attendance "github.com/Dom-HTG/attendance-management-system/internal/attendance/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// lifecycleRepo records the event changes written through UpdateEvent.
+type lifecycleRepo struct {
+	*fakeAttendanceRepo
+	updates     []map[string]interface{}
+	eventAudits []*entities.EventAudit
+}
+
+func (r *lifecycleRepo) UpdateEvent(event *entities.Event, columns map[string]interface{}, audit *entities.EventAudit) error {
+	r.updates = append(r.updates, columns)
+	r.eventAudits = append(r.eventAudits, audit)
+	return nil
+}
+
+func TestEventStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		want   string
+	}{
+		{"", entities.EventScheduled},
+		{entities.EventScheduled, entities.EventScheduled},
+		{entities.EventCancelled, entities.EventCancelled},
+	}
+
+	for _, tt := range tests {
+		if got := eventStatus(&entities.Event{Status: tt.status}); got != tt.want {
+			t.Errorf("eventStatus(%q) = %q, want %q", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestCheckTransition(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name   string
+		status string
+		start  time.Duration // Event start relative to now
+		want   bool
+	}{
+		{"running", entities.EventScheduled, -10 * time.Minute, true},
+		{"ended", entities.EventScheduled, -3 * time.Hour, true},
+		{"legacy row without status", "", -10 * time.Minute, true},
+		{"starting now", entities.EventScheduled, 0, true},
+		{"not started", entities.EventScheduled, time.Minute, false},
+		{"cancelled", entities.EventCancelled, -10 * time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &entities.Event{Status: tt.status, StartTime: now.Add(tt.start), EndTime: now.Add(tt.start + time.Hour)}
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			if got := checkTransition(ctx, event, now); got != tt.want {
+				t.Errorf("checkTransition = %v, want %v", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusConflict {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+			}
+		})
+	}
+}
+
+func TestCheckReopenLimit(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name  string
+		ended time.Duration // How long ago the event ended; negative while it runs
+		limit time.Duration
+		want  bool
+	}{
+		{"still running", -20 * time.Minute, time.Hour, true},
+		{"just ended", time.Minute, time.Hour, true},
+		{"at the limit", time.Hour, time.Hour, true},
+		{"past the limit", time.Hour + time.Second, time.Hour, false},
+		{"shorter limit", 20 * time.Minute, 15 * time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := &entities.Event{StartTime: now.Add(-tt.ended - time.Hour), EndTime: now.Add(-tt.ended)}
+			w := httptest.NewRecorder()
+			ctx, _ := gin.CreateTestContext(w)
+
+			if got := checkReopenLimit(ctx, event, now, tt.limit); got != tt.want {
+				t.Errorf("checkReopenLimit = %v, want %v", got, tt.want)
+			}
+			if !tt.want && w.Code != http.StatusConflict {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusConflict)
+			}
+		})
+	}
+}
+
+func TestReopenCheckIn(t *testing.T) {
+	lecturerID := 5
+	params := gin.Params{{Key: "event_id", Value: "42"}}
+
+	tests := []struct {
+		name           string
+		ended          time.Duration // How long ago the event ended; negative while it runs
+		status         string
+		finalized      bool
+		req            attendance.ReopenCheckInDTO
+		want           int
+		wantWindow     time.Duration
+		wantUnfinalize bool
+	}{
+		{"ended recently", 20 * time.Minute, "", false, attendance.ReopenCheckInDTO{}, http.StatusOK, defaultReopenMinutes * time.Minute, false},
+		{"chosen length", 20 * time.Minute, "", false, attendance.ReopenCheckInDTO{Minutes: 5}, http.StatusOK, 5 * time.Minute, false},
+		{"absences recorded", 40 * time.Minute, "", true, attendance.ReopenCheckInDTO{}, http.StatusOK, defaultReopenMinutes * time.Minute, true},
+		{"past the reopen limit", 61 * time.Minute, "", true, attendance.ReopenCheckInDTO{}, http.StatusConflict, 0, false},
+		{"already open", -50 * time.Minute, "", false, attendance.ReopenCheckInDTO{}, http.StatusConflict, 0, false},
+		{"cancelled", 20 * time.Minute, entities.EventCancelled, false, attendance.ReopenCheckInDTO{}, http.StatusConflict, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			event := testEvent(now)
+			event.EndTime = now.Add(-tt.ended)
+			event.StartTime = event.EndTime.Add(-time.Hour)
+			event.Status = tt.status
+			event.CreatedBy = &lecturerID
+			if tt.finalized {
+				finalizedAt := event.EndTime.Add(5 * time.Minute)
+				event.AbsencesFinalizedAt = &finalizedAt
+			}
+			repo := &lifecycleRepo{fakeAttendanceRepo: &fakeAttendanceRepo{event: event}}
+			svc := &AttendanceSvc{attendanceRepo: repo, reopenLimit: time.Hour}
+
+			w := serve(t, svc.ReopenCheckIn, lecturerID, params, tt.req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if tt.want != http.StatusOK {
+				if len(repo.updates) != 0 {
+					t.Errorf("event updated with %v", repo.updates)
+				}
+				return
+			}
+
+			if len(repo.updates) != 1 || repo.eventAudits[0].Action != entities.EventAuditReopen {
+				t.Fatalf("updates = %v, want one reopen", repo.updates)
+			}
+			columns := repo.updates[0]
+			until, ok := columns["reopened_until"].(time.Time)
+			if window := until.Sub(now); !ok || window < tt.wantWindow || window > tt.wantWindow+time.Second {
+				t.Errorf("reopened_until = %v, want %v from now", columns["reopened_until"], tt.wantWindow)
+			}
+			cleared, unfinalized := columns["absences_finalized_at"]
+			if unfinalized != tt.wantUnfinalize || (unfinalized && cleared != nil) {
+				t.Errorf("absences_finalized_at = %v (set %v), want cleared %v", cleared, unfinalized, tt.wantUnfinalize)
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	attendance "github.com/Dom-HTG/attendance-management-system/internal/attendance/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/attendance/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// SetAttendanceStatus lets a lecturer create or change a student's attendance
+// record for an event they created, co-lecture or assist on as a teaching
+// assistant. Every change is written to the audit trail with the lecturer and reason.
+func (as *AttendanceSvc) SetAttendanceStatus(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermAttendanceOverride)
+	if !ok {
+		return
+	}
+	lecturerID, _ := middleware.GetUserIDFromContext(ctx)
+
+	studentID, ok := studentIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req attendance.SetAttendanceStatusDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "reason is required",
+		})
+		return
+	}
+
+	// Students without a record must be enrolled in the event's course
+	hasRecord, err := as.attendanceRepo.CheckIfStudentMarkedAttendance(int(event.ID), studentID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to check attendance status",
+			"details": err.Error(),
+		})
+		return
+	}
+	if !hasRecord {
+		if event.CourseID == nil {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": "student has no attendance record for this event",
+			})
+			return
+		}
+		enrolled, err := as.enrollmentRepo.IsStudentEnrolled(*event.CourseID, studentID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "failed to check enrollment",
+				"details": err.Error(),
+			})
+			return
+		}
+		if !enrolled {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": "student is not enrolled in this course",
+			})
+			return
+		}
+	}
+
+	record, audit, err := as.attendanceRepo.OverrideAttendance(int(event.ID), studentID, req.Status, reason, lecturerID, time.Now())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to update attendance status",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	message := "attendance status updated successfully"
+	if audit == nil {
+		message = "attendance status unchanged"
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":     message,
+		"event_id":    int(event.ID),
+		"student_id":  studentID,
+		"record_id":   int(record.ID),
+		"status":      record.Status,
+		"source":      record.Source,
+		"marked_time": record.MarkedTime.Format(time.RFC3339),
+	})
+}
+
+// VoidAttendance removes a student's attendance record for an event, for
+// example a fraudulent check-in. The removal is written to the audit trail.
+func (as *AttendanceSvc) VoidAttendance(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermAttendanceOverride)
+	if !ok {
+		return
+	}
+	lecturerID, _ := middleware.GetUserIDFromContext(ctx)
+
+	studentID, ok := studentIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	var req attendance.VoidAttendanceDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error":   "invalid request body",
+			"details": err.Error(),
+		})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "reason is required",
+		})
+		return
+	}
+
+	audit, err := as.attendanceRepo.VoidAttendanceRecord(int(event.ID), studentID, reason, lecturerID, time.Now())
+	if err != nil {
+		if errors.Is(err, repository.ErrAttendanceRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to void attendance record",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message":         "attendance record voided successfully",
+		"event_id":        int(event.ID),
+		"student_id":      studentID,
+		"record_id":       int(audit.AttendanceRecordID),
+		"previous_status": audit.PreviousStatus,
+	})
+}
+
+// GetRecordAudit returns the audit trail for one student's record at an event.
+func (as *AttendanceSvc) GetRecordAudit(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermAttendanceRead)
+	if !ok {
+		return
+	}
+
+	studentID, ok := studentIDParam(ctx)
+	if !ok {
+		return
+	}
+
+	as.respondAudit(ctx, event, &studentID)
+}
+
+// GetEventAudit returns the audit trail for every record at an event.
+func (as *AttendanceSvc) GetEventAudit(ctx *gin.Context) {
+	event, ok := as.loadAccessibleEvent(ctx, rbac.PermAttendanceRead)
+	if !ok {
+		return
+	}
+
+	as.respondAudit(ctx, event, nil)
+}
+
+// respondAudit loads and writes the audit trail for an event, optionally
+// narrowed to one student.
+func (as *AttendanceSvc) respondAudit(ctx *gin.Context, event *entities.Event, studentID *int) {
+	entries, err := as.attendanceRepo.ListAttendanceAudit(int(event.ID), studentID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{
+			"error":   "failed to retrieve audit trail",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result := []attendance.AttendanceAuditEntryResponse{}
+	for _, entry := range entries {
+		result = append(result, attendance.AttendanceAuditEntryResponse{
+			ID:                 int(entry.ID),
+			AttendanceRecordID: int(entry.AttendanceRecordID),
+			StudentID:          entry.StudentID,
+			StudentName:        fmt.Sprintf("%s %s", entry.Student.FirstName, entry.Student.LastName),
+			MatricNumber:       entry.Student.MatricNumber,
+			Action:             entry.Action,
+			PreviousStatus:     entry.PreviousStatus,
+			NewStatus:          entry.NewStatus,
+			Reason:             entry.Reason,
+			ChangedByID:        entry.ChangedBy,
+			ChangedBy:          lecturerName(&entry.Lecturer),
+			ChangedAt:          entry.ChangedAt.Format(time.RFC3339),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, attendance.AttendanceAuditResponse{
+		Message:      "Audit trail retrieved successfully",
+		EventID:      int(event.ID),
+		StudentID:    studentID,
+		TotalEntries: len(result),
+		Entries:      result,
+		GeneratedAt:  time.Now().Format(time.RFC3339),
+	})
+}
+
+// studentIDParam parses the student_id URL parameter.
+// It writes the error response itself and returns false when the request should stop.
+func studentIDParam(ctx *gin.Context) (int, bool) {
+	var studentID int
+	if _, err := fmt.Sscanf(ctx.Param("student_id"), "%d", &studentID); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"error": "student_id must be a valid integer",
+		})
+		return 0, false
+	}
+	return studentID, true
+}
@@ -0,0 +1,142 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	attendance "github.com/Dom-HTG/attendance-management-system/internal/attendance/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/attendance/repository"
+	"github.com/gin-gonic/gin"
+)
+
+func (r *fakeAttendanceRepo) CanLecturerAccessEvent(event *entities.Event, lecturerID int) (bool, error) {
+	return event.CreatedBy != nil && *event.CreatedBy == lecturerID, nil
+}
+
+func (r *fakeAttendanceRepo) record(studentID int) *entities.UserAttendance {
+	for _, record := range r.records {
+		if record.StudentID == studentID {
+			return record
+		}
+	}
+	return nil
+}
+
+func (r *fakeAttendanceRepo) OverrideAttendance(eventID, studentID int, status, reason string, lecturerID int, at time.Time) (*entities.UserAttendance, *entities.AttendanceAudit, error) {
+	audit := &entities.AttendanceAudit{EventID: eventID, StudentID: studentID, NewStatus: status, Reason: reason, ChangedBy: lecturerID, ChangedAt: at}
+	record := r.record(studentID)
+	switch {
+	case record == nil:
+		record = &entities.UserAttendance{AttendanceID: eventID, StudentID: studentID, Status: status, MarkedTime: at}
+		record.ID = uint(len(r.records) + 1)
+		r.records = append(r.records, record)
+		audit.Action = entities.AuditActionCreate
+	case record.Status == status:
+		return record, nil, nil
+	default:
+		audit.Action, audit.PreviousStatus = entities.AuditActionUpdate, record.Status
+		record.Status = status
+	}
+	record.Source = entities.AttendanceSourceManual
+	audit.AttendanceRecordID = record.ID
+	r.audits = append(r.audits, audit)
+	return record, audit, nil
+}
+
+func (r *fakeAttendanceRepo) VoidAttendanceRecord(eventID, studentID int, reason string, lecturerID int, at time.Time) (*entities.AttendanceAudit, error) {
+	for i, record := range r.records {
+		if record.StudentID == studentID {
+			r.records = append(r.records[:i], r.records[i+1:]...)
+			audit := &entities.AttendanceAudit{AttendanceRecordID: record.ID, EventID: eventID, StudentID: studentID, Action: entities.AuditActionVoid, PreviousStatus: record.Status, Reason: reason, ChangedBy: lecturerID, ChangedAt: at}
+			r.audits = append(r.audits, audit)
+			return audit, nil
+		}
+	}
+	return nil, repository.ErrAttendanceRecordNotFound
+}
+
+func (r *fakeAttendanceRepo) ListAttendanceAudit(eventID int, studentID *int) ([]*entities.AttendanceAudit, error) {
+	var entries []*entities.AttendanceAudit
+	for i := len(r.audits) - 1; i >= 0; i-- {
+		if studentID == nil || r.audits[i].StudentID == *studentID {
+			entries = append(entries, r.audits[i])
+		}
+	}
+	return entries, nil
+}
+
+func TestAttendanceOverrides(t *testing.T) {
+	const lecturerID = 1
+	creator := lecturerID
+	event := testEvent(time.Now())
+	event.CreatedBy = &creator
+	repo := &fakeAttendanceRepo{event: event}
+	repo.records = []*entities.UserAttendance{{AttendanceID: 42, StudentID: 7, Status: entities.AttendanceStatusLate}}
+	repo.records[0].ID = 1
+	svc := &AttendanceSvc{attendanceRepo: repo, enrollmentRepo: &fakeEnrollments{students: map[int]bool{7: true, 8: true}}}
+
+	params := func(studentID int) gin.Params {
+		return gin.Params{{Key: "event_id", Value: "42"}, {Key: "student_id", Value: strconv.Itoa(studentID)}}
+	}
+
+	steps := []struct {
+		name       string
+		handler    gin.HandlerFunc
+		userID     int
+		studentID  int
+		body       interface{}
+		wantStatus int
+		wantAudits int // Audit entries written so far
+	}{
+		{"another lecturer", svc.SetAttendanceStatus, 2, 7, attendance.SetAttendanceStatusDTO{Status: "present", Reason: "seen in class"}, http.StatusForbidden, 0},
+		{"blank reason", svc.SetAttendanceStatus, lecturerID, 7, attendance.SetAttendanceStatusDTO{Status: "present", Reason: "  "}, http.StatusBadRequest, 0},
+		{"unknown status", svc.SetAttendanceStatus, lecturerID, 7, attendance.SetAttendanceStatusDTO{Status: "asleep", Reason: "r"}, http.StatusBadRequest, 0},
+		{"change a check-in", svc.SetAttendanceStatus, lecturerID, 7, attendance.SetAttendanceStatusDTO{Status: "present", Reason: "bus was late"}, http.StatusOK, 1},
+		{"unchanged status", svc.SetAttendanceStatus, lecturerID, 7, attendance.SetAttendanceStatusDTO{Status: "present", Reason: "again"}, http.StatusOK, 1},
+		{"create for an enrolled student", svc.SetAttendanceStatus, lecturerID, 8, attendance.SetAttendanceStatusDTO{Status: "excused", Reason: "medical note"}, http.StatusOK, 2},
+		{"student not enrolled", svc.SetAttendanceStatus, lecturerID, 9, attendance.SetAttendanceStatusDTO{Status: "present", Reason: "r"}, http.StatusNotFound, 2},
+		{"void", svc.VoidAttendance, lecturerID, 7, attendance.VoidAttendanceDTO{Reason: "checked in for a friend"}, http.StatusOK, 3},
+		{"void without a record", svc.VoidAttendance, lecturerID, 7, attendance.VoidAttendanceDTO{Reason: "again"}, http.StatusNotFound, 3},
+	}
+
+	for _, step := range steps {
+		w := serve(t, step.handler, step.userID, params(step.studentID), step.body)
+		if w.Code != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d: %s", step.name, w.Code, step.wantStatus, w.Body)
+		}
+		if len(repo.audits) != step.wantAudits {
+			t.Fatalf("%s: %d audit entries, want %d", step.name, len(repo.audits), step.wantAudits)
+		}
+	}
+
+	w := serve(t, svc.GetRecordAudit, lecturerID, params(7), nil)
+	var trail attendance.AttendanceAuditResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &trail); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	want := []struct{ action, previous, next, reason string }{
+		{entities.AuditActionVoid, "present", "", "checked in for a friend"},
+		{entities.AuditActionUpdate, "late", "present", "bus was late"},
+	}
+	if len(trail.Entries) != len(want) {
+		t.Fatalf("trail = %+v, want %d entries", trail.Entries, len(want))
+	}
+	for i, entry := range trail.Entries {
+		if entry.Action != want[i].action || entry.PreviousStatus != want[i].previous || entry.NewStatus != want[i].next ||
+			entry.Reason != want[i].reason || entry.ChangedByID != lecturerID || entry.AttendanceRecordID != 1 {
+			t.Errorf("entry %d = %+v, want %+v", i, entry, want[i])
+		}
+	}
+
+	w = serve(t, svc.GetEventAudit, lecturerID, params(0), nil)
+	if err := json.Unmarshal(w.Body.Bytes(), &trail); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if trail.TotalEntries != 3 || trail.Entries[1].Action != entities.AuditActionCreate || trail.Entries[1].StudentID != 8 {
+		t.Errorf("event trail = %+v, want 3 entries with the creation for student 8 second", trail.Entries)
+	}
+}
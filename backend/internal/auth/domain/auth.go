@@ -7,20 +7,68 @@ import (
 
 // Repository Interface.
 type AuthRepoInterface interface {
-	RegisterStudent(student *RegisterStudentDTO) error
-	RegisterLecturer(lecturer *RegisterLecturerDTO) error
+	RegisterStudent(student *RegisterStudentDTO, requireVerification bool) (int, error)
+	RegisterLecturer(lecturer *RegisterLecturerDTO, requireVerification bool) (int, error)
 	FindStudentByEmail(email string) (*StudentResponse, error)
 	FindLecturerByEmail(email string) (*LecturerResponse, error)
 	GetStudentByEmailWithPassword(email string) (*entities.Student, error)
 	GetLecturerByEmailWithPassword(email string) (*entities.Lecturer, error)
+	GetAdminByEmailWithPassword(email string) (*entities.Admin, error)
+	GetLoginProfiles(email string) ([]LoginProfile, error)
+	GetIdentityByEmail(email string) (*entities.Identity, error)
+	GetAccount(role string, userID int) (*Account, error)
+	GetStudentByID(studentID int) (*entities.Student, error)
+	GetLecturerByID(lecturerID int) (*entities.Lecturer, error)
+	GetAdminByID(adminID int) (*entities.Admin, error)
+	UpdateStudent(studentID int, updates map[string]interface{}) error
+	UpdateLecturer(lecturerID int, updates map[string]interface{}) error
+	IsTaken(role, column, value string, exceptID int) (bool, error)
 }
 
 // Service Interface.
 type AuthSvcInterface interface {
 	RegisterStudent(ctx *gin.Context)
 	RegisterLecturer(ctx *gin.Context)
+	Login(ctx *gin.Context)
 	LoginStudent(ctx *gin.Context)
 	LoginLecturer(ctx *gin.Context)
+	LoginAdmin(ctx *gin.Context)
+	RefreshToken(ctx *gin.Context)
+	Logout(ctx *gin.Context)
+	LogoutAll(ctx *gin.Context)
+	ForgotPassword(ctx *gin.Context)
+	ResetPassword(ctx *gin.Context)
+	VerifyEmail(ctx *gin.Context)
+	ResendVerification(ctx *gin.Context)
+	GetStudentProfile(ctx *gin.Context)
+	UpdateStudentProfile(ctx *gin.Context)
+	GetLecturerProfile(ctx *gin.Context)
+	UpdateLecturerProfile(ctx *gin.Context)
+	LoginSecondFactor(ctx *gin.Context)
+	LoginTwoFactorSetup(ctx *gin.Context)
+	LoginTwoFactorEnable(ctx *gin.Context)
+	GetTwoFactorStatus(ctx *gin.Context)
+	SetupTwoFactor(ctx *gin.Context)
+	EnableTwoFactor(ctx *gin.Context)
+	DisableTwoFactor(ctx *gin.Context)
+	RegenerateRecoveryCodes(ctx *gin.Context)
+	JWKS(ctx *gin.Context)
+}
+
+// Account identifies a user of any role for session handling.
+type Account struct {
+	ID        int
+	Email     string
+	Role      string
+	Suspended bool
+}
+
+// LoginProfile is a student or lecturer profile that signs in with a given
+// email, with the password hash to check.
+type LoginProfile struct {
+	Role     string
+	ID       int
+	Password string
 }
 
 // Request DTOs
@@ -30,6 +78,11 @@ type RegisterStudentDTO struct {
 	Email        string `json:"email" binding:"required,email"`
 	Password     string `json:"password" binding:"required,min=6"`
 	MatricNumber string `json:"matric_number" binding:"required"`
+	Faculty      string `json:"faculty"`
+	Department   string `json:"department"`
+	Programme    string `json:"programme"`
+	Level        int    `json:"level" binding:"omitempty,min=1"`
+	EntrySession string `json:"entry_session"` // e.g. "2024/2025"
 }
 
 type LoginStudentDTO struct {
@@ -51,6 +104,19 @@ type LoginLecturerDTO struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginDTO signs a student or lecturer in through /api/auth/login. Role is
+// only needed when the account holds both roles.
+type LoginDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+	Role     string `json:"role" binding:"omitempty,oneof=student lecturer"`
+}
+
+type LoginAdminDTO struct {
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"required"`
+}
+
 // Response DTOs
 type StudentResponse struct {
 	ID           int    `json:"id"`
@@ -58,32 +124,168 @@ type StudentResponse struct {
 	LastName     string `json:"last_name"`
 	Email        string `json:"email"`
 	MatricNumber string `json:"matric_number"`
+	Faculty      string `json:"faculty,omitempty"`
+	Department   string `json:"department,omitempty"`
+	Programme    string `json:"programme,omitempty"`
+	Level        int    `json:"level,omitempty"`
+	EntrySession string `json:"entry_session,omitempty"`
 	Role         string `json:"role"`
+	PendingEmail string `json:"pending_email,omitempty"` // New address awaiting verification
 	CreatedAt    string `json:"created_at,omitempty"`
 }
 
 type LecturerResponse struct {
-	ID         int    `json:"id"`
-	FirstName  string `json:"first_name"`
-	LastName   string `json:"last_name"`
-	Email      string `json:"email"`
-	Department string `json:"department"`
-	StaffID    string `json:"staff_id"`
-	Role       string `json:"role"`
-	CreatedAt  string `json:"created_at,omitempty"`
+	ID           int    `json:"id"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Email        string `json:"email"`
+	Department   string `json:"department"`
+	StaffID      string `json:"staff_id"`
+	Role         string `json:"role"`
+	PendingEmail string `json:"pending_email,omitempty"` // New address awaiting verification
+	CreatedAt    string `json:"created_at,omitempty"`
+}
+
+type AdminResponse struct {
+	ID        int    `json:"id"`
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+	Email     string `json:"email"`
+	Role      string `json:"role"`
 }
 
 type LoginResponse struct {
-	Message     string      `json:"message"`
-	AccessToken string      `json:"access_token"`
-	User        interface{} `json:"user"`
+	Message          string      `json:"message"`
+	AccessToken      string      `json:"access_token"`
+	RefreshToken     string      `json:"refresh_token"`
+	TokenType        string      `json:"token_type"`
+	ExpiresAt        string      `json:"expires_at"`         // When the access token expires
+	RefreshExpiresAt string      `json:"refresh_expires_at"` // When the refresh token expires
+	User             interface{} `json:"user"`
+	RecoveryCodes    []string    `json:"recovery_codes,omitempty"` // Only when two-factor was enabled during this login
+}
+
+// TwoFactorChallengeResponse is returned by login instead of tokens when the
+// password was accepted but a second factor is still needed.
+type TwoFactorChallengeResponse struct {
+	Message                string `json:"message"`
+	TwoFactorRequired      bool   `json:"two_factor_required,omitempty"`       // Send a code to /api/auth/login/2fa
+	TwoFactorSetupRequired bool   `json:"two_factor_setup_required,omitempty"` // Enroll through /api/auth/login/2fa/setup first
+	ChallengeToken         string `json:"challenge_token"`
+	ExpiresAt              string `json:"expires_at"`
+}
+
+// SecondFactorDTO completes a login with a TOTP code or a recovery code.
+type SecondFactorDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code"`
+	RecoveryCode   string `json:"recovery_code"`
 }
 
+// ChallengeDTO names the challenge of a login that is waiting for two-factor setup.
+type ChallengeDTO struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+}
+
+// TwoFactorCodeDTO carries a TOTP code, or a recovery code where one is accepted.
+type TwoFactorCodeDTO struct {
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+// TwoFactorSetupResponse carries a new TOTP secret for an authenticator app.
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`      // Base32, for manual entry
+	OTPAuthURL string `json:"otpauth_url"` // Encoded in qr_code
+	QRCode     string `json:"qr_code"`     // Base64-encoded PNG
+}
+
+// TwoFactorStatusResponse describes a user's two-factor settings.
+type TwoFactorStatusResponse struct {
+	Enabled                bool    `json:"enabled"`
+	Pending                bool    `json:"pending"`  // Set up but not yet confirmed with a code
+	Required               bool    `json:"required"` // The user's role must use two-factor authentication
+	RecoveryCodesRemaining int     `json:"recovery_codes_remaining"`
+	EnabledAt              *string `json:"enabled_at,omitempty"`
+}
+
+// RecoveryCodesResponse returns newly generated recovery codes. They are shown
+// once and only their hashes are stored.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ForgotPasswordDTO requests a password reset email. Role narrows the lookup
+// when the same address is registered as both a student and a lecturer.
 type ForgotPasswordDTO struct {
-	Email string `json:"email"`
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=student lecturer"`
+}
+
+// ResetPasswordDTO redeems a password reset token.
+type ResetPasswordDTO struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=6"`
+}
+
+// UpdateStudentProfileDTO changes a student's profile. Omitted fields are left
+// unchanged. MatricNumber and the academic fields (faculty, department,
+// programme, level and entry session) can only be changed by an admin.
+type UpdateStudentProfileDTO struct {
+	FirstName       *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName        *string `json:"last_name" binding:"omitempty,min=1"`
+	Email           *string `json:"email" binding:"omitempty,email"`
+	MatricNumber    *string `json:"matric_number" binding:"omitempty,min=1"`
+	Faculty         *string `json:"faculty" binding:"omitempty,min=1"`
+	Department      *string `json:"department" binding:"omitempty,min=1"`
+	Programme       *string `json:"programme" binding:"omitempty,min=1"`
+	Level           *int    `json:"level" binding:"omitempty,min=1"`
+	EntrySession    *string `json:"entry_session" binding:"omitempty,min=1"`
+	NewPassword     *string `json:"new_password" binding:"omitempty,min=6"`
+	CurrentPassword string  `json:"current_password"` // Required when users change their own password
+}
+
+// UpdateLecturerProfileDTO changes a lecturer's profile. Omitted fields are left
+// unchanged. Department and StaffID can only be changed by an admin.
+type UpdateLecturerProfileDTO struct {
+	FirstName       *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName        *string `json:"last_name" binding:"omitempty,min=1"`
+	Email           *string `json:"email" binding:"omitempty,email"`
+	Department      *string `json:"department" binding:"omitempty,min=1"`
+	StaffID         *string `json:"staff_id" binding:"omitempty,min=1"`
+	NewPassword     *string `json:"new_password" binding:"omitempty,min=6"`
+	CurrentPassword string  `json:"current_password"` // Required when users change their own password
+}
+
+// ResendVerificationDTO requests a new verification email for an unverified
+// account. Role narrows the lookup when the same address is registered as both
+// a student and a lecturer.
+type ResendVerificationDTO struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"omitempty,oneof=student lecturer"`
+}
+
+// VerifyEmailDTO redeems an email verification token.
+type VerifyEmailDTO struct {
+	Token string `json:"token" binding:"required"`
 }
 
 type RefreshTokenDTO struct {
-	AccessToken  string `json:"access_token"`
+	AccessToken  string `json:"access_token"` // Optional; the refresh token alone identifies the session
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// TokenPairResponse is returned when a refresh token is exchanged.
+type TokenPairResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresAt        string `json:"expires_at"`
+	RefreshExpiresAt string `json:"refresh_expires_at"`
+}
+
+// LogoutDTO optionally names the refresh token of the session to end.
+// When omitted, the session that issued the caller's access token is ended.
+type LogoutDTO struct {
 	RefreshToken string `json:"refresh_token"`
 }
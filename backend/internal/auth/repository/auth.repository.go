@@ -12,12 +12,22 @@ type AuthRepo struct {
 }
 
 type AuthRepoInterface interface {
-	RegisterStudent(student *auth.RegisterStudentDTO) error
-	RegisterLecturer(lecturer *auth.RegisterLecturerDTO) error
+	RegisterStudent(student *auth.RegisterStudentDTO, requireVerification bool) (int, error)
+	RegisterLecturer(lecturer *auth.RegisterLecturerDTO, requireVerification bool) (int, error)
 	FindStudentByEmail(email string) (*auth.StudentResponse, error)
 	FindLecturerByEmail(email string) (*auth.LecturerResponse, error)
 	GetStudentByEmailWithPassword(email string) (*entities.Student, error)
 	GetLecturerByEmailWithPassword(email string) (*entities.Lecturer, error)
+	GetAdminByEmailWithPassword(email string) (*entities.Admin, error)
+	GetLoginProfiles(email string) ([]auth.LoginProfile, error)
+	GetIdentityByEmail(email string) (*entities.Identity, error)
+	GetAccount(role string, userID int) (*auth.Account, error)
+	GetStudentByID(studentID int) (*entities.Student, error)
+	GetLecturerByID(lecturerID int) (*entities.Lecturer, error)
+	GetAdminByID(adminID int) (*entities.Admin, error)
+	UpdateStudent(studentID int, updates map[string]interface{}) error
+	UpdateLecturer(lecturerID int, updates map[string]interface{}) error
+	IsTaken(role, column, value string, exceptID int) (bool, error)
 }
 
 func NewAuthRepo(dbInstance *gorm.DB) *AuthRepo {
@@ -26,49 +36,78 @@ func NewAuthRepo(dbInstance *gorm.DB) *AuthRepo {
 	}
 }
 
-func (ar *AuthRepo) RegisterStudent(student *auth.RegisterStudentDTO) error {
+// RegisterStudent creates a student and returns its ID. When requireVerification
+// is set the student cannot log in until their email is verified. The student is
+// linked to the identity for its email, and signs in with that identity's
+// password if it already has one. It returns ErrAccountExists if that identity
+// already has a student profile.
+func (ar *AuthRepo) RegisterStudent(student *auth.RegisterStudentDTO, requireVerification bool) (int, error) {
 	// Map DTO to Student entity
 	studentEntity := &entities.Student{
 		FirstName:    student.FirstName,
 		LastName:     student.LastName,
-		Email:        student.Email,
-		Password:     student.Password,
 		MatricNumber: student.MatricNumber,
+		Faculty:      student.Faculty,
+		Department:   student.Department,
+		Programme:    student.Programme,
+		Level:        student.Level,
+		EntrySession: student.EntrySession,
 		Role:         "student",
+
+		RequiresEmailVerification: requireVerification,
 	}
 
-	tx := ar.DB.Create(&studentEntity)
-	if tx.Error != nil {
-		logger.Errorf("RegisterStudent DB create failed: %v", tx.Error)
-		return tx.Error
+	err := ar.DB.Transaction(func(tx *gorm.DB) error {
+		identity, err := linkNewProfile(tx, "student", student.Email, student.Password)
+		if err != nil {
+			return err
+		}
+		studentEntity.IdentityID = identity.ID
+		return tx.Create(&studentEntity).Error
+	})
+	if err != nil {
+		logger.Errorf("RegisterStudent DB create failed: %v", err)
+		return 0, err
 	}
-	return nil
+	return int(studentEntity.ID), nil
 }
 
-func (ar *AuthRepo) RegisterLecturer(lecturer *auth.RegisterLecturerDTO) error {
+// RegisterLecturer creates a lecturer and returns its ID. When requireVerification
+// is set the lecturer cannot log in until their email is verified. The lecturer is
+// linked to the identity for its email, and signs in with that identity's
+// password if it already has one. It returns ErrAccountExists if that identity
+// already has a lecturer profile.
+func (ar *AuthRepo) RegisterLecturer(lecturer *auth.RegisterLecturerDTO, requireVerification bool) (int, error) {
 	// Map DTO to Lecturer entity
 	lecturerEntity := &entities.Lecturer{
 		FirstName:  lecturer.FirstName,
 		LastName:   lecturer.LastName,
-		Email:      lecturer.Email,
-		Password:   lecturer.Password,
 		Department: lecturer.Department,
 		StaffID:    lecturer.StaffID,
 		Role:       "lecturer",
+
+		RequiresEmailVerification: requireVerification,
 	}
 
-	tx := ar.DB.Create(&lecturerEntity)
-	if tx.Error != nil {
-		logger.Errorf("RegisterLecturer DB create failed: %v", tx.Error)
-		return tx.Error
+	err := ar.DB.Transaction(func(tx *gorm.DB) error {
+		identity, err := linkNewProfile(tx, "lecturer", lecturer.Email, lecturer.Password)
+		if err != nil {
+			return err
+		}
+		lecturerEntity.IdentityID = identity.ID
+		return tx.Create(&lecturerEntity).Error
+	})
+	if err != nil {
+		logger.Errorf("RegisterLecturer DB create failed: %v", err)
+		return 0, err
 	}
-	return nil
+	return int(lecturerEntity.ID), nil
 }
 
 func (ar *AuthRepo) FindStudentByEmail(email string) (*auth.StudentResponse, error) {
 	var student entities.Student
 
-	tx := ar.DB.Where("email = ?", email).First(&student)
+	tx := ar.DB.Joins("Identity").Where(`"Identity".email = ?`, email).First(&student)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -77,7 +116,7 @@ func (ar *AuthRepo) FindStudentByEmail(email string) (*auth.StudentResponse, err
 		ID:           int(student.ID),
 		FirstName:    student.FirstName,
 		LastName:     student.LastName,
-		Email:        student.Email,
+		Email:        student.Identity.Email,
 		MatricNumber: student.MatricNumber,
 		Role:         student.Role,
 		CreatedAt:    student.CreatedAt.String(),
@@ -87,7 +126,7 @@ func (ar *AuthRepo) FindStudentByEmail(email string) (*auth.StudentResponse, err
 func (ar *AuthRepo) FindLecturerByEmail(email string) (*auth.LecturerResponse, error) {
 	var lecturer entities.Lecturer
 
-	tx := ar.DB.Where("email = ?", email).First(&lecturer)
+	tx := ar.DB.Joins("Identity").Where(`"Identity".email = ?`, email).First(&lecturer)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -96,7 +135,7 @@ func (ar *AuthRepo) FindLecturerByEmail(email string) (*auth.LecturerResponse, e
 		ID:         int(lecturer.ID),
 		FirstName:  lecturer.FirstName,
 		LastName:   lecturer.LastName,
-		Email:      lecturer.Email,
+		Email:      lecturer.Identity.Email,
 		Department: lecturer.Department,
 		StaffID:    lecturer.StaffID,
 		Role:       lecturer.Role,
@@ -107,7 +146,7 @@ func (ar *AuthRepo) FindLecturerByEmail(email string) (*auth.LecturerResponse, e
 // Helper methods to get full entities with passwords for login
 func (ar *AuthRepo) GetStudentByEmailWithPassword(email string) (*entities.Student, error) {
 	var student entities.Student
-	tx := ar.DB.Where("email = ?", email).First(&student)
+	tx := ar.DB.Joins("Identity").Where(`"Identity".email = ?`, email).First(&student)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
@@ -116,9 +155,45 @@ func (ar *AuthRepo) GetStudentByEmailWithPassword(email string) (*entities.Stude
 
 func (ar *AuthRepo) GetLecturerByEmailWithPassword(email string) (*entities.Lecturer, error) {
 	var lecturer entities.Lecturer
-	tx := ar.DB.Where("email = ?", email).First(&lecturer)
+	tx := ar.DB.Joins("Identity").Where(`"Identity".email = ?`, email).First(&lecturer)
 	if tx.Error != nil {
 		return nil, tx.Error
 	}
 	return &lecturer, nil
 }
+
+func (ar *AuthRepo) GetAdminByEmailWithPassword(email string) (*entities.Admin, error) {
+	var admin entities.Admin
+	tx := ar.DB.Where("email = ?", email).First(&admin)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &admin, nil
+}
+
+// GetAccount retrieves the account behind a session by role and ID.
+func (ar *AuthRepo) GetAccount(role string, userID int) (*auth.Account, error) {
+	var query *gorm.DB
+	switch role {
+	case "student", "lecturer":
+		model, _ := accountModel(role)
+		table := role + "s"
+		query = ar.DB.Model(model).
+			Select(table+".id, identities.email, "+table+".role, "+table+".suspended_at IS NOT NULL AS suspended").
+			Joins("JOIN identities ON identities.id = "+table+".identity_id").
+			Where(table+".id = ?", userID)
+	case "admin":
+		// Admins cannot be suspended
+		query = ar.DB.Model(&entities.Admin{}).Select("id, email, role").Where("id = ?", userID)
+	default:
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var account auth.Account
+	tx := query.Take(&account)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	account.Role = role
+	return &account, nil
+}
@@ -0,0 +1,221 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrAccountExists is returned when registering a role that the identity for
+// an email already holds.
+var ErrAccountExists = errors.New("account already exists")
+
+// identityEmailConflict makes an insert into identities do nothing when an
+// identity that is not deleted already has the email, going by the partial
+// unique index idx_identities_email_active.
+var identityEmailConflict = clause.OnConflict{
+	Columns:     []clause.Column{{Name: "email"}},
+	TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "deleted_at IS NULL"}}},
+	DoNothing:   true,
+}
+
+// GetLoginProfiles returns every student and lecturer profile that signs in
+// with email, with its identity's password hash to check.
+func (ar *AuthRepo) GetLoginProfiles(email string) ([]auth.LoginProfile, error) {
+	var profiles []auth.LoginProfile
+	err := ar.DB.Raw(`
+		SELECT 'student' AS role, s.id AS id, i.password AS password
+		FROM students s
+		JOIN identities i ON i.id = s.identity_id AND i.deleted_at IS NULL
+		WHERE s.deleted_at IS NULL AND i.email = ?
+		UNION ALL
+		SELECT 'lecturer' AS role, l.id AS id, i.password AS password
+		FROM lecturers l
+		JOIN identities i ON i.id = l.identity_id AND i.deleted_at IS NULL
+		WHERE l.deleted_at IS NULL AND i.email = ?
+		ORDER BY role`, email, email).Scan(&profiles).Error
+	if err != nil {
+		return nil, errors.New("failed to retrieve login profiles: " + err.Error())
+	}
+	return profiles, nil
+}
+
+// GetIdentityByEmail retrieves the identity that signs in with email. Identities
+// whose profiles were all deleted are treated as not found, since registering
+// again should not require the old password.
+func (ar *AuthRepo) GetIdentityByEmail(email string) (*entities.Identity, error) {
+	var identity entities.Identity
+	if err := ar.DB.Where("email = ?", email).First(&identity).Error; err != nil {
+		return nil, err
+	}
+	active, err := identityActive(ar.DB, identity.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &identity, nil
+}
+
+// LinkIdentity returns the identity a new student or lecturer profile with
+// email should link to, creating it with passwordHash if there is none. An
+// identity whose profiles were all deleted takes passwordHash as well; an
+// active identity keeps its password, which the new profile then signs in
+// with. The identity stays locked until the transaction ends, so concurrent
+// registrations for one email are applied one at a time. It returns
+// ErrAccountExists if another registration created the identity first. It
+// must run inside a transaction.
+func LinkIdentity(tx *gorm.DB, email, passwordHash string) (*entities.Identity, error) {
+	var identity entities.Identity
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("email = ?", email).First(&identity).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		identity = entities.Identity{Email: email, Password: passwordHash}
+		res := tx.Clauses(identityEmailConflict).Create(&identity)
+		if res.Error != nil {
+			return nil, errors.New("failed to create identity: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return nil, ErrAccountExists
+		}
+		return &identity, nil
+	}
+	if err != nil {
+		return nil, errors.New("failed to retrieve identity: " + err.Error())
+	}
+
+	active, err := identityActive(tx, identity.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !active {
+		if err := tx.Model(&identity).Update("password", passwordHash).Error; err != nil {
+			return nil, errors.New("failed to update identity: " + err.Error())
+		}
+	}
+	return &identity, nil
+}
+
+// linkNewProfile returns the identity a new profile of role with email should
+// link to, as LinkIdentity does. It returns ErrAccountExists if the identity
+// already has a profile of role that is not deleted. It must run inside a
+// transaction.
+func linkNewProfile(tx *gorm.DB, role, email, passwordHash string) (*entities.Identity, error) {
+	model, ok := accountModel(role)
+	if !ok {
+		return nil, errors.New("unknown role " + role)
+	}
+
+	identity, err := LinkIdentity(tx, email, passwordHash)
+	if err != nil {
+		return nil, err
+	}
+
+	var count int64
+	if err := tx.Model(model).Where("identity_id = ?", identity.ID).Count(&count).Error; err != nil {
+		return nil, errors.New("failed to check profiles: " + err.Error())
+	}
+	if count > 0 {
+		return nil, ErrAccountExists
+	}
+	return identity, nil
+}
+
+// UpdateCredentials applies email and password changes to the identity of a
+// profile, which every profile linked to it signs in with. It must run inside
+// a transaction.
+func UpdateCredentials(tx *gorm.DB, role string, userID int, credentials map[string]interface{}) error {
+	identityID, err := identityOf(tx, role, userID)
+	if err != nil {
+		return err
+	}
+	if err := tx.Model(&entities.Identity{}).Where("id = ?", identityID).Updates(credentials).Error; err != nil {
+		return errors.New("failed to update identity: " + err.Error())
+	}
+	return nil
+}
+
+// LinkedAccounts returns the profile itself and every other profile sharing
+// its identity.
+func LinkedAccounts(tx *gorm.DB, role string, userID int) ([]auth.Account, error) {
+	identityID, err := identityOf(tx.Unscoped(), role, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var accounts []auth.Account
+	err = tx.Raw(`
+		SELECT s.id, i.email, 'student' AS role FROM students s JOIN identities i ON i.id = s.identity_id WHERE s.identity_id = ?
+		UNION ALL
+		SELECT l.id, i.email, 'lecturer' AS role FROM lecturers l JOIN identities i ON i.id = l.identity_id WHERE l.identity_id = ?`,
+		identityID, identityID).Scan(&accounts).Error
+	if err != nil {
+		return nil, errors.New("failed to retrieve linked profiles: " + err.Error())
+	}
+	return accounts, nil
+}
+
+// EmailInUse reports whether email belongs to an identity other than the given
+// profile's. Deleted identities no longer hold their address.
+func EmailInUse(tx *gorm.DB, email, role string, userID int) (bool, error) {
+	identityID, err := identityOf(tx.Unscoped(), role, userID)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = tx.Model(&entities.Identity{}).Where("email = ? AND id <> ?", email, identityID).Count(&count).Error
+	if err != nil {
+		return false, errors.New("failed to check email: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// ReleaseIdentity deletes the identity of a deleted profile once no other
+// profile signs in with it, so its email can be used again. It must run
+// inside a transaction.
+func ReleaseIdentity(tx *gorm.DB, role string, userID int) error {
+	identityID, err := identityOf(tx.Unscoped(), role, userID)
+	if err != nil {
+		return err
+	}
+	active, err := identityActive(tx, identityID)
+	if err != nil || active {
+		return err
+	}
+	if err := tx.Delete(&entities.Identity{}, identityID).Error; err != nil {
+		return errors.New("failed to delete identity: " + err.Error())
+	}
+	return nil
+}
+
+// identityOf returns the identity ID a profile signs in with.
+func identityOf(tx *gorm.DB, role string, userID int) (uint, error) {
+	model, ok := accountModel(role)
+	if !ok {
+		return 0, errors.New("unknown role " + role)
+	}
+
+	var profile struct{ IdentityID uint }
+	if err := tx.Model(model).Select("identity_id").Where("id = ?", userID).Take(&profile).Error; err != nil {
+		return 0, errors.New("failed to retrieve profile: " + err.Error())
+	}
+	return profile.IdentityID, nil
+}
+
+// identityActive reports whether an identity still has a profile that is not deleted.
+func identityActive(tx *gorm.DB, identityID uint) (bool, error) {
+	var count int64
+	err := tx.Raw(`
+		SELECT
+			(SELECT COUNT(*) FROM students WHERE identity_id = ? AND deleted_at IS NULL) +
+			(SELECT COUNT(*) FROM lecturers WHERE identity_id = ? AND deleted_at IS NULL)`,
+		identityID, identityID).Scan(&count).Error
+	if err != nil {
+		return false, errors.New("failed to check identity: " + err.Error())
+	}
+	return count > 0, nil
+}
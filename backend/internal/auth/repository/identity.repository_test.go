@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// dryRunDB returns a Postgres handle that builds statements without running
+// them, so tests can inspect the SQL a repository function would send.
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+	return db
+}
+
+// statementSQL returns the statement's SQL with runs of whitespace collapsed.
+func statementSQL(db *gorm.DB) string {
+	return strings.Join(strings.Fields(db.Statement.SQL.String()), " ")
+}
+
+func TestIdentityEmailConflict(t *testing.T) {
+	identity := entities.Identity{Email: "ada@uni.edu", Password: "hash"}
+	sql := statementSQL(dryRunDB(t).Clauses(identityEmailConflict).Create(&identity))
+
+	// The conflict target must name the partial index's column and predicate,
+	// or Postgres cannot match it to idx_identities_email_active
+	want := `ON CONFLICT ("email") WHERE deleted_at IS NULL DO NOTHING`
+	if !strings.Contains(sql, want) {
+		t.Errorf("insert = %s, want it to contain %s", sql, want)
+	}
+}
+
+func TestLinkIdentityLocksExistingIdentity(t *testing.T) {
+	db := dryRunDB(t)
+	var statements []string
+	record := func(tx *gorm.DB) { statements = append(statements, statementSQL(tx)) }
+	if err := db.Callback().Query().After("gorm:query").Register("test:record", record); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// A dry run cannot scan the profile count that follows, so only the
+	// lookup is checked
+	_, _ = LinkIdentity(db.Session(&gorm.Session{Logger: db.Logger.LogMode(gormlogger.Silent)}), "ada@uni.edu", "hash")
+
+	// Without the lock two registrations for one email could both find no
+	// profile of their role and both insert one
+	if len(statements) == 0 || !strings.HasSuffix(statements[0], "FOR UPDATE") ||
+		!strings.Contains(statements[0], `"identities"."deleted_at" IS NULL`) {
+		t.Errorf("statements = %q, want the active identity locked FOR UPDATE first", statements)
+	}
+}
@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"github.com/Dom-HTG/attendance-management-system/pkg/lockout"
+	"gorm.io/gorm"
+)
+
+// LoginAttemptRepo is a lockout.Store backed by Postgres, so failed login
+// counts are shared by every replica.
+type LoginAttemptRepo struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepo returns a new instance of LoginAttemptRepo.
+func NewLoginAttemptRepo(db *gorm.DB) *LoginAttemptRepo {
+	return &LoginAttemptRepo{
+		db: db,
+	}
+}
+
+// Get returns the state of key, or a zero state if it has no failures.
+func (lr *LoginAttemptRepo) Get(ctx context.Context, key string) (lockout.State, error) {
+	var attempt entities.LoginAttempt
+	if err := lr.db.WithContext(ctx).Where("attempt_key = ?", key).First(&attempt).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return lockout.State{}, nil
+		}
+		return lockout.State{}, errors.New("failed to retrieve login attempts: " + err.Error())
+	}
+	return toLockoutState(&attempt), nil
+}
+
+// Fail records a failure at now in a single statement, restarting the count
+// when the previous failure and any lock both ended before since, and returns
+// the new state.
+func (lr *LoginAttemptRepo) Fail(ctx context.Context, key string, now, since time.Time) (lockout.State, error) {
+	var attempt entities.LoginAttempt
+	err := lr.db.WithContext(ctx).Raw(`
+		INSERT INTO login_attempts (attempt_key, failures, last_failure_at, updated_at)
+		VALUES (?, 1, ?, ?)
+		ON CONFLICT (attempt_key) DO UPDATE SET
+			failures = CASE WHEN GREATEST(login_attempts.last_failure_at, login_attempts.locked_until) < ? THEN 1 ELSE login_attempts.failures + 1 END,
+			last_failure_at = EXCLUDED.last_failure_at,
+			updated_at = EXCLUDED.updated_at
+		RETURNING attempt_key, failures, last_failure_at, locked_until, updated_at`,
+		key, now, now, since).Scan(&attempt).Error
+	if err != nil {
+		return lockout.State{}, errors.New("failed to record login attempt: " + err.Error())
+	}
+	return toLockoutState(&attempt), nil
+}
+
+// Lock locks key until the given time. An existing later lock is kept.
+func (lr *LoginAttemptRepo) Lock(ctx context.Context, key string, until time.Time) error {
+	if err := lr.db.WithContext(ctx).Model(&entities.LoginAttempt{}).
+		Where("attempt_key = ? AND (locked_until IS NULL OR locked_until < ?)", key, until).
+		Update("locked_until", until).Error; err != nil {
+		return errors.New("failed to lock login: " + err.Error())
+	}
+	return nil
+}
+
+// Reset deletes every failure recorded for key.
+func (lr *LoginAttemptRepo) Reset(ctx context.Context, key string) error {
+	if err := lr.db.WithContext(ctx).Where("attempt_key = ?", key).Delete(&entities.LoginAttempt{}).Error; err != nil {
+		return errors.New("failed to reset login attempts: " + err.Error())
+	}
+	return nil
+}
+
+// Purge deletes keys whose last failure and lock are both before the given
+// time and returns how many were deleted.
+func (lr *LoginAttemptRepo) Purge(ctx context.Context, before time.Time) (int, error) {
+	res := lr.db.WithContext(ctx).
+		Where("last_failure_at < ? AND (locked_until IS NULL OR locked_until < ?)", before, before).
+		Delete(&entities.LoginAttempt{})
+	if res.Error != nil {
+		return 0, errors.New("failed to purge login attempts: " + res.Error.Error())
+	}
+	return int(res.RowsAffected), nil
+}
+
+// toLockoutState maps a stored attempt to a lockout state.
+func toLockoutState(attempt *entities.LoginAttempt) lockout.State {
+	state := lockout.State{
+		Failures:    attempt.Failures,
+		LastFailure: attempt.LastFailureAt,
+	}
+	if attempt.LockedUntil != nil {
+		state.LockedUntil = *attempt.LockedUntil
+	}
+	return state
+}
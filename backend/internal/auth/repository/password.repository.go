@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPasswordResetTokenNotFound is returned when no reset token matches a hash.
+	ErrPasswordResetTokenNotFound = errors.New("password reset token not found")
+	// ErrPasswordResetTokenInvalid is returned when a reset token was already
+	// used, superseded or has expired.
+	ErrPasswordResetTokenInvalid = errors.New("password reset token is invalid or has expired")
+)
+
+// RevokeReasonPasswordReset is recorded on sessions ended by a password reset.
+const RevokeReasonPasswordReset = "password_reset"
+
+// CreatePasswordResetToken stores a new reset token and invalidates any earlier
+// unused tokens for the same account, so only the latest email works.
+func (tr *TokenRepo) CreatePasswordResetToken(token *entities.PasswordResetToken) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.PasswordResetToken{}).
+			Where("role = ? AND user_id = ? AND used_at IS NULL", token.Role, token.UserID).
+			Update("used_at", time.Now()).Error; err != nil {
+			return errors.New("failed to invalidate password reset tokens: " + err.Error())
+		}
+		if err := tx.Create(token).Error; err != nil {
+			return errors.New("failed to create password reset token: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// GetPasswordResetTokenByHash retrieves a reset token by the hash of its value.
+func (tr *TokenRepo) GetPasswordResetTokenByHash(tokenHash string) (*entities.PasswordResetToken, error) {
+	var token entities.PasswordResetToken
+	if err := tr.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPasswordResetTokenNotFound
+		}
+		return nil, errors.New("failed to retrieve password reset token: " + err.Error())
+	}
+	return &token, nil
+}
+
+// ResetPassword redeems a reset token, stores the new password hash and revokes
+// every session the account has, all in one transaction. Profiles sharing the
+// account's identity get the new password and lose their sessions too. The token is only
+// redeemed if it is still unused and unexpired, so it cannot be used twice;
+// otherwise ErrPasswordResetTokenInvalid is returned and nothing changes.
+// It returns the number of sessions revoked.
+func (tr *TokenRepo) ResetPassword(token *entities.PasswordResetToken, passwordHash string) (int, error) {
+	var model interface{}
+	switch token.Role {
+	case "student":
+		model = &entities.Student{}
+	case "lecturer":
+		model = &entities.Lecturer{}
+	default:
+		return 0, ErrPasswordResetTokenInvalid
+	}
+
+	var families int64
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		res := tx.Model(&entities.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if res.Error != nil {
+			return errors.New("failed to redeem password reset token: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return ErrPasswordResetTokenInvalid
+		}
+
+		// The account must still exist; its password lives on its identity
+		var profile struct{ IdentityID uint }
+		found := tx.Model(model).Select("identity_id").Where("id = ?", token.UserID).Limit(1).Find(&profile)
+		if found.Error != nil {
+			return errors.New("failed to retrieve account: " + found.Error.Error())
+		}
+		if found.RowsAffected == 0 {
+			return ErrPasswordResetTokenInvalid
+		}
+		if err := tx.Model(&entities.Identity{}).Where("id = ?", profile.IdentityID).Update("password", passwordHash).Error; err != nil {
+			return errors.New("failed to update password: " + err.Error())
+		}
+
+		// The password is shared by every role on the identity, so end all their sessions
+		accounts, err := LinkedAccounts(tx, token.Role, token.UserID)
+		if err != nil {
+			return err
+		}
+		for _, account := range accounts {
+			var count int64
+			if err := tx.Model(&entities.RefreshToken{}).
+				Where("role = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", account.Role, account.ID, now).
+				Distinct("family_id").
+				Count(&count).Error; err != nil {
+				return errors.New("failed to count sessions: " + err.Error())
+			}
+			families += count
+			if err := revokeWhere(tx, RevokeReasonPasswordReset, "role = ? AND user_id = ?", account.Role, account.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(families), nil
+}
@@ -0,0 +1,132 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+)
+
+// ErrEmailTaken is returned when an email address already belongs to another
+// account outside the user's own identity.
+var ErrEmailTaken = errors.New("email address is already in use")
+
+// profileColumns are the unique columns IsTaken may check, by role.
+var profileColumns = map[string]map[string]bool{
+	"student":  {"email": true, "matric_number": true},
+	"lecturer": {"email": true, "staff_id": true},
+}
+
+// GetStudentByID retrieves a student by ID, with its identity.
+func (ar *AuthRepo) GetStudentByID(studentID int) (*entities.Student, error) {
+	var student entities.Student
+	tx := ar.DB.Joins("Identity").First(&student, studentID)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &student, nil
+}
+
+// GetLecturerByID retrieves a lecturer by ID, with its identity.
+func (ar *AuthRepo) GetLecturerByID(lecturerID int) (*entities.Lecturer, error) {
+	var lecturer entities.Lecturer
+	tx := ar.DB.Joins("Identity").First(&lecturer, lecturerID)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &lecturer, nil
+}
+
+// GetAdminByID retrieves an admin by ID.
+func (ar *AuthRepo) GetAdminByID(adminID int) (*entities.Admin, error) {
+	var admin entities.Admin
+	tx := ar.DB.First(&admin, adminID)
+	if tx.Error != nil {
+		return nil, tx.Error
+	}
+	return &admin, nil
+}
+
+// UpdateStudent applies column updates to a student. Email and password changes
+// are made on its identity, so every profile sharing it signs in with them.
+func (ar *AuthRepo) UpdateStudent(studentID int, updates map[string]interface{}) error {
+	return ar.DB.Transaction(func(tx *gorm.DB) error {
+		profile, credentials := splitCredentials(updates)
+		if len(profile) > 0 {
+			if err := tx.Model(&entities.Student{}).Where("id = ?", studentID).Updates(profile).Error; err != nil {
+				return errors.New("failed to update student: " + err.Error())
+			}
+		}
+		if len(credentials) == 0 {
+			return nil
+		}
+		return UpdateCredentials(tx, "student", studentID, credentials)
+	})
+}
+
+// UpdateLecturer applies column updates to a lecturer. Email and password changes
+// are made on its identity, so every profile sharing it signs in with them.
+func (ar *AuthRepo) UpdateLecturer(lecturerID int, updates map[string]interface{}) error {
+	return ar.DB.Transaction(func(tx *gorm.DB) error {
+		profile, credentials := splitCredentials(updates)
+		if len(profile) > 0 {
+			if err := tx.Model(&entities.Lecturer{}).Where("id = ?", lecturerID).Updates(profile).Error; err != nil {
+				return errors.New("failed to update lecturer: " + err.Error())
+			}
+		}
+		if len(credentials) == 0 {
+			return nil
+		}
+		return UpdateCredentials(tx, "lecturer", lecturerID, credentials)
+	})
+}
+
+// IsTaken reports whether another account of role already uses value in one of
+// its unique columns (email, matric_number or staff_id). An email is taken if
+// anyone outside the account's own identity uses it, in either role. Deleted
+// accounts no longer hold their values.
+func (ar *AuthRepo) IsTaken(role, column, value string, exceptID int) (bool, error) {
+	model, ok := accountModel(role)
+	if !ok {
+		return false, errors.New("unknown role " + role)
+	}
+	if !profileColumns[role][column] {
+		return false, errors.New("cannot check column " + column)
+	}
+	if column == "email" {
+		return EmailInUse(ar.DB, value, role, exceptID)
+	}
+
+	var count int64
+	if err := ar.DB.Model(model).Where(column+" = ? AND id <> ?", value, exceptID).Count(&count).Error; err != nil {
+		return false, errors.New("failed to check " + column + ": " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// splitCredentials separates the email and password, which live on the
+// identity, from the profile columns in updates.
+func splitCredentials(updates map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	profile := map[string]interface{}{}
+	credentials := map[string]interface{}{}
+	for column, value := range updates {
+		if column == "email" || column == "password" {
+			credentials[column] = value
+		} else {
+			profile[column] = value
+		}
+	}
+	return profile, credentials
+}
+
+// accountModel returns the entity model for an account role.
+func accountModel(role string) (interface{}, bool) {
+	switch role {
+	case "student":
+		return &entities.Student{}, true
+	case "lecturer":
+		return &entities.Lecturer{}, true
+	default:
+		return nil, false
+	}
+}
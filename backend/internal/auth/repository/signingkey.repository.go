@@ -0,0 +1,71 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"github.com/Dom-HTG/attendance-management-system/pkg/signing"
+	"gorm.io/gorm"
+)
+
+// SigningKeyRepo is a signing.Store backed by Postgres, so every replica signs
+// and verifies with the same generated keys.
+type SigningKeyRepo struct {
+	db *gorm.DB
+}
+
+// NewSigningKeyRepo returns a new instance of SigningKeyRepo.
+func NewSigningKeyRepo(db *gorm.DB) *SigningKeyRepo {
+	return &SigningKeyRepo{
+		db: db,
+	}
+}
+
+// ListKeys returns every stored key, oldest first.
+func (sr *SigningKeyRepo) ListKeys(ctx context.Context) ([]signing.StoredKey, error) {
+	var rows []entities.SigningKey
+	if err := sr.db.WithContext(ctx).Order("created_at ASC").Find(&rows).Error; err != nil {
+		return nil, errors.New("failed to retrieve signing keys: " + err.Error())
+	}
+
+	keys := make([]signing.StoredKey, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, signing.StoredKey{
+			ID:         row.Kid,
+			Algorithm:  row.Algorithm,
+			PrivateKey: row.PrivateKey,
+			PublicKey:  row.PublicKey,
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return keys, nil
+}
+
+// CreateKey stores a new key.
+func (sr *SigningKeyRepo) CreateKey(ctx context.Context, key signing.StoredKey) error {
+	row := &entities.SigningKey{
+		Kid:        key.ID,
+		Algorithm:  key.Algorithm,
+		PrivateKey: key.PrivateKey,
+		PublicKey:  key.PublicKey,
+		CreatedAt:  key.CreatedAt,
+	}
+	if err := sr.db.WithContext(ctx).Create(row).Error; err != nil {
+		return errors.New("failed to store signing key: " + err.Error())
+	}
+	return nil
+}
+
+// PurgeKeys deletes keys that a newer key replaced before the given time and
+// returns how many were deleted.
+func (sr *SigningKeyRepo) PurgeKeys(ctx context.Context, before time.Time) (int, error) {
+	res := sr.db.WithContext(ctx).
+		Where("created_at < (SELECT MAX(created_at) FROM signing_keys WHERE created_at < ?)", before).
+		Delete(&entities.SigningKey{})
+	if res.Error != nil {
+		return 0, errors.New("failed to purge signing keys: " + res.Error.Error())
+	}
+	return int(res.RowsAffected), nil
+}
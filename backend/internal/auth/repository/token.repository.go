@@ -0,0 +1,216 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRefreshTokenNotFound is returned when no refresh token matches a hash.
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+	// ErrRefreshTokenReused is returned when a refresh token that was already
+	// exchanged is presented again.
+	ErrRefreshTokenReused = errors.New("refresh token has already been used")
+)
+
+// Reasons recorded when refresh tokens are revoked.
+const (
+	RevokeReasonLogout    = "logout"
+	RevokeReasonLogoutAll = "logout_all"
+	RevokeReasonReuse     = "reuse_detected"
+	RevokeReasonSuspended = "account_suspended"
+	RevokeReasonDeleted   = "account_deleted"
+)
+
+// TokenRepoInterface defines the repository interface for login sessions,
+// access token revocation, password reset and email verification tokens.
+type TokenRepoInterface interface {
+	CreateRefreshToken(token *entities.RefreshToken) error
+	GetRefreshTokenByHash(tokenHash string) (*entities.RefreshToken, error)
+	RotateRefreshToken(current *entities.RefreshToken, next *entities.RefreshToken) error
+	RevokeFamily(familyID, reason string) error
+	RevokeFamilyByAccessJTI(jti, reason string) error
+	RevokeAllForUser(role string, userID int, reason string) (int, error)
+	RevokeAccessToken(jti, role string, userID int, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	CreatePasswordResetToken(token *entities.PasswordResetToken) error
+	GetPasswordResetTokenByHash(tokenHash string) (*entities.PasswordResetToken, error)
+	ResetPassword(token *entities.PasswordResetToken, passwordHash string) (int, error)
+	CreateEmailVerification(verification *entities.EmailVerification) error
+	GetEmailVerificationByHash(tokenHash string) (*entities.EmailVerification, error)
+	EmailVerificationsSince(role string, userID int, purpose string, since time.Time) ([]entities.EmailVerification, error)
+	ConfirmEmailVerification(verification *entities.EmailVerification) error
+	PurgeExpired(now time.Time) (int, error)
+}
+
+// TokenRepo implements the TokenRepoInterface.
+type TokenRepo struct {
+	db *gorm.DB
+}
+
+// NewTokenRepo returns a new instance of TokenRepo.
+func NewTokenRepo(db *gorm.DB) *TokenRepo {
+	return &TokenRepo{
+		db: db,
+	}
+}
+
+// CreateRefreshToken stores a newly issued refresh token.
+func (tr *TokenRepo) CreateRefreshToken(token *entities.RefreshToken) error {
+	if err := tr.db.Create(token).Error; err != nil {
+		return errors.New("failed to create refresh token: " + err.Error())
+	}
+	return nil
+}
+
+// GetRefreshTokenByHash retrieves a refresh token by the hash of its value.
+func (tr *TokenRepo) GetRefreshTokenByHash(tokenHash string) (*entities.RefreshToken, error) {
+	var token entities.RefreshToken
+	if err := tr.db.Where("token_hash = ?", tokenHash).First(&token).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRefreshTokenNotFound
+		}
+		return nil, errors.New("failed to retrieve refresh token: " + err.Error())
+	}
+	return &token, nil
+}
+
+// RotateRefreshToken marks current as used and stores next in one transaction.
+// The update only succeeds if current is still unused and unrevoked, so two
+// concurrent refreshes with the same token cannot both win; the loser gets
+// ErrRefreshTokenReused.
+func (tr *TokenRepo) RotateRefreshToken(current *entities.RefreshToken, next *entities.RefreshToken) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&entities.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL AND revoked_at IS NULL", current.ID).
+			Update("used_at", time.Now())
+		if res.Error != nil {
+			return errors.New("failed to rotate refresh token: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		if err := tx.Create(next).Error; err != nil {
+			return errors.New("failed to create refresh token: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// RevokeFamily revokes every refresh token in a family and denylists the
+// access tokens issued with them that have not yet expired.
+func (tr *TokenRepo) RevokeFamily(familyID, reason string) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		return revokeWhere(tx, reason, "family_id = ?", familyID)
+	})
+}
+
+// RevokeFamilyByAccessJTI revokes the family that issued the given access token.
+// It is a no-op when the access token did not come from a refresh token.
+func (tr *TokenRepo) RevokeFamilyByAccessJTI(jti, reason string) error {
+	var token entities.RefreshToken
+	err := tr.db.Select("family_id").Where("access_jti = ?", jti).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return errors.New("failed to retrieve session: " + err.Error())
+	}
+	return tr.RevokeFamily(token.FamilyID, reason)
+}
+
+// RevokeAllForUser revokes every active session belonging to a user and
+// returns how many sessions were revoked.
+func (tr *TokenRepo) RevokeAllForUser(role string, userID int, reason string) (int, error) {
+	var families int64
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.RefreshToken{}).
+			Where("role = ? AND user_id = ? AND revoked_at IS NULL AND expires_at > ?", role, userID, time.Now()).
+			Distinct("family_id").
+			Count(&families).Error; err != nil {
+			return errors.New("failed to count sessions: " + err.Error())
+		}
+		return revokeWhere(tx, reason, "role = ? AND user_id = ?", role, userID)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int(families), nil
+}
+
+// RevokeAccessToken adds an access token to the denylist until it expires.
+func (tr *TokenRepo) RevokeAccessToken(jti, role string, userID int, expiresAt time.Time) error {
+	revoked := &entities.RevokedAccessToken{JTI: jti, UserID: userID, Role: role, ExpiresAt: expiresAt}
+	if err := tr.db.Clauses(clause.OnConflict{DoNothing: true}).Create(revoked).Error; err != nil {
+		return errors.New("failed to revoke access token: " + err.Error())
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token is on the denylist.
+func (tr *TokenRepo) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	if err := tr.db.Model(&entities.RevokedAccessToken{}).Where("jti = ?", jti).Count(&count).Error; err != nil {
+		return false, errors.New("failed to check token revocation: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// PurgeExpired permanently deletes refresh tokens, denylist entries, password
+// reset and email verification tokens that expired before now and returns the
+// number of rows removed.
+func (tr *TokenRepo) PurgeExpired(now time.Time) (int, error) {
+	refresh := tr.db.Unscoped().Where("expires_at < ?", now).Delete(&entities.RefreshToken{})
+	if refresh.Error != nil {
+		return 0, errors.New("failed to purge refresh tokens: " + refresh.Error.Error())
+	}
+	denied := tr.db.Unscoped().Where("expires_at < ?", now).Delete(&entities.RevokedAccessToken{})
+	if denied.Error != nil {
+		return 0, errors.New("failed to purge revoked access tokens: " + denied.Error.Error())
+	}
+	resets := tr.db.Unscoped().Where("expires_at < ?", now).Delete(&entities.PasswordResetToken{})
+	if resets.Error != nil {
+		return 0, errors.New("failed to purge password reset tokens: " + resets.Error.Error())
+	}
+	verifications := tr.db.Unscoped().Where("expires_at < ?", now).Delete(&entities.EmailVerification{})
+	if verifications.Error != nil {
+		return 0, errors.New("failed to purge email verifications: " + verifications.Error.Error())
+	}
+	return int(refresh.RowsAffected + denied.RowsAffected + resets.RowsAffected + verifications.RowsAffected), nil
+}
+
+// revokeWhere revokes the refresh tokens matched by the condition and denylists
+// their unexpired access tokens. It must run inside a transaction.
+func revokeWhere(tx *gorm.DB, reason string, condition string, args ...interface{}) error {
+	now := time.Now()
+
+	var tokens []entities.RefreshToken
+	if err := tx.Where(condition, args...).
+		Where("access_jti <> '' AND access_expires_at > ?", now).
+		Find(&tokens).Error; err != nil {
+		return errors.New("failed to retrieve sessions: " + err.Error())
+	}
+
+	if len(tokens) > 0 {
+		denied := make([]entities.RevokedAccessToken, 0, len(tokens))
+		for _, t := range tokens {
+			denied = append(denied, entities.RevokedAccessToken{JTI: t.AccessJTI, UserID: t.UserID, Role: t.Role, ExpiresAt: t.AccessExpiresAt})
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&denied).Error; err != nil {
+			return errors.New("failed to revoke access tokens: " + err.Error())
+		}
+	}
+
+	if err := tx.Model(&entities.RefreshToken{}).
+		Where(condition, args...).
+		Where("revoked_at IS NULL").
+		Updates(map[string]interface{}{"revoked_at": now, "revoked_reason": reason}).Error; err != nil {
+		return errors.New("failed to revoke refresh tokens: " + err.Error())
+	}
+	return nil
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTwoFactorNotFound is returned when a user has no TOTP enrollment.
+	ErrTwoFactorNotFound = errors.New("two-factor authentication is not set up")
+	// ErrTwoFactorEnabled is returned when enabling an enrollment that is
+	// already active.
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTOTPCodeReused is returned when a TOTP code's time step was already used.
+	ErrTOTPCodeReused = errors.New("code has already been used")
+	// ErrRecoveryCodeInvalid is returned when a recovery code does not match an
+	// unused code of the user.
+	ErrRecoveryCodeInvalid = errors.New("invalid recovery code")
+)
+
+// TwoFactorRepoInterface defines the repository interface for TOTP enrollments
+// and recovery codes.
+type TwoFactorRepoInterface interface {
+	GetTwoFactor(role string, userID int) (*entities.TwoFactor, error)
+	SavePendingTwoFactor(twoFactor *entities.TwoFactor) error
+	EnableTwoFactor(twoFactor *entities.TwoFactor, step int64, codeHashes []string) error
+	UseTOTPStep(twoFactorID uint, step int64) error
+	UseRecoveryCode(role string, userID int, codeHash string) error
+	ReplaceRecoveryCodes(role string, userID int, codeHashes []string) error
+	CountUnusedRecoveryCodes(role string, userID int) (int, error)
+	DeleteTwoFactor(role string, userID int) error
+}
+
+// TwoFactorRepo implements the TwoFactorRepoInterface.
+type TwoFactorRepo struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepo returns a new instance of TwoFactorRepo.
+func NewTwoFactorRepo(db *gorm.DB) *TwoFactorRepo {
+	return &TwoFactorRepo{
+		db: db,
+	}
+}
+
+// GetTwoFactor retrieves a user's enrollment, pending or enabled.
+func (tr *TwoFactorRepo) GetTwoFactor(role string, userID int) (*entities.TwoFactor, error) {
+	var twoFactor entities.TwoFactor
+	if err := tr.db.Where("role = ? AND user_id = ?", role, userID).First(&twoFactor).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTwoFactorNotFound
+		}
+		return nil, errors.New("failed to retrieve two-factor settings: " + err.Error())
+	}
+	return &twoFactor, nil
+}
+
+// SavePendingTwoFactor stores a new, not yet enabled enrollment, replacing any
+// earlier pending one. It returns ErrTwoFactorEnabled if the user already has
+// an enabled enrollment.
+func (tr *TwoFactorRepo) SavePendingTwoFactor(twoFactor *entities.TwoFactor) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		var enabled int64
+		if err := tx.Model(&entities.TwoFactor{}).
+			Where("role = ? AND user_id = ? AND enabled_at IS NOT NULL", twoFactor.Role, twoFactor.UserID).
+			Count(&enabled).Error; err != nil {
+			return errors.New("failed to check two-factor settings: " + err.Error())
+		}
+		if enabled > 0 {
+			return ErrTwoFactorEnabled
+		}
+
+		if err := tx.Unscoped().
+			Where("role = ? AND user_id = ?", twoFactor.Role, twoFactor.UserID).
+			Delete(&entities.TwoFactor{}).Error; err != nil {
+			return errors.New("failed to replace pending two-factor setup: " + err.Error())
+		}
+		if err := tx.Create(twoFactor).Error; err != nil {
+			return errors.New("failed to save two-factor setup: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// EnableTwoFactor activates a pending enrollment after its first code was
+// verified at step, and stores a fresh set of recovery codes.
+func (tr *TwoFactorRepo) EnableTwoFactor(twoFactor *entities.TwoFactor, step int64, codeHashes []string) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&entities.TwoFactor{}).
+			Where("id = ? AND enabled_at IS NULL", twoFactor.ID).
+			Updates(map[string]interface{}{"enabled_at": now, "last_used_step": step})
+		if res.Error != nil {
+			return errors.New("failed to enable two-factor authentication: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return ErrTwoFactorEnabled
+		}
+		twoFactor.EnabledAt = &now
+		twoFactor.LastUsedStep = step
+
+		return replaceRecoveryCodes(tx, twoFactor.Role, twoFactor.UserID, codeHashes)
+	})
+}
+
+// UseTOTPStep records that a code for step was accepted. It returns
+// ErrTOTPCodeReused if that step or a later one was already used, so each code
+// works only once even when two requests race.
+func (tr *TwoFactorRepo) UseTOTPStep(twoFactorID uint, step int64) error {
+	res := tr.db.Model(&entities.TwoFactor{}).
+		Where("id = ? AND last_used_step < ?", twoFactorID, step).
+		Update("last_used_step", step)
+	if res.Error != nil {
+		return errors.New("failed to record two-factor code: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return ErrTOTPCodeReused
+	}
+	return nil
+}
+
+// UseRecoveryCode marks one of the user's unused recovery codes as used. It
+// returns ErrRecoveryCodeInvalid if no unused code has the given hash.
+func (tr *TwoFactorRepo) UseRecoveryCode(role string, userID int, codeHash string) error {
+	res := tr.db.Model(&entities.RecoveryCode{}).
+		Where("role = ? AND user_id = ? AND code_hash = ? AND used_at IS NULL", role, userID, codeHash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return errors.New("failed to use recovery code: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return ErrRecoveryCodeInvalid
+	}
+	return nil
+}
+
+// ReplaceRecoveryCodes deletes the user's recovery codes and stores new ones.
+func (tr *TwoFactorRepo) ReplaceRecoveryCodes(role string, userID int, codeHashes []string) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		return replaceRecoveryCodes(tx, role, userID, codeHashes)
+	})
+}
+
+// CountUnusedRecoveryCodes returns how many recovery codes the user has left.
+func (tr *TwoFactorRepo) CountUnusedRecoveryCodes(role string, userID int) (int, error) {
+	var count int64
+	if err := tr.db.Model(&entities.RecoveryCode{}).
+		Where("role = ? AND user_id = ? AND used_at IS NULL", role, userID).
+		Count(&count).Error; err != nil {
+		return 0, errors.New("failed to count recovery codes: " + err.Error())
+	}
+	return int(count), nil
+}
+
+// DeleteTwoFactor permanently removes the user's enrollment and recovery codes.
+// It returns ErrTwoFactorNotFound if the user had no enrollment.
+func (tr *TwoFactorRepo) DeleteTwoFactor(role string, userID int) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Unscoped().Where("role = ? AND user_id = ?", role, userID).Delete(&entities.TwoFactor{})
+		if res.Error != nil {
+			return errors.New("failed to delete two-factor settings: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return ErrTwoFactorNotFound
+		}
+		if err := tx.Unscoped().Where("role = ? AND user_id = ?", role, userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+			return errors.New("failed to delete recovery codes: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// replaceRecoveryCodes swaps the user's recovery codes. It must run inside a transaction.
+func replaceRecoveryCodes(tx *gorm.DB, role string, userID int, codeHashes []string) error {
+	if err := tx.Unscoped().Where("role = ? AND user_id = ?", role, userID).Delete(&entities.RecoveryCode{}).Error; err != nil {
+		return errors.New("failed to delete recovery codes: " + err.Error())
+	}
+
+	codes := make([]entities.RecoveryCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, entities.RecoveryCode{UserID: userID, Role: role, CodeHash: hash})
+	}
+	if len(codes) > 0 {
+		if err := tx.Create(&codes).Error; err != nil {
+			return errors.New("failed to store recovery codes: " + err.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrEmailVerificationNotFound is returned when no verification token matches a hash.
+	ErrEmailVerificationNotFound = errors.New("email verification token not found")
+	// ErrEmailVerificationInvalid is returned when a verification token was
+	// already used, superseded or has expired.
+	ErrEmailVerificationInvalid = errors.New("email verification token is invalid or has expired")
+)
+
+// CreateEmailVerification stores a new verification token and invalidates any
+// earlier unused tokens for the same account and purpose, so only the latest
+// email works.
+func (tr *TokenRepo) CreateEmailVerification(verification *entities.EmailVerification) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.EmailVerification{}).
+			Where("role = ? AND user_id = ? AND purpose = ? AND used_at IS NULL", verification.Role, verification.UserID, verification.Purpose).
+			Update("used_at", time.Now()).Error; err != nil {
+			return errors.New("failed to invalidate email verifications: " + err.Error())
+		}
+		if err := tx.Create(verification).Error; err != nil {
+			return errors.New("failed to create email verification: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// GetEmailVerificationByHash retrieves a verification token by the hash of its value.
+func (tr *TokenRepo) GetEmailVerificationByHash(tokenHash string) (*entities.EmailVerification, error) {
+	var verification entities.EmailVerification
+	if err := tr.db.Where("token_hash = ?", tokenHash).First(&verification).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEmailVerificationNotFound
+		}
+		return nil, errors.New("failed to retrieve email verification: " + err.Error())
+	}
+	return &verification, nil
+}
+
+// EmailVerificationsSince returns the verification tokens issued to an account
+// for purpose since the given time, newest first. Callers use it to throttle
+// resends.
+func (tr *TokenRepo) EmailVerificationsSince(role string, userID int, purpose string, since time.Time) ([]entities.EmailVerification, error) {
+	var verifications []entities.EmailVerification
+	if err := tr.db.
+		Where("role = ? AND user_id = ? AND purpose = ? AND created_at >= ?", role, userID, purpose, since).
+		Order("created_at DESC").
+		Find(&verifications).Error; err != nil {
+		return nil, errors.New("failed to retrieve email verifications: " + err.Error())
+	}
+	return verifications, nil
+}
+
+// ConfirmEmailVerification redeems a verification token in one transaction.
+// A registration token marks the account's current email as verified; an email
+// change token replaces the account's email with the verified address. The
+// token is only redeemed if it is still unused and unexpired; otherwise
+// ErrEmailVerificationInvalid is returned and nothing changes. ErrEmailTaken is
+// returned if another account claimed a new address in the meantime. A new
+// address is set on the account's identity, so every profile sharing it
+// signs in with it.
+func (tr *TokenRepo) ConfirmEmailVerification(verification *entities.EmailVerification) error {
+	model, ok := accountModel(verification.Role)
+	if !ok {
+		return ErrEmailVerificationInvalid
+	}
+
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		res := tx.Model(&entities.EmailVerification{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", verification.ID, now).
+			Update("used_at", now)
+		if res.Error != nil {
+			return errors.New("failed to redeem email verification: " + res.Error.Error())
+		}
+		if res.RowsAffected == 0 {
+			return ErrEmailVerificationInvalid
+		}
+
+		var updated *gorm.DB
+		switch verification.Purpose {
+		case entities.EmailVerificationRegistration:
+			// The token only verifies the address it was sent to
+			updated = tx.Model(model).
+				Where("id = ? AND identity_id IN (?)", verification.UserID,
+					tx.Model(&entities.Identity{}).Select("id").Where("email = ?", verification.Email)).
+				Update("email_verified_at", now)
+		case entities.EmailVerificationChange:
+			taken, err := EmailInUse(tx, verification.Email, verification.Role, verification.UserID)
+			if err != nil {
+				return err
+			}
+			if taken {
+				return ErrEmailTaken
+			}
+			updated = tx.Model(model).
+				Where("id = ?", verification.UserID).
+				Update("email_verified_at", now)
+			if updated.Error == nil && updated.RowsAffected > 0 {
+				// Every role on the identity signs in with the new address
+				credentials := map[string]interface{}{"email": verification.Email}
+				if err := UpdateCredentials(tx, verification.Role, verification.UserID, credentials); err != nil {
+					return err
+				}
+			}
+		default:
+			return ErrEmailVerificationInvalid
+		}
+		if updated.Error != nil {
+			return errors.New("failed to update email: " + updated.Error.Error())
+		}
+		if updated.RowsAffected == 0 {
+			return ErrEmailVerificationInvalid
+		}
+		return nil
+	})
+}
@@ -3,22 +3,50 @@ package auth
 import (
 	"errors"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Dom-HTG/attendance-management-system/entities"
 	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/lockout"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/mailer"
 	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/signing"
 	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
 type AuthSvc struct {
-	Repository auth.AuthRepoInterface
+	Repository        auth.AuthRepoInterface
+	Tokens            authRepo.TokenRepoInterface
+	Mailer            mailer.Mailer
+	Sessions          SessionConfig
+	PasswordReset     PasswordResetConfig
+	EmailVerification EmailVerificationConfig
+	Registration      RegistrationConfig
+	TwoFactor         TwoFactorConfig
+	Lockout           *lockout.Guard
+	TwoFactors        authRepo.TwoFactorRepoInterface
+	Keys              *signing.Manager
 }
 
 // constructor.
-func NewAuthSvc(repo auth.AuthRepoInterface) *AuthSvc {
+func NewAuthSvc(repo auth.AuthRepoInterface, tokens authRepo.TokenRepoInterface, mail mailer.Mailer, sessions SessionConfig, passwordReset PasswordResetConfig, emailVerification EmailVerificationConfig, registration RegistrationConfig, twoFactor TwoFactorConfig, guard *lockout.Guard, twoFactors authRepo.TwoFactorRepoInterface, keys *signing.Manager) *AuthSvc {
 	return &AuthSvc{
-		Repository: repo,
+		Repository:        repo,
+		Tokens:            tokens,
+		Mailer:            mail,
+		Sessions:          sessions,
+		PasswordReset:     passwordReset,
+		EmailVerification: emailVerification,
+		Registration:      registration,
+		TwoFactor:         twoFactor,
+		Lockout:           guard,
+		TwoFactors:        twoFactors,
+		Keys:              keys,
 	}
 }
 
@@ -30,6 +58,19 @@ func (svc *AuthSvc) RegisterStudent(ctx *gin.Context) {
 		return
 	}
 
+	registerUserData.Faculty = strings.TrimSpace(registerUserData.Faculty)
+	registerUserData.Department = strings.TrimSpace(registerUserData.Department)
+	registerUserData.Programme = strings.TrimSpace(registerUserData.Programme)
+	registerUserData.EntrySession = strings.TrimSpace(registerUserData.EntrySession)
+	if registerUserData.EntrySession != "" && !utils.ValidAcademicSession(registerUserData.EntrySession) {
+		responses.ApiFailure(ctx, "entry_session must look like 2024/2025", http.StatusBadRequest, nil)
+		return
+	}
+
+	if !svc.identityPasswordMatches(ctx, registerUserData.Email, registerUserData.Password) {
+		return
+	}
+
 	// hash password.
 	hash, er := utils.HashPassword(registerUserData.Password)
 	if er != nil {
@@ -41,13 +82,18 @@ func (svc *AuthSvc) RegisterStudent(ctx *gin.Context) {
 	registerUserData.Password = string(hash)
 
 	// Save user to database.
-	if err := svc.Repository.RegisterStudent(&registerUserData); err != nil {
+	studentID, err := svc.Repository.RegisterStudent(&registerUserData, svc.Registration.RequireEmailVerification)
+	if errors.Is(err, authRepo.ErrAccountExists) {
+		responses.ApiFailure(ctx, "Account already exists", http.StatusConflict, nil)
+		return
+	}
+	if err != nil {
 		responses.ApiFailure(ctx, "Failed to register student", http.StatusInternalServerError, err)
 		return
 	}
 
 	responses.ApiSuccess(ctx, http.StatusCreated, "Student successfully registered", map[string]string{
-		"message": "Student successfully registered. Please login with your credentials.",
+		"message": svc.registered(ctx, "Student", auth.Account{ID: studentID, Email: registerUserData.Email, Role: "student"}),
 	})
 }
 
@@ -59,6 +105,15 @@ func (svc *AuthSvc) RegisterLecturer(ctx *gin.Context) {
 		return
 	}
 
+	if !svc.lecturerEmailAllowed(registerUserData.Email) {
+		responses.ApiFailure(ctx, "Lecturers must register with an institutional email address", http.StatusBadRequest, nil)
+		return
+	}
+
+	if !svc.identityPasswordMatches(ctx, registerUserData.Email, registerUserData.Password) {
+		return
+	}
+
 	// hash password.
 	hash, er := utils.HashPassword(registerUserData.Password)
 	if er != nil {
@@ -70,16 +125,70 @@ func (svc *AuthSvc) RegisterLecturer(ctx *gin.Context) {
 	registerUserData.Password = string(hash)
 
 	// Save user to database.
-	if err := svc.Repository.RegisterLecturer(&registerUserData); err != nil {
+	lecturerID, err := svc.Repository.RegisterLecturer(&registerUserData, svc.Registration.RequireEmailVerification)
+	if errors.Is(err, authRepo.ErrAccountExists) {
+		responses.ApiFailure(ctx, "Account already exists", http.StatusConflict, nil)
+		return
+	}
+	if err != nil {
 		responses.ApiFailure(ctx, "Failed to register lecturer", http.StatusInternalServerError, err)
 		return
 	}
 
 	responses.ApiSuccess(ctx, http.StatusCreated, "Lecturer successfully registered", map[string]string{
-		"message": "Lecturer successfully registered. Please login with your credentials.",
+		"message": svc.registered(ctx, "Lecturer", auth.Account{ID: lecturerID, Email: registerUserData.Email, Role: "lecturer"}),
 	})
 }
 
+// identityPasswordMatches checks that someone registering a second role with
+// an email that already signs in gives that account's password, since both
+// roles will share it.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) identityPasswordMatches(ctx *gin.Context, email, password string) bool {
+	identity, err := svc.Repository.GetIdentityByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true
+	}
+	if err != nil {
+		responses.ApiFailure(ctx, "Database error", http.StatusInternalServerError, err)
+		return false
+	}
+	if !utils.CompareHash(password, identity.Password) {
+		responses.ApiFailure(ctx, "An account with this email already exists. Register with its password to add this role", http.StatusConflict, nil)
+		return false
+	}
+	return true
+}
+
+// registered sends the verification email for a new account when verification
+// is required and returns the message telling the user what to do next. A
+// failed email is logged rather than failing the registration; the user can
+// ask for another.
+func (svc *AuthSvc) registered(ctx *gin.Context, noun string, account auth.Account) string {
+	if !svc.Registration.RequireEmailVerification {
+		return noun + " successfully registered. Please login with your credentials."
+	}
+	if err := svc.sendEmailVerification(ctx, account, entities.EmailVerificationRegistration); err != nil {
+		logger.Errorf("failed to send verification email to %s %d: %v", account.Role, account.ID, err)
+	}
+	return noun + " successfully registered. Check your email to verify your address before logging in."
+}
+
+// Login handles POST /api/auth/login for students and lecturers. Someone who
+// holds both roles on one identity must say which to sign in as.
+func (svc *AuthSvc) Login(ctx *gin.Context) {
+	var loginData *auth.LoginDTO
+
+	if e := ctx.ShouldBindJSON(&loginData); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e)
+		return
+	}
+
+	svc.login(ctx, loginData.Email, loginData.Password, loginData.Role)
+}
+
+// LoginStudent handles POST /api/auth/login-student, kept as an alias of
+// Login with the role fixed to student.
 func (svc *AuthSvc) LoginStudent(ctx *gin.Context) {
 	var loginData *auth.LoginStudentDTO
 
@@ -88,61 +197,124 @@ func (svc *AuthSvc) LoginStudent(ctx *gin.Context) {
 		return
 	}
 
-	// Get student by email with password for comparison
-	studentEntity, err := svc.Repository.GetStudentByEmailWithPassword(loginData.Email)
+	svc.login(ctx, loginData.Email, loginData.Password, "student")
+}
+
+// LoginLecturer handles POST /api/auth/login-lecturer, kept as an alias of
+// Login with the role fixed to lecturer.
+func (svc *AuthSvc) LoginLecturer(ctx *gin.Context) {
+	var loginData *auth.LoginLecturerDTO
+
+	if e := ctx.ShouldBindJSON(&loginData); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e)
+		return
+	}
+
+	svc.login(ctx, loginData.Email, loginData.Password, "lecturer")
+}
+
+// login signs a student or lecturer in. An empty role accepts whichever
+// profile the password matches, and asks the caller to choose when it
+// matches more than one.
+func (svc *AuthSvc) login(ctx *gin.Context, email, password, role string) {
+	lockRole := role
+	if lockRole == "" {
+		lockRole = "user"
+	}
+	if !svc.loginAllowed(ctx, lockRole, email) {
+		return
+	}
+
+	// Get every profile for the email with its password for comparison
+	profiles, err := svc.Repository.GetLoginProfiles(email)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			responses.ApiFailure(ctx, "Invalid email or password", http.StatusUnauthorized, nil)
-			return
-		}
 		responses.ApiFailure(ctx, "Database error", http.StatusInternalServerError, err)
 		return
 	}
 
-	// Compare passwords
-	if !utils.CompareHash(loginData.Password, studentEntity.Password) {
+	var candidates []auth.LoginProfile
+	for _, profile := range profiles {
+		if role == "" || profile.Role == role {
+			candidates = append(candidates, profile)
+		}
+	}
+	if len(candidates) == 0 {
+		svc.loginFailed(ctx, lockRole, email, "unknown_account")
 		responses.ApiFailure(ctx, "Invalid email or password", http.StatusUnauthorized, nil)
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(int(studentEntity.ID), studentEntity.Email, "student", 60)
-	if err != nil {
-		responses.ApiFailure(ctx, "Failed to generate token", http.StatusInternalServerError, err)
+	// Every profile for the email signs in with the same identity's password
+	if !utils.CompareHash(password, candidates[0].Password) {
+		svc.loginFailed(ctx, lockRole, email, "invalid_password")
+		responses.ApiFailure(ctx, "Invalid email or password", http.StatusUnauthorized, nil)
 		return
 	}
-
-	// Prepare response
-	studentResponse := &auth.StudentResponse{
-		ID:           int(studentEntity.ID),
-		FirstName:    studentEntity.FirstName,
-		LastName:     studentEntity.LastName,
-		Email:        studentEntity.Email,
-		MatricNumber: studentEntity.MatricNumber,
-		Role:         studentEntity.Role,
+	if len(candidates) > 1 {
+		roles := make([]string, 0, len(candidates))
+		for _, profile := range candidates {
+			roles = append(roles, profile.Role)
+		}
+		responses.ApiFailure(ctx, "This account has more than one role. Send role to choose one", http.StatusConflict, map[string]interface{}{"roles": roles})
+		return
 	}
 
-	loginResponse := &auth.LoginResponse{
-		Message:     "Student login successful",
-		AccessToken: token,
-		User:        studentResponse,
+	switch candidates[0].Role {
+	case "student":
+		studentEntity, err := svc.Repository.GetStudentByID(candidates[0].ID)
+		if err != nil {
+			responses.ApiFailure(ctx, "Database error", http.StatusInternalServerError, err)
+			return
+		}
+		if !svc.loginPermitted(ctx, studentEntity.SuspendedAt, studentEntity.RequiresEmailVerification, studentEntity.EmailVerifiedAt) {
+			return
+		}
+		svc.completeLogin(ctx, auth.Account{ID: int(studentEntity.ID), Email: studentEntity.Identity.Email, Role: "student"}, studentLoginUser(studentEntity))
+	case "lecturer":
+		lecturerEntity, err := svc.Repository.GetLecturerByID(candidates[0].ID)
+		if err != nil {
+			responses.ApiFailure(ctx, "Database error", http.StatusInternalServerError, err)
+			return
+		}
+		if !svc.loginPermitted(ctx, lecturerEntity.SuspendedAt, lecturerEntity.RequiresEmailVerification, lecturerEntity.EmailVerifiedAt) {
+			return
+		}
+		svc.completeLogin(ctx, auth.Account{ID: int(lecturerEntity.ID), Email: lecturerEntity.Identity.Email, Role: "lecturer"}, lecturerLoginUser(lecturerEntity))
 	}
+}
 
-	responses.ApiSuccess(ctx, http.StatusOK, "Login successful", loginResponse)
+// loginPermitted rejects suspended and unverified profiles after their
+// password was accepted.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) loginPermitted(ctx *gin.Context, suspendedAt *time.Time, requiresVerification bool, verifiedAt *time.Time) bool {
+	if suspendedAt != nil {
+		responses.ApiFailure(ctx, "Account suspended. Please contact an administrator", http.StatusForbidden, nil)
+		return false
+	}
+	if !emailVerified(requiresVerification, verifiedAt) {
+		responses.ApiFailure(ctx, emailNotVerifiedMessage, http.StatusForbidden, nil)
+		return false
+	}
+	return true
 }
 
-func (svc *AuthSvc) LoginLecturer(ctx *gin.Context) {
-	var loginData *auth.LoginLecturerDTO
+func (svc *AuthSvc) LoginAdmin(ctx *gin.Context) {
+	var loginData *auth.LoginAdminDTO
 
 	if e := ctx.ShouldBindJSON(&loginData); e != nil {
 		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e)
 		return
 	}
 
-	// Get lecturer by email with password for comparison
-	lecturerEntity, err := svc.Repository.GetLecturerByEmailWithPassword(loginData.Email)
+	if !svc.loginAllowed(ctx, "admin", loginData.Email) {
+		return
+	}
+
+	// Get admin by email with password for comparison
+	adminEntity, err := svc.Repository.GetAdminByEmailWithPassword(loginData.Email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			svc.loginFailed(ctx, "admin", loginData.Email, "unknown_account")
 			responses.ApiFailure(ctx, "Invalid email or password", http.StatusUnauthorized, nil)
 			return
 		}
@@ -151,34 +323,104 @@ func (svc *AuthSvc) LoginLecturer(ctx *gin.Context) {
 	}
 
 	// Compare passwords
-	if !utils.CompareHash(loginData.Password, lecturerEntity.Password) {
+	if !utils.CompareHash(loginData.Password, adminEntity.Password) {
+		svc.loginFailed(ctx, "admin", loginData.Email, "invalid_password")
 		responses.ApiFailure(ctx, "Invalid email or password", http.StatusUnauthorized, nil)
 		return
 	}
 
-	// Generate JWT token
-	token, err := utils.GenerateToken(int(lecturerEntity.ID), lecturerEntity.Email, "lecturer", 60)
-	if err != nil {
-		responses.ApiFailure(ctx, "Failed to generate token", http.StatusInternalServerError, err)
+	svc.completeLogin(ctx, auth.Account{ID: int(adminEntity.ID), Email: adminEntity.Email, Role: "admin"}, adminLoginUser(adminEntity))
+}
+
+// completeLogin finishes a login whose password was accepted. Users enrolled
+// in two-factor authentication, or whose role requires it, get a challenge
+// instead of tokens.
+func (svc *AuthSvc) completeLogin(ctx *gin.Context, account auth.Account, user interface{}) {
+	twoFactor, err := svc.TwoFactors.GetTwoFactor(account.Role, account.ID)
+	if err != nil && !errors.Is(err, authRepo.ErrTwoFactorNotFound) {
+		responses.ApiFailure(ctx, "Failed to retrieve two-factor settings", http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	// Prepare response
-	lecturerResponse := &auth.LecturerResponse{
-		ID:         int(lecturerEntity.ID),
-		FirstName:  lecturerEntity.FirstName,
-		LastName:   lecturerEntity.LastName,
-		Email:      lecturerEntity.Email,
-		Department: lecturerEntity.Department,
-		StaffID:    lecturerEntity.StaffID,
-		Role:       lecturerEntity.Role,
+	switch {
+	case twoFactor != nil && twoFactor.EnabledAt != nil:
+		svc.issueChallenge(ctx, account, utils.PurposeTwoFactor)
+	case svc.twoFactorRequired(account.Role):
+		svc.issueChallenge(ctx, account, utils.PurposeTwoFactorSetup)
+	default:
+		svc.startSession(ctx, account, user, nil)
 	}
+}
+
+// startSession issues an access and refresh token for a fully authenticated
+// login and writes the login response.
+func (svc *AuthSvc) startSession(ctx *gin.Context, account auth.Account, user interface{}, recoveryCodes []string) {
+	// Start a session: short-lived access token plus a rotating refresh token
+	tokens, err := svc.issueSession(ctx, account.ID, account.Email, account.Role, "")
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate token", http.StatusInternalServerError, err.Error())
+		return
+	}
+	svc.loginSucceeded(ctx, account.Role, account.Email)
 
 	loginResponse := &auth.LoginResponse{
-		Message:     "Lecturer login successful",
-		AccessToken: token,
-		User:        lecturerResponse,
+		Message:          loginNouns[account.Role] + " login successful",
+		AccessToken:      tokens.AccessToken,
+		RefreshToken:     tokens.RefreshToken,
+		TokenType:        tokens.TokenType,
+		ExpiresAt:        tokens.ExpiresAt,
+		RefreshExpiresAt: tokens.RefreshExpiresAt,
+		User:             user,
+		RecoveryCodes:    recoveryCodes,
 	}
 
 	responses.ApiSuccess(ctx, http.StatusOK, "Login successful", loginResponse)
 }
+
+// loginNouns name each role in login messages.
+var loginNouns = map[string]string{
+	"student":  "Student",
+	"lecturer": "Lecturer",
+	"admin":    "Admin",
+}
+
+// studentLoginUser maps a student to the user returned on login.
+func studentLoginUser(s *entities.Student) *auth.StudentResponse {
+	return &auth.StudentResponse{
+		ID:           int(s.ID),
+		FirstName:    s.FirstName,
+		LastName:     s.LastName,
+		Email:        s.Identity.Email,
+		MatricNumber: s.MatricNumber,
+		Faculty:      s.Faculty,
+		Department:   s.Department,
+		Programme:    s.Programme,
+		Level:        s.Level,
+		EntrySession: s.EntrySession,
+		Role:         s.Role,
+	}
+}
+
+// lecturerLoginUser maps a lecturer to the user returned on login.
+func lecturerLoginUser(l *entities.Lecturer) *auth.LecturerResponse {
+	return &auth.LecturerResponse{
+		ID:         int(l.ID),
+		FirstName:  l.FirstName,
+		LastName:   l.LastName,
+		Email:      l.Identity.Email,
+		Department: l.Department,
+		StaffID:    l.StaffID,
+		Role:       l.Role,
+	}
+}
+
+// adminLoginUser maps an admin to the user returned on login.
+func adminLoginUser(a *entities.Admin) *auth.AdminResponse {
+	return &auth.AdminResponse{
+		ID:        int(a.ID),
+		FirstName: a.FirstName,
+		LastName:  a.LastName,
+		Email:     a.Email,
+		Role:      a.Role,
+	}
+}
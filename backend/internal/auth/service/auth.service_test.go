@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeRegistry keeps one identity per email and the roles linked to it.
+type fakeRegistry struct {
+	auth.AuthRepoInterface
+	identities map[string]*entities.Identity
+	roles      map[string]map[string]bool
+}
+
+func (r *fakeRegistry) GetIdentityByEmail(email string) (*entities.Identity, error) {
+	if identity, ok := r.identities[email]; ok {
+		return identity, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakeRegistry) register(role, email, passwordHash string) (int, error) {
+	if r.roles[email][role] {
+		return 0, authRepo.ErrAccountExists
+	}
+	if _, ok := r.identities[email]; !ok {
+		r.identities[email] = &entities.Identity{Email: email, Password: passwordHash}
+		r.roles[email] = map[string]bool{}
+	}
+	r.roles[email][role] = true
+	return len(r.roles[email]), nil
+}
+
+func (r *fakeRegistry) RegisterStudent(student *auth.RegisterStudentDTO, requireVerification bool) (int, error) {
+	return r.register("student", student.Email, student.Password)
+}
+
+func (r *fakeRegistry) RegisterLecturer(lecturer *auth.RegisterLecturerDTO, requireVerification bool) (int, error) {
+	return r.register("lecturer", lecturer.Email, lecturer.Password)
+}
+
+func TestRegisterSecondRole(t *testing.T) {
+	registry := &fakeRegistry{identities: map[string]*entities.Identity{}, roles: map[string]map[string]bool{}}
+	svc := &AuthSvc{Repository: registry}
+
+	student := func(password string) auth.RegisterStudentDTO {
+		return auth.RegisterStudentDTO{FirstName: "Ada", LastName: "Obi", Email: "ada@uni.edu", Password: password, MatricNumber: "PG/2024/001"}
+	}
+	lecturer := func(password string) auth.RegisterLecturerDTO {
+		return auth.RegisterLecturerDTO{FirstName: "Ada", LastName: "Obi", Email: "ada@uni.edu", Password: password, Department: "CS", StaffID: "TA-7"}
+	}
+
+	steps := []struct {
+		name        string
+		register    func() (int, string)
+		want        int
+		wantMessage string
+	}{
+		{"new student", func() (int, string) { return call(t, svc.RegisterStudent, student("secret1")) }, http.StatusCreated, ""},
+		{"same student again", func() (int, string) { return call(t, svc.RegisterStudent, student("secret1")) }, http.StatusConflict, "Account already exists"},
+		{"lecturer with another password", func() (int, string) { return call(t, svc.RegisterLecturer, lecturer("secret2")) }, http.StatusConflict,
+			"An account with this email already exists. Register with its password to add this role"},
+		{"lecturer with the same password", func() (int, string) { return call(t, svc.RegisterLecturer, lecturer("secret1")) }, http.StatusCreated, ""},
+		{"same lecturer again", func() (int, string) { return call(t, svc.RegisterLecturer, lecturer("secret1")) }, http.StatusConflict, "Account already exists"},
+	}
+	for _, step := range steps {
+		code, message := step.register()
+		if code != step.want || (step.wantMessage != "" && message != step.wantMessage) {
+			t.Errorf("%s: %d %q, want %d %q", step.name, code, message, step.want, step.wantMessage)
+		}
+	}
+
+	if roles := registry.roles["ada@uni.edu"]; len(registry.identities) != 1 || !roles["student"] || !roles["lecturer"] {
+		t.Errorf("identities = %v, roles = %v, want one identity with both roles", registry.identities, roles)
+	}
+}
+
+// call posts body to handler and returns the status and error message.
+func call(t *testing.T, handler gin.HandlerFunc, body interface{}) (int, string) {
+	t.Helper()
+	w := post(t, handler, body)
+	var resp struct {
+		ErrorMessage string `json:"error_message"`
+	}
+	_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp.ErrorMessage
+}
@@ -0,0 +1,23 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/Dom-HTG/attendance-management-system/pkg/signing"
+	"github.com/gin-gonic/gin"
+)
+
+// JWKS handles GET /.well-known/jwks.json.
+// It publishes the public keys that verify access tokens so other services can
+// check them without sharing a secret. The set is returned as a bare JWKS
+// document, not wrapped in the usual response envelope, since that is what JWT
+// libraries expect. It is empty when tokens are signed with HS256.
+func (svc *AuthSvc) JWKS(ctx *gin.Context) {
+	set := signing.JWKSet{Keys: []signing.JWK{}}
+	if svc.Keys != nil {
+		set = svc.Keys.JWKS()
+	}
+
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(http.StatusOK, set)
+}
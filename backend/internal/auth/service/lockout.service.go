@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/pkg/lockout"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// loginAllowed checks that neither the account nor the caller's IP is locked
+// out before a password is compared. Lockout storage errors are logged and the
+// attempt is allowed, so an outage of the store does not block every login.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) loginAllowed(ctx *gin.Context, role, email string) bool {
+	if svc.Lockout == nil {
+		return true
+	}
+
+	wait, err := svc.Lockout.Check(ctx.Request.Context(), lockout.AccountKey(role, email), ctx.ClientIP())
+	if err != nil {
+		logger.Errorf("lockout check failed for %s %s: %v", role, email, err)
+		return true
+	}
+	if wait <= 0 {
+		return true
+	}
+
+	retryAfter := int((wait + time.Second - 1) / time.Second)
+	logger.Security("login_blocked", map[string]interface{}{
+		"role":        role,
+		"email":       email,
+		"ip":          ctx.ClientIP(),
+		"retry_after": retryAfter,
+	})
+	ctx.Header("Retry-After", strconv.Itoa(retryAfter))
+	responses.ApiFailure(ctx, "Too many failed login attempts. Try again in "+lockout.FormatWait(wait), http.StatusTooManyRequests, nil)
+	return false
+}
+
+// loginFailed records a failed login against the account and the caller's IP
+// and logs it as a security event. reason is recorded in the log only; callers
+// send the same response for unknown accounts and wrong passwords.
+func (svc *AuthSvc) loginFailed(ctx *gin.Context, role, email, reason string) {
+	fields := map[string]interface{}{
+		"role":       role,
+		"email":      email,
+		"ip":         ctx.ClientIP(),
+		"user_agent": ctx.Request.UserAgent(),
+		"reason":     reason,
+	}
+	if svc.Lockout != nil {
+		failures, err := svc.Lockout.Fail(ctx.Request.Context(), lockout.AccountKey(role, email), ctx.ClientIP())
+		if err != nil {
+			logger.Errorf("failed to record failed login for %s %s: %v", role, email, err)
+		} else {
+			fields["failures"] = failures
+		}
+	}
+	logger.Security("login_failed", fields)
+}
+
+// loginSucceeded clears the account's failed attempts once a session has been
+// started. It is not called after the password step alone, so a known password
+// cannot be used to reset the count while guessing second-factor codes.
+func (svc *AuthSvc) loginSucceeded(ctx *gin.Context, role, email string) {
+	if svc.Lockout == nil {
+		return
+	}
+	if err := svc.Lockout.Succeed(ctx.Request.Context(), lockout.AccountKey(role, email)); err != nil {
+		logger.Errorf("failed to clear failed logins for %s %s: %v", role, email, err)
+	}
+}
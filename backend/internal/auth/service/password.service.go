@@ -0,0 +1,174 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/mailer"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PasswordResetConfig controls password reset emails.
+type PasswordResetConfig struct {
+	TokenTTL time.Duration
+	URL      string // Frontend page that accepts the token as ?token=; optional
+}
+
+// ForgotPassword handles POST /api/auth/forgot-password.
+// It emails a single-use reset token to every matching account. The response is
+// the same whether or not the email is registered, so it cannot be used to
+// discover accounts.
+func (svc *AuthSvc) ForgotPassword(ctx *gin.Context) {
+	var req auth.ForgotPasswordDTO
+	if e := ctx.ShouldBindJSON(&req); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+		return
+	}
+
+	accounts, err := svc.passwordResetAccounts(req.Email, req.Role)
+	if err != nil {
+		responses.ApiFailure(ctx, "Database error", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, account := range accounts {
+		if err := svc.sendPasswordReset(ctx, account); err != nil {
+			logger.Errorf("failed to send password reset to %s %d: %v", account.Role, account.ID, err)
+		}
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "If an account exists for that email, a password reset link has been sent", nil)
+}
+
+// ResetPassword handles POST /api/auth/reset-password.
+// A valid token sets the new password and ends every existing session, so
+// anyone holding the old credentials or tokens is signed out.
+func (svc *AuthSvc) ResetPassword(ctx *gin.Context) {
+	var req auth.ResetPasswordDTO
+	if e := ctx.ShouldBindJSON(&req); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+		return
+	}
+
+	token, err := svc.Tokens.GetPasswordResetTokenByHash(utils.HashOpaqueToken(req.Token))
+	if err != nil {
+		if errors.Is(err, authRepo.ErrPasswordResetTokenNotFound) {
+			responses.ApiFailure(ctx, "Invalid or expired reset token", http.StatusBadRequest, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve reset token", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if token.UsedAt != nil || time.Now().After(token.ExpiresAt) {
+		responses.ApiFailure(ctx, "Invalid or expired reset token", http.StatusBadRequest, nil)
+		return
+	}
+
+	hash, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		responses.ApiFailure(ctx, "Unable to hash password", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	sessions, err := svc.Tokens.ResetPassword(token, string(hash))
+	if err != nil {
+		if errors.Is(err, authRepo.ErrPasswordResetTokenInvalid) {
+			responses.ApiFailure(ctx, "Invalid or expired reset token", http.StatusBadRequest, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to reset password", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.Infof("password reset for %s %d; %d session(s) revoked", token.Role, token.UserID, sessions)
+	responses.ApiSuccess(ctx, http.StatusOK, "Password reset successfully. Please login with your new password.", map[string]int{
+		"sessions_revoked": sessions,
+	})
+}
+
+// passwordResetAccounts returns the accounts registered with email, limited to
+// role when it is set.
+func (svc *AuthSvc) passwordResetAccounts(email, role string) ([]auth.Account, error) {
+	var accounts []auth.Account
+
+	if role == "" || role == "student" {
+		student, err := svc.Repository.GetStudentByEmailWithPassword(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if student != nil {
+			accounts = append(accounts, auth.Account{ID: int(student.ID), Email: student.Identity.Email, Role: "student"})
+		}
+	}
+
+	if role == "" || role == "lecturer" {
+		lecturer, err := svc.Repository.GetLecturerByEmailWithPassword(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if lecturer != nil {
+			accounts = append(accounts, auth.Account{ID: int(lecturer.ID), Email: lecturer.Identity.Email, Role: "lecturer"})
+		}
+	}
+
+	return accounts, nil
+}
+
+// sendPasswordReset issues a reset token for account and emails it.
+func (svc *AuthSvc) sendPasswordReset(ctx *gin.Context, account auth.Account) error {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := svc.Tokens.CreatePasswordResetToken(&entities.PasswordResetToken{
+		TokenHash:   utils.HashOpaqueToken(token),
+		UserID:      account.ID,
+		Role:        account.Role,
+		ExpiresAt:   time.Now().Add(svc.PasswordReset.TokenTTL),
+		RequestedIP: ctx.ClientIP(),
+	}); err != nil {
+		return err
+	}
+
+	body := fmt.Sprintf("We received a request to reset the password for your %s account.\n\n", account.Role)
+	if link := svc.passwordResetLink(token); link != "" {
+		body += fmt.Sprintf("Reset your password: %s\n\n", link)
+	}
+	body += fmt.Sprintf("Reset token: %s\n\n", token)
+	body += fmt.Sprintf("The token expires in %d minutes and can only be used once. If you did not request a reset, you can ignore this email.\n",
+		int(svc.PasswordReset.TokenTTL.Minutes()))
+
+	return svc.Mailer.Send(ctx.Request.Context(), mailer.Message{
+		To:      account.Email,
+		Subject: "Reset your password",
+		Body:    body,
+	})
+}
+
+// passwordResetLink appends token to the configured reset page URL. It returns
+// an empty string when no URL is configured.
+func (svc *AuthSvc) passwordResetLink(token string) string {
+	if svc.PasswordReset.URL == "" {
+		return ""
+	}
+	link, err := url.Parse(svc.PasswordReset.URL)
+	if err != nil {
+		logger.Errorf("invalid password reset URL %q: %v", svc.PasswordReset.URL, err)
+		return ""
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
@@ -0,0 +1,210 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/mailer"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// outbox records sent mail.
+type outbox struct {
+	sent []mailer.Message
+}
+
+func (o *outbox) Send(ctx context.Context, msg mailer.Message) error {
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+// tokenIn returns the value after label in the last message sent.
+func (o *outbox) tokenIn(t *testing.T, label string) string {
+	t.Helper()
+	if len(o.sent) == 0 {
+		t.Fatal("no mail sent")
+	}
+	token := tokenAfter(o.sent[len(o.sent)-1].Body, label)
+	if token == "" {
+		t.Fatalf("no %q in mail body", label)
+	}
+	return token
+}
+
+// fakeDirectory looks up students and lecturers by email.
+type fakeDirectory struct {
+	auth.AuthRepoInterface
+	students  map[string]*entities.Student
+	lecturers map[string]*entities.Lecturer
+}
+
+func (d *fakeDirectory) GetStudentByEmailWithPassword(email string) (*entities.Student, error) {
+	if student, ok := d.students[email]; ok {
+		return student, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (d *fakeDirectory) GetLecturerByEmailWithPassword(email string) (*entities.Lecturer, error) {
+	if lecturer, ok := d.lecturers[email]; ok {
+		return lecturer, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// memoryResets is an in-memory store of password reset tokens.
+type memoryResets struct {
+	authRepo.TokenRepoInterface
+	resets    []*entities.PasswordResetToken
+	passwords map[string]string // Password hash by role and user ID, e.g. "student/7"
+}
+
+func (m *memoryResets) CreatePasswordResetToken(token *entities.PasswordResetToken) error {
+	token.ID = uint(len(m.resets) + 1)
+	m.resets = append(m.resets, token)
+	return nil
+}
+
+func (m *memoryResets) GetPasswordResetTokenByHash(tokenHash string) (*entities.PasswordResetToken, error) {
+	for _, token := range m.resets {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, authRepo.ErrPasswordResetTokenNotFound
+}
+
+func (m *memoryResets) ResetPassword(token *entities.PasswordResetToken, passwordHash string) (int, error) {
+	stored := m.resets[token.ID-1]
+	if stored.UsedAt != nil {
+		return 0, authRepo.ErrPasswordResetTokenInvalid
+	}
+	now := time.Now()
+	stored.UsedAt = &now
+	m.passwords[fmt.Sprintf("%s/%d", stored.Role, stored.UserID)] = passwordHash
+	return 2, nil
+}
+
+func TestForgotPassword(t *testing.T) {
+	directory := &fakeDirectory{
+		students: map[string]*entities.Student{
+			"ada@uni.edu": {Model: gorm.Model{ID: 7}, Identity: &entities.Identity{Email: "ada@uni.edu"}},
+		},
+		lecturers: map[string]*entities.Lecturer{
+			"ada@uni.edu": {Model: gorm.Model{ID: 3}, Identity: &entities.Identity{Email: "ada@uni.edu"}},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		req       auth.ForgotPasswordDTO
+		wantRoles []string
+	}{
+		{"both roles", auth.ForgotPasswordDTO{Email: "ada@uni.edu"}, []string{"student", "lecturer"}},
+		{"student only", auth.ForgotPasswordDTO{Email: "ada@uni.edu", Role: "student"}, []string{"student"}},
+		{"lecturer only", auth.ForgotPasswordDTO{Email: "ada@uni.edu", Role: "lecturer"}, []string{"lecturer"}},
+		{"unknown email", auth.ForgotPasswordDTO{Email: "nobody@uni.edu"}, nil},
+	}
+
+	var unknownBody string
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := &memoryResets{}
+			mail := &outbox{}
+			svc := &AuthSvc{Repository: directory, Tokens: tokens, Mailer: mail, PasswordReset: PasswordResetConfig{TokenTTL: 30 * time.Minute}}
+
+			w := post(t, svc.ForgotPassword, tt.req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+			if tt.wantRoles == nil {
+				unknownBody = w.Body.String()
+			}
+			if len(tokens.resets) != len(tt.wantRoles) || len(mail.sent) != len(tt.wantRoles) {
+				t.Fatalf("%d tokens and %d emails, want %d", len(tokens.resets), len(mail.sent), len(tt.wantRoles))
+			}
+			for i, role := range tt.wantRoles {
+				token := tokens.resets[i]
+				if token.Role != role {
+					t.Errorf("token %d role = %q, want %q", i, token.Role, role)
+				}
+				if until := time.Until(token.ExpiresAt); until <= 29*time.Minute || until > 30*time.Minute {
+					t.Errorf("token %d expires in %v, want 30m", i, until)
+				}
+				if raw := tokenAfter(mail.sent[i].Body, "Reset token: "); raw == "" || utils.HashOpaqueToken(raw) != token.TokenHash {
+					t.Errorf("email %d does not carry the stored token", i)
+				}
+			}
+		})
+	}
+
+	// The response must not reveal whether the email is registered
+	svc := &AuthSvc{Repository: directory, Tokens: &memoryResets{}, Mailer: &outbox{}, PasswordReset: PasswordResetConfig{TokenTTL: time.Minute}}
+	if known := post(t, svc.ForgotPassword, auth.ForgotPasswordDTO{Email: "ada@uni.edu"}).Body.String(); known != unknownBody {
+		t.Errorf("known email response %s differs from unknown email response %s", known, unknownBody)
+	}
+}
+
+func TestResetPassword(t *testing.T) {
+	directory := &fakeDirectory{students: map[string]*entities.Student{
+		"ada@uni.edu": {Model: gorm.Model{ID: 7}, Identity: &entities.Identity{Email: "ada@uni.edu"}},
+	}}
+	tokens := &memoryResets{passwords: map[string]string{}}
+	mail := &outbox{}
+	svc := &AuthSvc{Repository: directory, Tokens: tokens, Mailer: mail, PasswordReset: PasswordResetConfig{TokenTTL: time.Hour}}
+
+	post(t, svc.ForgotPassword, auth.ForgotPasswordDTO{Email: "ada@uni.edu"})
+	token := mail.tokenIn(t, "Reset token: ")
+
+	expired, _ := utils.GenerateOpaqueToken()
+	_ = tokens.CreatePasswordResetToken(&entities.PasswordResetToken{
+		TokenHash: utils.HashOpaqueToken(expired), UserID: 7, Role: "student", ExpiresAt: time.Now().Add(-time.Second),
+	})
+
+	steps := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"unknown token", "not-a-token", http.StatusBadRequest},
+		{"expired token", expired, http.StatusBadRequest},
+		{"valid token", token, http.StatusOK},
+		{"token used twice", token, http.StatusBadRequest},
+	}
+	for _, step := range steps {
+		w := post(t, svc.ResetPassword, auth.ResetPasswordDTO{Token: step.token, NewPassword: "n3w-secret"})
+		if w.Code != step.want {
+			t.Errorf("%s: status = %d, want %d: %s", step.name, w.Code, step.want, w.Body)
+		}
+	}
+
+	hash, ok := tokens.passwords["student/7"]
+	if !ok || len(tokens.passwords) != 1 {
+		t.Fatalf("passwords = %v, want only student 7 reset", tokens.passwords)
+	}
+	if !utils.CompareHash("n3w-secret", hash) {
+		t.Error("stored hash does not match the new password")
+	}
+}
+
+// tokenAfter returns the word following label in body.
+func tokenAfter(body, label string) string {
+	at := strings.Index(body, label)
+	if at < 0 {
+		return ""
+	}
+	fields := strings.Fields(body[at+len(label):])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
@@ -0,0 +1,383 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// GetStudentProfile handles GET /api/student/{id}.
+// Students can read their own profile; admins can read any.
+func (svc *AuthSvc) GetStudentProfile(ctx *gin.Context) {
+	studentID, _, ok := profileAccess(ctx, "student")
+	if !ok {
+		return
+	}
+
+	student, err := svc.Repository.GetStudentByID(studentID)
+	if err != nil {
+		respondProfileLookupError(ctx, err)
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Profile retrieved successfully", studentProfile(student))
+}
+
+// UpdateStudentProfile handles PUT /api/student/{id}.
+// Students can change their name, password and email; a new email only takes
+// effect once verified. Admins can change any field, including the matric number.
+func (svc *AuthSvc) UpdateStudentProfile(ctx *gin.Context) {
+	studentID, privileged, ok := profileAccess(ctx, "student")
+	if !ok {
+		return
+	}
+
+	var req auth.UpdateStudentProfileDTO
+	if e := ctx.ShouldBindJSON(&req); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+		return
+	}
+
+	student, err := svc.Repository.GetStudentByID(studentID)
+	if err != nil {
+		respondProfileLookupError(ctx, err)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	setName(updates, req.FirstName, req.LastName)
+
+	if req.MatricNumber != nil {
+		if !privileged {
+			responses.ApiFailure(ctx, "Matric number can only be changed by an administrator", http.StatusForbidden, nil)
+			return
+		}
+		if !svc.setUnique(ctx, updates, "student", studentID, "matric_number", student.MatricNumber, *req.MatricNumber) {
+			return
+		}
+	}
+
+	if req.Faculty != nil || req.Department != nil || req.Programme != nil || req.Level != nil || req.EntrySession != nil {
+		if !privileged {
+			responses.ApiFailure(ctx, "Faculty, department, programme, level and entry session can only be changed by an administrator", http.StatusForbidden, nil)
+			return
+		}
+		if req.EntrySession != nil && !utils.ValidAcademicSession(strings.TrimSpace(*req.EntrySession)) {
+			responses.ApiFailure(ctx, "entry_session must look like 2024/2025", http.StatusBadRequest, nil)
+			return
+		}
+		setTrimmed(updates, "faculty", req.Faculty)
+		setTrimmed(updates, "department", req.Department)
+		setTrimmed(updates, "programme", req.Programme)
+		setTrimmed(updates, "entry_session", req.EntrySession)
+		if req.Level != nil {
+			updates["level"] = *req.Level
+		}
+	}
+
+	if !svc.setPassword(ctx, updates, student.Identity.Password, req.NewPassword, req.CurrentPassword, privileged) {
+		return
+	}
+
+	pendingEmail, ok := svc.setEmail(ctx, updates, "student", studentID, student.Identity.Email, req.Email, privileged)
+	if !ok {
+		return
+	}
+
+	if len(updates) > 0 {
+		if err := svc.Repository.UpdateStudent(studentID, updates); err != nil {
+			responses.ApiFailure(ctx, "Failed to update profile", http.StatusInternalServerError, err.Error())
+			return
+		}
+		if student, err = svc.Repository.GetStudentByID(studentID); err != nil {
+			respondProfileLookupError(ctx, err)
+			return
+		}
+	}
+
+	if pendingEmail != "" {
+		if err := svc.sendEmailVerification(ctx, auth.Account{ID: studentID, Email: pendingEmail, Role: "student"}, entities.EmailVerificationChange); err != nil {
+			responses.ApiFailure(ctx, "Failed to send verification email", http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	profile := studentProfile(student)
+	profile.PendingEmail = pendingEmail
+	responses.ApiSuccess(ctx, http.StatusOK, profileUpdatedMessage(pendingEmail), profile)
+}
+
+// GetLecturerProfile handles GET /api/lecturer/{id}.
+// Lecturers can read their own profile; admins can read any.
+func (svc *AuthSvc) GetLecturerProfile(ctx *gin.Context) {
+	lecturerID, _, ok := profileAccess(ctx, "lecturer")
+	if !ok {
+		return
+	}
+
+	lecturer, err := svc.Repository.GetLecturerByID(lecturerID)
+	if err != nil {
+		respondProfileLookupError(ctx, err)
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Profile retrieved successfully", lecturerProfile(lecturer))
+}
+
+// UpdateLecturerProfile handles PUT /api/lecturer/{id}.
+// Lecturers can change their name, password and email; a new email only takes
+// effect once verified. Admins can change any field. The department decides
+// which department a head of department oversees, so only admins can change it
+// and doing so clears the head of department designation.
+func (svc *AuthSvc) UpdateLecturerProfile(ctx *gin.Context) {
+	lecturerID, privileged, ok := profileAccess(ctx, "lecturer")
+	if !ok {
+		return
+	}
+
+	var req auth.UpdateLecturerProfileDTO
+	if e := ctx.ShouldBindJSON(&req); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+		return
+	}
+
+	lecturer, err := svc.Repository.GetLecturerByID(lecturerID)
+	if err != nil {
+		respondProfileLookupError(ctx, err)
+		return
+	}
+
+	updates := map[string]interface{}{}
+	setName(updates, req.FirstName, req.LastName)
+
+	if req.StaffID != nil {
+		if !privileged {
+			responses.ApiFailure(ctx, "Staff ID can only be changed by an administrator", http.StatusForbidden, nil)
+			return
+		}
+		if !svc.setUnique(ctx, updates, "lecturer", lecturerID, "staff_id", lecturer.StaffID, *req.StaffID) {
+			return
+		}
+	}
+
+	if req.Department != nil {
+		if !privileged {
+			responses.ApiFailure(ctx, "Department can only be changed by an administrator", http.StatusForbidden, nil)
+			return
+		}
+		department := strings.TrimSpace(*req.Department)
+		if department != lecturer.Department {
+			updates["department"] = department
+		}
+	}
+
+	if !svc.setPassword(ctx, updates, lecturer.Identity.Password, req.NewPassword, req.CurrentPassword, privileged) {
+		return
+	}
+
+	pendingEmail, ok := svc.setEmail(ctx, updates, "lecturer", lecturerID, lecturer.Identity.Email, req.Email, privileged)
+	if !ok {
+		return
+	}
+
+	if len(updates) > 0 {
+		if err := svc.Repository.UpdateLecturer(lecturerID, updates); err != nil {
+			responses.ApiFailure(ctx, "Failed to update profile", http.StatusInternalServerError, err.Error())
+			return
+		}
+		if lecturer, err = svc.Repository.GetLecturerByID(lecturerID); err != nil {
+			respondProfileLookupError(ctx, err)
+			return
+		}
+	}
+
+	if pendingEmail != "" {
+		if err := svc.sendEmailVerification(ctx, auth.Account{ID: lecturerID, Email: pendingEmail, Role: "lecturer"}, entities.EmailVerificationChange); err != nil {
+			responses.ApiFailure(ctx, "Failed to send verification email", http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	profile := lecturerProfile(lecturer)
+	profile.PendingEmail = pendingEmail
+	responses.ApiSuccess(ctx, http.StatusOK, profileUpdatedMessage(pendingEmail), profile)
+}
+
+// profileAccess parses the id URL parameter of a role's profile route and
+// checks the caller may access it: their own profile, or any profile for
+// callers who manage users. It reports whether the caller manages users.
+// It writes the failure response itself and returns false when the request should stop.
+func profileAccess(ctx *gin.Context, role string) (int, bool, bool) {
+	callerID, callerRole, ok := callerIdentity(ctx)
+	if !ok {
+		return 0, false, false
+	}
+
+	userID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid user ID", http.StatusBadRequest, err.Error())
+		return 0, false, false
+	}
+
+	privileged := middleware.HasPermission(ctx, rbac.PermUsersManage, rbac.Scope{Type: rbac.ScopeGlobal})
+	if !privileged && (callerRole != role || callerID != userID) {
+		responses.ApiFailure(ctx, "You can only access your own profile", http.StatusForbidden, nil)
+		return 0, false, false
+	}
+
+	return userID, privileged, true
+}
+
+// setName records first and last name changes.
+func setName(updates map[string]interface{}, firstName, lastName *string) {
+	if firstName != nil {
+		updates["first_name"] = strings.TrimSpace(*firstName)
+	}
+	if lastName != nil {
+		updates["last_name"] = strings.TrimSpace(*lastName)
+	}
+}
+
+// setTrimmed records a change to a free-text column when a value was given.
+func setTrimmed(updates map[string]interface{}, column string, value *string) {
+	if value != nil {
+		updates[column] = strings.TrimSpace(*value)
+	}
+}
+
+// setUnique records a change to a unique column after checking no other
+// account of role uses the value.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) setUnique(ctx *gin.Context, updates map[string]interface{}, role string, userID int, column, current, value string) bool {
+	value = strings.TrimSpace(value)
+	if value == current {
+		return true
+	}
+
+	taken, err := svc.Repository.IsTaken(role, column, value, userID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to update profile", http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if taken {
+		responses.ApiFailure(ctx, fmt.Sprintf("That %s is already in use", strings.ReplaceAll(column, "_", " ")), http.StatusConflict, nil)
+		return false
+	}
+
+	updates[column] = value
+	return true
+}
+
+// setPassword records a password change. Users changing their own password
+// must confirm the current one; admins do not.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) setPassword(ctx *gin.Context, updates map[string]interface{}, currentHash string, newPassword *string, currentPassword string, privileged bool) bool {
+	if newPassword == nil {
+		return true
+	}
+
+	if !privileged {
+		if currentPassword == "" {
+			responses.ApiFailure(ctx, "current_password is required to change your password", http.StatusBadRequest, nil)
+			return false
+		}
+		if !utils.CompareHash(currentPassword, currentHash) {
+			responses.ApiFailure(ctx, "Current password is incorrect", http.StatusForbidden, nil)
+			return false
+		}
+	}
+
+	hash, err := utils.HashPassword(*newPassword)
+	if err != nil {
+		responses.ApiFailure(ctx, "Unable to hash password", http.StatusInternalServerError, err.Error())
+		return false
+	}
+	updates["password"] = string(hash)
+	return true
+}
+
+// setEmail handles an email change. Admins change the email directly; users
+// get back the new address, which must be verified before it is saved.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) setEmail(ctx *gin.Context, updates map[string]interface{}, role string, userID int, current string, email *string, privileged bool) (string, bool) {
+	if email == nil || strings.EqualFold(strings.TrimSpace(*email), current) {
+		return "", true
+	}
+	if role == "lecturer" && !svc.lecturerEmailAllowed(*email) {
+		responses.ApiFailure(ctx, "Lecturer email must use an institutional email domain", http.StatusBadRequest, nil)
+		return "", false
+	}
+
+	if !svc.setUnique(ctx, updates, role, userID, "email", current, *email) {
+		return "", false
+	}
+	if privileged {
+		return "", true
+	}
+
+	pending := updates["email"].(string)
+	delete(updates, "email")
+	return pending, true
+}
+
+// profileUpdatedMessage tells the caller whether an email change is waiting
+// for verification.
+func profileUpdatedMessage(pendingEmail string) string {
+	if pendingEmail != "" {
+		return "Profile updated. Confirm your new email address using the link sent to " + pendingEmail
+	}
+	return "Profile updated successfully"
+}
+
+// respondProfileLookupError maps profile lookup errors to HTTP responses.
+func respondProfileLookupError(ctx *gin.Context, err error) {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		responses.ApiFailure(ctx, "User not found", http.StatusNotFound, nil)
+		return
+	}
+	responses.ApiFailure(ctx, "Failed to retrieve profile", http.StatusInternalServerError, err.Error())
+}
+
+// studentProfile maps a student entity to its profile response.
+func studentProfile(s *entities.Student) auth.StudentResponse {
+	return auth.StudentResponse{
+		ID:           int(s.ID),
+		FirstName:    s.FirstName,
+		LastName:     s.LastName,
+		Email:        s.Identity.Email,
+		MatricNumber: s.MatricNumber,
+		Faculty:      s.Faculty,
+		Department:   s.Department,
+		Programme:    s.Programme,
+		Level:        s.Level,
+		EntrySession: s.EntrySession,
+		Role:         s.Role,
+		CreatedAt:    s.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// lecturerProfile maps a lecturer entity to its profile response.
+func lecturerProfile(l *entities.Lecturer) auth.LecturerResponse {
+	return auth.LecturerResponse{
+		ID:         int(l.ID),
+		FirstName:  l.FirstName,
+		LastName:   l.LastName,
+		Email:      l.Identity.Email,
+		Department: l.Department,
+		StaffID:    l.StaffID,
+		Role:       l.Role,
+		CreatedAt:  l.CreatedAt.Format(time.RFC3339),
+	}
+}
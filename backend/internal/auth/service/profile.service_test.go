@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// fakeProfiles holds student 7 and lecturer 3, records the changes written to
+// them and reports the values in taken as used by another account.
+type fakeProfiles struct {
+	auth.AuthRepoInterface
+	student  *entities.Student
+	lecturer *entities.Lecturer
+	taken    map[string]bool
+	updates  map[string]interface{}
+}
+
+func (r *fakeProfiles) GetStudentByID(studentID int) (*entities.Student, error) {
+	if int(r.student.ID) != studentID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.student, nil
+}
+
+func (r *fakeProfiles) GetLecturerByID(lecturerID int) (*entities.Lecturer, error) {
+	if int(r.lecturer.ID) != lecturerID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.lecturer, nil
+}
+
+func (r *fakeProfiles) UpdateStudent(studentID int, updates map[string]interface{}) error {
+	r.updates = updates
+	return nil
+}
+
+func (r *fakeProfiles) UpdateLecturer(lecturerID int, updates map[string]interface{}) error {
+	r.updates = updates
+	return nil
+}
+
+func (r *fakeProfiles) IsTaken(role, column, value string, exceptID int) (bool, error) {
+	return r.taken[value], nil
+}
+
+func newProfileSvc(t *testing.T) (*AuthSvc, *fakeProfiles, *memoryVerifications) {
+	t.Helper()
+	hash, err := utils.HashPassword("secret1")
+	if err != nil {
+		t.Fatalf("HashPassword: %v", err)
+	}
+	profiles := &fakeProfiles{
+		student: &entities.Student{Model: gorm.Model{ID: 7}, Identity: &entities.Identity{Email: "ada@uni.edu", Password: string(hash)},
+			FirstName: "Ada", LastName: "Obi", MatricNumber: "CSC/2024/001"},
+		lecturer: &entities.Lecturer{Model: gorm.Model{ID: 3}, Identity: &entities.Identity{Email: "grace@uni.edu", Password: string(hash)},
+			FirstName: "Grace", LastName: "Eze", StaffID: "STF-19", Department: "Computer Science"},
+		taken: map[string]bool{"CSC/2024/002": true, "taken@uni.edu": true},
+	}
+	tokens := &memoryVerifications{}
+	svc := &AuthSvc{Repository: profiles, Tokens: tokens, Mailer: &outbox{}, EmailVerification: EmailVerificationConfig{TokenTTL: 24 * time.Hour}}
+	return svc, profiles, tokens
+}
+
+// serveProfile calls handler for profile id as the given account with a JSON body.
+func serveProfile(t *testing.T, handler gin.HandlerFunc, callerRole string, callerID int, id string, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(payload))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	ctx.Params = gin.Params{{Key: "id", Value: id}}
+	ctx.Set("user_id", callerID)
+	ctx.Set("user_role", callerRole)
+	handler(ctx)
+	return w
+}
+
+func TestGetProfileAccess(t *testing.T) {
+	tests := []struct {
+		name       string
+		callerRole string
+		callerID   int
+		id         string
+		want       int
+	}{
+		{"own profile", "student", 7, "7", http.StatusOK},
+		{"another student", "student", 8, "7", http.StatusForbidden},
+		{"lecturer with the same ID", "lecturer", 7, "7", http.StatusForbidden},
+		{"admin", "admin", 1, "7", http.StatusOK},
+		{"admin, unknown student", "admin", 1, "8", http.StatusNotFound},
+		{"malformed ID", "student", 7, "me", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, _, _ := newProfileSvc(t)
+			if w := serveProfile(t, svc.GetStudentProfile, tt.callerRole, tt.callerID, tt.id, nil); w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+		})
+	}
+}
+
+func TestUpdateStudentProfileRules(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		callerRole  string
+		callerID    int
+		req         auth.UpdateStudentProfileDTO
+		want        int
+		wantUpdates []string // Columns written, sorted
+	}{
+		{"own name", "student", 7, auth.UpdateStudentProfileDTO{FirstName: str(" Adaeze ")}, http.StatusOK, []string{"first_name"}},
+		{"own matric number", "student", 7, auth.UpdateStudentProfileDTO{MatricNumber: str("CSC/2024/009")}, http.StatusForbidden, nil},
+		{"own department", "student", 7, auth.UpdateStudentProfileDTO{Department: str("Mathematics")}, http.StatusForbidden, nil},
+		{"password without the current one", "student", 7, auth.UpdateStudentProfileDTO{NewPassword: str("secret2")}, http.StatusBadRequest, nil},
+		{"password with a wrong current one", "student", 7, auth.UpdateStudentProfileDTO{NewPassword: str("secret2"), CurrentPassword: "wrong"}, http.StatusForbidden, nil},
+		{"password with the current one", "student", 7, auth.UpdateStudentProfileDTO{NewPassword: str("secret2"), CurrentPassword: "secret1"}, http.StatusOK, []string{"password"}},
+		{"admin changes matric number", "admin", 1, auth.UpdateStudentProfileDTO{MatricNumber: str("CSC/2024/009")}, http.StatusOK, []string{"matric_number"}},
+		{"admin reuses a matric number", "admin", 1, auth.UpdateStudentProfileDTO{MatricNumber: str("CSC/2024/002")}, http.StatusConflict, nil},
+		{"admin sets a password", "admin", 1, auth.UpdateStudentProfileDTO{NewPassword: str("secret2")}, http.StatusOK, []string{"password"}},
+		{"admin changes email", "admin", 1, auth.UpdateStudentProfileDTO{Email: str("adaeze@uni.edu")}, http.StatusOK, []string{"email"}},
+		{"email in use", "student", 7, auth.UpdateStudentProfileDTO{Email: str("taken@uni.edu")}, http.StatusConflict, nil},
+		{"invalid entry session", "admin", 1, auth.UpdateStudentProfileDTO{EntrySession: str("2024")}, http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, profiles, tokens := newProfileSvc(t)
+			w := serveProfile(t, svc.UpdateStudentProfile, tt.callerRole, tt.callerID, "7", tt.req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			var columns []string
+			for column := range profiles.updates {
+				columns = append(columns, column)
+			}
+			if !reflect.DeepEqual(columns, tt.wantUpdates) {
+				t.Errorf("updated %v, want %v", columns, tt.wantUpdates)
+			}
+			if len(tokens.verifications) != 0 {
+				t.Errorf("sent %d verification emails, want none", len(tokens.verifications))
+			}
+		})
+	}
+}
+
+func TestUpdateOwnEmailNeedsVerification(t *testing.T) {
+	svc, profiles, tokens := newProfileSvc(t)
+	w := serveProfile(t, svc.UpdateStudentProfile, "student", 7, "7", map[string]string{"email": "adaeze@uni.edu"})
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+	}
+	if profiles.updates != nil {
+		t.Errorf("updated %v before the new email was verified", profiles.updates)
+	}
+	if len(tokens.verifications) != 1 {
+		t.Fatalf("%d verifications, want one", len(tokens.verifications))
+	}
+	if v := tokens.verifications[0]; v.Email != "adaeze@uni.edu" || v.Purpose != entities.EmailVerificationChange || v.UserID != 7 {
+		t.Errorf("verification = %+v, want an email change for student 7", v)
+	}
+
+	var resp struct {
+		Data auth.StudentResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if resp.Data.Email != "ada@uni.edu" || resp.Data.PendingEmail != "adaeze@uni.edu" {
+		t.Errorf("email %q, pending %q; want the old address with the new one pending", resp.Data.Email, resp.Data.PendingEmail)
+	}
+}
+
+func TestUpdateLecturerProfileRules(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	tests := []struct {
+		name        string
+		callerRole  string
+		req         auth.UpdateLecturerProfileDTO
+		want        int
+		wantUpdates []string
+	}{
+		{"own staff ID", "lecturer", auth.UpdateLecturerProfileDTO{StaffID: str("STF-20")}, http.StatusForbidden, nil},
+		{"own department", "lecturer", auth.UpdateLecturerProfileDTO{Department: str("Mathematics")}, http.StatusForbidden, nil},
+		{"own name", "lecturer", auth.UpdateLecturerProfileDTO{LastName: str("Okafor")}, http.StatusOK, []string{"last_name"}},
+		{"admin changes staff ID", "admin", auth.UpdateLecturerProfileDTO{StaffID: str("STF-20")}, http.StatusOK, []string{"staff_id"}},
+		{"admin keeps the department", "admin", auth.UpdateLecturerProfileDTO{Department: str(" Computer Science ")}, http.StatusOK, nil},
+		{"admin changes the department", "admin", auth.UpdateLecturerProfileDTO{Department: str("Mathematics")}, http.StatusOK, []string{"department"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, profiles, _ := newProfileSvc(t)
+			callerID := 3
+			if tt.callerRole == "admin" {
+				callerID = 1
+			}
+			w := serveProfile(t, svc.UpdateLecturerProfile, tt.callerRole, callerID, "3", tt.req)
+			if w.Code != tt.want {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			var columns []string
+			for column := range profiles.updates {
+				columns = append(columns, column)
+			}
+			if !reflect.DeepEqual(columns, tt.wantUpdates) {
+				t.Errorf("updated %v, want %v", columns, tt.wantUpdates)
+			}
+		})
+	}
+}
@@ -0,0 +1,275 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// SessionConfig controls token lifetimes.
+type SessionConfig struct {
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+}
+
+// RefreshToken handles POST /api/auth/refresh-token.
+// The presented refresh token is exchanged for a new access and refresh token.
+// Presenting a token that was already exchanged revokes the whole session,
+// since it means either the client or an attacker holds a stale copy.
+func (svc *AuthSvc) RefreshToken(ctx *gin.Context) {
+	var req auth.RefreshTokenDTO
+	if e := ctx.ShouldBindJSON(&req); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+		return
+	}
+
+	current, err := svc.Tokens.GetRefreshTokenByHash(utils.HashOpaqueToken(req.RefreshToken))
+	if err != nil {
+		if errors.Is(err, authRepo.ErrRefreshTokenNotFound) {
+			responses.ApiFailure(ctx, "Invalid refresh token", http.StatusUnauthorized, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve refresh token", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if current.UsedAt != nil {
+		svc.revokeReusedFamily(current)
+		responses.ApiFailure(ctx, "Refresh token reuse detected. Please login again", http.StatusUnauthorized, nil)
+		return
+	}
+	if current.RevokedAt != nil || time.Now().After(current.ExpiresAt) {
+		responses.ApiFailure(ctx, "Refresh token has expired or been revoked. Please login again", http.StatusUnauthorized, nil)
+		return
+	}
+
+	account, err := svc.Repository.GetAccount(current.Role, current.UserID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Account no longer exists", http.StatusUnauthorized, nil)
+		return
+	}
+	if account.Suspended {
+		responses.ApiFailure(ctx, "Account suspended. Please contact an administrator", http.StatusForbidden, nil)
+		return
+	}
+
+	tokens, next, err := svc.newTokens(ctx, account.ID, account.Email, account.Role, current.FamilyID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate token", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := svc.Tokens.RotateRefreshToken(current, next); err != nil {
+		if errors.Is(err, authRepo.ErrRefreshTokenReused) {
+			svc.revokeReusedFamily(current)
+			responses.ApiFailure(ctx, "Refresh token reuse detected. Please login again", http.StatusUnauthorized, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to refresh token", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Token refreshed successfully", tokens)
+}
+
+// Logout handles POST /api/auth/logout.
+// It revokes the caller's access token and the session it belongs to.
+func (svc *AuthSvc) Logout(ctx *gin.Context) {
+	userID, role, ok := callerIdentity(ctx)
+	if !ok {
+		return
+	}
+
+	var req auth.LogoutDTO
+	if ctx.Request.ContentLength > 0 {
+		if e := ctx.ShouldBindJSON(&req); e != nil {
+			responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+			return
+		}
+	}
+
+	if req.RefreshToken != "" {
+		token, err := svc.Tokens.GetRefreshTokenByHash(utils.HashOpaqueToken(req.RefreshToken))
+		if err != nil && !errors.Is(err, authRepo.ErrRefreshTokenNotFound) {
+			responses.ApiFailure(ctx, "Failed to retrieve refresh token", http.StatusInternalServerError, err.Error())
+			return
+		}
+		if token != nil && token.UserID == userID && token.Role == role {
+			if err := svc.Tokens.RevokeFamily(token.FamilyID, authRepo.RevokeReasonLogout); err != nil {
+				responses.ApiFailure(ctx, "Failed to logout", http.StatusInternalServerError, err.Error())
+				return
+			}
+		}
+	}
+
+	if jti, ok := middleware.GetTokenIDFromContext(ctx); ok {
+		if err := svc.Tokens.RevokeFamilyByAccessJTI(jti, authRepo.RevokeReasonLogout); err != nil {
+			responses.ApiFailure(ctx, "Failed to logout", http.StatusInternalServerError, err.Error())
+			return
+		}
+		if err := svc.revokeCurrentAccessToken(ctx, jti, userID, role); err != nil {
+			responses.ApiFailure(ctx, "Failed to logout", http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Logged out successfully", nil)
+}
+
+// LogoutAll handles POST /api/auth/logout-all.
+// It revokes every session the caller has on any device.
+func (svc *AuthSvc) LogoutAll(ctx *gin.Context) {
+	userID, role, ok := callerIdentity(ctx)
+	if !ok {
+		return
+	}
+
+	sessions, err := svc.Tokens.RevokeAllForUser(role, userID, authRepo.RevokeReasonLogoutAll)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to logout", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if jti, ok := middleware.GetTokenIDFromContext(ctx); ok {
+		if err := svc.revokeCurrentAccessToken(ctx, jti, userID, role); err != nil {
+			responses.ApiFailure(ctx, "Failed to logout", http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Logged out of all sessions", map[string]int{
+		"sessions_revoked": sessions,
+	})
+}
+
+// issueSession starts a new session, or continues familyID when it is set,
+// and stores its refresh token.
+func (svc *AuthSvc) issueSession(ctx *gin.Context, userID int, email, role, familyID string) (*auth.TokenPairResponse, error) {
+	tokens, refresh, err := svc.newTokens(ctx, userID, email, role, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := svc.Tokens.CreateRefreshToken(refresh); err != nil {
+		return nil, err
+	}
+	return tokens, nil
+}
+
+// newTokens generates an access token and a refresh token without storing
+// anything. A new family is started when familyID is empty.
+func (svc *AuthSvc) newTokens(ctx *gin.Context, userID int, email, role, familyID string) (*auth.TokenPairResponse, *entities.RefreshToken, error) {
+	accessToken, claims, err := utils.GenerateToken(userID, email, role, svc.Sessions.AccessTokenTTL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if familyID == "" {
+		familyID = uuid.New().String()
+	}
+
+	refreshExpiresAt := time.Now().Add(svc.Sessions.RefreshTokenTTL)
+	refresh := &entities.RefreshToken{
+		TokenHash:       utils.HashOpaqueToken(refreshToken),
+		FamilyID:        familyID,
+		UserID:          userID,
+		Role:            role,
+		AccessJTI:       claims.RegisteredClaims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		ExpiresAt:       refreshExpiresAt,
+		UserAgent:       ctx.Request.UserAgent(),
+		IPAddress:       ctx.ClientIP(),
+	}
+
+	return &auth.TokenPairResponse{
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        claims.ExpiresAt.Time.Format(time.RFC3339),
+		RefreshExpiresAt: refreshExpiresAt.Format(time.RFC3339),
+	}, refresh, nil
+}
+
+// revokeReusedFamily revokes a session after one of its used refresh tokens
+// was presented again.
+func (svc *AuthSvc) revokeReusedFamily(token *entities.RefreshToken) {
+	logger.Errorf("refresh token reuse detected for %s %d (family %s); revoking session", token.Role, token.UserID, token.FamilyID)
+	if err := svc.Tokens.RevokeFamily(token.FamilyID, authRepo.RevokeReasonReuse); err != nil {
+		logger.Errorf("failed to revoke reused session %s: %v", token.FamilyID, err)
+	}
+}
+
+// revokeCurrentAccessToken denylists the access token used for this request.
+func (svc *AuthSvc) revokeCurrentAccessToken(ctx *gin.Context, jti string, userID int, role string) error {
+	expiresAt, ok := middleware.GetTokenExpiryFromContext(ctx)
+	if !ok {
+		expiresAt = time.Now().Add(svc.Sessions.AccessTokenTTL)
+	}
+	return svc.Tokens.RevokeAccessToken(jti, role, userID, expiresAt)
+}
+
+// callerIdentity returns the authenticated user's ID and role.
+// It writes the failure response itself and returns false when the request should stop.
+func callerIdentity(ctx *gin.Context) (int, string, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return 0, "", false
+	}
+	role, ok := middleware.GetUserRoleFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User role not found in context", http.StatusUnauthorized, nil)
+		return 0, "", false
+	}
+	return userID, role, true
+}
+
+// TokenJanitor is a background job that deletes expired refresh tokens and
+// denylist entries so the tables do not grow without bound.
+type TokenJanitor struct {
+	tokens   authRepo.TokenRepoInterface
+	interval time.Duration
+}
+
+// NewTokenJanitor returns a new TokenJanitor that runs every interval.
+func NewTokenJanitor(tokens authRepo.TokenRepoInterface, interval time.Duration) *TokenJanitor {
+	return &TokenJanitor{
+		tokens:   tokens,
+		interval: interval,
+	}
+}
+
+// Run purges expired tokens immediately and then on every tick until ctx is cancelled.
+func (tj *TokenJanitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(tj.interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := tj.tokens.PurgeExpired(time.Now()); err != nil {
+			logger.Errorf("token janitor failed: %v", err)
+		} else if removed > 0 {
+			logger.Infof("token janitor removed %d expired token(s)", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/signing"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// memoryTokens is an in-memory TokenRepoInterface.
+type memoryTokens struct {
+	authRepo.TokenRepoInterface
+	refresh []*entities.RefreshToken
+}
+
+func (m *memoryTokens) CreateRefreshToken(token *entities.RefreshToken) error {
+	token.ID = uint(len(m.refresh) + 1)
+	m.refresh = append(m.refresh, token)
+	return nil
+}
+
+func (m *memoryTokens) GetRefreshTokenByHash(tokenHash string) (*entities.RefreshToken, error) {
+	for _, token := range m.refresh {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, authRepo.ErrRefreshTokenNotFound
+}
+
+func (m *memoryTokens) RotateRefreshToken(current, next *entities.RefreshToken) error {
+	stored := m.refresh[current.ID-1]
+	if stored.UsedAt != nil || stored.RevokedAt != nil {
+		return authRepo.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	stored.UsedAt = &now
+	return m.CreateRefreshToken(next)
+}
+
+func (m *memoryTokens) RevokeFamily(familyID, reason string) error {
+	now := time.Now()
+	for _, token := range m.refresh {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt, token.RevokedReason = &now, reason
+		}
+	}
+	return nil
+}
+
+// fakeAccounts serves accounts by role and ID.
+type fakeAccounts struct {
+	auth.AuthRepoInterface
+	accounts map[string]*auth.Account
+}
+
+func (r *fakeAccounts) GetAccount(role string, userID int) (*auth.Account, error) {
+	for _, account := range r.accounts {
+		if account.Role == role && account.ID == userID {
+			return account, nil
+		}
+	}
+	return nil, errors.New("account not found")
+}
+
+// useTestSigningKeys signs tokens with an HS256 secret for the test.
+func useTestSigningKeys(t *testing.T) {
+	t.Helper()
+	keys, err := signing.NewManager(context.Background(), signing.Config{
+		Algorithm: signing.AlgHS256,
+		Secret:    "test-secret",
+		Issuer:    "attendance-api",
+		Audience:  "attendance-clients",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	utils.UseSigningKeys(keys)
+}
+
+// post runs handler with body as the JSON request and returns the response.
+func post(t *testing.T, handler gin.HandlerFunc, body interface{}) *httptest.ResponseRecorder {
+	t.Helper()
+	payload, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(payload))
+	ctx.Request.Header.Set("Content-Type", "application/json")
+	handler(ctx)
+	return w
+}
+
+// refreshTokenOf returns the refresh token in a successful token response.
+func refreshTokenOf(t *testing.T, w *httptest.ResponseRecorder) string {
+	t.Helper()
+	var resp struct {
+		Data auth.TokenPairResponse `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return resp.Data.RefreshToken
+}
+
+func TestRefreshTokenRotation(t *testing.T) {
+	useTestSigningKeys(t)
+	tokens := &memoryTokens{}
+	accounts := &fakeAccounts{accounts: map[string]*auth.Account{
+		"ada": {ID: 7, Email: "ada@uni.edu", Role: "student"},
+	}}
+	svc := &AuthSvc{Repository: accounts, Tokens: tokens, Sessions: SessionConfig{AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour}}
+
+	gin.SetMode(gin.TestMode)
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+	login, err := svc.issueSession(ctx, 7, "ada@uni.edu", "student", "")
+	if err != nil {
+		t.Fatalf("issueSession: %v", err)
+	}
+
+	refresh := func(token string) *httptest.ResponseRecorder {
+		return post(t, svc.RefreshToken, auth.RefreshTokenDTO{RefreshToken: token})
+	}
+
+	first := refresh(login.RefreshToken)
+	if first.Code != http.StatusOK {
+		t.Fatalf("first refresh: status = %d: %s", first.Code, first.Body)
+	}
+	second := refresh(refreshTokenOf(t, first))
+	if second.Code != http.StatusOK {
+		t.Fatalf("second refresh: status = %d: %s", second.Code, second.Body)
+	}
+	latest := refreshTokenOf(t, second)
+
+	family := tokens.refresh[0].FamilyID
+	for i, token := range tokens.refresh {
+		if token.FamilyID != family {
+			t.Errorf("token %d started a new family", i)
+		}
+		if used := token.UsedAt != nil; used != (i < 2) {
+			t.Errorf("token %d used = %v", i, used)
+		}
+	}
+
+	// Presenting the login token again means a stale copy is in someone's hands
+	if w := refresh(login.RefreshToken); w.Code != http.StatusUnauthorized {
+		t.Fatalf("reused token: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+	for i, token := range tokens.refresh {
+		if token.RevokedAt == nil || token.RevokedReason != authRepo.RevokeReasonReuse {
+			t.Errorf("token %d not revoked for reuse: %+v", i, token)
+		}
+	}
+	if w := refresh(latest); w.Code != http.StatusUnauthorized {
+		t.Errorf("latest token after reuse: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestRefreshTokenRejected(t *testing.T) {
+	useTestSigningKeys(t)
+	past := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name        string
+		token       entities.RefreshToken
+		account     *auth.Account
+		want        int
+		wantRevoked bool
+	}{
+		{"expired", entities.RefreshToken{ExpiresAt: past}, &auth.Account{ID: 7, Role: "student"}, http.StatusUnauthorized, false},
+		{"revoked on logout", entities.RefreshToken{RevokedAt: &past, RevokedReason: authRepo.RevokeReasonLogout}, &auth.Account{ID: 7, Role: "student"}, http.StatusUnauthorized, false},
+		{"already used", entities.RefreshToken{UsedAt: &past}, &auth.Account{ID: 7, Role: "student"}, http.StatusUnauthorized, true},
+		{"account deleted", entities.RefreshToken{}, nil, http.StatusUnauthorized, false},
+		{"account suspended", entities.RefreshToken{}, &auth.Account{ID: 7, Role: "student", Suspended: true}, http.StatusForbidden, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored := tt.token
+			stored.TokenHash = utils.HashOpaqueToken("presented")
+			stored.FamilyID, stored.UserID, stored.Role = "family", 7, "student"
+			if stored.ExpiresAt.IsZero() {
+				stored.ExpiresAt = time.Now().Add(time.Hour)
+			}
+			tokens := &memoryTokens{}
+			_ = tokens.CreateRefreshToken(&stored)
+			accounts := &fakeAccounts{accounts: map[string]*auth.Account{}}
+			if tt.account != nil {
+				accounts.accounts["ada"] = tt.account
+			}
+			svc := &AuthSvc{Repository: accounts, Tokens: tokens, Sessions: SessionConfig{AccessTokenTTL: time.Minute, RefreshTokenTTL: time.Hour}}
+
+			w := post(t, svc.RefreshToken, auth.RefreshTokenDTO{RefreshToken: "presented"})
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.want, w.Body)
+			}
+			if len(tokens.refresh) != 1 {
+				t.Errorf("%d tokens stored, want no new token", len(tokens.refresh))
+			}
+			if revoked := tokens.refresh[0].RevokedReason == authRepo.RevokeReasonReuse; revoked != tt.wantRevoked {
+				t.Errorf("revoked for reuse = %v, want %v", revoked, tt.wantRevoked)
+			}
+		})
+	}
+
+	svc := &AuthSvc{Tokens: &memoryTokens{}}
+	if w := post(t, svc.RefreshToken, auth.RefreshTokenDTO{RefreshToken: "unknown"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown token: status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
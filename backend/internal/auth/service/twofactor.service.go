@@ -0,0 +1,607 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// TwoFactorConfig controls TOTP two-factor authentication.
+type TwoFactorConfig struct {
+	Issuer        string        // Name shown in authenticator apps
+	RequiredRoles []string      // Roles that must enroll before they can log in
+	ChallengeTTL  time.Duration // How long a login may wait between the password and the second factor
+}
+
+// recoveryCodeCount is how many recovery codes are issued at a time.
+const recoveryCodeCount = 10
+
+// twoFactorRoles are the roles that can enroll in two-factor authentication.
+var twoFactorRoles = map[string]bool{
+	"lecturer": true,
+	"admin":    true,
+}
+
+// errInvalidTOTPCode is returned when a TOTP code does not match the secret.
+var errInvalidTOTPCode = errors.New("invalid two-factor code")
+
+// LoginSecondFactor handles POST /api/auth/login/2fa.
+// It completes a login that was answered with a challenge, using a TOTP code
+// or one of the user's recovery codes.
+func (svc *AuthSvc) LoginSecondFactor(ctx *gin.Context) {
+	var req auth.SecondFactorDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err)
+		return
+	}
+
+	account, claims, ok := svc.redeemChallenge(ctx, req.ChallengeToken, utils.PurposeTwoFactor)
+	if !ok {
+		return
+	}
+
+	twoFactor, err := svc.TwoFactors.GetTwoFactor(account.Role, account.ID)
+	if err != nil && !errors.Is(err, authRepo.ErrTwoFactorNotFound) {
+		responses.ApiFailure(ctx, "Failed to retrieve two-factor settings", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if twoFactor == nil || twoFactor.EnabledAt == nil {
+		responses.ApiFailure(ctx, "Two-factor authentication is no longer enabled. Please login again", http.StatusUnauthorized, nil)
+		return
+	}
+
+	if !svc.checkSecondFactor(ctx, *account, twoFactor, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	svc.finishChallenge(ctx, *account, claims, nil)
+}
+
+// LoginTwoFactorSetup handles POST /api/auth/login/2fa/setup.
+// Users whose role requires two-factor authentication but who have not
+// enrolled yet use their login challenge to get a TOTP secret.
+func (svc *AuthSvc) LoginTwoFactorSetup(ctx *gin.Context) {
+	var req auth.ChallengeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err)
+		return
+	}
+
+	account, _, ok := svc.redeemChallenge(ctx, req.ChallengeToken, utils.PurposeTwoFactorSetup)
+	if !ok {
+		return
+	}
+
+	setup, ok := svc.beginEnrollment(ctx, *account)
+	if !ok {
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Scan the QR code with an authenticator app, then confirm with a code", setup)
+}
+
+// LoginTwoFactorEnable handles POST /api/auth/login/2fa/enable.
+// It confirms the enrollment started by LoginTwoFactorSetup and completes the
+// login. The response includes the new recovery codes.
+func (svc *AuthSvc) LoginTwoFactorEnable(ctx *gin.Context) {
+	var req auth.SecondFactorDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err)
+		return
+	}
+
+	account, claims, ok := svc.redeemChallenge(ctx, req.ChallengeToken, utils.PurposeTwoFactorSetup)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, ok := svc.enableTwoFactor(ctx, *account, req.Code)
+	if !ok {
+		return
+	}
+
+	svc.finishChallenge(ctx, *account, claims, recoveryCodes)
+}
+
+// GetTwoFactorStatus handles GET /api/auth/2fa.
+func (svc *AuthSvc) GetTwoFactorStatus(ctx *gin.Context) {
+	account, ok := svc.twoFactorAccount(ctx)
+	if !ok {
+		return
+	}
+
+	twoFactor, err := svc.TwoFactors.GetTwoFactor(account.Role, account.ID)
+	if err != nil && !errors.Is(err, authRepo.ErrTwoFactorNotFound) {
+		responses.ApiFailure(ctx, "Failed to retrieve two-factor settings", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	status := &auth.TwoFactorStatusResponse{
+		Required: svc.twoFactorRequired(account.Role),
+	}
+	if twoFactor != nil {
+		status.Pending = twoFactor.EnabledAt == nil
+		if twoFactor.EnabledAt != nil {
+			enabledAt := twoFactor.EnabledAt.Format(time.RFC3339)
+			status.Enabled = true
+			status.EnabledAt = &enabledAt
+
+			remaining, err := svc.TwoFactors.CountUnusedRecoveryCodes(account.Role, account.ID)
+			if err != nil {
+				responses.ApiFailure(ctx, "Failed to count recovery codes", http.StatusInternalServerError, err.Error())
+				return
+			}
+			status.RecoveryCodesRemaining = remaining
+		}
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Two-factor status retrieved successfully", status)
+}
+
+// SetupTwoFactor handles POST /api/auth/2fa/setup.
+// It starts an enrollment, replacing any earlier one that was not confirmed.
+func (svc *AuthSvc) SetupTwoFactor(ctx *gin.Context) {
+	account, ok := svc.twoFactorAccount(ctx)
+	if !ok {
+		return
+	}
+
+	setup, ok := svc.beginEnrollment(ctx, *account)
+	if !ok {
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Scan the QR code with an authenticator app, then confirm with a code", setup)
+}
+
+// EnableTwoFactor handles POST /api/auth/2fa/enable.
+// It confirms a pending enrollment with a code from the authenticator app.
+func (svc *AuthSvc) EnableTwoFactor(ctx *gin.Context) {
+	var req auth.TwoFactorCodeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := svc.twoFactorAccount(ctx)
+	if !ok {
+		return
+	}
+
+	recoveryCodes, ok := svc.enableTwoFactor(ctx, *account, req.Code)
+	if !ok {
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Two-factor authentication enabled. Store the recovery codes somewhere safe", &auth.RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// DisableTwoFactor handles POST /api/auth/2fa/disable.
+// An enabled enrollment can only be removed with a current code or a recovery
+// code; a pending one is removed without. Roles that require two-factor
+// authentication cannot disable it.
+func (svc *AuthSvc) DisableTwoFactor(ctx *gin.Context) {
+	var req auth.TwoFactorCodeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := svc.twoFactorAccount(ctx)
+	if !ok {
+		return
+	}
+	if svc.twoFactorRequired(account.Role) {
+		responses.ApiFailure(ctx, "Two-factor authentication is required for your role and cannot be disabled", http.StatusForbidden, nil)
+		return
+	}
+
+	twoFactor, ok := svc.currentTwoFactor(ctx, *account)
+	if !ok {
+		return
+	}
+	if twoFactor.EnabledAt != nil && !svc.checkSecondFactor(ctx, *account, twoFactor, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	if err := svc.TwoFactors.DeleteTwoFactor(account.Role, account.ID); err != nil && !errors.Is(err, authRepo.ErrTwoFactorNotFound) {
+		responses.ApiFailure(ctx, "Failed to disable two-factor authentication", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if twoFactor.EnabledAt != nil {
+		svc.logTwoFactorEvent(ctx, "two_factor_disabled", *account)
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Two-factor authentication disabled", nil)
+}
+
+// RegenerateRecoveryCodes handles POST /api/auth/2fa/recovery-codes.
+// It replaces every recovery code, used or not, after checking a current code
+// or a recovery code.
+func (svc *AuthSvc) RegenerateRecoveryCodes(ctx *gin.Context) {
+	var req auth.TwoFactorCodeDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err)
+		return
+	}
+
+	account, ok := svc.twoFactorAccount(ctx)
+	if !ok {
+		return
+	}
+
+	twoFactor, ok := svc.currentTwoFactor(ctx, *account)
+	if !ok {
+		return
+	}
+	if twoFactor.EnabledAt == nil {
+		responses.ApiFailure(ctx, "Two-factor authentication is not enabled", http.StatusBadRequest, nil)
+		return
+	}
+	if !svc.checkSecondFactor(ctx, *account, twoFactor, req.Code, req.RecoveryCode) {
+		return
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate recovery codes", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if err := svc.TwoFactors.ReplaceRecoveryCodes(account.Role, account.ID, hashes); err != nil {
+		responses.ApiFailure(ctx, "Failed to store recovery codes", http.StatusInternalServerError, err.Error())
+		return
+	}
+	svc.logTwoFactorEvent(ctx, "recovery_codes_regenerated", *account)
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Recovery codes regenerated. Earlier codes no longer work", &auth.RecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// issueChallenge answers a login whose password was accepted with a challenge
+// token for the second step instead of a session.
+func (svc *AuthSvc) issueChallenge(ctx *gin.Context, account auth.Account, purpose string) {
+	token, claims, err := utils.GenerateChallengeToken(account.ID, account.Email, account.Role, purpose, svc.TwoFactor.ChallengeTTL)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate token", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	challenge := &auth.TwoFactorChallengeResponse{
+		ChallengeToken: token,
+		ExpiresAt:      claims.ExpiresAt.Time.Format(time.RFC3339),
+	}
+	if purpose == utils.PurposeTwoFactorSetup {
+		challenge.Message = "Two-factor authentication is required for your role. Set it up to continue"
+		challenge.TwoFactorSetupRequired = true
+	} else {
+		challenge.Message = "Enter a code from your authenticator app or a recovery code"
+		challenge.TwoFactorRequired = true
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Two-factor authentication required", challenge)
+}
+
+// redeemChallenge validates a challenge token and loads the account it was
+// issued for.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) redeemChallenge(ctx *gin.Context, token, purpose string) (*auth.Account, *utils.Claims, bool) {
+	claims, err := utils.ValidateChallengeToken(token, purpose)
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid or expired challenge. Please login again", http.StatusUnauthorized, nil)
+		return nil, nil, false
+	}
+
+	revoked, err := svc.Tokens.IsAccessTokenRevoked(claims.RegisteredClaims.ID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to check challenge", http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	if revoked {
+		responses.ApiFailure(ctx, "Invalid or expired challenge. Please login again", http.StatusUnauthorized, nil)
+		return nil, nil, false
+	}
+
+	account, err := svc.Repository.GetAccount(claims.Role, claims.ID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.ApiFailure(ctx, "Account no longer exists", http.StatusUnauthorized, nil)
+			return nil, nil, false
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve account", http.StatusInternalServerError, err.Error())
+		return nil, nil, false
+	}
+	if account.Suspended {
+		responses.ApiFailure(ctx, "Account suspended. Please contact an administrator", http.StatusForbidden, nil)
+		return nil, nil, false
+	}
+
+	return account, claims, true
+}
+
+// finishChallenge revokes a redeemed challenge so it cannot be replayed and
+// starts the session it was issued for.
+func (svc *AuthSvc) finishChallenge(ctx *gin.Context, account auth.Account, claims *utils.Claims, recoveryCodes []string) {
+	user, err := svc.loginUser(account)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve user", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := svc.Tokens.RevokeAccessToken(claims.RegisteredClaims.ID, account.Role, account.ID, claims.ExpiresAt.Time); err != nil {
+		responses.ApiFailure(ctx, "Failed to complete login", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	svc.startSession(ctx, account, user, recoveryCodes)
+}
+
+// loginUser loads the user returned on login for an account.
+func (svc *AuthSvc) loginUser(account auth.Account) (interface{}, error) {
+	switch account.Role {
+	case "student":
+		student, err := svc.Repository.GetStudentByID(account.ID)
+		if err != nil {
+			return nil, err
+		}
+		return studentLoginUser(student), nil
+	case "lecturer":
+		lecturer, err := svc.Repository.GetLecturerByID(account.ID)
+		if err != nil {
+			return nil, err
+		}
+		return lecturerLoginUser(lecturer), nil
+	case "admin":
+		admin, err := svc.Repository.GetAdminByID(account.ID)
+		if err != nil {
+			return nil, err
+		}
+		return adminLoginUser(admin), nil
+	}
+	return nil, errors.New("unknown role: " + account.Role)
+}
+
+// twoFactorAccount returns the caller's account for the self-service
+// endpoints, which are open to lecturers and admins only.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) twoFactorAccount(ctx *gin.Context) (*auth.Account, bool) {
+	userID, role, ok := callerIdentity(ctx)
+	if !ok {
+		return nil, false
+	}
+	if !twoFactorRoles[role] {
+		responses.ApiFailure(ctx, "Two-factor authentication is available to lecturers and admins only", http.StatusForbidden, nil)
+		return nil, false
+	}
+
+	account, err := svc.Repository.GetAccount(role, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			responses.ApiFailure(ctx, "User not found", http.StatusNotFound, nil)
+			return nil, false
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve account", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return account, true
+}
+
+// currentTwoFactor returns the account's enrollment, pending or enabled.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) currentTwoFactor(ctx *gin.Context, account auth.Account) (*entities.TwoFactor, bool) {
+	twoFactor, err := svc.TwoFactors.GetTwoFactor(account.Role, account.ID)
+	if err != nil {
+		if errors.Is(err, authRepo.ErrTwoFactorNotFound) {
+			responses.ApiFailure(ctx, "Two-factor authentication is not set up", http.StatusNotFound, nil)
+			return nil, false
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve two-factor settings", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	return twoFactor, true
+}
+
+// beginEnrollment generates a TOTP secret and stores it as a pending
+// enrollment until a first code confirms it.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) beginEnrollment(ctx *gin.Context, account auth.Account) (*auth.TwoFactorSetupResponse, bool) {
+	if !twoFactorRoles[account.Role] {
+		responses.ApiFailure(ctx, "Two-factor authentication is available to lecturers and admins only", http.StatusForbidden, nil)
+		return nil, false
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate two-factor secret", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	encrypted, err := utils.EncryptTOTPSecret(secret)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to secure two-factor secret", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	err = svc.TwoFactors.SavePendingTwoFactor(&entities.TwoFactor{
+		UserID: account.ID,
+		Role:   account.Role,
+		Secret: encrypted,
+	})
+	if err != nil {
+		if errors.Is(err, authRepo.ErrTwoFactorEnabled) {
+			responses.ApiFailure(ctx, "Two-factor authentication is already enabled", http.StatusConflict, nil)
+			return nil, false
+		}
+		responses.ApiFailure(ctx, "Failed to save two-factor setup", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	uri := utils.TOTPURI(svc.TwoFactor.Issuer, account.Email, secret)
+	qrCode, err := utils.GenerateQRCodePNG(uri, 256)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate QR code", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+
+	return &auth.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURL: uri,
+		QRCode:     qrCode,
+	}, true
+}
+
+// enableTwoFactor confirms the account's pending enrollment with a TOTP code
+// and returns its first recovery codes.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) enableTwoFactor(ctx *gin.Context, account auth.Account, code string) ([]string, bool) {
+	if code == "" {
+		responses.ApiFailure(ctx, "Code is required", http.StatusBadRequest, nil)
+		return nil, false
+	}
+
+	twoFactor, err := svc.TwoFactors.GetTwoFactor(account.Role, account.ID)
+	if err != nil {
+		if errors.Is(err, authRepo.ErrTwoFactorNotFound) {
+			responses.ApiFailure(ctx, "Start two-factor setup first", http.StatusBadRequest, nil)
+			return nil, false
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve two-factor settings", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if twoFactor.EnabledAt != nil {
+		responses.ApiFailure(ctx, "Two-factor authentication is already enabled", http.StatusConflict, nil)
+		return nil, false
+	}
+
+	if !svc.loginAllowed(ctx, account.Role, account.Email) {
+		return nil, false
+	}
+
+	secret, err := utils.DecryptTOTPSecret(twoFactor.Secret)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to read two-factor secret", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	step, valid := utils.VerifyTOTP(secret, code, time.Now())
+	if !valid {
+		svc.loginFailed(ctx, account.Role, account.Email, "invalid_second_factor")
+		responses.ApiFailure(ctx, "Invalid two-factor code", http.StatusUnauthorized, nil)
+		return nil, false
+	}
+
+	recoveryCodes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate recovery codes", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	if err := svc.TwoFactors.EnableTwoFactor(twoFactor, step, hashes); err != nil {
+		if errors.Is(err, authRepo.ErrTwoFactorEnabled) {
+			responses.ApiFailure(ctx, "Two-factor authentication is already enabled", http.StatusConflict, nil)
+			return nil, false
+		}
+		responses.ApiFailure(ctx, "Failed to enable two-factor authentication", http.StatusInternalServerError, err.Error())
+		return nil, false
+	}
+	svc.logTwoFactorEvent(ctx, "two_factor_enabled", account)
+
+	return recoveryCodes, true
+}
+
+// checkSecondFactor verifies a TOTP code or a recovery code against an enabled
+// enrollment. Wrong codes count as failed logins, so the account lockout also
+// limits code guessing.
+// It writes the failure response itself and returns false when the request should stop.
+func (svc *AuthSvc) checkSecondFactor(ctx *gin.Context, account auth.Account, twoFactor *entities.TwoFactor, code, recoveryCode string) bool {
+	if code == "" && recoveryCode == "" {
+		responses.ApiFailure(ctx, "Code or recovery code is required", http.StatusBadRequest, nil)
+		return false
+	}
+	if !svc.loginAllowed(ctx, account.Role, account.Email) {
+		return false
+	}
+
+	err := svc.verifySecondFactor(account, twoFactor, code, recoveryCode)
+	switch {
+	case err == nil:
+	case errors.Is(err, errInvalidTOTPCode), errors.Is(err, authRepo.ErrTOTPCodeReused), errors.Is(err, authRepo.ErrRecoveryCodeInvalid):
+		svc.loginFailed(ctx, account.Role, account.Email, "invalid_second_factor")
+		responses.ApiFailure(ctx, "Invalid two-factor code", http.StatusUnauthorized, nil)
+		return false
+	default:
+		responses.ApiFailure(ctx, "Failed to verify two-factor code", http.StatusInternalServerError, err.Error())
+		return false
+	}
+
+	if code == "" {
+		svc.logTwoFactorEvent(ctx, "recovery_code_used", account)
+	}
+	return true
+}
+
+// verifySecondFactor consumes a recovery code, or a TOTP code when no recovery
+// code is given. TOTP codes are rejected once their time step has been used.
+func (svc *AuthSvc) verifySecondFactor(account auth.Account, twoFactor *entities.TwoFactor, code, recoveryCode string) error {
+	if code == "" {
+		hash := utils.HashOpaqueToken(utils.NormalizeRecoveryCode(recoveryCode))
+		return svc.TwoFactors.UseRecoveryCode(account.Role, account.ID, hash)
+	}
+
+	secret, err := utils.DecryptTOTPSecret(twoFactor.Secret)
+	if err != nil {
+		return err
+	}
+	step, valid := utils.VerifyTOTP(secret, code, time.Now())
+	if !valid {
+		return errInvalidTOTPCode
+	}
+	if step <= twoFactor.LastUsedStep {
+		return authRepo.ErrTOTPCodeReused
+	}
+	return svc.TwoFactors.UseTOTPStep(twoFactor.ID, step)
+}
+
+// twoFactorRequired reports whether role must use two-factor authentication.
+func (svc *AuthSvc) twoFactorRequired(role string) bool {
+	for _, required := range svc.TwoFactor.RequiredRoles {
+		if required == role {
+			return true
+		}
+	}
+	return false
+}
+
+// logTwoFactorEvent records a change to an account's second factor.
+func (svc *AuthSvc) logTwoFactorEvent(ctx *gin.Context, event string, account auth.Account) {
+	logger.Security(event, map[string]interface{}{
+		"role":    account.Role,
+		"user_id": account.ID,
+		"email":   account.Email,
+		"ip":      ctx.ClientIP(),
+	})
+}
+
+// newRecoveryCodes generates a set of recovery codes and the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := utils.GenerateRecoveryCode()
+		if err != nil {
+			return nil, nil, err
+		}
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashOpaqueToken(utils.NormalizeRecoveryCode(code)))
+	}
+	return codes, hashes, nil
+}
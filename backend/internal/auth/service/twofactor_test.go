@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+)
+
+// stepRecorder is a TwoFactorRepoInterface that records the TOTP steps used.
+type stepRecorder struct {
+	authRepo.TwoFactorRepoInterface
+	used []int64
+}
+
+func (r *stepRecorder) UseTOTPStep(twoFactorID uint, step int64) error {
+	r.used = append(r.used, step)
+	return nil
+}
+
+func TestVerifySecondFactorRejectsReplayedSteps(t *testing.T) {
+	utils.UseTOTPEncryptionKey("test key")
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("GenerateTOTPSecret: %v", err)
+	}
+	sealed, err := utils.EncryptTOTPSecret(secret)
+	if err != nil {
+		t.Fatalf("EncryptTOTPSecret: %v", err)
+	}
+
+	current := time.Now().Unix() / 30
+	code, err := utils.TOTPCode(secret, current)
+	if err != nil {
+		t.Fatalf("TOTPCode: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		code         string
+		lastUsedStep int64
+		wantErr      error
+	}{
+		{"fresh code", code, current - 1, nil},
+		{"same step replayed", code, current, authRepo.ErrTOTPCodeReused},
+		{"later step already used", code, current + 1, authRepo.ErrTOTPCodeReused},
+		{"wrong code", "000000", 0, errInvalidTOTPCode},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.code == "000000" && tt.code == code {
+				t.Skip("the current code happens to be 000000")
+			}
+			repo := &stepRecorder{}
+			svc := &AuthSvc{TwoFactors: repo}
+			twoFactor := &entities.TwoFactor{Secret: sealed, LastUsedStep: tt.lastUsedStep}
+
+			err := svc.verifySecondFactor(auth.Account{ID: 1, Role: "student"}, twoFactor, tt.code, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("verifySecondFactor error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && (len(repo.used) != 1 || repo.used[0] <= tt.lastUsedStep) {
+				t.Errorf("used steps = %v, want one step after %d", repo.used, tt.lastUsedStep)
+			}
+			if tt.wantErr != nil && len(repo.used) != 0 {
+				t.Errorf("used steps = %v, want none", repo.used)
+			}
+		})
+	}
+}
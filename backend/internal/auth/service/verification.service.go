@@ -0,0 +1,244 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/mailer"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// emailNotVerifiedMessage is returned when an unverified account tries to log in.
+const emailNotVerifiedMessage = "Email not verified. Check your inbox for the verification email or request a new one"
+
+// RegistrationConfig controls self-registration.
+type RegistrationConfig struct {
+	RequireEmailVerification bool     // New accounts cannot log in until their email is verified
+	LecturerEmailDomains     []string // Lecturer emails must use one of these domains; empty allows any
+}
+
+// EmailVerificationConfig controls email verification messages.
+type EmailVerificationConfig struct {
+	TokenTTL          time.Duration
+	URL               string        // Frontend page that accepts the token as ?token=; optional
+	ResendInterval    time.Duration // Minimum time between verification emails to one account
+	MaxResendsPerHour int           // Verification emails one account can receive per hour
+}
+
+// VerifyEmail handles POST /api/auth/verify-email.
+// Redeeming a registration token lets the account log in; redeeming the token
+// sent to a new address makes it the account's email.
+func (svc *AuthSvc) VerifyEmail(ctx *gin.Context) {
+	var req auth.VerifyEmailDTO
+	if e := ctx.ShouldBindJSON(&req); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+		return
+	}
+
+	verification, err := svc.Tokens.GetEmailVerificationByHash(utils.HashOpaqueToken(req.Token))
+	if err != nil {
+		if errors.Is(err, authRepo.ErrEmailVerificationNotFound) {
+			responses.ApiFailure(ctx, "Invalid or expired verification token", http.StatusBadRequest, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve verification token", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if verification.UsedAt != nil || time.Now().After(verification.ExpiresAt) {
+		responses.ApiFailure(ctx, "Invalid or expired verification token", http.StatusBadRequest, nil)
+		return
+	}
+
+	if err := svc.Tokens.ConfirmEmailVerification(verification); err != nil {
+		switch {
+		case errors.Is(err, authRepo.ErrEmailVerificationInvalid):
+			responses.ApiFailure(ctx, "Invalid or expired verification token", http.StatusBadRequest, nil)
+		case errors.Is(err, authRepo.ErrEmailTaken):
+			responses.ApiFailure(ctx, "Email address is already in use", http.StatusConflict, nil)
+		default:
+			responses.ApiFailure(ctx, "Failed to verify email", http.StatusInternalServerError, err.Error())
+		}
+		return
+	}
+
+	message := "Email verified successfully. You can now login."
+	if verification.Purpose == entities.EmailVerificationChange {
+		message = "Email verified successfully"
+		logger.Infof("email changed for %s %d", verification.Role, verification.UserID)
+	}
+	responses.ApiSuccess(ctx, http.StatusOK, message, map[string]string{
+		"email": verification.Email,
+	})
+}
+
+// ResendVerification handles POST /api/auth/resend-verification.
+// It emails a new registration token to every matching unverified account. The
+// response is the same whether or not the email is registered, and accounts
+// that were sent a token recently are skipped rather than refused, so the
+// endpoint cannot be used to discover accounts.
+func (svc *AuthSvc) ResendVerification(ctx *gin.Context) {
+	var req auth.ResendVerificationDTO
+	if e := ctx.ShouldBindJSON(&req); e != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, e.Error())
+		return
+	}
+
+	accounts, err := svc.unverifiedAccounts(req.Email, req.Role)
+	if err != nil {
+		responses.ApiFailure(ctx, "Database error", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	for _, account := range accounts {
+		throttled, err := svc.verificationThrottled(account)
+		if err != nil {
+			logger.Errorf("failed to check verification throttle for %s %d: %v", account.Role, account.ID, err)
+			continue
+		}
+		if throttled {
+			logger.Infof("verification resend throttled for %s %d", account.Role, account.ID)
+			continue
+		}
+		if err := svc.sendEmailVerification(ctx, account, entities.EmailVerificationRegistration); err != nil {
+			logger.Errorf("failed to send verification email to %s %d: %v", account.Role, account.ID, err)
+		}
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "If an unverified account exists for that email, a verification email has been sent", nil)
+}
+
+// unverifiedAccounts returns the accounts registered with email that still
+// need to verify it, limited to role when it is set.
+func (svc *AuthSvc) unverifiedAccounts(email, role string) ([]auth.Account, error) {
+	var accounts []auth.Account
+
+	if role == "" || role == "student" {
+		student, err := svc.Repository.GetStudentByEmailWithPassword(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if student != nil && student.RequiresEmailVerification && student.EmailVerifiedAt == nil {
+			accounts = append(accounts, auth.Account{ID: int(student.ID), Email: student.Identity.Email, Role: "student"})
+		}
+	}
+
+	if role == "" || role == "lecturer" {
+		lecturer, err := svc.Repository.GetLecturerByEmailWithPassword(email)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if lecturer != nil && lecturer.RequiresEmailVerification && lecturer.EmailVerifiedAt == nil {
+			accounts = append(accounts, auth.Account{ID: int(lecturer.ID), Email: lecturer.Identity.Email, Role: "lecturer"})
+		}
+	}
+
+	return accounts, nil
+}
+
+// verificationThrottled reports whether account was sent a registration token
+// within the resend interval or has reached the hourly limit.
+func (svc *AuthSvc) verificationThrottled(account auth.Account) (bool, error) {
+	recent, err := svc.Tokens.EmailVerificationsSince(account.Role, account.ID, entities.EmailVerificationRegistration, time.Now().Add(-time.Hour))
+	if err != nil {
+		return false, err
+	}
+	if len(recent) == 0 {
+		return false, nil
+	}
+	if svc.EmailVerification.MaxResendsPerHour > 0 && len(recent) >= svc.EmailVerification.MaxResendsPerHour {
+		return true, nil
+	}
+	return time.Since(recent[0].CreatedAt) < svc.EmailVerification.ResendInterval, nil
+}
+
+// sendEmailVerification issues a verification token for the address in
+// account.Email and emails it there.
+func (svc *AuthSvc) sendEmailVerification(ctx *gin.Context, account auth.Account, purpose string) error {
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return err
+	}
+
+	if err := svc.Tokens.CreateEmailVerification(&entities.EmailVerification{
+		TokenHash: utils.HashOpaqueToken(token),
+		UserID:    account.ID,
+		Role:      account.Role,
+		Email:     account.Email,
+		Purpose:   purpose,
+		ExpiresAt: time.Now().Add(svc.EmailVerification.TokenTTL),
+	}); err != nil {
+		return err
+	}
+
+	subject := "Verify your email address"
+	body := fmt.Sprintf("Welcome! Please verify the email address for your new %s account.\n\n", account.Role)
+	if purpose == entities.EmailVerificationChange {
+		subject = "Confirm your new email address"
+		body = fmt.Sprintf("We received a request to use this address for your %s account.\n\n", account.Role)
+	}
+	if link := svc.emailVerificationLink(token); link != "" {
+		body += fmt.Sprintf("Verify your email: %s\n\n", link)
+	}
+	body += fmt.Sprintf("Verification token: %s\n\n", token)
+	body += fmt.Sprintf("The token expires in %d hours and can only be used once. If you did not request this, you can ignore this email.\n",
+		int(svc.EmailVerification.TokenTTL.Hours()))
+
+	return svc.Mailer.Send(ctx.Request.Context(), mailer.Message{
+		To:      account.Email,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// emailVerificationLink appends token to the configured verification page URL.
+// It returns an empty string when no URL is configured.
+func (svc *AuthSvc) emailVerificationLink(token string) string {
+	if svc.EmailVerification.URL == "" {
+		return ""
+	}
+	link, err := url.Parse(svc.EmailVerification.URL)
+	if err != nil {
+		logger.Errorf("invalid email verification URL %q: %v", svc.EmailVerification.URL, err)
+		return ""
+	}
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+	return link.String()
+}
+
+// lecturerEmailAllowed reports whether email uses one of the configured
+// lecturer domains. Any email is allowed when no domains are configured.
+func (svc *AuthSvc) lecturerEmailAllowed(email string) bool {
+	if len(svc.Registration.LecturerEmailDomains) == 0 {
+		return true
+	}
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(strings.TrimSpace(email[at+1:]))
+	for _, allowed := range svc.Registration.LecturerEmailDomains {
+		if domain == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// emailVerified reports whether an account may log in as far as email
+// verification is concerned.
+func emailVerified(requiresVerification bool, verifiedAt *time.Time) bool {
+	return !requiresVerification || verifiedAt != nil
+}
@@ -0,0 +1,204 @@
+package auth
+
+import (
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	auth "github.com/Dom-HTG/attendance-management-system/internal/auth/domain"
+	authRepo "github.com/Dom-HTG/attendance-management-system/internal/auth/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"gorm.io/gorm"
+)
+
+// memoryVerifications is an in-memory store of email verification tokens.
+type memoryVerifications struct {
+	authRepo.TokenRepoInterface
+	verifications []*entities.EmailVerification
+	takenEmails   map[string]bool // Addresses another identity already uses
+}
+
+func (m *memoryVerifications) CreateEmailVerification(verification *entities.EmailVerification) error {
+	verification.ID = uint(len(m.verifications) + 1)
+	if verification.CreatedAt.IsZero() {
+		verification.CreatedAt = time.Now()
+	}
+	m.verifications = append(m.verifications, verification)
+	return nil
+}
+
+func (m *memoryVerifications) GetEmailVerificationByHash(tokenHash string) (*entities.EmailVerification, error) {
+	for _, verification := range m.verifications {
+		if verification.TokenHash == tokenHash {
+			copied := *verification
+			return &copied, nil
+		}
+	}
+	return nil, authRepo.ErrEmailVerificationNotFound
+}
+
+func (m *memoryVerifications) EmailVerificationsSince(role string, userID int, purpose string, since time.Time) ([]entities.EmailVerification, error) {
+	var recent []entities.EmailVerification
+	for _, verification := range m.verifications {
+		if verification.Role == role && verification.UserID == userID && verification.Purpose == purpose && !verification.CreatedAt.Before(since) {
+			recent = append(recent, *verification)
+		}
+	}
+	sort.Slice(recent, func(i, j int) bool { return recent[i].CreatedAt.After(recent[j].CreatedAt) })
+	return recent, nil
+}
+
+func (m *memoryVerifications) ConfirmEmailVerification(verification *entities.EmailVerification) error {
+	stored := m.verifications[verification.ID-1]
+	if stored.UsedAt != nil {
+		return authRepo.ErrEmailVerificationInvalid
+	}
+	if m.takenEmails[stored.Email] {
+		return authRepo.ErrEmailTaken
+	}
+	now := time.Now()
+	stored.UsedAt = &now
+	return nil
+}
+
+func TestVerificationThrottled(t *testing.T) {
+	now := time.Now()
+	ago := func(d time.Duration) time.Time { return now.Add(-d) }
+
+	tests := []struct {
+		name string
+		sent []time.Time
+		want bool
+	}{
+		{"never sent", nil, false},
+		{"sent within interval", []time.Time{ago(30 * time.Second)}, true},
+		{"sent before interval", []time.Time{ago(2 * time.Minute)}, false},
+		{"sent over an hour ago", []time.Time{ago(61 * time.Minute), ago(62 * time.Minute), ago(63 * time.Minute)}, false},
+		{"under hourly limit", []time.Time{ago(10 * time.Minute), ago(20 * time.Minute)}, false},
+		{"at hourly limit", []time.Time{ago(10 * time.Minute), ago(20 * time.Minute), ago(30 * time.Minute)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens := &memoryVerifications{}
+			for _, createdAt := range tt.sent {
+				_ = tokens.CreateEmailVerification(&entities.EmailVerification{
+					Model: gorm.Model{CreatedAt: createdAt}, UserID: 7, Role: "student", Purpose: entities.EmailVerificationRegistration,
+				})
+			}
+			// Email change tokens and other accounts do not count
+			_ = tokens.CreateEmailVerification(&entities.EmailVerification{UserID: 7, Role: "student", Purpose: entities.EmailVerificationChange})
+			_ = tokens.CreateEmailVerification(&entities.EmailVerification{UserID: 7, Role: "lecturer", Purpose: entities.EmailVerificationRegistration})
+
+			svc := &AuthSvc{Tokens: tokens, EmailVerification: EmailVerificationConfig{ResendInterval: time.Minute, MaxResendsPerHour: 3}}
+			got, err := svc.verificationThrottled(auth.Account{ID: 7, Role: "student"})
+			if err != nil {
+				t.Fatalf("verificationThrottled: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("verificationThrottled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResendVerification(t *testing.T) {
+	verifiedAt := time.Now().Add(-24 * time.Hour)
+	directory := &fakeDirectory{
+		students: map[string]*entities.Student{
+			"ada@uni.edu": {Model: gorm.Model{ID: 7}, Identity: &entities.Identity{Email: "ada@uni.edu"}, RequiresEmailVerification: true},
+		},
+		lecturers: map[string]*entities.Lecturer{
+			"ada@uni.edu": {Model: gorm.Model{ID: 3}, Identity: &entities.Identity{Email: "ada@uni.edu"}, RequiresEmailVerification: true, EmailVerifiedAt: &verifiedAt},
+		},
+	}
+	tokens := &memoryVerifications{}
+	mail := &outbox{}
+	svc := &AuthSvc{Repository: directory, Tokens: tokens, Mailer: mail, EmailVerification: EmailVerificationConfig{
+		TokenTTL: 24 * time.Hour, ResendInterval: time.Minute, MaxResendsPerHour: 3,
+	}}
+
+	for _, req := range []auth.ResendVerificationDTO{
+		{Email: "ada@uni.edu"},
+		{Email: "ada@uni.edu"}, // Inside the resend interval
+		{Email: "nobody@uni.edu"},
+	} {
+		if w := post(t, svc.ResendVerification, req); w.Code != http.StatusOK {
+			t.Errorf("resend to %s: status = %d, want %d", req.Email, w.Code, http.StatusOK)
+		}
+	}
+
+	if len(mail.sent) != 1 || len(tokens.verifications) != 1 {
+		t.Fatalf("%d emails and %d tokens, want one for the unverified student", len(mail.sent), len(tokens.verifications))
+	}
+	verification := tokens.verifications[0]
+	if verification.Role != "student" || verification.UserID != 7 || verification.Purpose != entities.EmailVerificationRegistration {
+		t.Errorf("verification = %+v, want a registration token for student 7", verification)
+	}
+	if utils.HashOpaqueToken(mail.tokenIn(t, "Verification token: ")) != verification.TokenHash {
+		t.Error("email does not carry the stored token")
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	tokens := &memoryVerifications{takenEmails: map[string]bool{"taken@uni.edu": true}}
+	issue := func(email, purpose string, expiresAt time.Time) string {
+		token, _ := utils.GenerateOpaqueToken()
+		_ = tokens.CreateEmailVerification(&entities.EmailVerification{
+			TokenHash: utils.HashOpaqueToken(token), UserID: 7, Role: "student", Email: email, Purpose: purpose, ExpiresAt: expiresAt,
+		})
+		return token
+	}
+	later := time.Now().Add(time.Hour)
+	registration := issue("ada@uni.edu", entities.EmailVerificationRegistration, later)
+	expired := issue("ada@uni.edu", entities.EmailVerificationRegistration, time.Now().Add(-time.Second))
+	taken := issue("taken@uni.edu", entities.EmailVerificationChange, later)
+	svc := &AuthSvc{Tokens: tokens}
+
+	steps := []struct {
+		name  string
+		token string
+		want  int
+	}{
+		{"unknown token", "not-a-token", http.StatusBadRequest},
+		{"expired token", expired, http.StatusBadRequest},
+		{"registration token", registration, http.StatusOK},
+		{"token used twice", registration, http.StatusBadRequest},
+		{"new address in use", taken, http.StatusConflict},
+	}
+	for _, step := range steps {
+		w := post(t, svc.VerifyEmail, auth.VerifyEmailDTO{Token: step.token})
+		if w.Code != step.want {
+			t.Errorf("%s: status = %d, want %d: %s", step.name, w.Code, step.want, w.Body)
+		}
+	}
+}
+
+func TestLecturerEmailAllowed(t *testing.T) {
+	tests := []struct {
+		name    string
+		domains []string
+		email   string
+		want    bool
+	}{
+		{"no domains configured", nil, "ada@gmail.com", true},
+		{"allowed domain", []string{"uni.edu"}, "ada@uni.edu", true},
+		{"domain case ignored", []string{"uni.edu"}, "ada@UNI.edu", true},
+		{"second allowed domain", []string{"uni.edu", "staff.uni.edu"}, "ada@staff.uni.edu", true},
+		{"other domain", []string{"uni.edu"}, "ada@gmail.com", false},
+		{"subdomain not implied", []string{"uni.edu"}, "ada@evil.uni.edu", false},
+		{"suffix is not a domain", []string{"uni.edu"}, "ada@notuni.edu", false},
+		{"no at sign", []string{"uni.edu"}, "uni.edu", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &AuthSvc{Registration: RegistrationConfig{LecturerEmailDomains: tt.domains}}
+			if got := svc.lecturerEmailAllowed(tt.email); got != tt.want {
+				t.Errorf("lecturerEmailAllowed(%q) = %v, want %v", tt.email, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package calendar
+
+// DateLayout is the format of every calendar date in requests and responses.
+const DateLayout = "2006-01-02"
+
+// Request DTOs
+
+// CreateSessionDTO represents the request to create an academic session.
+type CreateSessionDTO struct {
+	Name      string `json:"name" binding:"required"`       // e.g. 2024/2025
+	StartDate string `json:"start_date" binding:"required"` // YYYY-MM-DD
+	EndDate   string `json:"end_date" binding:"required"`   // YYYY-MM-DD, inclusive
+}
+
+// UpdateSessionDTO represents the request to update an academic session.
+// Only fields that are provided are changed.
+type UpdateSessionDTO struct {
+	Name      *string `json:"name"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+}
+
+// CreateSemesterDTO represents the request to add a semester to a session.
+// The exam period is optional but its dates must be given together.
+type CreateSemesterDTO struct {
+	Name          string  `json:"name" binding:"required"`
+	StartDate     string  `json:"start_date" binding:"required"`
+	EndDate       string  `json:"end_date" binding:"required"`
+	TeachingWeeks int     `json:"teaching_weeks" binding:"required,min=1,max=52"`
+	ExamStartDate *string `json:"exam_start_date"`
+	ExamEndDate   *string `json:"exam_end_date"`
+}
+
+// UpdateSemesterDTO represents the request to update a semester.
+// Only fields that are provided are changed; empty exam dates clear the exam
+// period.
+type UpdateSemesterDTO struct {
+	Name          *string `json:"name"`
+	StartDate     *string `json:"start_date"`
+	EndDate       *string `json:"end_date"`
+	TeachingWeeks *int    `json:"teaching_weeks" binding:"omitempty,min=1,max=52"`
+	ExamStartDate *string `json:"exam_start_date"`
+	ExamEndDate   *string `json:"exam_end_date"`
+}
+
+// CreateHolidayDTO represents the request to add a public holiday or break.
+// A one-day holiday may leave out end_date.
+type CreateHolidayDTO struct {
+	Name      string `json:"name" binding:"required"`
+	StartDate string `json:"start_date" binding:"required"`
+	EndDate   string `json:"end_date"`
+}
+
+// UpdateHolidayDTO represents the request to update a holiday.
+// Only fields that are provided are changed.
+type UpdateHolidayDTO struct {
+	Name      *string `json:"name"`
+	StartDate *string `json:"start_date"`
+	EndDate   *string `json:"end_date"`
+}
+
+// Response DTOs
+
+// SessionResponse represents an academic session and its semesters.
+type SessionResponse struct {
+	ID        int                `json:"id"`
+	Name      string             `json:"name"`
+	StartDate string             `json:"start_date"`
+	EndDate   string             `json:"end_date"`
+	Semesters []SemesterResponse `json:"semesters"`
+	CreatedAt string             `json:"created_at"`
+	UpdatedAt string             `json:"updated_at"`
+}
+
+// SemesterResponse represents a semester.
+type SemesterResponse struct {
+	ID              int     `json:"id"`
+	SessionID       int     `json:"session_id"`
+	Name            string  `json:"name"`
+	StartDate       string  `json:"start_date"`
+	EndDate         string  `json:"end_date"`
+	TeachingWeeks   int     `json:"teaching_weeks"`
+	TeachingEndDate string  `json:"teaching_end_date"` // Last day of the final teaching week
+	ExamStartDate   *string `json:"exam_start_date"`
+	ExamEndDate     *string `json:"exam_end_date"`
+	CreatedAt       string  `json:"created_at"`
+	UpdatedAt       string  `json:"updated_at"`
+}
+
+// HolidayResponse represents a public holiday or break.
+type HolidayResponse struct {
+	ID        int    `json:"id"`
+	Name      string `json:"name"`
+	StartDate string `json:"start_date"`
+	EndDate   string `json:"end_date"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+// FeedResponse describes the caller's iCalendar feed. The URLs are only
+// returned when the feed is created, since the token is not stored.
+type FeedResponse struct {
+	Enabled    bool    `json:"enabled"`
+	URL        string  `json:"url,omitempty"`
+	WebcalURL  string  `json:"webcal_url,omitempty"` // Same feed for one-tap subscription on phones
+	CreatedAt  *string `json:"created_at"`
+	LastUsedAt *string `json:"last_used_at"`
+}
@@ -0,0 +1,307 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	calendar "github.com/Dom-HTG/attendance-management-system/internal/calendar/domain"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSessionNotFound is returned when an academic session lookup matches no rows.
+	ErrSessionNotFound = errors.New("academic session not found")
+	// ErrSemesterNotFound is returned when a semester lookup matches no rows.
+	ErrSemesterNotFound = errors.New("semester not found")
+	// ErrHolidayNotFound is returned when a holiday lookup matches no rows.
+	ErrHolidayNotFound = errors.New("holiday not found")
+)
+
+// CalendarRepoInterface defines the repository interface for the academic calendar.
+type CalendarRepoInterface interface {
+	// Academic sessions
+	CreateSession(session *entities.AcademicSession) error
+	GetSessionByID(sessionID int) (*entities.AcademicSession, error)
+	GetSessionByName(name string) (*entities.AcademicSession, error)
+	ListSessions() ([]*entities.AcademicSession, error)
+	UpdateSession(session *entities.AcademicSession) error
+	DeleteSession(sessionID int) error
+	FindOverlappingSession(start, end time.Time, exceptID int) (*entities.AcademicSession, error)
+
+	// Semesters
+	CreateSemester(semester *entities.Semester) error
+	GetSemesterByID(semesterID int) (*entities.Semester, error)
+	GetSemesterOn(day time.Time) (*entities.Semester, error)
+	UpdateSemester(semester *entities.Semester) error
+	DeleteSemester(semesterID int) error
+	FindOverlappingSemester(start, end time.Time, exceptID int) (*entities.Semester, error)
+
+	// Holidays
+	CreateHoliday(holiday *entities.Holiday) error
+	GetHolidayByID(holidayID int) (*entities.Holiday, error)
+	ListHolidays(from, to *time.Time) ([]*entities.Holiday, error)
+	UpdateHoliday(holiday *entities.Holiday) error
+	DeleteHoliday(holidayID int) error
+}
+
+// CalendarRepo implements the CalendarRepoInterface.
+type CalendarRepo struct {
+	db *gorm.DB
+}
+
+// NewCalendarRepo returns a new instance of CalendarRepo.
+func NewCalendarRepo(db *gorm.DB) *CalendarRepo {
+	return &CalendarRepo{
+		db: db,
+	}
+}
+
+// semesterOfEvent selects the semester containing an event's start date. It is
+// correlated with the events table being updated.
+const semesterOfEvent = `(
+	SELECT s.id FROM semesters s
+	WHERE s.deleted_at IS NULL AND events.start_time::date BETWEEN s.start_date AND s.end_date
+	ORDER BY s.start_date LIMIT 1)`
+
+// TagEvent sets the semester of a newly created or rescheduled event from its
+// start time, leaving it untagged when no semester contains it.
+func TagEvent(tx *gorm.DB, event *entities.Event) error {
+	var tagged struct{ SemesterID *int }
+	err := tx.Raw("UPDATE events SET semester_id = "+semesterOfEvent+" WHERE id = ? RETURNING semester_id", event.ID).Scan(&tagged).Error
+	if err != nil {
+		return errors.New("failed to tag event with its semester: " + err.Error())
+	}
+	event.SemesterID = tagged.SemesterID
+	return nil
+}
+
+// retagEvents recomputes the semester of every event matching the condition.
+func retagEvents(tx *gorm.DB, condition string, args ...interface{}) error {
+	if err := tx.Exec("UPDATE events SET semester_id = "+semesterOfEvent+" WHERE "+condition, args...).Error; err != nil {
+		return errors.New("failed to tag events with their semester: " + err.Error())
+	}
+	return nil
+}
+
+// day formats a date for comparison with date columns.
+func day(t time.Time) string {
+	return t.Format(calendar.DateLayout)
+}
+
+// CreateSession creates a new academic session.
+func (cr *CalendarRepo) CreateSession(session *entities.AcademicSession) error {
+	if err := cr.db.Create(session).Error; err != nil {
+		return errors.New("failed to create academic session: " + err.Error())
+	}
+	return nil
+}
+
+// GetSessionByID retrieves an academic session and its semesters by ID.
+func (cr *CalendarRepo) GetSessionByID(sessionID int) (*entities.AcademicSession, error) {
+	var session entities.AcademicSession
+	if err := cr.db.Preload("Semesters", orderByStart).First(&session, sessionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, errors.New("failed to retrieve academic session: " + err.Error())
+	}
+	return &session, nil
+}
+
+// GetSessionByName retrieves an academic session and its semesters by name,
+// ignoring surrounding whitespace.
+func (cr *CalendarRepo) GetSessionByName(name string) (*entities.AcademicSession, error) {
+	var session entities.AcademicSession
+	if err := cr.db.Preload("Semesters", orderByStart).Where("name = ?", strings.TrimSpace(name)).First(&session).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionNotFound
+		}
+		return nil, errors.New("failed to retrieve academic session: " + err.Error())
+	}
+	return &session, nil
+}
+
+// ListSessions retrieves every academic session with its semesters, newest first.
+func (cr *CalendarRepo) ListSessions() ([]*entities.AcademicSession, error) {
+	var sessions []*entities.AcademicSession
+	if err := cr.db.Preload("Semesters", orderByStart).Order("start_date DESC").Find(&sessions).Error; err != nil {
+		return nil, errors.New("failed to retrieve academic sessions: " + err.Error())
+	}
+	return sessions, nil
+}
+
+// UpdateSession saves changes to an existing academic session.
+func (cr *CalendarRepo) UpdateSession(session *entities.AcademicSession) error {
+	if err := cr.db.Omit("Semesters").Save(session).Error; err != nil {
+		return errors.New("failed to update academic session: " + err.Error())
+	}
+	return nil
+}
+
+// DeleteSession permanently deletes an academic session and its semesters so
+// the name can be reused. Events in those semesters are untagged.
+func (cr *CalendarRepo) DeleteSession(sessionID int) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		var semesterIDs []int
+		if err := tx.Model(&entities.Semester{}).Where("session_id = ?", sessionID).Pluck("id", &semesterIDs).Error; err != nil {
+			return errors.New("failed to retrieve semesters: " + err.Error())
+		}
+		if err := tx.Unscoped().Where("session_id = ?", sessionID).Delete(&entities.Semester{}).Error; err != nil {
+			return errors.New("failed to delete semesters: " + err.Error())
+		}
+		if len(semesterIDs) > 0 {
+			if err := retagEvents(tx, "semester_id IN ?", semesterIDs); err != nil {
+				return err
+			}
+		}
+		if err := tx.Unscoped().Delete(&entities.AcademicSession{}, sessionID).Error; err != nil {
+			return errors.New("failed to delete academic session: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// FindOverlappingSession returns a session other than exceptID whose dates
+// overlap start to end, or nil when there is none.
+func (cr *CalendarRepo) FindOverlappingSession(start, end time.Time, exceptID int) (*entities.AcademicSession, error) {
+	var sessions []*entities.AcademicSession
+	if err := cr.db.Where("start_date <= ? AND end_date >= ? AND id <> ?", day(end), day(start), exceptID).
+		Order("start_date").Limit(1).Find(&sessions).Error; err != nil {
+		return nil, errors.New("failed to check academic session dates: " + err.Error())
+	}
+	if len(sessions) == 0 {
+		return nil, nil
+	}
+	return sessions[0], nil
+}
+
+// CreateSemester creates a new semester and tags the events it contains.
+func (cr *CalendarRepo) CreateSemester(semester *entities.Semester) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(semester).Error; err != nil {
+			return errors.New("failed to create semester: " + err.Error())
+		}
+		return retagEvents(tx, "start_time::date BETWEEN ? AND ?", day(semester.StartDate), day(semester.EndDate))
+	})
+}
+
+// GetSemesterByID retrieves a semester by ID.
+func (cr *CalendarRepo) GetSemesterByID(semesterID int) (*entities.Semester, error) {
+	var semester entities.Semester
+	if err := cr.db.First(&semester, semesterID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		return nil, errors.New("failed to retrieve semester: " + err.Error())
+	}
+	return &semester, nil
+}
+
+// GetSemesterOn retrieves the semester containing the given date.
+func (cr *CalendarRepo) GetSemesterOn(date time.Time) (*entities.Semester, error) {
+	var semester entities.Semester
+	if err := cr.db.Where("start_date <= ? AND end_date >= ?", day(date), day(date)).First(&semester).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSemesterNotFound
+		}
+		return nil, errors.New("failed to retrieve semester: " + err.Error())
+	}
+	return &semester, nil
+}
+
+// UpdateSemester saves changes to an existing semester and retags the events
+// it used to contain and now contains.
+func (cr *CalendarRepo) UpdateSemester(semester *entities.Semester) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(semester).Error; err != nil {
+			return errors.New("failed to update semester: " + err.Error())
+		}
+		return retagEvents(tx, "semester_id = ? OR start_time::date BETWEEN ? AND ?",
+			semester.ID, day(semester.StartDate), day(semester.EndDate))
+	})
+}
+
+// DeleteSemester permanently deletes a semester and untags its events.
+func (cr *CalendarRepo) DeleteSemester(semesterID int) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&entities.Semester{}, semesterID).Error; err != nil {
+			return errors.New("failed to delete semester: " + err.Error())
+		}
+		return retagEvents(tx, "semester_id = ?", semesterID)
+	})
+}
+
+// FindOverlappingSemester returns a semester other than exceptID whose dates
+// overlap start to end, or nil when there is none.
+func (cr *CalendarRepo) FindOverlappingSemester(start, end time.Time, exceptID int) (*entities.Semester, error) {
+	var semesters []*entities.Semester
+	if err := cr.db.Where("start_date <= ? AND end_date >= ? AND id <> ?", day(end), day(start), exceptID).
+		Order("start_date").Limit(1).Find(&semesters).Error; err != nil {
+		return nil, errors.New("failed to check semester dates: " + err.Error())
+	}
+	if len(semesters) == 0 {
+		return nil, nil
+	}
+	return semesters[0], nil
+}
+
+// CreateHoliday creates a new holiday.
+func (cr *CalendarRepo) CreateHoliday(holiday *entities.Holiday) error {
+	if err := cr.db.Create(holiday).Error; err != nil {
+		return errors.New("failed to create holiday: " + err.Error())
+	}
+	return nil
+}
+
+// GetHolidayByID retrieves a holiday by ID.
+func (cr *CalendarRepo) GetHolidayByID(holidayID int) (*entities.Holiday, error) {
+	var holiday entities.Holiday
+	if err := cr.db.First(&holiday, holidayID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrHolidayNotFound
+		}
+		return nil, errors.New("failed to retrieve holiday: " + err.Error())
+	}
+	return &holiday, nil
+}
+
+// ListHolidays retrieves the holidays overlapping from to to, in date order.
+// A nil bound leaves that side open.
+func (cr *CalendarRepo) ListHolidays(from, to *time.Time) ([]*entities.Holiday, error) {
+	query := cr.db.Order("start_date ASC")
+	if from != nil {
+		query = query.Where("end_date >= ?", day(*from))
+	}
+	if to != nil {
+		query = query.Where("start_date <= ?", day(*to))
+	}
+
+	var holidays []*entities.Holiday
+	if err := query.Find(&holidays).Error; err != nil {
+		return nil, errors.New("failed to retrieve holidays: " + err.Error())
+	}
+	return holidays, nil
+}
+
+// UpdateHoliday saves changes to an existing holiday.
+func (cr *CalendarRepo) UpdateHoliday(holiday *entities.Holiday) error {
+	if err := cr.db.Save(holiday).Error; err != nil {
+		return errors.New("failed to update holiday: " + err.Error())
+	}
+	return nil
+}
+
+// DeleteHoliday permanently deletes a holiday.
+func (cr *CalendarRepo) DeleteHoliday(holidayID int) error {
+	if err := cr.db.Unscoped().Delete(&entities.Holiday{}, holidayID).Error; err != nil {
+		return errors.New("failed to delete holiday: " + err.Error())
+	}
+	return nil
+}
+
+// orderByStart orders preloaded semesters by start date.
+func orderByStart(db *gorm.DB) *gorm.DB {
+	return db.Order("start_date ASC")
+}
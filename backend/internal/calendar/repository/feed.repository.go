@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+)
+
+// ErrFeedNotFound is returned when a calendar feed lookup matches no rows.
+var ErrFeedNotFound = errors.New("calendar feed not found")
+
+// FeedRepoInterface defines the repository interface for iCalendar feeds.
+type FeedRepoInterface interface {
+	GetFeed(role string, userID int) (*entities.CalendarFeed, error)
+	GetFeedByHash(tokenHash string) (*entities.CalendarFeed, error)
+	ReplaceFeed(feed *entities.CalendarFeed) error
+	DeleteFeed(role string, userID int) error
+	TouchFeed(feedID uint, at time.Time) error
+	AccountActive(role string, userID int) (bool, error)
+	ListStudentEvents(studentID int, since time.Time) ([]*entities.Event, error)
+	ListLecturerEvents(lecturerID int, since time.Time) ([]*entities.Event, error)
+}
+
+// FeedRepo implements the FeedRepoInterface.
+type FeedRepo struct {
+	db *gorm.DB
+}
+
+// NewFeedRepo returns a new instance of FeedRepo.
+func NewFeedRepo(db *gorm.DB) *FeedRepo {
+	return &FeedRepo{
+		db: db,
+	}
+}
+
+// GetFeed retrieves a user's calendar feed.
+func (fr *FeedRepo) GetFeed(role string, userID int) (*entities.CalendarFeed, error) {
+	var feed entities.CalendarFeed
+	if err := fr.db.Where("role = ? AND user_id = ?", role, userID).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFeedNotFound
+		}
+		return nil, errors.New("failed to retrieve calendar feed: " + err.Error())
+	}
+	return &feed, nil
+}
+
+// GetFeedByHash retrieves a calendar feed by the hash of its token.
+func (fr *FeedRepo) GetFeedByHash(tokenHash string) (*entities.CalendarFeed, error) {
+	var feed entities.CalendarFeed
+	if err := fr.db.Where("token_hash = ?", tokenHash).First(&feed).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrFeedNotFound
+		}
+		return nil, errors.New("failed to retrieve calendar feed: " + err.Error())
+	}
+	return &feed, nil
+}
+
+// ReplaceFeed stores a user's calendar feed, permanently deleting the one it
+// replaces so the old URL stops working.
+func (fr *FeedRepo) ReplaceFeed(feed *entities.CalendarFeed) error {
+	return fr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("role = ? AND user_id = ?", feed.Role, feed.UserID).Delete(&entities.CalendarFeed{}).Error; err != nil {
+			return errors.New("failed to delete calendar feed: " + err.Error())
+		}
+		if err := tx.Create(feed).Error; err != nil {
+			return errors.New("failed to create calendar feed: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// DeleteFeed permanently deletes a user's calendar feed.
+func (fr *FeedRepo) DeleteFeed(role string, userID int) error {
+	if err := fr.db.Unscoped().Where("role = ? AND user_id = ?", role, userID).Delete(&entities.CalendarFeed{}).Error; err != nil {
+		return errors.New("failed to delete calendar feed: " + err.Error())
+	}
+	return nil
+}
+
+// TouchFeed records that a calendar app fetched the feed.
+func (fr *FeedRepo) TouchFeed(feedID uint, at time.Time) error {
+	if err := fr.db.Model(&entities.CalendarFeed{}).Where("id = ?", feedID).Update("last_used_at", at).Error; err != nil {
+		return errors.New("failed to update calendar feed: " + err.Error())
+	}
+	return nil
+}
+
+// AccountActive reports whether the feed owner's account still exists and is
+// not suspended.
+func (fr *FeedRepo) AccountActive(role string, userID int) (bool, error) {
+	var model interface{}
+	switch role {
+	case "student":
+		model = &entities.Student{}
+	case "lecturer":
+		model = &entities.Lecturer{}
+	default:
+		return false, nil
+	}
+
+	var count int64
+	if err := fr.db.Model(model).Where("id = ? AND suspended_at IS NULL", userID).Count(&count).Error; err != nil {
+		return false, errors.New("failed to check account: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// ListStudentEvents retrieves the events of the courses a student is enrolled
+// in that end after since, including cancelled ones, in start order.
+func (fr *FeedRepo) ListStudentEvents(studentID int, since time.Time) ([]*entities.Event, error) {
+	var events []*entities.Event
+	if err := fr.db.Preload("Course").
+		Preload("Location").
+		Where("end_time >= ? AND course_id IN (SELECT course_id FROM enrollments WHERE student_id = ? AND deleted_at IS NULL)", since, studentID).
+		Order("start_time ASC").
+		Find(&events).Error; err != nil {
+		return nil, errors.New("failed to retrieve events: " + err.Error())
+	}
+	return events, nil
+}
+
+// ListLecturerEvents retrieves the events a lecturer teaches that end after
+// since, including cancelled ones, in start order: events they created or
+// co-lecture, and timetabled classes of courses they lecture.
+func (fr *FeedRepo) ListLecturerEvents(lecturerID int, since time.Time) ([]*entities.Event, error) {
+	var events []*entities.Event
+	if err := fr.db.Preload("Course").
+		Preload("Location").
+		Where("end_time >= ?", since).
+		Where(fr.db.Where("created_by = ?", lecturerID).
+			Or("id IN (SELECT event_id FROM event_co_lecturers WHERE lecturer_id = ?)", lecturerID).
+			Or("timetable_entry_id IS NOT NULL AND course_id IN (SELECT course_id FROM course_lecturers WHERE lecturer_id = ?)", lecturerID)).
+		Order("start_time ASC").
+		Find(&events).Error; err != nil {
+		return nil, errors.New("failed to retrieve events: " + err.Error())
+	}
+	return events, nil
+}
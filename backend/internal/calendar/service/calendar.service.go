@@ -0,0 +1,634 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	calendar "github.com/Dom-HTG/attendance-management-system/internal/calendar/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// CalendarSvcInterface defines the service interface for the academic calendar.
+type CalendarSvcInterface interface {
+	ListSessions(ctx *gin.Context)
+	GetSession(ctx *gin.Context)
+	CreateSession(ctx *gin.Context)
+	UpdateSession(ctx *gin.Context)
+	DeleteSession(ctx *gin.Context)
+	CreateSemester(ctx *gin.Context)
+	UpdateSemester(ctx *gin.Context)
+	DeleteSemester(ctx *gin.Context)
+	ListHolidays(ctx *gin.Context)
+	CreateHoliday(ctx *gin.Context)
+	UpdateHoliday(ctx *gin.Context)
+	DeleteHoliday(ctx *gin.Context)
+}
+
+// CalendarSvc implements the CalendarSvcInterface.
+type CalendarSvc struct {
+	calendarRepo repository.CalendarRepoInterface
+}
+
+// NewCalendarSvc returns a new instance of CalendarSvc.
+func NewCalendarSvc(calendarRepo repository.CalendarRepoInterface) *CalendarSvc {
+	return &CalendarSvc{
+		calendarRepo: calendarRepo,
+	}
+}
+
+// ListSessions handles GET /api/calendar/sessions.
+func (cs *CalendarSvc) ListSessions(ctx *gin.Context) {
+	sessions, err := cs.calendarRepo.ListSessions()
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve academic sessions", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []calendar.SessionResponse{}
+	for _, s := range sessions {
+		result = append(result, toSessionResponse(s))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Academic sessions retrieved successfully", result)
+}
+
+// GetSession handles GET /api/calendar/sessions/{session_id}.
+func (cs *CalendarSvc) GetSession(ctx *gin.Context) {
+	session, ok := cs.loadSession(ctx)
+	if !ok {
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Academic session retrieved successfully", toSessionResponse(session))
+}
+
+// CreateSession handles POST /api/calendar/sessions.
+func (cs *CalendarSvc) CreateSession(ctx *gin.Context) {
+	var req calendar.CreateSessionDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	session := &entities.AcademicSession{Name: strings.TrimSpace(req.Name)}
+	var ok bool
+	if session.StartDate, ok = parseDate(ctx, "start_date", req.StartDate); !ok {
+		return
+	}
+	if session.EndDate, ok = parseDate(ctx, "end_date", req.EndDate); !ok {
+		return
+	}
+	if !cs.validateSession(ctx, session) {
+		return
+	}
+
+	if err := cs.calendarRepo.CreateSession(session); err != nil {
+		responses.ApiFailure(ctx, "Failed to create academic session", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Academic session created successfully", toSessionResponse(session))
+}
+
+// UpdateSession handles PUT /api/calendar/sessions/{session_id}. The new dates
+// must still contain every semester of the session.
+func (cs *CalendarSvc) UpdateSession(ctx *gin.Context) {
+	session, ok := cs.loadSession(ctx)
+	if !ok {
+		return
+	}
+
+	var req calendar.UpdateSessionDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Name != nil {
+		session.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.StartDate != nil {
+		if session.StartDate, ok = parseDate(ctx, "start_date", *req.StartDate); !ok {
+			return
+		}
+	}
+	if req.EndDate != nil {
+		if session.EndDate, ok = parseDate(ctx, "end_date", *req.EndDate); !ok {
+			return
+		}
+	}
+	if !cs.validateSession(ctx, session) {
+		return
+	}
+	for _, semester := range session.Semesters {
+		if semester.StartDate.Before(session.StartDate) || semester.EndDate.After(session.EndDate) {
+			responses.ApiFailure(ctx, fmt.Sprintf("%s would fall outside the session; change it first", semester.Name), http.StatusConflict, nil)
+			return
+		}
+	}
+
+	if err := cs.calendarRepo.UpdateSession(session); err != nil {
+		responses.ApiFailure(ctx, "Failed to update academic session", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Academic session updated successfully", toSessionResponse(session))
+}
+
+// DeleteSession handles DELETE /api/calendar/sessions/{session_id}. Its
+// semesters are deleted too and their events untagged.
+func (cs *CalendarSvc) DeleteSession(ctx *gin.Context) {
+	session, ok := cs.loadSession(ctx)
+	if !ok {
+		return
+	}
+
+	if err := cs.calendarRepo.DeleteSession(int(session.ID)); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete academic session", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Academic session deleted successfully", nil)
+}
+
+// CreateSemester handles POST /api/calendar/sessions/{session_id}/semesters.
+// Existing events within the semester's dates are tagged with it.
+func (cs *CalendarSvc) CreateSemester(ctx *gin.Context) {
+	session, ok := cs.loadSession(ctx)
+	if !ok {
+		return
+	}
+
+	var req calendar.CreateSemesterDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	semester := &entities.Semester{
+		SessionID:     int(session.ID),
+		Name:          strings.TrimSpace(req.Name),
+		TeachingWeeks: req.TeachingWeeks,
+	}
+	if semester.StartDate, ok = parseDate(ctx, "start_date", req.StartDate); !ok {
+		return
+	}
+	if semester.EndDate, ok = parseDate(ctx, "end_date", req.EndDate); !ok {
+		return
+	}
+	if semester.ExamStartDate, ok = parseOptionalDate(ctx, "exam_start_date", req.ExamStartDate); !ok {
+		return
+	}
+	if semester.ExamEndDate, ok = parseOptionalDate(ctx, "exam_end_date", req.ExamEndDate); !ok {
+		return
+	}
+	if !cs.validateSemester(ctx, session, semester) {
+		return
+	}
+
+	if err := cs.calendarRepo.CreateSemester(semester); err != nil {
+		responses.ApiFailure(ctx, "Failed to create semester", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Semester created successfully", toSemesterResponse(semester))
+}
+
+// UpdateSemester handles PUT /api/calendar/semesters/{semester_id}. Events
+// are retagged when the dates change.
+func (cs *CalendarSvc) UpdateSemester(ctx *gin.Context) {
+	semester, ok := cs.loadSemester(ctx)
+	if !ok {
+		return
+	}
+
+	var req calendar.UpdateSemesterDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Name != nil {
+		semester.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.TeachingWeeks != nil {
+		semester.TeachingWeeks = *req.TeachingWeeks
+	}
+	if req.StartDate != nil {
+		if semester.StartDate, ok = parseDate(ctx, "start_date", *req.StartDate); !ok {
+			return
+		}
+	}
+	if req.EndDate != nil {
+		if semester.EndDate, ok = parseDate(ctx, "end_date", *req.EndDate); !ok {
+			return
+		}
+	}
+	if req.ExamStartDate != nil {
+		if semester.ExamStartDate, ok = parseOptionalDate(ctx, "exam_start_date", req.ExamStartDate); !ok {
+			return
+		}
+	}
+	if req.ExamEndDate != nil {
+		if semester.ExamEndDate, ok = parseOptionalDate(ctx, "exam_end_date", req.ExamEndDate); !ok {
+			return
+		}
+	}
+
+	session, err := cs.calendarRepo.GetSessionByID(semester.SessionID)
+	if err != nil {
+		respondCalendarLookupError(ctx, err)
+		return
+	}
+	if !cs.validateSemester(ctx, session, semester) {
+		return
+	}
+
+	if err := cs.calendarRepo.UpdateSemester(semester); err != nil {
+		responses.ApiFailure(ctx, "Failed to update semester", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Semester updated successfully", toSemesterResponse(semester))
+}
+
+// DeleteSemester handles DELETE /api/calendar/semesters/{semester_id}. Its
+// events are untagged.
+func (cs *CalendarSvc) DeleteSemester(ctx *gin.Context) {
+	semester, ok := cs.loadSemester(ctx)
+	if !ok {
+		return
+	}
+
+	if err := cs.calendarRepo.DeleteSemester(int(semester.ID)); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete semester", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Semester deleted successfully", nil)
+}
+
+// ListHolidays handles GET /api/calendar/holidays. The optional from and to
+// query parameters (YYYY-MM-DD) limit the list to holidays overlapping them.
+func (cs *CalendarSvc) ListHolidays(ctx *gin.Context) {
+	var from, to *time.Time
+	var ok bool
+	if raw := ctx.Query("from"); raw != "" {
+		if from, ok = parseOptionalDate(ctx, "from", &raw); !ok {
+			return
+		}
+	}
+	if raw := ctx.Query("to"); raw != "" {
+		if to, ok = parseOptionalDate(ctx, "to", &raw); !ok {
+			return
+		}
+	}
+
+	holidays, err := cs.calendarRepo.ListHolidays(from, to)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve holidays", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []calendar.HolidayResponse{}
+	for _, h := range holidays {
+		result = append(result, toHolidayResponse(h))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Holidays retrieved successfully", result)
+}
+
+// CreateHoliday handles POST /api/calendar/holidays.
+func (cs *CalendarSvc) CreateHoliday(ctx *gin.Context) {
+	var req calendar.CreateHolidayDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	holiday := &entities.Holiday{Name: strings.TrimSpace(req.Name)}
+	var ok bool
+	if holiday.StartDate, ok = parseDate(ctx, "start_date", req.StartDate); !ok {
+		return
+	}
+	holiday.EndDate = holiday.StartDate
+	if req.EndDate != "" {
+		if holiday.EndDate, ok = parseDate(ctx, "end_date", req.EndDate); !ok {
+			return
+		}
+	}
+	if !validateHoliday(ctx, holiday) {
+		return
+	}
+
+	if err := cs.calendarRepo.CreateHoliday(holiday); err != nil {
+		responses.ApiFailure(ctx, "Failed to create holiday", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Holiday created successfully", toHolidayResponse(holiday))
+}
+
+// UpdateHoliday handles PUT /api/calendar/holidays/{holiday_id}.
+func (cs *CalendarSvc) UpdateHoliday(ctx *gin.Context) {
+	holiday, ok := cs.loadHoliday(ctx)
+	if !ok {
+		return
+	}
+
+	var req calendar.UpdateHolidayDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Name != nil {
+		holiday.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.StartDate != nil {
+		if holiday.StartDate, ok = parseDate(ctx, "start_date", *req.StartDate); !ok {
+			return
+		}
+	}
+	if req.EndDate != nil {
+		if holiday.EndDate, ok = parseDate(ctx, "end_date", *req.EndDate); !ok {
+			return
+		}
+	}
+	if !validateHoliday(ctx, holiday) {
+		return
+	}
+
+	if err := cs.calendarRepo.UpdateHoliday(holiday); err != nil {
+		responses.ApiFailure(ctx, "Failed to update holiday", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Holiday updated successfully", toHolidayResponse(holiday))
+}
+
+// DeleteHoliday handles DELETE /api/calendar/holidays/{holiday_id}.
+func (cs *CalendarSvc) DeleteHoliday(ctx *gin.Context) {
+	holiday, ok := cs.loadHoliday(ctx)
+	if !ok {
+		return
+	}
+
+	if err := cs.calendarRepo.DeleteHoliday(int(holiday.ID)); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete holiday", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Holiday deleted successfully", nil)
+}
+
+// validateSession checks a session's name and dates, and that no other
+// session claims the name or overlaps it.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CalendarSvc) validateSession(ctx *gin.Context, session *entities.AcademicSession) bool {
+	if !utils.ValidAcademicSession(session.Name) {
+		responses.ApiFailure(ctx, "Session name must look like 2024/2025", http.StatusBadRequest, nil)
+		return false
+	}
+	if session.EndDate.Before(session.StartDate) {
+		responses.ApiFailure(ctx, "end_date must not be before start_date", http.StatusBadRequest, nil)
+		return false
+	}
+
+	if existing, err := cs.calendarRepo.GetSessionByName(session.Name); err == nil && existing.ID != session.ID {
+		responses.ApiFailure(ctx, fmt.Sprintf("Academic session %s already exists", session.Name), http.StatusConflict, nil)
+		return false
+	} else if err != nil && !errors.Is(err, repository.ErrSessionNotFound) {
+		responses.ApiFailure(ctx, "Failed to check academic session name", http.StatusInternalServerError, err.Error())
+		return false
+	}
+
+	overlap, err := cs.calendarRepo.FindOverlappingSession(session.StartDate, session.EndDate, int(session.ID))
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to check academic session dates", http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if overlap != nil {
+		responses.ApiFailure(ctx, fmt.Sprintf("Dates overlap academic session %s", overlap.Name), http.StatusConflict, nil)
+		return false
+	}
+	return true
+}
+
+// validateSemester checks that a semester lies within its session, leaves room
+// for its teaching weeks before the exam period, and overlaps no other
+// semester.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CalendarSvc) validateSemester(ctx *gin.Context, session *entities.AcademicSession, semester *entities.Semester) bool {
+	if semester.Name == "" {
+		responses.ApiFailure(ctx, "Semester name is required", http.StatusBadRequest, nil)
+		return false
+	}
+	if semester.EndDate.Before(semester.StartDate) {
+		responses.ApiFailure(ctx, "end_date must not be before start_date", http.StatusBadRequest, nil)
+		return false
+	}
+	if semester.StartDate.Before(session.StartDate) || semester.EndDate.After(session.EndDate) {
+		responses.ApiFailure(ctx, fmt.Sprintf("Semester must fall within academic session %s (%s to %s)",
+			session.Name, session.StartDate.Format(calendar.DateLayout), session.EndDate.Format(calendar.DateLayout)), http.StatusBadRequest, nil)
+		return false
+	}
+
+	teachingEnd := teachingEndDate(semester)
+	if teachingEnd.After(semester.EndDate) {
+		responses.ApiFailure(ctx, fmt.Sprintf("%d teaching weeks do not fit between start_date and end_date", semester.TeachingWeeks), http.StatusBadRequest, nil)
+		return false
+	}
+	if (semester.ExamStartDate == nil) != (semester.ExamEndDate == nil) {
+		responses.ApiFailure(ctx, "exam_start_date and exam_end_date must be set together", http.StatusBadRequest, nil)
+		return false
+	}
+	if semester.ExamStartDate != nil {
+		if semester.ExamEndDate.Before(*semester.ExamStartDate) {
+			responses.ApiFailure(ctx, "exam_end_date must not be before exam_start_date", http.StatusBadRequest, nil)
+			return false
+		}
+		if !semester.ExamStartDate.After(teachingEnd) || semester.ExamEndDate.After(semester.EndDate) {
+			responses.ApiFailure(ctx, "The exam period must follow the teaching weeks and end within the semester", http.StatusBadRequest, nil)
+			return false
+		}
+	}
+
+	overlap, err := cs.calendarRepo.FindOverlappingSemester(semester.StartDate, semester.EndDate, int(semester.ID))
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to check semester dates", http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if overlap != nil {
+		responses.ApiFailure(ctx, fmt.Sprintf("Dates overlap semester %s", overlap.Name), http.StatusConflict, nil)
+		return false
+	}
+	return true
+}
+
+// validateHoliday checks a holiday's name and dates.
+// It writes the failure response itself and returns false when the request should stop.
+func validateHoliday(ctx *gin.Context, holiday *entities.Holiday) bool {
+	if holiday.Name == "" {
+		responses.ApiFailure(ctx, "Holiday name is required", http.StatusBadRequest, nil)
+		return false
+	}
+	if holiday.EndDate.Before(holiday.StartDate) {
+		responses.ApiFailure(ctx, "end_date must not be before start_date", http.StatusBadRequest, nil)
+		return false
+	}
+	return true
+}
+
+// loadSession loads the academic session in the URL.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CalendarSvc) loadSession(ctx *gin.Context) (*entities.AcademicSession, bool) {
+	sessionID, err := strconv.Atoi(ctx.Param("session_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid session ID", http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	session, err := cs.calendarRepo.GetSessionByID(sessionID)
+	if err != nil {
+		respondCalendarLookupError(ctx, err)
+		return nil, false
+	}
+	return session, true
+}
+
+// loadSemester loads the semester in the URL.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CalendarSvc) loadSemester(ctx *gin.Context) (*entities.Semester, bool) {
+	semesterID, err := strconv.Atoi(ctx.Param("semester_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid semester ID", http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	semester, err := cs.calendarRepo.GetSemesterByID(semesterID)
+	if err != nil {
+		respondCalendarLookupError(ctx, err)
+		return nil, false
+	}
+	return semester, true
+}
+
+// loadHoliday loads the holiday in the URL.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CalendarSvc) loadHoliday(ctx *gin.Context) (*entities.Holiday, bool) {
+	holidayID, err := strconv.Atoi(ctx.Param("holiday_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid holiday ID", http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	holiday, err := cs.calendarRepo.GetHolidayByID(holidayID)
+	if err != nil {
+		respondCalendarLookupError(ctx, err)
+		return nil, false
+	}
+	return holiday, true
+}
+
+// parseDate parses a YYYY-MM-DD date from the named field.
+// It writes the failure response itself and returns false when the request should stop.
+func parseDate(ctx *gin.Context, field, value string) (time.Time, bool) {
+	t, err := time.Parse(calendar.DateLayout, strings.TrimSpace(value))
+	if err != nil {
+		responses.ApiFailure(ctx, fmt.Sprintf("Invalid %s; use YYYY-MM-DD", field), http.StatusBadRequest, err.Error())
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// parseOptionalDate is parseDate for optional fields; nil or empty values
+// yield nil.
+func parseOptionalDate(ctx *gin.Context, field string, value *string) (*time.Time, bool) {
+	if value == nil || strings.TrimSpace(*value) == "" {
+		return nil, true
+	}
+	t, ok := parseDate(ctx, field, *value)
+	if !ok {
+		return nil, false
+	}
+	return &t, true
+}
+
+// teachingEndDate returns the last day of a semester's final teaching week.
+func teachingEndDate(semester *entities.Semester) time.Time {
+	return semester.StartDate.AddDate(0, 0, 7*semester.TeachingWeeks-1)
+}
+
+// respondCalendarLookupError maps calendar lookup errors to HTTP responses.
+func respondCalendarLookupError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrSessionNotFound), errors.Is(err, repository.ErrSemesterNotFound), errors.Is(err, repository.ErrHolidayNotFound):
+		responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+	default:
+		responses.ApiFailure(ctx, "Failed to retrieve academic calendar", http.StatusInternalServerError, err.Error())
+	}
+}
+
+// toSessionResponse maps an academic session entity to its response DTO.
+func toSessionResponse(s *entities.AcademicSession) calendar.SessionResponse {
+	semesters := []calendar.SemesterResponse{}
+	for i := range s.Semesters {
+		semesters = append(semesters, toSemesterResponse(&s.Semesters[i]))
+	}
+	return calendar.SessionResponse{
+		ID:        int(s.ID),
+		Name:      s.Name,
+		StartDate: s.StartDate.Format(calendar.DateLayout),
+		EndDate:   s.EndDate.Format(calendar.DateLayout),
+		Semesters: semesters,
+		CreatedAt: s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toSemesterResponse maps a semester entity to its response DTO.
+func toSemesterResponse(s *entities.Semester) calendar.SemesterResponse {
+	return calendar.SemesterResponse{
+		ID:              int(s.ID),
+		SessionID:       s.SessionID,
+		Name:            s.Name,
+		StartDate:       s.StartDate.Format(calendar.DateLayout),
+		EndDate:         s.EndDate.Format(calendar.DateLayout),
+		TeachingWeeks:   s.TeachingWeeks,
+		TeachingEndDate: teachingEndDate(s).Format(calendar.DateLayout),
+		ExamStartDate:   formatOptionalDate(s.ExamStartDate),
+		ExamEndDate:     formatOptionalDate(s.ExamEndDate),
+		CreatedAt:       s.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:       s.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toHolidayResponse maps a holiday entity to its response DTO.
+func toHolidayResponse(h *entities.Holiday) calendar.HolidayResponse {
+	return calendar.HolidayResponse{
+		ID:        int(h.ID),
+		Name:      h.Name,
+		StartDate: h.StartDate.Format(calendar.DateLayout),
+		EndDate:   h.EndDate.Format(calendar.DateLayout),
+		CreatedAt: h.CreatedAt.Format(time.RFC3339),
+		UpdatedAt: h.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// formatOptionalDate formats an optional date, keeping nil as nil.
+func formatOptionalDate(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(calendar.DateLayout)
+	return &s
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	calendar "github.com/Dom-HTG/attendance-management-system/internal/calendar/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// feedPath is where feeds are served; the token and ".ics" follow it.
+const feedPath = "/api/calendar/feeds/"
+
+// FeedConfig controls iCalendar feeds.
+type FeedConfig struct {
+	BaseURL  string        // Public URL of the API for feed links, e.g. https://api.school.edu; taken from the request when empty
+	Lookback time.Duration // How far back ended events stay in the feed
+	Refresh  time.Duration // How often calendar apps are asked to refresh
+}
+
+// FeedSvcInterface defines the service interface for iCalendar feeds.
+type FeedSvcInterface interface {
+	GetFeed(ctx *gin.Context)
+	CreateFeed(ctx *gin.Context)
+	DeleteFeed(ctx *gin.Context)
+	ServeFeed(ctx *gin.Context)
+}
+
+// FeedSvc implements the FeedSvcInterface.
+type FeedSvc struct {
+	feedRepo repository.FeedRepoInterface
+	config   FeedConfig
+}
+
+// NewFeedSvc returns a new instance of FeedSvc.
+func NewFeedSvc(feedRepo repository.FeedRepoInterface, config FeedConfig) *FeedSvc {
+	return &FeedSvc{
+		feedRepo: feedRepo,
+		config:   config,
+	}
+}
+
+// GetFeed handles GET /api/calendar/feed. It reports whether the caller has a
+// feed; the URL itself is only shown when it is created.
+func (fs *FeedSvc) GetFeed(ctx *gin.Context) {
+	role, userID, ok := feedOwner(ctx)
+	if !ok {
+		return
+	}
+
+	feed, err := fs.feedRepo.GetFeed(role, userID)
+	if errors.Is(err, repository.ErrFeedNotFound) {
+		responses.ApiSuccess(ctx, http.StatusOK, "Calendar feed is not enabled", calendar.FeedResponse{})
+		return
+	}
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve calendar feed", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Calendar feed retrieved successfully", toFeedResponse(feed))
+}
+
+// CreateFeed handles POST /api/calendar/feed. It issues a new feed URL,
+// retiring any earlier one.
+func (fs *FeedSvc) CreateFeed(ctx *gin.Context) {
+	role, userID, ok := feedOwner(ctx)
+	if !ok {
+		return
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to generate feed token", http.StatusInternalServerError, err.Error())
+		return
+	}
+	feed := &entities.CalendarFeed{
+		UserID:    userID,
+		Role:      role,
+		TokenHash: utils.HashOpaqueToken(token),
+	}
+	if err := fs.feedRepo.ReplaceFeed(feed); err != nil {
+		responses.ApiFailure(ctx, "Failed to create calendar feed", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response := toFeedResponse(feed)
+	response.URL = fs.baseURL(ctx) + feedPath + token + ".ics"
+	response.WebcalURL = "webcal://" + strings.SplitN(response.URL, "://", 2)[1]
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Calendar feed created successfully. Keep the URL private; anyone with it can see your timetable", response)
+}
+
+// DeleteFeed handles DELETE /api/calendar/feed. Calendar apps subscribed to
+// the feed stop receiving updates.
+func (fs *FeedSvc) DeleteFeed(ctx *gin.Context) {
+	role, userID, ok := feedOwner(ctx)
+	if !ok {
+		return
+	}
+
+	if err := fs.feedRepo.DeleteFeed(role, userID); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete calendar feed", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Calendar feed deleted successfully", nil)
+}
+
+// ServeFeed handles GET /api/calendar/feeds/{token}.ics. The token in the URL
+// authenticates the request, since calendar apps cannot log in. Students get
+// the events of the courses they are enrolled in and lecturers the events they
+// teach. The response is a bare text/calendar document.
+func (fs *FeedSvc) ServeFeed(ctx *gin.Context) {
+	token := strings.TrimSuffix(ctx.Param("feed"), ".ics")
+	feed, err := fs.feedRepo.GetFeedByHash(utils.HashOpaqueToken(token))
+	if err != nil {
+		if errors.Is(err, repository.ErrFeedNotFound) {
+			responses.ApiFailure(ctx, "Calendar feed not found", http.StatusNotFound, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve calendar feed", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// Suspended and deleted accounts lose their feed without revealing why
+	active, err := fs.feedRepo.AccountActive(feed.Role, feed.UserID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve calendar feed", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !active {
+		responses.ApiFailure(ctx, "Calendar feed not found", http.StatusNotFound, nil)
+		return
+	}
+
+	now := time.Now()
+	since := now.Add(-fs.config.Lookback)
+	var events []*entities.Event
+	name := "My classes"
+	if feed.Role == rbac.RoleLecturer {
+		name = "My teaching"
+		events, err = fs.feedRepo.ListLecturerEvents(feed.UserID, since)
+	} else {
+		events, err = fs.feedRepo.ListStudentEvents(feed.UserID, since)
+	}
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve events", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if err := fs.feedRepo.TouchFeed(feed.ID, now); err != nil {
+		logger.Errorf("failed to record calendar feed use: %v", err)
+	}
+
+	ical := make([]utils.ICalEvent, 0, len(events))
+	for _, event := range events {
+		ical = append(ical, toICalEvent(event))
+	}
+
+	ctx.Header("Cache-Control", "private, max-age=300")
+	ctx.Header("Content-Disposition", `inline; filename="calendar.ics"`)
+	ctx.Data(http.StatusOK, "text/calendar; charset=utf-8", utils.ICalendar(name, ical, fs.config.Refresh, now))
+}
+
+// baseURL returns the public URL of the API: the configured one, or else the
+// scheme and host the request came in on.
+func (fs *FeedSvc) baseURL(ctx *gin.Context) string {
+	if fs.config.BaseURL != "" {
+		return strings.TrimRight(fs.config.BaseURL, "/")
+	}
+	scheme := "http"
+	if ctx.Request.TLS != nil || strings.EqualFold(ctx.GetHeader("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + ctx.Request.Host
+}
+
+// feedOwner returns the caller's role and ID. Only students and lecturers have
+// feeds.
+// It writes the failure response itself and returns false when the request should stop.
+func feedOwner(ctx *gin.Context) (string, int, bool) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return "", 0, false
+	}
+	role, _ := middleware.GetUserRoleFromContext(ctx)
+	if role != rbac.RoleStudent && role != rbac.RoleLecturer {
+		responses.ApiFailure(ctx, "Calendar feeds are only available to students and lecturers", http.StatusForbidden, nil)
+		return "", 0, false
+	}
+	return role, userID, true
+}
+
+// toFeedResponse maps a calendar feed entity to its response DTO, without URLs.
+func toFeedResponse(feed *entities.CalendarFeed) calendar.FeedResponse {
+	createdAt := feed.CreatedAt.Format(time.RFC3339)
+	response := calendar.FeedResponse{
+		Enabled:   true,
+		CreatedAt: &createdAt,
+	}
+	if feed.LastUsedAt != nil {
+		lastUsedAt := feed.LastUsedAt.Format(time.RFC3339)
+		response.LastUsedAt = &lastUsedAt
+	}
+	return response
+}
+
+// toICalEvent maps an event to a VEVENT titled with its course.
+func toICalEvent(event *entities.Event) utils.ICalEvent {
+	summary := event.EventName
+	description := ""
+	if event.Course != nil {
+		summary = fmt.Sprintf("%s %s", event.Course.Code, event.Course.Title)
+		description = fmt.Sprintf("Course: %s (%s)", event.Course.Title, event.Course.Code)
+		if event.Course.Department != "" {
+			description += "\nDepartment: " + event.Course.Department
+		}
+	}
+
+	cancelled := event.Status == entities.EventCancelled
+	if cancelled {
+		summary = "Cancelled: " + summary
+		if event.CancelReason != "" {
+			description = strings.TrimPrefix(description+"\nCancelled: "+event.CancelReason, "\n")
+		}
+	}
+
+	ical := utils.ICalEvent{
+		UID:          fmt.Sprintf("event-%d@attendance-management-system", event.ID),
+		Summary:      summary,
+		Description:  description,
+		Location:     event.Venue,
+		Start:        event.StartTime,
+		End:          event.EndTime,
+		Created:      event.CreatedAt,
+		LastModified: event.UpdatedAt,
+		Cancelled:    cancelled,
+	}
+	if event.Location != nil {
+		ical.Latitude = &event.Location.Latitude
+		ical.Longitude = &event.Location.Longitude
+	}
+	return ical
+}
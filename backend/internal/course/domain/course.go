@@ -0,0 +1,92 @@
+package course
+
+// Request DTOs
+
+// CreateCourseDTO represents the request to create a new course.
+type CreateCourseDTO struct {
+	Code        string `json:"code" binding:"required"`
+	Title       string `json:"title" binding:"required"`
+	Department  string `json:"department" binding:"required"`
+	CreditUnits int    `json:"credit_units" binding:"gte=0"`
+	LecturerIDs []int  `json:"lecturer_ids"` // Additional owning lecturers; the creator is always included
+}
+
+// UpdateCourseDTO represents the request to update an existing course.
+// Only fields that are provided are changed.
+type UpdateCourseDTO struct {
+	Title       *string `json:"title"`
+	Department  *string `json:"department"`
+	CreditUnits *int    `json:"credit_units" binding:"omitempty,gte=0"`
+	LecturerIDs *[]int  `json:"lecturer_ids"` // Replaces the owning lecturers when provided
+}
+
+// Response DTOs
+
+// CourseLecturerResponse represents a lecturer that owns a course.
+type CourseLecturerResponse struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	StaffID string `json:"staff_id"`
+}
+
+// CourseResponse represents a course.
+type CourseResponse struct {
+	ID          int                      `json:"id"`
+	Code        string                   `json:"code"`
+	Title       string                   `json:"title"`
+	Department  string                   `json:"department"`
+	CreditUnits int                      `json:"credit_units"`
+	Lecturers   []CourseLecturerResponse `json:"lecturers"`
+	CreatedAt   string                   `json:"created_at"`
+	UpdatedAt   string                   `json:"updated_at"`
+}
+
+// Enrollment DTOs
+
+// EnrollStudentDTO represents the request to enroll a single student.
+// Either StudentID or MatricNumber must be provided.
+type EnrollStudentDTO struct {
+	StudentID    int    `json:"student_id"`
+	MatricNumber string `json:"matric_number"`
+}
+
+// BulkEnrollDTO represents the request to enroll many students by matric number.
+type BulkEnrollDTO struct {
+	MatricNumbers []string `json:"matric_numbers" binding:"required,min=1"`
+}
+
+// EnrollmentResponse represents a student's enrollment in a course.
+type EnrollmentResponse struct {
+	StudentID    int    `json:"student_id"`
+	StudentName  string `json:"student_name"`
+	MatricNumber string `json:"matric_number"`
+	EnrolledAt   string `json:"enrolled_at"`
+}
+
+// CourseEnrollmentsResponse represents the roster of students enrolled in a course.
+type CourseEnrollmentsResponse struct {
+	CourseID      int                  `json:"course_id"`
+	CourseCode    string               `json:"course_code"`
+	TotalEnrolled int                  `json:"total_enrolled"`
+	Enrollments   []EnrollmentResponse `json:"enrollments"`
+}
+
+// BulkEnrollmentResponse summarises the outcome of a bulk enrollment.
+type BulkEnrollmentResponse struct {
+	CourseID        int      `json:"course_id"`
+	Requested       int      `json:"requested"`
+	Enrolled        int      `json:"enrolled"`
+	AlreadyEnrolled int      `json:"already_enrolled"`
+	NotFound        []string `json:"not_found"` // Matric numbers with no matching student
+}
+
+// StudentCourseResponse represents a course a student is enrolled in.
+type StudentCourseResponse struct {
+	CourseID    int    `json:"course_id"`
+	Code        string `json:"code"`
+	Title       string `json:"title"`
+	Department  string `json:"department"`
+	CreditUnits int    `json:"credit_units"`
+	EnrolledAt  string `json:"enrolled_at"`
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrCourseNotFound is returned when a course lookup matches no rows.
+	ErrCourseNotFound = errors.New("course not found")
+	// ErrUnknownLecturer is returned when an owning lecturer id does not exist.
+	ErrUnknownLecturer = errors.New("one or more lecturer ids do not exist")
+)
+
+// CourseRepoInterface defines the repository interface for course operations.
+type CourseRepoInterface interface {
+	CreateCourse(course *entities.Course, lecturerIDs []int) error
+	GetCourseByID(courseID int) (*entities.Course, error)
+	GetCourseByCode(code string) (*entities.Course, error)
+	ListCoursesByLecturer(lecturerID int) ([]*entities.Course, error)
+	UpdateCourse(course *entities.Course, lecturerIDs *[]int) error
+	DeleteCourse(courseID int) error
+	IsCourseLecturer(courseID, lecturerID int) (bool, error)
+}
+
+// CourseRepo implements the CourseRepoInterface.
+type CourseRepo struct {
+	db *gorm.DB
+}
+
+// NewCourseRepo returns a new instance of CourseRepo.
+func NewCourseRepo(db *gorm.DB) *CourseRepo {
+	return &CourseRepo{
+		db: db,
+	}
+}
+
+// NormalizeCourseCode upper-cases a course code and strips whitespace so that
+// "csc 101" and "CSC101" refer to the same course.
+func NormalizeCourseCode(code string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(code), ""))
+}
+
+// CreateCourse creates a new course owned by the given lecturers.
+func (cr *CourseRepo) CreateCourse(course *entities.Course, lecturerIDs []int) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		lecturers, err := findLecturers(tx, lecturerIDs)
+		if err != nil {
+			return err
+		}
+		course.Lecturers = lecturers
+
+		if err := tx.Create(course).Error; err != nil {
+			return errors.New("failed to create course: " + err.Error())
+		}
+		return loadLecturerIdentities(tx, course.Lecturers)
+	})
+}
+
+// GetCourseByID retrieves a course and its lecturers by ID.
+func (cr *CourseRepo) GetCourseByID(courseID int) (*entities.Course, error) {
+	var course entities.Course
+	if err := cr.db.Preload("Lecturers.Identity").First(&course, courseID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCourseNotFound
+		}
+		return nil, errors.New("failed to retrieve course: " + err.Error())
+	}
+	return &course, nil
+}
+
+// GetCourseByCode retrieves a course and its lecturers by course code.
+func (cr *CourseRepo) GetCourseByCode(code string) (*entities.Course, error) {
+	var course entities.Course
+	if err := cr.db.Preload("Lecturers.Identity").
+		Where("code = ?", NormalizeCourseCode(code)).
+		First(&course).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCourseNotFound
+		}
+		return nil, errors.New("failed to retrieve course: " + err.Error())
+	}
+	return &course, nil
+}
+
+// ListCoursesByLecturer retrieves every course owned by a lecturer.
+func (cr *CourseRepo) ListCoursesByLecturer(lecturerID int) ([]*entities.Course, error) {
+	var courses []*entities.Course
+	if err := cr.db.Preload("Lecturers.Identity").
+		Joins("JOIN course_lecturers cl ON cl.course_id = courses.id").
+		Where("cl.lecturer_id = ?", lecturerID).
+		Order("courses.code ASC").
+		Find(&courses).Error; err != nil {
+		return nil, errors.New("failed to retrieve courses: " + err.Error())
+	}
+	return courses, nil
+}
+
+// UpdateCourse saves changes to a course. When lecturerIDs is not nil the
+// owning lecturers are replaced with the given set.
+func (cr *CourseRepo) UpdateCourse(course *entities.Course, lecturerIDs *[]int) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(course).
+			Select("title", "department", "credit_units").
+			Updates(course).Error; err != nil {
+			return errors.New("failed to update course: " + err.Error())
+		}
+
+		if lecturerIDs == nil {
+			return nil
+		}
+
+		lecturers, err := findLecturers(tx, *lecturerIDs)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(course).Association("Lecturers").Replace(lecturers); err != nil {
+			return errors.New("failed to update course lecturers: " + err.Error())
+		}
+		course.Lecturers = lecturers
+		return loadLecturerIdentities(tx, course.Lecturers)
+	})
+}
+
+// DeleteCourse soft-deletes a course and removes its lecturer links.
+func (cr *CourseRepo) DeleteCourse(courseID int) error {
+	return cr.db.Transaction(func(tx *gorm.DB) error {
+		course := &entities.Course{}
+		course.ID = uint(courseID)
+		if err := tx.Model(course).Association("Lecturers").Clear(); err != nil {
+			return errors.New("failed to remove course lecturers: " + err.Error())
+		}
+		if err := tx.Delete(course).Error; err != nil {
+			return errors.New("failed to delete course: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// IsCourseLecturer reports whether a lecturer owns the given course.
+func (cr *CourseRepo) IsCourseLecturer(courseID, lecturerID int) (bool, error) {
+	var count int64
+	if err := cr.db.Table("course_lecturers").
+		Where("course_id = ? AND lecturer_id = ?", courseID, lecturerID).
+		Count(&count).Error; err != nil {
+		return false, errors.New("failed to check course ownership: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// findLecturers loads the lecturers with the given IDs and fails if any are missing.
+func findLecturers(tx *gorm.DB, lecturerIDs []int) ([]entities.Lecturer, error) {
+	var lecturers []entities.Lecturer
+	if len(lecturerIDs) == 0 {
+		return lecturers, nil
+	}
+
+	if err := tx.Where("id IN ?", lecturerIDs).Find(&lecturers).Error; err != nil {
+		return nil, errors.New("failed to retrieve lecturers: " + err.Error())
+	}
+
+	unique := map[int]struct{}{}
+	for _, id := range lecturerIDs {
+		unique[id] = struct{}{}
+	}
+	if len(lecturers) != len(unique) {
+		return nil, ErrUnknownLecturer
+	}
+	return lecturers, nil
+}
+
+// loadLecturerIdentities loads the identity of each lecturer for its email. It
+// runs after the course is saved, so the identities are not saved with it.
+func loadLecturerIdentities(tx *gorm.DB, lecturers []entities.Lecturer) error {
+	for i := range lecturers {
+		var identity entities.Identity
+		if err := tx.First(&identity, lecturers[i].IdentityID).Error; err != nil {
+			return errors.New("failed to retrieve lecturer identity: " + err.Error())
+		}
+		lecturers[i].Identity = &identity
+	}
+	return nil
+}
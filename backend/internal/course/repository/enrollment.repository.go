@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EnrollmentRepoInterface defines the repository interface for course enrollment operations.
+type EnrollmentRepoInterface interface {
+	EnrollStudents(courseID int, studentIDs []int) (int, error)
+	UnenrollStudent(courseID, studentID int) (bool, error)
+	ListEnrollments(courseID int) ([]*entities.Enrollment, error)
+	ListStudentEnrollments(studentID int) ([]*entities.Enrollment, error)
+	IsStudentEnrolled(courseID, studentID int) (bool, error)
+	FindStudentIDsByMatric(matricNumbers []string) (map[string]int, error)
+	StudentExists(studentID int) (bool, error)
+}
+
+// EnrollmentRepo implements the EnrollmentRepoInterface.
+type EnrollmentRepo struct {
+	db *gorm.DB
+}
+
+// NewEnrollmentRepo returns a new instance of EnrollmentRepo.
+func NewEnrollmentRepo(db *gorm.DB) *EnrollmentRepo {
+	return &EnrollmentRepo{
+		db: db,
+	}
+}
+
+// EnrollStudents enrolls students in a course, skipping those already enrolled.
+// It returns the number of new enrollments.
+func (er *EnrollmentRepo) EnrollStudents(courseID int, studentIDs []int) (int, error) {
+	if len(studentIDs) == 0 {
+		return 0, nil
+	}
+
+	enrollments := make([]entities.Enrollment, 0, len(studentIDs))
+	for _, id := range studentIDs {
+		enrollments = append(enrollments, entities.Enrollment{CourseID: courseID, StudentID: id})
+	}
+
+	tx := er.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&enrollments)
+	if tx.Error != nil {
+		return 0, errors.New("failed to enroll students: " + tx.Error.Error())
+	}
+	return int(tx.RowsAffected), nil
+}
+
+// UnenrollStudent removes a student from a course. Enrollments are deleted
+// permanently so the student can be enrolled again later.
+func (er *EnrollmentRepo) UnenrollStudent(courseID, studentID int) (bool, error) {
+	tx := er.db.Unscoped().
+		Where("course_id = ? AND student_id = ?", courseID, studentID).
+		Delete(&entities.Enrollment{})
+	if tx.Error != nil {
+		return false, errors.New("failed to unenroll student: " + tx.Error.Error())
+	}
+	return tx.RowsAffected > 0, nil
+}
+
+// ListEnrollments retrieves every enrollment for a course with student details.
+func (er *EnrollmentRepo) ListEnrollments(courseID int) ([]*entities.Enrollment, error) {
+	var enrollments []*entities.Enrollment
+	if err := er.db.Preload("Student").
+		Where("course_id = ?", courseID).
+		Order("created_at ASC").
+		Find(&enrollments).Error; err != nil {
+		return nil, errors.New("failed to retrieve enrollments: " + err.Error())
+	}
+	return enrollments, nil
+}
+
+// ListStudentEnrollments retrieves every course a student is enrolled in.
+func (er *EnrollmentRepo) ListStudentEnrollments(studentID int) ([]*entities.Enrollment, error) {
+	var enrollments []*entities.Enrollment
+	if err := er.db.Preload("Course").
+		Where("student_id = ?", studentID).
+		Order("created_at ASC").
+		Find(&enrollments).Error; err != nil {
+		return nil, errors.New("failed to retrieve enrollments: " + err.Error())
+	}
+	return enrollments, nil
+}
+
+// IsStudentEnrolled reports whether a student is enrolled in a course.
+func (er *EnrollmentRepo) IsStudentEnrolled(courseID, studentID int) (bool, error) {
+	var count int64
+	if err := er.db.Model(&entities.Enrollment{}).
+		Where("course_id = ? AND student_id = ?", courseID, studentID).
+		Count(&count).Error; err != nil {
+		return false, errors.New("failed to check enrollment: " + err.Error())
+	}
+	return count > 0, nil
+}
+
+// FindStudentIDsByMatric maps matric numbers to student IDs. Unknown matric
+// numbers are absent from the result.
+func (er *EnrollmentRepo) FindStudentIDsByMatric(matricNumbers []string) (map[string]int, error) {
+	result := map[string]int{}
+	if len(matricNumbers) == 0 {
+		return result, nil
+	}
+
+	var students []entities.Student
+	if err := er.db.Select("id", "matric_number").
+		Where("matric_number IN ?", matricNumbers).
+		Find(&students).Error; err != nil {
+		return nil, errors.New("failed to retrieve students: " + err.Error())
+	}
+
+	for _, s := range students {
+		result[s.MatricNumber] = int(s.ID)
+	}
+	return result, nil
+}
+
+// StudentExists reports whether a student with the given ID exists.
+func (er *EnrollmentRepo) StudentExists(studentID int) (bool, error) {
+	var count int64
+	if err := er.db.Model(&entities.Student{}).Where("id = ?", studentID).Count(&count).Error; err != nil {
+		return false, errors.New("failed to retrieve student: " + err.Error())
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	course "github.com/Dom-HTG/attendance-management-system/internal/course/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/course/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// CourseSvcInterface defines the service interface for course operations.
+type CourseSvcInterface interface {
+	CreateCourse(ctx *gin.Context)
+	ListCourses(ctx *gin.Context)
+	GetCourse(ctx *gin.Context)
+	UpdateCourse(ctx *gin.Context)
+	DeleteCourse(ctx *gin.Context)
+
+	// Enrollment
+	ListEnrollments(ctx *gin.Context)
+	EnrollStudent(ctx *gin.Context)
+	BulkEnroll(ctx *gin.Context)
+	UnenrollStudent(ctx *gin.Context)
+	ListStudentCourses(ctx *gin.Context)
+}
+
+// CourseSvc implements the CourseSvcInterface.
+type CourseSvc struct {
+	courseRepo     repository.CourseRepoInterface
+	enrollmentRepo repository.EnrollmentRepoInterface
+}
+
+// NewCourseSvc returns a new instance of CourseSvc.
+func NewCourseSvc(courseRepo repository.CourseRepoInterface, enrollmentRepo repository.EnrollmentRepoInterface) *CourseSvc {
+	return &CourseSvc{
+		courseRepo:     courseRepo,
+		enrollmentRepo: enrollmentRepo,
+	}
+}
+
+// CreateCourse handles POST /api/lecturer/courses.
+// The authenticated lecturer is always recorded as an owner of the course.
+func (cs *CourseSvc) CreateCourse(ctx *gin.Context) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return
+	}
+
+	var req course.CreateCourseDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	code := repository.NormalizeCourseCode(req.Code)
+	if _, err := cs.courseRepo.GetCourseByCode(code); err == nil {
+		responses.ApiFailure(ctx, fmt.Sprintf("Course %s already exists", code), http.StatusConflict, nil)
+		return
+	} else if !errors.Is(err, repository.ErrCourseNotFound) {
+		responses.ApiFailure(ctx, "Failed to check course code", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	entity := &entities.Course{
+		Code:        code,
+		Title:       req.Title,
+		Department:  req.Department,
+		CreditUnits: req.CreditUnits,
+	}
+
+	if err := cs.courseRepo.CreateCourse(entity, withLecturer(req.LecturerIDs, lecturerID)); err != nil {
+		if errors.Is(err, repository.ErrUnknownLecturer) {
+			responses.ApiFailure(ctx, err.Error(), http.StatusBadRequest, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to create course", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Course created successfully", toCourseResponse(entity))
+}
+
+// ListCourses handles GET /api/lecturer/courses and returns the lecturer's courses.
+func (cs *CourseSvc) ListCourses(ctx *gin.Context) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return
+	}
+
+	courses, err := cs.courseRepo.ListCoursesByLecturer(lecturerID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve courses", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []course.CourseResponse{}
+	for _, c := range courses {
+		result = append(result, toCourseResponse(c))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Courses retrieved successfully", result)
+}
+
+// GetCourse handles GET /api/lecturer/courses/{course_id}.
+func (cs *CourseSvc) GetCourse(ctx *gin.Context) {
+	courseID, err := strconv.Atoi(ctx.Param("course_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid course ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entity, err := cs.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		respondCourseLookupError(ctx, err)
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Course retrieved successfully", toCourseResponse(entity))
+}
+
+// UpdateCourse handles PUT /api/lecturer/courses/{course_id}.
+// Only lecturers that own the course may update it.
+func (cs *CourseSvc) UpdateCourse(ctx *gin.Context) {
+	entity, ok := cs.loadOwnedCourse(ctx)
+	if !ok {
+		return
+	}
+
+	var req course.UpdateCourseDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Title != nil {
+		entity.Title = *req.Title
+	}
+	if req.Department != nil {
+		entity.Department = *req.Department
+	}
+	if req.CreditUnits != nil {
+		entity.CreditUnits = *req.CreditUnits
+	}
+
+	var lecturerIDs *[]int
+	if req.LecturerIDs != nil {
+		if len(*req.LecturerIDs) == 0 {
+			responses.ApiFailure(ctx, "A course must have at least one lecturer", http.StatusBadRequest, nil)
+			return
+		}
+		lecturerIDs = req.LecturerIDs
+	}
+
+	if err := cs.courseRepo.UpdateCourse(entity, lecturerIDs); err != nil {
+		if errors.Is(err, repository.ErrUnknownLecturer) {
+			responses.ApiFailure(ctx, err.Error(), http.StatusBadRequest, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to update course", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Course updated successfully", toCourseResponse(entity))
+}
+
+// DeleteCourse handles DELETE /api/lecturer/courses/{course_id}.
+// Only lecturers that own the course may delete it.
+func (cs *CourseSvc) DeleteCourse(ctx *gin.Context) {
+	entity, ok := cs.loadOwnedCourse(ctx)
+	if !ok {
+		return
+	}
+
+	if err := cs.courseRepo.DeleteCourse(int(entity.ID)); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete course", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Course deleted successfully", nil)
+}
+
+// loadOwnedCourse loads the course in the URL and verifies the caller owns it.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CourseSvc) loadOwnedCourse(ctx *gin.Context) (*entities.Course, bool) {
+	entity, lecturerID, ok := cs.loadCourse(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	if !ownsCourse(entity, lecturerID) {
+		responses.ApiFailure(ctx, "Only lecturers of this course can manage it", http.StatusForbidden, nil)
+		return nil, false
+	}
+
+	return entity, true
+}
+
+// loadRosterCourse loads the course in the URL for reading its roster. Owners
+// always qualify; other lecturers need rosters:read through a role covering the
+// course or its department, such as head of department.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CourseSvc) loadRosterCourse(ctx *gin.Context) (*entities.Course, bool) {
+	entity, lecturerID, ok := cs.loadCourse(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	if !ownsCourse(entity, lecturerID) &&
+		!middleware.HasPermission(ctx, rbac.PermRostersRead, rbac.Course(entity.Code), rbac.Department(entity.Department)) {
+		responses.ApiFailure(ctx, "You do not have access to this course's roster", http.StatusForbidden, nil)
+		return nil, false
+	}
+
+	return entity, true
+}
+
+// loadCourse loads the course in the URL along with the caller's ID.
+// It writes the failure response itself and returns false when the request should stop.
+func (cs *CourseSvc) loadCourse(ctx *gin.Context) (*entities.Course, int, bool) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return nil, 0, false
+	}
+
+	courseID, err := strconv.Atoi(ctx.Param("course_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid course ID", http.StatusBadRequest, err.Error())
+		return nil, 0, false
+	}
+
+	entity, err := cs.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		respondCourseLookupError(ctx, err)
+		return nil, 0, false
+	}
+
+	return entity, lecturerID, true
+}
+
+// respondCourseLookupError maps course lookup errors to HTTP responses.
+func respondCourseLookupError(ctx *gin.Context, err error) {
+	if errors.Is(err, repository.ErrCourseNotFound) {
+		responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+		return
+	}
+	responses.ApiFailure(ctx, "Failed to retrieve course", http.StatusInternalServerError, err.Error())
+}
+
+// ownsCourse reports whether the lecturer is one of the course's owners.
+func ownsCourse(c *entities.Course, lecturerID int) bool {
+	for _, l := range c.Lecturers {
+		if int(l.ID) == lecturerID {
+			return true
+		}
+	}
+	return false
+}
+
+// withLecturer returns ids with lecturerID appended if it is not already present.
+func withLecturer(ids []int, lecturerID int) []int {
+	for _, id := range ids {
+		if id == lecturerID {
+			return ids
+		}
+	}
+	return append(ids, lecturerID)
+}
+
+// toCourseResponse maps a course entity to its response DTO.
+func toCourseResponse(c *entities.Course) course.CourseResponse {
+	lecturers := []course.CourseLecturerResponse{}
+	for _, l := range c.Lecturers {
+		lecturers = append(lecturers, course.CourseLecturerResponse{
+			ID:      int(l.ID),
+			Name:    fmt.Sprintf("%s %s", l.FirstName, l.LastName),
+			Email:   l.Identity.Email,
+			StaffID: l.StaffID,
+		})
+	}
+
+	return course.CourseResponse{
+		ID:          int(c.ID),
+		Code:        c.Code,
+		Title:       c.Title,
+		Department:  c.Department,
+		CreditUnits: c.CreditUnits,
+		Lecturers:   lecturers,
+		CreatedAt:   c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:   c.UpdatedAt.Format(time.RFC3339),
+	}
+}
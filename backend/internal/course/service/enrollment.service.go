@@ -0,0 +1,221 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	course "github.com/Dom-HTG/attendance-management-system/internal/course/domain"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// ListEnrollments handles GET /api/lecturer/courses/{course_id}/enrollments.
+// Course owners and lecturers with roster access to the course, such as the
+// head of its department, can read the roster.
+func (cs *CourseSvc) ListEnrollments(ctx *gin.Context) {
+	entity, ok := cs.loadRosterCourse(ctx)
+	if !ok {
+		return
+	}
+
+	enrollments, err := cs.enrollmentRepo.ListEnrollments(int(entity.ID))
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve enrollments", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []course.EnrollmentResponse{}
+	for _, e := range enrollments {
+		result = append(result, course.EnrollmentResponse{
+			StudentID:    e.StudentID,
+			StudentName:  fmt.Sprintf("%s %s", e.Student.FirstName, e.Student.LastName),
+			MatricNumber: e.Student.MatricNumber,
+			EnrolledAt:   e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Enrollments retrieved successfully", course.CourseEnrollmentsResponse{
+		CourseID:      int(entity.ID),
+		CourseCode:    entity.Code,
+		TotalEnrolled: len(result),
+		Enrollments:   result,
+	})
+}
+
+// EnrollStudent handles POST /api/lecturer/courses/{course_id}/enrollments.
+func (cs *CourseSvc) EnrollStudent(ctx *gin.Context) {
+	entity, ok := cs.loadOwnedCourse(ctx)
+	if !ok {
+		return
+	}
+
+	var req course.EnrollStudentDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	studentID := req.StudentID
+	switch {
+	case studentID > 0:
+		exists, err := cs.enrollmentRepo.StudentExists(studentID)
+		if err != nil {
+			responses.ApiFailure(ctx, "Failed to retrieve student", http.StatusInternalServerError, err.Error())
+			return
+		}
+		if !exists {
+			responses.ApiFailure(ctx, "Student not found", http.StatusNotFound, nil)
+			return
+		}
+	case strings.TrimSpace(req.MatricNumber) != "":
+		ids, err := cs.enrollmentRepo.FindStudentIDsByMatric([]string{strings.TrimSpace(req.MatricNumber)})
+		if err != nil {
+			responses.ApiFailure(ctx, "Failed to retrieve student", http.StatusInternalServerError, err.Error())
+			return
+		}
+		id, found := ids[strings.TrimSpace(req.MatricNumber)]
+		if !found {
+			responses.ApiFailure(ctx, "Student not found", http.StatusNotFound, nil)
+			return
+		}
+		studentID = id
+	default:
+		responses.ApiFailure(ctx, "student_id or matric_number is required", http.StatusBadRequest, nil)
+		return
+	}
+
+	created, err := cs.enrollmentRepo.EnrollStudents(int(entity.ID), []int{studentID})
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to enroll student", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if created == 0 {
+		responses.ApiFailure(ctx, "Student is already enrolled in this course", http.StatusConflict, nil)
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Student enrolled successfully", map[string]int{
+		"course_id":  int(entity.ID),
+		"student_id": studentID,
+	})
+}
+
+// BulkEnroll handles POST /api/lecturer/courses/{course_id}/enrollments/bulk.
+// Unknown matric numbers are reported back rather than failing the whole request.
+func (cs *CourseSvc) BulkEnroll(ctx *gin.Context) {
+	entity, ok := cs.loadOwnedCourse(ctx)
+	if !ok {
+		return
+	}
+
+	var req course.BulkEnrollDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// De-duplicate and trim the matric numbers
+	seen := map[string]struct{}{}
+	matrics := []string{}
+	for _, m := range req.MatricNumbers {
+		m = strings.TrimSpace(m)
+		if m == "" {
+			continue
+		}
+		if _, dup := seen[m]; dup {
+			continue
+		}
+		seen[m] = struct{}{}
+		matrics = append(matrics, m)
+	}
+
+	ids, err := cs.enrollmentRepo.FindStudentIDsByMatric(matrics)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve students", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	notFound := []string{}
+	studentIDs := []int{}
+	for _, m := range matrics {
+		id, found := ids[m]
+		if !found {
+			notFound = append(notFound, m)
+			continue
+		}
+		studentIDs = append(studentIDs, id)
+	}
+
+	created, err := cs.enrollmentRepo.EnrollStudents(int(entity.ID), studentIDs)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to enroll students", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Bulk enrollment completed", course.BulkEnrollmentResponse{
+		CourseID:        int(entity.ID),
+		Requested:       len(matrics),
+		Enrolled:        created,
+		AlreadyEnrolled: len(studentIDs) - created,
+		NotFound:        notFound,
+	})
+}
+
+// UnenrollStudent handles DELETE /api/lecturer/courses/{course_id}/enrollments/{student_id}.
+func (cs *CourseSvc) UnenrollStudent(ctx *gin.Context) {
+	entity, ok := cs.loadOwnedCourse(ctx)
+	if !ok {
+		return
+	}
+
+	studentID, err := strconv.Atoi(ctx.Param("student_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid student ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	removed, err := cs.enrollmentRepo.UnenrollStudent(int(entity.ID), studentID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to unenroll student", http.StatusInternalServerError, err.Error())
+		return
+	}
+	if !removed {
+		responses.ApiFailure(ctx, "Student is not enrolled in this course", http.StatusNotFound, nil)
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Student unenrolled successfully", nil)
+}
+
+// ListStudentCourses handles GET /api/student/courses and returns the caller's enrollments.
+func (cs *CourseSvc) ListStudentCourses(ctx *gin.Context) {
+	studentID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return
+	}
+
+	enrollments, err := cs.enrollmentRepo.ListStudentEnrollments(studentID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve courses", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []course.StudentCourseResponse{}
+	for _, e := range enrollments {
+		result = append(result, course.StudentCourseResponse{
+			CourseID:    e.CourseID,
+			Code:        e.Course.Code,
+			Title:       e.Course.Title,
+			Department:  e.Course.Department,
+			CreditUnits: e.Course.CreditUnits,
+			EnrolledAt:  e.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Courses retrieved successfully", result)
+}
@@ -0,0 +1,123 @@
+package timetable
+
+import "time"
+
+// TimeLayout is the format of the time of day an entry starts at.
+const TimeLayout = "15:04"
+
+// Request DTOs
+
+// CreateEntryDTO represents the request to add a recurring class to a course's
+// timetable. The event settings match GenerateQRCodeDTO and are copied to
+// every generated event.
+type CreateEntryDTO struct {
+	SemesterID      int    `json:"semester_id" binding:"required"`
+	Weekday         string `json:"weekday" binding:"required"`                         // monday to sunday
+	StartTime       string `json:"start_time" binding:"required"`                      // HH:MM, local to the timetable
+	DurationMinutes int    `json:"duration_minutes" binding:"required,min=10,max=720"` // Length of each class
+
+	// The class meets every IntervalWeeks weeks (default 1) from FirstWeek
+	// (default 1) to LastWeek (default: the semester's last teaching week).
+	IntervalWeeks int `json:"interval_weeks" binding:"omitempty,min=1,max=4"`
+	FirstWeek     int `json:"first_week" binding:"omitempty,min=1"`
+	LastWeek      int `json:"last_week" binding:"omitempty,min=1"`
+
+	Venue              string `json:"venue"`
+	VenueID            *int   `json:"venue_id"`
+	GeofenceMode       string `json:"geofence_mode" binding:"omitempty,oneof=off lenient strict"`
+	GracePeriodMinutes *int   `json:"grace_period_minutes" binding:"omitempty,gte=0"`
+	LateCutoffMinutes  *int   `json:"late_cutoff_minutes" binding:"omitempty,gte=0"`
+	QRMode             string `json:"qr_mode" binding:"omitempty,oneof=static rotating"`
+	RotationSeconds    int    `json:"rotation_seconds" binding:"omitempty,min=10,max=300"`
+}
+
+// UpdateEntryDTO represents the request to update a timetable entry.
+// Only fields that are provided are changed. Generated events that have not
+// started yet follow the change; a venue_id of 0 detaches the managed venue.
+type UpdateEntryDTO struct {
+	Weekday            *string `json:"weekday"`
+	StartTime          *string `json:"start_time"`
+	DurationMinutes    *int    `json:"duration_minutes" binding:"omitempty,min=10,max=720"`
+	IntervalWeeks      *int    `json:"interval_weeks" binding:"omitempty,min=1,max=4"`
+	FirstWeek          *int    `json:"first_week" binding:"omitempty,min=1"`
+	LastWeek           *int    `json:"last_week" binding:"omitempty,min=1"`
+	Venue              *string `json:"venue"`
+	VenueID            *int    `json:"venue_id"`
+	GeofenceMode       *string `json:"geofence_mode" binding:"omitempty,oneof=off lenient strict"`
+	GracePeriodMinutes *int    `json:"grace_period_minutes" binding:"omitempty,gte=0"`
+	LateCutoffMinutes  *int    `json:"late_cutoff_minutes" binding:"omitempty,gte=0"`
+	QRMode             *string `json:"qr_mode" binding:"omitempty,oneof=static rotating"`
+	RotationSeconds    *int    `json:"rotation_seconds" binding:"omitempty,min=10,max=300"`
+}
+
+// CreateExceptionDTO represents the request to cancel or reschedule one
+// occurrence of a timetable entry. Rescheduling needs the new start and end
+// time; the venue is kept unless a new one is given.
+type CreateExceptionDTO struct {
+	Date      string `json:"date" binding:"required"` // YYYY-MM-DD the class would have met on
+	Action    string `json:"action" binding:"required,oneof=cancel reschedule"`
+	StartTime string `json:"start_time"` // ISO 8601 format: 2025-11-27T10:00:00Z
+	EndTime   string `json:"end_time"`
+	Venue     string `json:"venue"`
+	VenueID   *int   `json:"venue_id"`
+	Reason    string `json:"reason"`
+}
+
+// Response DTOs
+
+// EntryResponse represents a timetable entry.
+type EntryResponse struct {
+	ID                 int    `json:"id"`
+	CourseID           int    `json:"course_id"`
+	CourseCode         string `json:"course_code"`
+	SemesterID         int    `json:"semester_id"`
+	Weekday            string `json:"weekday"`
+	StartTime          string `json:"start_time"`
+	DurationMinutes    int    `json:"duration_minutes"`
+	IntervalWeeks      int    `json:"interval_weeks"`
+	FirstWeek          int    `json:"first_week"`
+	LastWeek           int    `json:"last_week"`
+	Venue              string `json:"venue"`
+	VenueID            *int   `json:"venue_id"`
+	GeofenceMode       string `json:"geofence_mode"`
+	QRMode             string `json:"qr_mode"`
+	RotationSeconds    int    `json:"rotation_seconds"`
+	GracePeriodMinutes int    `json:"grace_period_minutes"`
+	LateCutoffMinutes  *int   `json:"late_cutoff_minutes"`
+	CreatedAt          string `json:"created_at"`
+	UpdatedAt          string `json:"updated_at"`
+}
+
+// ExceptionResponse represents a cancelled or rescheduled occurrence.
+type ExceptionResponse struct {
+	ID        int     `json:"id"`
+	EntryID   int     `json:"entry_id"`
+	Date      string  `json:"date"`
+	Action    string  `json:"action"`
+	StartTime *string `json:"start_time"`
+	EndTime   *string `json:"end_time"`
+	Venue     string  `json:"venue"`
+	VenueID   *int    `json:"venue_id"`
+	Reason    string  `json:"reason"`
+	CreatedAt string  `json:"created_at"`
+}
+
+// Generator types
+
+// Occurrence is one meeting of a timetable entry as the generator wants its
+// event to look.
+type Occurrence struct {
+	Date      time.Time // The date the entry meets on, at midnight UTC
+	StartTime time.Time
+	EndTime   time.Time
+	Venue     string
+	VenueID   *int
+	Cancelled bool // Falls on a holiday or was cancelled by an exception
+}
+
+// SyncResult counts the events a sync created, changed and cancelled.
+type SyncResult struct {
+	Created   int
+	Updated   int
+	Cancelled int
+}
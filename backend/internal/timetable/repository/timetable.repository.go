@@ -0,0 +1,365 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	calendar "github.com/Dom-HTG/attendance-management-system/internal/calendar/domain"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
+	timetable "github.com/Dom-HTG/attendance-management-system/internal/timetable/domain"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrEntryNotFound is returned when a timetable entry lookup matches no rows.
+	ErrEntryNotFound = errors.New("timetable entry not found")
+	// ErrExceptionNotFound is returned when a timetable exception lookup matches no rows.
+	ErrExceptionNotFound = errors.New("timetable exception not found")
+)
+
+// TimetableRepoInterface defines the repository interface for course timetables.
+type TimetableRepoInterface interface {
+	// Entries
+	CreateEntry(entry *entities.TimetableEntry) error
+	GetEntryByID(entryID int) (*entities.TimetableEntry, error)
+	ListEntriesForCourse(courseID int) ([]*entities.TimetableEntry, error)
+	ListEntriesMeetingBetween(from, to time.Time) ([]*entities.TimetableEntry, error)
+	UpdateEntry(entry *entities.TimetableEntry) error
+	DeleteEntry(entryID int, now time.Time) error
+
+	// Exceptions
+	CreateException(exception *entities.TimetableException) error
+	GetExceptionByID(exceptionID int) (*entities.TimetableException, error)
+	GetExceptionOn(entryID int, date time.Time) (*entities.TimetableException, error)
+	ListExceptions(entryID int, from, to *time.Time) ([]*entities.TimetableException, error)
+	DeleteException(exceptionID int) error
+
+	// Event generation
+	SyncEvents(entry *entities.TimetableEntry, occurrences []timetable.Occurrence, from, to, now time.Time) (timetable.SyncResult, error)
+}
+
+// TimetableRepo implements the TimetableRepoInterface.
+type TimetableRepo struct {
+	db *gorm.DB
+}
+
+// NewTimetableRepo returns a new instance of TimetableRepo.
+func NewTimetableRepo(db *gorm.DB) *TimetableRepo {
+	return &TimetableRepo{
+		db: db,
+	}
+}
+
+// CreateEntry creates a new timetable entry.
+func (tr *TimetableRepo) CreateEntry(entry *entities.TimetableEntry) error {
+	if err := tr.db.Omit("Course", "Semester").Create(entry).Error; err != nil {
+		return errors.New("failed to create timetable entry: " + err.Error())
+	}
+	return nil
+}
+
+// GetEntryByID retrieves a timetable entry with its course and semester.
+func (tr *TimetableRepo) GetEntryByID(entryID int) (*entities.TimetableEntry, error) {
+	var entry entities.TimetableEntry
+	if err := tr.db.Preload("Course").Preload("Semester").First(&entry, entryID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrEntryNotFound
+		}
+		return nil, errors.New("failed to retrieve timetable entry: " + err.Error())
+	}
+	return &entry, nil
+}
+
+// ListEntriesForCourse retrieves a course's timetable in weekday and time order.
+func (tr *TimetableRepo) ListEntriesForCourse(courseID int) ([]*entities.TimetableEntry, error) {
+	var entries []*entities.TimetableEntry
+	if err := tr.db.Preload("Course").
+		Where("course_id = ?", courseID).
+		Order("semester_id ASC, weekday ASC, start_time ASC").
+		Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to retrieve timetable: " + err.Error())
+	}
+	return entries, nil
+}
+
+// ListEntriesMeetingBetween retrieves the entries of courses that still exist
+// whose semester overlaps from to to.
+func (tr *TimetableRepo) ListEntriesMeetingBetween(from, to time.Time) ([]*entities.TimetableEntry, error) {
+	var entries []*entities.TimetableEntry
+	if err := tr.db.Preload("Course").Preload("Semester").
+		Joins("JOIN semesters sem ON sem.id = timetable_entries.semester_id AND sem.deleted_at IS NULL").
+		Joins("JOIN courses c ON c.id = timetable_entries.course_id AND c.deleted_at IS NULL").
+		Where("sem.start_date <= ? AND sem.end_date >= ?", day(to), day(from)).
+		Order("timetable_entries.id ASC").
+		Find(&entries).Error; err != nil {
+		return nil, errors.New("failed to retrieve timetable entries: " + err.Error())
+	}
+	return entries, nil
+}
+
+// UpdateEntry saves changes to an existing timetable entry.
+func (tr *TimetableRepo) UpdateEntry(entry *entities.TimetableEntry) error {
+	if err := tr.db.Omit("Course", "Semester").Save(entry).Error; err != nil {
+		return errors.New("failed to update timetable entry: " + err.Error())
+	}
+	return nil
+}
+
+// DeleteEntry permanently deletes a timetable entry and its exceptions, and
+// cancels its generated events that have not started by now. Past events keep
+// their attendance.
+func (tr *TimetableRepo) DeleteEntry(entryID int, now time.Time) error {
+	return tr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Event{}).
+			Where("timetable_entry_id = ? AND start_time > ?", entryID, now).
+			Update("status", entities.EventCancelled).Error; err != nil {
+			return errors.New("failed to cancel timetabled events: " + err.Error())
+		}
+		if err := tx.Unscoped().Where("entry_id = ?", entryID).Delete(&entities.TimetableException{}).Error; err != nil {
+			return errors.New("failed to delete timetable exceptions: " + err.Error())
+		}
+		if err := tx.Unscoped().Delete(&entities.TimetableEntry{}, entryID).Error; err != nil {
+			return errors.New("failed to delete timetable entry: " + err.Error())
+		}
+		return nil
+	})
+}
+
+// CreateException creates a new timetable exception.
+func (tr *TimetableRepo) CreateException(exception *entities.TimetableException) error {
+	if err := tr.db.Create(exception).Error; err != nil {
+		return errors.New("failed to create timetable exception: " + err.Error())
+	}
+	return nil
+}
+
+// GetExceptionByID retrieves a timetable exception by ID.
+func (tr *TimetableRepo) GetExceptionByID(exceptionID int) (*entities.TimetableException, error) {
+	var exception entities.TimetableException
+	if err := tr.db.First(&exception, exceptionID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExceptionNotFound
+		}
+		return nil, errors.New("failed to retrieve timetable exception: " + err.Error())
+	}
+	return &exception, nil
+}
+
+// GetExceptionOn retrieves the exception for an entry's occurrence on date.
+func (tr *TimetableRepo) GetExceptionOn(entryID int, date time.Time) (*entities.TimetableException, error) {
+	var exception entities.TimetableException
+	if err := tr.db.Where("entry_id = ? AND date = ?", entryID, day(date)).First(&exception).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExceptionNotFound
+		}
+		return nil, errors.New("failed to retrieve timetable exception: " + err.Error())
+	}
+	return &exception, nil
+}
+
+// ListExceptions retrieves an entry's exceptions dated from to to, in date
+// order. A nil bound leaves that side open.
+func (tr *TimetableRepo) ListExceptions(entryID int, from, to *time.Time) ([]*entities.TimetableException, error) {
+	query := tr.db.Where("entry_id = ?", entryID).Order("date ASC")
+	if from != nil {
+		query = query.Where("date >= ?", day(*from))
+	}
+	if to != nil {
+		query = query.Where("date <= ?", day(*to))
+	}
+
+	var exceptions []*entities.TimetableException
+	if err := query.Find(&exceptions).Error; err != nil {
+		return nil, errors.New("failed to retrieve timetable exceptions: " + err.Error())
+	}
+	return exceptions, nil
+}
+
+// DeleteException permanently deletes a timetable exception so the date can
+// be given a new one.
+func (tr *TimetableRepo) DeleteException(exceptionID int) error {
+	if err := tr.db.Unscoped().Delete(&entities.TimetableException{}, exceptionID).Error; err != nil {
+		return errors.New("failed to delete timetable exception: " + err.Error())
+	}
+	return nil
+}
+
+// SyncEvents brings the entry's generated events dated from to to in line with
+// occurrences. Missing occurrences are created unless cancelled or already
+// over; events that have not started by now are updated, cancelled or restored
+// to match; and events that have started are left alone. Concurrent syncs are
+// safe: the unique index on (timetable_entry_id, occurrence_date) turns a
+// second insert of the same occurrence into a no-op.
+func (tr *TimetableRepo) SyncEvents(entry *entities.TimetableEntry, occurrences []timetable.Occurrence, from, to, now time.Time) (timetable.SyncResult, error) {
+	var result timetable.SyncResult
+	err := tr.db.Transaction(func(tx *gorm.DB) error {
+		var existing []*entities.Event
+		if err := tx.Where("timetable_entry_id = ? AND occurrence_date BETWEEN ? AND ?", entry.ID, day(from), day(to)).
+			Find(&existing).Error; err != nil {
+			return errors.New("failed to retrieve timetabled events: " + err.Error())
+		}
+		byDate := make(map[string]*entities.Event, len(existing))
+		for _, event := range existing {
+			if event.OccurrenceDate != nil {
+				byDate[day(*event.OccurrenceDate)] = event
+			}
+		}
+
+		for _, occurrence := range occurrences {
+			key := day(occurrence.Date)
+			event, found := byDate[key]
+			delete(byDate, key)
+
+			if !found {
+				if occurrence.Cancelled || !occurrence.EndTime.After(now) {
+					continue
+				}
+				created, err := createOccurrence(tx, entry, occurrence)
+				if err != nil {
+					return err
+				}
+				if created {
+					result.Created++
+				}
+				continue
+			}
+
+			if !event.StartTime.After(now) {
+				continue
+			}
+			changes := occurrenceChanges(event, entry, occurrence)
+			if len(changes) == 0 {
+				continue
+			}
+			if err := tx.Model(event).Updates(changes).Error; err != nil {
+				return errors.New("failed to update timetabled event: " + err.Error())
+			}
+			if _, moved := changes["start_time"]; moved {
+				if err := calendarRepo.TagEvent(tx, event); err != nil {
+					return err
+				}
+			}
+			if changes["status"] == entities.EventCancelled {
+				result.Cancelled++
+			} else {
+				result.Updated++
+			}
+		}
+
+		// Whatever is left no longer belongs to the timetable
+		for _, event := range byDate {
+			if !event.StartTime.After(now) || event.Status == entities.EventCancelled {
+				continue
+			}
+			if err := tx.Model(event).Update("status", entities.EventCancelled).Error; err != nil {
+				return errors.New("failed to cancel timetabled event: " + err.Error())
+			}
+			result.Cancelled++
+		}
+		return nil
+	})
+	if err != nil {
+		return timetable.SyncResult{}, err
+	}
+	return result, nil
+}
+
+// createOccurrence inserts the event for an occurrence and tags it with its
+// semester. It reports false when another sync created the event first.
+func createOccurrence(tx *gorm.DB, entry *entities.TimetableEntry, occurrence timetable.Occurrence) (bool, error) {
+	courseID := entry.CourseID
+	entryID := int(entry.ID)
+	date := occurrence.Date
+	event := &entities.Event{
+		CourseID:    &courseID,
+		EventName:   fmt.Sprintf("%s (%s)", entry.Course.Title, entry.Course.Code),
+		StartTime:   occurrence.StartTime,
+		EndTime:     occurrence.EndTime,
+		Venue:       occurrence.Venue,
+		QRCodeToken: uuid.New().String(),
+		QRMode:      entry.QRMode,
+		QRRotation:  entry.QRRotation,
+
+		VenueID:      occurrence.VenueID,
+		GeofenceMode: entry.GeofenceMode,
+
+		GracePeriodMinutes: entry.GracePeriodMinutes,
+		LateCutoffMinutes:  entry.LateCutoffMinutes,
+
+		Status:           entities.EventScheduled,
+		CreatedBy:        entry.CreatedBy,
+		TimetableEntryID: &entryID,
+		OccurrenceDate:   &date,
+	}
+
+	res := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(event)
+	if res.Error != nil {
+		return false, errors.New("failed to create timetabled event: " + res.Error.Error())
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+	if err := calendarRepo.TagEvent(tx, event); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// occurrenceChanges returns the columns of a generated event that differ from
+// what its entry and occurrence now call for.
+func occurrenceChanges(event *entities.Event, entry *entities.TimetableEntry, occurrence timetable.Occurrence) map[string]interface{} {
+	status := entities.EventScheduled
+	if occurrence.Cancelled {
+		status = entities.EventCancelled
+	}
+
+	changes := map[string]interface{}{}
+	if !event.StartTime.Equal(occurrence.StartTime) {
+		changes["start_time"] = occurrence.StartTime
+	}
+	if !event.EndTime.Equal(occurrence.EndTime) {
+		changes["end_time"] = occurrence.EndTime
+	}
+	if event.Venue != occurrence.Venue {
+		changes["venue"] = occurrence.Venue
+	}
+	if !sameInt(event.VenueID, occurrence.VenueID) {
+		changes["venue_id"] = occurrence.VenueID
+	}
+	if event.GeofenceMode != entry.GeofenceMode {
+		changes["geofence_mode"] = entry.GeofenceMode
+	}
+	if event.QRMode != entry.QRMode {
+		changes["qr_mode"] = entry.QRMode
+	}
+	if event.QRRotation != entry.QRRotation {
+		changes["qr_rotation_seconds"] = entry.QRRotation
+	}
+	if event.GracePeriodMinutes != entry.GracePeriodMinutes {
+		changes["grace_period_minutes"] = entry.GracePeriodMinutes
+	}
+	if !sameInt(event.LateCutoffMinutes, entry.LateCutoffMinutes) {
+		changes["late_cutoff_minutes"] = entry.LateCutoffMinutes
+	}
+	if event.Status != status {
+		changes["status"] = status
+	}
+	return changes
+}
+
+// sameInt reports whether two optional integers are equal.
+func sameInt(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// day formats a date for comparison with date columns.
+func day(t time.Time) string {
+	return t.Format(calendar.DateLayout)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	calendar "github.com/Dom-HTG/attendance-management-system/internal/calendar/domain"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
+	timetable "github.com/Dom-HTG/attendance-management-system/internal/timetable/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/timetable/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+)
+
+// Generator is a background job that keeps the events of every timetable
+// entry created horizonDays ahead, so lecturers only need to open the QR code
+// when class starts. Holidays are skipped and exceptions applied. It is safe
+// to run on several replicas at once; see TimetableRepo.SyncEvents.
+type Generator struct {
+	timetableRepo repository.TimetableRepoInterface
+	calendarRepo  calendarRepo.CalendarRepoInterface
+	location      *time.Location
+	horizonDays   int
+	interval      time.Duration
+}
+
+// NewGenerator returns a new Generator that runs every interval. Class times
+// are read in location.
+func NewGenerator(timetableRepo repository.TimetableRepoInterface, calendarRepo calendarRepo.CalendarRepoInterface, location *time.Location, horizonDays int, interval time.Duration) *Generator {
+	return &Generator{
+		timetableRepo: timetableRepo,
+		calendarRepo:  calendarRepo,
+		location:      location,
+		horizonDays:   horizonDays,
+		interval:      interval,
+	}
+}
+
+// Run syncs every entry immediately and then on every tick until ctx is
+// cancelled. Ticks also pick up changes to semesters and holidays.
+func (g *Generator) Run(ctx context.Context) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	for {
+		g.RunOnce()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce syncs the events of every entry whose semester overlaps the horizon.
+func (g *Generator) RunOnce() {
+	now := time.Now()
+	from, to := g.window(now)
+
+	entries, err := g.timetableRepo.ListEntriesMeetingBetween(from, to)
+	if err != nil {
+		logger.Errorf("timetable generator failed: %v", err)
+		return
+	}
+	holidays, err := g.calendarRepo.ListHolidays(&from, &to)
+	if err != nil {
+		logger.Errorf("timetable generator failed: %v", err)
+		return
+	}
+
+	var total timetable.SyncResult
+	for _, entry := range entries {
+		result, err := g.sync(entry, holidays, from, to, now)
+		if err != nil {
+			logger.Errorf("timetable generator failed for entry %d: %v", entry.ID, err)
+			continue
+		}
+		total.Created += result.Created
+		total.Updated += result.Updated
+		total.Cancelled += result.Cancelled
+	}
+	if total != (timetable.SyncResult{}) {
+		logger.Infof("timetable generator created %d, updated %d and cancelled %d event(s)", total.Created, total.Updated, total.Cancelled)
+	}
+}
+
+// SyncEntry syncs one entry's events right away, after the entry or its
+// exceptions changed. The entry must have its course and semester loaded.
+func (g *Generator) SyncEntry(entry *entities.TimetableEntry) (timetable.SyncResult, error) {
+	now := time.Now()
+	from, to := g.window(now)
+
+	holidays, err := g.calendarRepo.ListHolidays(&from, &to)
+	if err != nil {
+		return timetable.SyncResult{}, err
+	}
+	return g.sync(entry, holidays, from, to, now)
+}
+
+// sync applies an entry's occurrences between from and to to its events.
+func (g *Generator) sync(entry *entities.TimetableEntry, holidays []*entities.Holiday, from, to, now time.Time) (timetable.SyncResult, error) {
+	exceptions, err := g.timetableRepo.ListExceptions(int(entry.ID), &from, &to)
+	if err != nil {
+		return timetable.SyncResult{}, err
+	}
+	occurrences := occurrencesBetween(entry, from, to, g.location)
+	applyCalendar(occurrences, holidays, exceptions)
+	return g.timetableRepo.SyncEvents(entry, occurrences, from, to, now)
+}
+
+// window returns the dates events are kept generated for: today, in the
+// timetable's time zone, through horizonDays later.
+func (g *Generator) window(now time.Time) (time.Time, time.Time) {
+	local := now.In(g.location)
+	from := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+	return from, from.AddDate(0, 0, g.horizonDays)
+}
+
+// occurrencesBetween returns the meetings of an entry dated from to to, before
+// holidays and exceptions are applied. Class times are read in location.
+func occurrencesBetween(entry *entities.TimetableEntry, from, to time.Time, location *time.Location) []timetable.Occurrence {
+	semester := entry.Semester
+	if semester == nil {
+		return nil
+	}
+	clock, err := time.Parse(timetable.TimeLayout, entry.StartTime)
+	if err != nil {
+		return nil
+	}
+	interval := entry.IntervalWeeks
+	if interval < 1 {
+		interval = 1
+	}
+
+	var occurrences []timetable.Occurrence
+	for week := entry.FirstWeek; week <= entry.LastWeek; week += interval {
+		weekStart := semester.StartDate.AddDate(0, 0, 7*(week-1))
+		date := weekStart.AddDate(0, 0, (entry.Weekday-int(weekStart.Weekday())+7)%7)
+		if date.Before(from) || date.After(to) || date.After(semester.EndDate) {
+			continue
+		}
+
+		start := time.Date(date.Year(), date.Month(), date.Day(), clock.Hour(), clock.Minute(), 0, 0, location)
+		occurrences = append(occurrences, timetable.Occurrence{
+			Date:      date,
+			StartTime: start,
+			EndTime:   start.Add(time.Duration(entry.DurationMinutes) * time.Minute),
+			Venue:     entry.Venue,
+			VenueID:   entry.VenueID,
+		})
+	}
+	return occurrences
+}
+
+// applyCalendar cancels occurrences that fall on a holiday and applies the
+// entry's exceptions. A rescheduled occurrence goes ahead even on a holiday,
+// since the new time was chosen deliberately.
+func applyCalendar(occurrences []timetable.Occurrence, holidays []*entities.Holiday, exceptions []*entities.TimetableException) {
+	byDate := make(map[string]*entities.TimetableException, len(exceptions))
+	for _, exception := range exceptions {
+		byDate[exception.Date.Format(calendar.DateLayout)] = exception
+	}
+
+	for i := range occurrences {
+		o := &occurrences[i]
+		for _, holiday := range holidays {
+			if !o.Date.Before(holiday.StartDate) && !o.Date.After(holiday.EndDate) {
+				o.Cancelled = true
+				break
+			}
+		}
+
+		exception, ok := byDate[o.Date.Format(calendar.DateLayout)]
+		if !ok {
+			continue
+		}
+		switch exception.Action {
+		case entities.TimetableCancel:
+			o.Cancelled = true
+		case entities.TimetableReschedule:
+			if exception.StartTime == nil || exception.EndTime == nil {
+				continue
+			}
+			o.StartTime = *exception.StartTime
+			o.EndTime = *exception.EndTime
+			if exception.Venue != "" {
+				o.Venue = exception.Venue
+				o.VenueID = exception.VenueID
+			}
+			o.Cancelled = false
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	timetable "github.com/Dom-HTG/attendance-management-system/internal/timetable/domain"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestOccurrencesBetween(t *testing.T) {
+	lagos := time.FixedZone("WAT", 3600)
+	// The semester starts on a Monday, so teaching week 1 is 6 to 12 January
+	semester := &entities.Semester{StartDate: date(2025, 1, 6), EndDate: date(2025, 3, 28)}
+	wednesdayStart := &entities.Semester{StartDate: date(2025, 1, 8), EndDate: date(2025, 3, 28)}
+
+	entry := func(weekday, first, last, interval int, sem *entities.Semester) *entities.TimetableEntry {
+		return &entities.TimetableEntry{
+			Semester:        sem,
+			Weekday:         weekday,
+			StartTime:       "10:00",
+			DurationMinutes: 90,
+			FirstWeek:       first,
+			LastWeek:        last,
+			IntervalWeeks:   interval,
+			Venue:           "LT1",
+		}
+	}
+
+	tests := []struct {
+		name     string
+		entry    *entities.TimetableEntry
+		from, to time.Time
+		want     []time.Time
+	}{
+		{
+			name:  "weekly",
+			entry: entry(int(time.Wednesday), 1, 4, 1, semester),
+			from:  date(2025, 1, 1), to: date(2025, 12, 31),
+			want: []time.Time{date(2025, 1, 8), date(2025, 1, 15), date(2025, 1, 22), date(2025, 1, 29)},
+		},
+		{
+			name:  "fortnightly",
+			entry: entry(int(time.Wednesday), 1, 6, 2, semester),
+			from:  date(2025, 1, 1), to: date(2025, 12, 31),
+			want: []time.Time{date(2025, 1, 8), date(2025, 1, 22), date(2025, 2, 5)},
+		},
+		{
+			name:  "interval below one is weekly",
+			entry: entry(int(time.Monday), 1, 2, 0, semester),
+			from:  date(2025, 1, 1), to: date(2025, 12, 31),
+			want: []time.Time{date(2025, 1, 6), date(2025, 1, 13)},
+		},
+		{
+			name:  "starts from a later week",
+			entry: entry(int(time.Friday), 3, 4, 1, semester),
+			from:  date(2025, 1, 1), to: date(2025, 12, 31),
+			want: []time.Time{date(2025, 1, 24), date(2025, 1, 31)},
+		},
+		{
+			name:  "weekday before the semester's first day falls later in week one",
+			entry: entry(int(time.Monday), 1, 2, 1, wednesdayStart),
+			from:  date(2025, 1, 1), to: date(2025, 12, 31),
+			want: []time.Time{date(2025, 1, 13), date(2025, 1, 20)},
+		},
+		{
+			name:  "window bounds are inclusive",
+			entry: entry(int(time.Wednesday), 1, 4, 1, semester),
+			from:  date(2025, 1, 15), to: date(2025, 1, 22),
+			want: []time.Time{date(2025, 1, 15), date(2025, 1, 22)},
+		},
+		{
+			name:  "stops at the semester end",
+			entry: entry(int(time.Wednesday), 11, 14, 1, semester),
+			from:  date(2025, 1, 1), to: date(2025, 12, 31),
+			want: []time.Time{date(2025, 3, 19), date(2025, 3, 26)},
+		},
+		{
+			name:  "no semester",
+			entry: entry(int(time.Wednesday), 1, 4, 1, nil),
+			from:  date(2025, 1, 1), to: date(2025, 12, 31),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := occurrencesBetween(tt.entry, tt.from, tt.to, lagos)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, o := range got {
+				if !o.Date.Equal(tt.want[i]) {
+					t.Errorf("occurrence %d date = %s, want %s", i, o.Date.Format("2006-01-02"), tt.want[i].Format("2006-01-02"))
+				}
+				wantStart := time.Date(tt.want[i].Year(), tt.want[i].Month(), tt.want[i].Day(), 10, 0, 0, 0, lagos)
+				if !o.StartTime.Equal(wantStart) || !o.EndTime.Equal(wantStart.Add(90*time.Minute)) {
+					t.Errorf("occurrence %d = %s to %s, want %s for 90 minutes", i, o.StartTime, o.EndTime, wantStart)
+				}
+				if o.Venue != "LT1" || o.Cancelled {
+					t.Errorf("occurrence %d = %+v, want venue LT1 and not cancelled", i, o)
+				}
+			}
+		})
+	}
+}
+
+func TestOccurrencesBetweenInvalidStartTime(t *testing.T) {
+	entry := &entities.TimetableEntry{
+		Semester:  &entities.Semester{StartDate: date(2025, 1, 6), EndDate: date(2025, 3, 28)},
+		Weekday:   int(time.Monday),
+		StartTime: "25:00",
+		FirstWeek: 1,
+		LastWeek:  2,
+	}
+	if got := occurrencesBetween(entry, date(2025, 1, 1), date(2025, 12, 31), time.UTC); got != nil {
+		t.Errorf("got %v, want no occurrences", got)
+	}
+}
+
+func TestApplyCalendar(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return time.Date(2025, 1, day, hour, 0, 0, 0, time.UTC)
+	}
+	occurrence := func(day int) timetable.Occurrence {
+		return timetable.Occurrence{Date: date(2025, 1, day), StartTime: at(day, 10), EndTime: at(day, 11), Venue: "LT1"}
+	}
+	venueID := 7
+	newStart, newEnd := at(16, 14), at(16, 15)
+
+	holidays := []*entities.Holiday{
+		{StartDate: date(2025, 1, 14), EndDate: date(2025, 1, 16)}, // Covers the 15th
+	}
+
+	tests := []struct {
+		name       string
+		day        int
+		exceptions []*entities.TimetableException
+		want       timetable.Occurrence
+	}{
+		{
+			name: "ordinary day",
+			day:  8,
+			want: occurrence(8),
+		},
+		{
+			name: "holiday",
+			day:  15,
+			want: func() timetable.Occurrence { o := occurrence(15); o.Cancelled = true; return o }(),
+		},
+		{
+			name:       "cancelled",
+			day:        22,
+			exceptions: []*entities.TimetableException{{Date: date(2025, 1, 22), Action: entities.TimetableCancel}},
+			want:       func() timetable.Occurrence { o := occurrence(22); o.Cancelled = true; return o }(),
+		},
+		{
+			name: "rescheduled with a new venue",
+			day:  22,
+			exceptions: []*entities.TimetableException{{
+				Date: date(2025, 1, 22), Action: entities.TimetableReschedule,
+				StartTime: &newStart, EndTime: &newEnd, Venue: "LT2", VenueID: &venueID,
+			}},
+			want: timetable.Occurrence{Date: date(2025, 1, 22), StartTime: newStart, EndTime: newEnd, Venue: "LT2", VenueID: &venueID},
+		},
+		{
+			name: "rescheduled keeps the venue when none is given",
+			day:  22,
+			exceptions: []*entities.TimetableException{{
+				Date: date(2025, 1, 22), Action: entities.TimetableReschedule,
+				StartTime: &newStart, EndTime: &newEnd,
+			}},
+			want: timetable.Occurrence{Date: date(2025, 1, 22), StartTime: newStart, EndTime: newEnd, Venue: "LT1"},
+		},
+		{
+			name: "rescheduled occurrence goes ahead on a holiday",
+			day:  15,
+			exceptions: []*entities.TimetableException{{
+				Date: date(2025, 1, 15), Action: entities.TimetableReschedule,
+				StartTime: &newStart, EndTime: &newEnd,
+			}},
+			want: timetable.Occurrence{Date: date(2025, 1, 15), StartTime: newStart, EndTime: newEnd, Venue: "LT1"},
+		},
+		{
+			name: "reschedule without times is ignored",
+			day:  22,
+			exceptions: []*entities.TimetableException{{
+				Date: date(2025, 1, 22), Action: entities.TimetableReschedule, Venue: "LT2",
+			}},
+			want: occurrence(22),
+		},
+		{
+			name:       "exception for another date",
+			day:        8,
+			exceptions: []*entities.TimetableException{{Date: date(2025, 1, 22), Action: entities.TimetableCancel}},
+			want:       occurrence(8),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			occurrences := []timetable.Occurrence{occurrence(tt.day)}
+			applyCalendar(occurrences, holidays, tt.exceptions)
+			got := occurrences[0]
+
+			if !got.Date.Equal(tt.want.Date) || !got.StartTime.Equal(tt.want.StartTime) || !got.EndTime.Equal(tt.want.EndTime) ||
+				got.Venue != tt.want.Venue || got.Cancelled != tt.want.Cancelled {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if (got.VenueID == nil) != (tt.want.VenueID == nil) || (got.VenueID != nil && *got.VenueID != *tt.want.VenueID) {
+				t.Errorf("venue ID = %v, want %v", got.VenueID, tt.want.VenueID)
+			}
+		})
+	}
+}
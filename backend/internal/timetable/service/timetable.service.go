@@ -0,0 +1,632 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	calendar "github.com/Dom-HTG/attendance-management-system/internal/calendar/domain"
+	calendarRepo "github.com/Dom-HTG/attendance-management-system/internal/calendar/repository"
+	courseRepo "github.com/Dom-HTG/attendance-management-system/internal/course/repository"
+	timetable "github.com/Dom-HTG/attendance-management-system/internal/timetable/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/timetable/repository"
+	venueRepo "github.com/Dom-HTG/attendance-management-system/internal/venue/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultQRRotationSeconds is used for rotating entries created without an explicit period.
+	defaultQRRotationSeconds = 30
+	// defaultGracePeriodMinutes is how long after the start a check-in still counts as on time.
+	defaultGracePeriodMinutes = 5
+)
+
+// weekdays maps the weekday names accepted in requests to time.Weekday.
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+// TimetableSvcInterface defines the service interface for course timetables.
+type TimetableSvcInterface interface {
+	CreateEntry(ctx *gin.Context)
+	ListEntries(ctx *gin.Context)
+	UpdateEntry(ctx *gin.Context)
+	DeleteEntry(ctx *gin.Context)
+	CreateException(ctx *gin.Context)
+	ListExceptions(ctx *gin.Context)
+	DeleteException(ctx *gin.Context)
+}
+
+// TimetableSvc implements the TimetableSvcInterface.
+type TimetableSvc struct {
+	timetableRepo repository.TimetableRepoInterface
+	courseRepo    courseRepo.CourseRepoInterface
+	venueRepo     venueRepo.VenueRepoInterface
+	calendarRepo  calendarRepo.CalendarRepoInterface
+	generator     *Generator
+}
+
+// NewTimetableSvc returns a new instance of TimetableSvc. Changes are synced
+// to events through generator straight away.
+func NewTimetableSvc(timetableRepo repository.TimetableRepoInterface, courseRepo courseRepo.CourseRepoInterface, venueRepo venueRepo.VenueRepoInterface, calendarRepo calendarRepo.CalendarRepoInterface, generator *Generator) *TimetableSvc {
+	return &TimetableSvc{
+		timetableRepo: timetableRepo,
+		courseRepo:    courseRepo,
+		venueRepo:     venueRepo,
+		calendarRepo:  calendarRepo,
+		generator:     generator,
+	}
+}
+
+// CreateEntry handles POST /api/lecturer/courses/{course_id}/timetable.
+func (ts *TimetableSvc) CreateEntry(ctx *gin.Context) {
+	course, lecturerID, ok := ts.loadOwnedCourse(ctx)
+	if !ok {
+		return
+	}
+
+	var req timetable.CreateEntryDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	semester, err := ts.calendarRepo.GetSemesterByID(req.SemesterID)
+	if err != nil {
+		if errors.Is(err, calendarRepo.ErrSemesterNotFound) {
+			responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+			return
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve semester", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	entry := &entities.TimetableEntry{
+		CourseID:        int(course.ID),
+		Course:          course,
+		SemesterID:      int(semester.ID),
+		Semester:        semester,
+		StartTime:       strings.TrimSpace(req.StartTime),
+		DurationMinutes: req.DurationMinutes,
+		IntervalWeeks:   req.IntervalWeeks,
+		FirstWeek:       req.FirstWeek,
+		LastWeek:        req.LastWeek,
+		Venue:           strings.TrimSpace(req.Venue),
+		VenueID:         req.VenueID,
+		GeofenceMode:    req.GeofenceMode,
+		QRMode:          req.QRMode,
+		QRRotation:      req.RotationSeconds,
+
+		GracePeriodMinutes: defaultGracePeriodMinutes,
+		LateCutoffMinutes:  req.LateCutoffMinutes,
+
+		CreatedBy: &lecturerID,
+	}
+	if entry.IntervalWeeks == 0 {
+		entry.IntervalWeeks = 1
+	}
+	if entry.FirstWeek == 0 {
+		entry.FirstWeek = 1
+	}
+	if entry.LastWeek == 0 {
+		entry.LastWeek = semester.TeachingWeeks
+	}
+	if entry.GeofenceMode == "" {
+		entry.GeofenceMode = entities.GeofenceOff
+	}
+	if entry.QRMode == "" {
+		entry.QRMode = entities.QRModeStatic
+	}
+	if req.GracePeriodMinutes != nil {
+		entry.GracePeriodMinutes = *req.GracePeriodMinutes
+	}
+	if !setWeekday(ctx, entry, req.Weekday) || !ts.validateEntry(ctx, entry) {
+		return
+	}
+
+	if err := ts.timetableRepo.CreateEntry(entry); err != nil {
+		responses.ApiFailure(ctx, "Failed to create timetable entry", http.StatusInternalServerError, err.Error())
+		return
+	}
+	ts.sync(entry)
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Timetable entry created successfully", toEntryResponse(entry))
+}
+
+// ListEntries handles GET /api/lecturer/courses/{course_id}/timetable.
+func (ts *TimetableSvc) ListEntries(ctx *gin.Context) {
+	course, _, ok := ts.loadOwnedCourse(ctx)
+	if !ok {
+		return
+	}
+
+	entries, err := ts.timetableRepo.ListEntriesForCourse(int(course.ID))
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve timetable", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []timetable.EntryResponse{}
+	for _, e := range entries {
+		result = append(result, toEntryResponse(e))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Timetable retrieved successfully", result)
+}
+
+// UpdateEntry handles PUT /api/lecturer/timetable/{entry_id}. Generated events
+// that have not started yet are updated to match.
+func (ts *TimetableSvc) UpdateEntry(ctx *gin.Context) {
+	entry, ok := ts.loadOwnedEntry(ctx)
+	if !ok {
+		return
+	}
+
+	var req timetable.UpdateEntryDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Weekday != nil && !setWeekday(ctx, entry, *req.Weekday) {
+		return
+	}
+	if req.StartTime != nil {
+		entry.StartTime = strings.TrimSpace(*req.StartTime)
+	}
+	if req.DurationMinutes != nil {
+		entry.DurationMinutes = *req.DurationMinutes
+	}
+	if req.IntervalWeeks != nil {
+		entry.IntervalWeeks = *req.IntervalWeeks
+	}
+	if req.FirstWeek != nil {
+		entry.FirstWeek = *req.FirstWeek
+	}
+	if req.LastWeek != nil {
+		entry.LastWeek = *req.LastWeek
+	}
+	if req.VenueID != nil {
+		entry.VenueID = req.VenueID
+		if *req.VenueID == 0 {
+			entry.VenueID = nil
+		}
+	}
+	if req.Venue != nil {
+		entry.Venue = strings.TrimSpace(*req.Venue)
+	}
+	if req.GeofenceMode != nil {
+		entry.GeofenceMode = *req.GeofenceMode
+	}
+	if req.GracePeriodMinutes != nil {
+		entry.GracePeriodMinutes = *req.GracePeriodMinutes
+	}
+	if req.LateCutoffMinutes != nil {
+		entry.LateCutoffMinutes = req.LateCutoffMinutes
+	}
+	if req.QRMode != nil {
+		entry.QRMode = *req.QRMode
+	}
+	if req.RotationSeconds != nil {
+		entry.QRRotation = *req.RotationSeconds
+	}
+	if !ts.validateEntry(ctx, entry) {
+		return
+	}
+
+	if err := ts.timetableRepo.UpdateEntry(entry); err != nil {
+		responses.ApiFailure(ctx, "Failed to update timetable entry", http.StatusInternalServerError, err.Error())
+		return
+	}
+	ts.sync(entry)
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Timetable entry updated successfully", toEntryResponse(entry))
+}
+
+// DeleteEntry handles DELETE /api/lecturer/timetable/{entry_id}. Generated
+// events that have not started yet are cancelled; past events are kept.
+func (ts *TimetableSvc) DeleteEntry(ctx *gin.Context) {
+	entry, ok := ts.loadOwnedEntry(ctx)
+	if !ok {
+		return
+	}
+
+	if err := ts.timetableRepo.DeleteEntry(int(entry.ID), time.Now()); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete timetable entry", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Timetable entry deleted successfully", nil)
+}
+
+// CreateException handles POST /api/lecturer/timetable/{entry_id}/exceptions.
+// It cancels or reschedules one future occurrence of the entry.
+func (ts *TimetableSvc) CreateException(ctx *gin.Context) {
+	entry, ok := ts.loadOwnedEntry(ctx)
+	if !ok {
+		return
+	}
+	lecturerID, _ := middleware.GetUserIDFromContext(ctx)
+
+	var req timetable.CreateExceptionDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	date, err := time.Parse(calendar.DateLayout, strings.TrimSpace(req.Date))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid date; use YYYY-MM-DD", http.StatusBadRequest, err.Error())
+		return
+	}
+	occurrences := occurrencesBetween(entry, date, date, ts.generator.location)
+	if len(occurrences) == 0 {
+		responses.ApiFailure(ctx, fmt.Sprintf("This class does not meet on %s", req.Date), http.StatusBadRequest, nil)
+		return
+	}
+	if !occurrences[0].StartTime.After(time.Now()) {
+		responses.ApiFailure(ctx, "Only classes that have not started can be cancelled or rescheduled", http.StatusBadRequest, nil)
+		return
+	}
+
+	if _, err := ts.timetableRepo.GetExceptionOn(int(entry.ID), date); err == nil {
+		responses.ApiFailure(ctx, fmt.Sprintf("The class on %s has already been changed; delete that change first", req.Date), http.StatusConflict, nil)
+		return
+	} else if !errors.Is(err, repository.ErrExceptionNotFound) {
+		responses.ApiFailure(ctx, "Failed to check timetable exceptions", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	exception := &entities.TimetableException{
+		EntryID:   int(entry.ID),
+		Date:      date,
+		Action:    req.Action,
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: &lecturerID,
+	}
+	if req.Action == entities.TimetableReschedule && !ts.setReschedule(ctx, exception, &req) {
+		return
+	}
+
+	if err := ts.timetableRepo.CreateException(exception); err != nil {
+		responses.ApiFailure(ctx, "Failed to create timetable exception", http.StatusInternalServerError, err.Error())
+		return
+	}
+	ts.sync(entry)
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Timetable exception created successfully", toExceptionResponse(exception))
+}
+
+// ListExceptions handles GET /api/lecturer/timetable/{entry_id}/exceptions.
+func (ts *TimetableSvc) ListExceptions(ctx *gin.Context) {
+	entry, ok := ts.loadOwnedEntry(ctx)
+	if !ok {
+		return
+	}
+
+	exceptions, err := ts.timetableRepo.ListExceptions(int(entry.ID), nil, nil)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve timetable exceptions", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []timetable.ExceptionResponse{}
+	for _, e := range exceptions {
+		result = append(result, toExceptionResponse(e))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Timetable exceptions retrieved successfully", result)
+}
+
+// DeleteException handles DELETE /api/lecturer/timetable/{entry_id}/exceptions/{exception_id}.
+// The occurrence goes back to the entry's usual time and venue.
+func (ts *TimetableSvc) DeleteException(ctx *gin.Context) {
+	entry, ok := ts.loadOwnedEntry(ctx)
+	if !ok {
+		return
+	}
+
+	exceptionID, err := strconv.Atoi(ctx.Param("exception_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid exception ID", http.StatusBadRequest, err.Error())
+		return
+	}
+	exception, err := ts.timetableRepo.GetExceptionByID(exceptionID)
+	if err == nil && exception.EntryID != int(entry.ID) {
+		err = repository.ErrExceptionNotFound
+	}
+	if err != nil {
+		respondTimetableLookupError(ctx, err)
+		return
+	}
+
+	if err := ts.timetableRepo.DeleteException(exceptionID); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete timetable exception", http.StatusInternalServerError, err.Error())
+		return
+	}
+	ts.sync(entry)
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Timetable exception deleted successfully", nil)
+}
+
+// sync brings the entry's events up to date after a change. Failures are
+// logged rather than returned, since the generator retries on its next run.
+func (ts *TimetableSvc) sync(entry *entities.TimetableEntry) {
+	if _, err := ts.generator.SyncEntry(entry); err != nil {
+		logger.Errorf("failed to sync events for timetable entry %d: %v", entry.ID, err)
+	}
+}
+
+// validateEntry checks an entry's time, weeks, venue and check-in settings,
+// resolving a managed venue's name into entry.Venue.
+// It writes the failure response itself and returns false when the request should stop.
+func (ts *TimetableSvc) validateEntry(ctx *gin.Context, entry *entities.TimetableEntry) bool {
+	clock, err := time.Parse(timetable.TimeLayout, entry.StartTime)
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid start_time; use HH:MM", http.StatusBadRequest, err.Error())
+		return false
+	}
+	entry.StartTime = clock.Format(timetable.TimeLayout)
+
+	if entry.FirstWeek > entry.LastWeek {
+		responses.ApiFailure(ctx, "first_week must not be after last_week", http.StatusBadRequest, nil)
+		return false
+	}
+	if entry.LastWeek > entry.Semester.TeachingWeeks {
+		responses.ApiFailure(ctx, fmt.Sprintf("Semester %s has %d teaching weeks", entry.Semester.Name, entry.Semester.TeachingWeeks), http.StatusBadRequest, nil)
+		return false
+	}
+
+	if entry.VenueID != nil {
+		venue, err := ts.venueRepo.GetVenueByID(*entry.VenueID)
+		if err != nil {
+			if errors.Is(err, venueRepo.ErrVenueNotFound) {
+				responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+				return false
+			}
+			responses.ApiFailure(ctx, "Failed to retrieve venue", http.StatusInternalServerError, err.Error())
+			return false
+		}
+		entry.Venue = venue.Name
+	}
+	if entry.Venue == "" {
+		responses.ApiFailure(ctx, "venue or venue_id is required", http.StatusBadRequest, nil)
+		return false
+	}
+	if entry.GeofenceMode != entities.GeofenceOff && entry.VenueID == nil {
+		responses.ApiFailure(ctx, "geofence_mode requires a venue_id", http.StatusBadRequest, nil)
+		return false
+	}
+
+	if entry.LateCutoffMinutes != nil && *entry.LateCutoffMinutes < entry.GracePeriodMinutes {
+		responses.ApiFailure(ctx, "late_cutoff_minutes must not be less than grace_period_minutes", http.StatusBadRequest, nil)
+		return false
+	}
+	if entry.LateCutoffMinutes != nil && *entry.LateCutoffMinutes > entry.DurationMinutes {
+		responses.ApiFailure(ctx, "late_cutoff_minutes must not be longer than the class", http.StatusBadRequest, nil)
+		return false
+	}
+
+	switch entry.QRMode {
+	case entities.QRModeRotating:
+		if entry.QRRotation == 0 {
+			entry.QRRotation = defaultQRRotationSeconds
+		}
+	default:
+		entry.QRRotation = 0
+	}
+	return true
+}
+
+// setReschedule reads the new time and venue of a rescheduled occurrence.
+// It writes the failure response itself and returns false when the request should stop.
+func (ts *TimetableSvc) setReschedule(ctx *gin.Context, exception *entities.TimetableException, req *timetable.CreateExceptionDTO) bool {
+	if req.StartTime == "" || req.EndTime == "" {
+		responses.ApiFailure(ctx, "start_time and end_time are required to reschedule a class", http.StatusBadRequest, nil)
+		return false
+	}
+	start, err := time.Parse(time.RFC3339, req.StartTime)
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid start_time; use RFC3339 (e.g. 2025-11-27T10:00:00Z)", http.StatusBadRequest, err.Error())
+		return false
+	}
+	end, err := time.Parse(time.RFC3339, req.EndTime)
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid end_time; use RFC3339 (e.g. 2025-11-27T11:00:00Z)", http.StatusBadRequest, err.Error())
+		return false
+	}
+	if !end.After(start) {
+		responses.ApiFailure(ctx, "end_time must be after start_time", http.StatusBadRequest, nil)
+		return false
+	}
+	if !start.After(time.Now()) {
+		responses.ApiFailure(ctx, "A class can only be rescheduled to a time in the future", http.StatusBadRequest, nil)
+		return false
+	}
+	exception.StartTime = &start
+	exception.EndTime = &end
+
+	exception.Venue = strings.TrimSpace(req.Venue)
+	if req.VenueID != nil {
+		venue, err := ts.venueRepo.GetVenueByID(*req.VenueID)
+		if err != nil {
+			if errors.Is(err, venueRepo.ErrVenueNotFound) {
+				responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+				return false
+			}
+			responses.ApiFailure(ctx, "Failed to retrieve venue", http.StatusInternalServerError, err.Error())
+			return false
+		}
+		exception.Venue = venue.Name
+		exception.VenueID = req.VenueID
+	}
+	return true
+}
+
+// setWeekday sets an entry's weekday from its name.
+// It writes the failure response itself and returns false when the request should stop.
+func setWeekday(ctx *gin.Context, entry *entities.TimetableEntry, name string) bool {
+	weekday, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		responses.ApiFailure(ctx, "Invalid weekday; use monday to sunday", http.StatusBadRequest, nil)
+		return false
+	}
+	entry.Weekday = int(weekday)
+	return true
+}
+
+// loadOwnedCourse loads the course in the URL and checks that the caller
+// lectures it.
+// It writes the failure response itself and returns false when the request should stop.
+func (ts *TimetableSvc) loadOwnedCourse(ctx *gin.Context) (*entities.Course, int, bool) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return nil, 0, false
+	}
+
+	courseID, err := strconv.Atoi(ctx.Param("course_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid course ID", http.StatusBadRequest, err.Error())
+		return nil, 0, false
+	}
+
+	course, err := ts.courseRepo.GetCourseByID(courseID)
+	if err != nil {
+		if errors.Is(err, courseRepo.ErrCourseNotFound) {
+			responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+			return nil, 0, false
+		}
+		responses.ApiFailure(ctx, "Failed to retrieve course", http.StatusInternalServerError, err.Error())
+		return nil, 0, false
+	}
+
+	if !ts.checkCourseLecturer(ctx, courseID, lecturerID) {
+		return nil, 0, false
+	}
+	return course, lecturerID, true
+}
+
+// loadOwnedEntry loads the timetable entry in the URL and checks that the
+// caller lectures its course.
+// It writes the failure response itself and returns false when the request should stop.
+func (ts *TimetableSvc) loadOwnedEntry(ctx *gin.Context) (*entities.TimetableEntry, bool) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return nil, false
+	}
+
+	entryID, err := strconv.Atoi(ctx.Param("entry_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid timetable entry ID", http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	entry, err := ts.timetableRepo.GetEntryByID(entryID)
+	if err != nil {
+		respondTimetableLookupError(ctx, err)
+		return nil, false
+	}
+	if entry.Course == nil || entry.Semester == nil {
+		responses.ApiFailure(ctx, "This timetable entry's course or semester no longer exists", http.StatusConflict, nil)
+		return nil, false
+	}
+
+	if !ts.checkCourseLecturer(ctx, entry.CourseID, lecturerID) {
+		return nil, false
+	}
+	return entry, true
+}
+
+// checkCourseLecturer checks that the lecturer lectures the course.
+// It writes the failure response itself and returns false when the request should stop.
+func (ts *TimetableSvc) checkCourseLecturer(ctx *gin.Context, courseID, lecturerID int) bool {
+	isLecturer, err := ts.courseRepo.IsCourseLecturer(courseID, lecturerID)
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to check course ownership", http.StatusInternalServerError, err.Error())
+		return false
+	}
+	if !isLecturer {
+		responses.ApiFailure(ctx, "Only lecturers of this course can manage its timetable", http.StatusForbidden, nil)
+		return false
+	}
+	return true
+}
+
+// respondTimetableLookupError maps timetable lookup errors to HTTP responses.
+func respondTimetableLookupError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, repository.ErrEntryNotFound), errors.Is(err, repository.ErrExceptionNotFound):
+		responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+	default:
+		responses.ApiFailure(ctx, "Failed to retrieve timetable", http.StatusInternalServerError, err.Error())
+	}
+}
+
+// toEntryResponse maps a timetable entry entity to its response DTO.
+func toEntryResponse(e *entities.TimetableEntry) timetable.EntryResponse {
+	courseCode := ""
+	if e.Course != nil {
+		courseCode = e.Course.Code
+	}
+	return timetable.EntryResponse{
+		ID:                 int(e.ID),
+		CourseID:           e.CourseID,
+		CourseCode:         courseCode,
+		SemesterID:         e.SemesterID,
+		Weekday:            strings.ToLower(time.Weekday(e.Weekday).String()),
+		StartTime:          e.StartTime,
+		DurationMinutes:    e.DurationMinutes,
+		IntervalWeeks:      e.IntervalWeeks,
+		FirstWeek:          e.FirstWeek,
+		LastWeek:           e.LastWeek,
+		Venue:              e.Venue,
+		VenueID:            e.VenueID,
+		GeofenceMode:       e.GeofenceMode,
+		QRMode:             e.QRMode,
+		RotationSeconds:    e.QRRotation,
+		GracePeriodMinutes: e.GracePeriodMinutes,
+		LateCutoffMinutes:  e.LateCutoffMinutes,
+		CreatedAt:          e.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:          e.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+// toExceptionResponse maps a timetable exception entity to its response DTO.
+func toExceptionResponse(e *entities.TimetableException) timetable.ExceptionResponse {
+	return timetable.ExceptionResponse{
+		ID:        int(e.ID),
+		EntryID:   e.EntryID,
+		Date:      e.Date.Format(calendar.DateLayout),
+		Action:    e.Action,
+		StartTime: formatOptionalTime(e.StartTime),
+		EndTime:   formatOptionalTime(e.EndTime),
+		Venue:     e.Venue,
+		VenueID:   e.VenueID,
+		Reason:    e.Reason,
+		CreatedAt: e.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// formatOptionalTime formats an optional timestamp, keeping nil as nil.
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := t.Format(time.RFC3339)
+	return &s
+}
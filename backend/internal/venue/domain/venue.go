@@ -0,0 +1,36 @@
+package venue
+
+// Request DTOs
+
+// CreateVenueDTO represents the request to register a venue.
+type CreateVenueDTO struct {
+	Name         string   `json:"name" binding:"required"`
+	Building     string   `json:"building"`
+	Latitude     *float64 `json:"latitude" binding:"required,gte=-90,lte=90"`
+	Longitude    *float64 `json:"longitude" binding:"required,gte=-180,lte=180"`
+	RadiusMeters float64  `json:"radius_meters" binding:"required,gt=0,lte=5000"`
+}
+
+// UpdateVenueDTO represents the request to update a venue.
+// Only fields that are provided are changed.
+type UpdateVenueDTO struct {
+	Name         *string  `json:"name"`
+	Building     *string  `json:"building"`
+	Latitude     *float64 `json:"latitude" binding:"omitempty,gte=-90,lte=90"`
+	Longitude    *float64 `json:"longitude" binding:"omitempty,gte=-180,lte=180"`
+	RadiusMeters *float64 `json:"radius_meters" binding:"omitempty,gt=0,lte=5000"`
+}
+
+// Response DTOs
+
+// VenueResponse represents a venue.
+type VenueResponse struct {
+	ID           int     `json:"id"`
+	Name         string  `json:"name"`
+	Building     string  `json:"building"`
+	Latitude     float64 `json:"latitude"`
+	Longitude    float64 `json:"longitude"`
+	RadiusMeters float64 `json:"radius_meters"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+}
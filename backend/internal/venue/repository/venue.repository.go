@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	"gorm.io/gorm"
+)
+
+// ErrVenueNotFound is returned when a venue lookup matches no rows.
+var ErrVenueNotFound = errors.New("venue not found")
+
+// VenueRepoInterface defines the repository interface for venue operations.
+type VenueRepoInterface interface {
+	CreateVenue(venue *entities.Venue) error
+	GetVenueByID(venueID int) (*entities.Venue, error)
+	GetVenueByName(name string) (*entities.Venue, error)
+	ListVenues() ([]*entities.Venue, error)
+	UpdateVenue(venue *entities.Venue) error
+	DeleteVenue(venueID int) error
+}
+
+// VenueRepo implements the VenueRepoInterface.
+type VenueRepo struct {
+	db *gorm.DB
+}
+
+// NewVenueRepo returns a new instance of VenueRepo.
+func NewVenueRepo(db *gorm.DB) *VenueRepo {
+	return &VenueRepo{
+		db: db,
+	}
+}
+
+// CreateVenue creates a new venue.
+func (vr *VenueRepo) CreateVenue(venue *entities.Venue) error {
+	if err := vr.db.Create(venue).Error; err != nil {
+		return errors.New("failed to create venue: " + err.Error())
+	}
+	return nil
+}
+
+// GetVenueByID retrieves a venue by ID.
+func (vr *VenueRepo) GetVenueByID(venueID int) (*entities.Venue, error) {
+	var venue entities.Venue
+	if err := vr.db.First(&venue, venueID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVenueNotFound
+		}
+		return nil, errors.New("failed to retrieve venue: " + err.Error())
+	}
+	return &venue, nil
+}
+
+// GetVenueByName retrieves a venue by name, ignoring case and surrounding whitespace.
+func (vr *VenueRepo) GetVenueByName(name string) (*entities.Venue, error) {
+	var venue entities.Venue
+	if err := vr.db.Where("LOWER(name) = ?", strings.ToLower(strings.TrimSpace(name))).First(&venue).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrVenueNotFound
+		}
+		return nil, errors.New("failed to retrieve venue: " + err.Error())
+	}
+	return &venue, nil
+}
+
+// ListVenues retrieves every venue ordered by name.
+func (vr *VenueRepo) ListVenues() ([]*entities.Venue, error) {
+	var venues []*entities.Venue
+	if err := vr.db.Order("name ASC").Find(&venues).Error; err != nil {
+		return nil, errors.New("failed to retrieve venues: " + err.Error())
+	}
+	return venues, nil
+}
+
+// UpdateVenue saves changes to an existing venue.
+func (vr *VenueRepo) UpdateVenue(venue *entities.Venue) error {
+	if err := vr.db.Save(venue).Error; err != nil {
+		return errors.New("failed to update venue: " + err.Error())
+	}
+	return nil
+}
+
+// DeleteVenue permanently deletes a venue so its name can be reused. Events and
+// timetable entries keep their venue name but lose geofencing.
+func (vr *VenueRepo) DeleteVenue(venueID int) error {
+	return vr.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&entities.Event{}).
+			Where("venue_id = ?", venueID).
+			Updates(map[string]interface{}{"venue_id": nil, "geofence_mode": entities.GeofenceOff}).Error; err != nil {
+			return errors.New("failed to detach venue from events: " + err.Error())
+		}
+		if err := tx.Model(&entities.TimetableEntry{}).
+			Where("venue_id = ?", venueID).
+			Updates(map[string]interface{}{"venue_id": nil, "geofence_mode": entities.GeofenceOff}).Error; err != nil {
+			return errors.New("failed to detach venue from timetable: " + err.Error())
+		}
+		if err := tx.Model(&entities.TimetableException{}).
+			Where("venue_id = ?", venueID).
+			Update("venue_id", nil).Error; err != nil {
+			return errors.New("failed to detach venue from timetable: " + err.Error())
+		}
+		if err := tx.Unscoped().Delete(&entities.Venue{}, venueID).Error; err != nil {
+			return errors.New("failed to delete venue: " + err.Error())
+		}
+		return nil
+	})
+}
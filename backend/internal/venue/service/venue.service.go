@@ -0,0 +1,222 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/entities"
+	venue "github.com/Dom-HTG/attendance-management-system/internal/venue/domain"
+	"github.com/Dom-HTG/attendance-management-system/internal/venue/repository"
+	"github.com/Dom-HTG/attendance-management-system/pkg/middleware"
+	"github.com/Dom-HTG/attendance-management-system/pkg/responses"
+	"github.com/gin-gonic/gin"
+)
+
+// VenueSvcInterface defines the service interface for venue operations.
+type VenueSvcInterface interface {
+	CreateVenue(ctx *gin.Context)
+	ListVenues(ctx *gin.Context)
+	GetVenue(ctx *gin.Context)
+	UpdateVenue(ctx *gin.Context)
+	DeleteVenue(ctx *gin.Context)
+}
+
+// VenueSvc implements the VenueSvcInterface.
+type VenueSvc struct {
+	venueRepo repository.VenueRepoInterface
+}
+
+// NewVenueSvc returns a new instance of VenueSvc.
+func NewVenueSvc(venueRepo repository.VenueRepoInterface) *VenueSvc {
+	return &VenueSvc{
+		venueRepo: venueRepo,
+	}
+}
+
+// CreateVenue handles POST /api/lecturer/venues.
+func (vs *VenueSvc) CreateVenue(ctx *gin.Context) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return
+	}
+
+	var req venue.CreateVenueDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if _, err := vs.venueRepo.GetVenueByName(name); err == nil {
+		responses.ApiFailure(ctx, fmt.Sprintf("Venue %s already exists", name), http.StatusConflict, nil)
+		return
+	} else if !errors.Is(err, repository.ErrVenueNotFound) {
+		responses.ApiFailure(ctx, "Failed to check venue name", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	entity := &entities.Venue{
+		Name:         name,
+		Building:     req.Building,
+		Latitude:     *req.Latitude,
+		Longitude:    *req.Longitude,
+		RadiusMeters: req.RadiusMeters,
+		CreatedBy:    &lecturerID,
+	}
+
+	if err := vs.venueRepo.CreateVenue(entity); err != nil {
+		responses.ApiFailure(ctx, "Failed to create venue", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusCreated, "Venue created successfully", toVenueResponse(entity))
+}
+
+// ListVenues handles GET /api/lecturer/venues. Venues are shared by all lecturers.
+func (vs *VenueSvc) ListVenues(ctx *gin.Context) {
+	venues, err := vs.venueRepo.ListVenues()
+	if err != nil {
+		responses.ApiFailure(ctx, "Failed to retrieve venues", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := []venue.VenueResponse{}
+	for _, v := range venues {
+		result = append(result, toVenueResponse(v))
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Venues retrieved successfully", result)
+}
+
+// GetVenue handles GET /api/lecturer/venues/{venue_id}.
+func (vs *VenueSvc) GetVenue(ctx *gin.Context) {
+	venueID, err := strconv.Atoi(ctx.Param("venue_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid venue ID", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	entity, err := vs.venueRepo.GetVenueByID(venueID)
+	if err != nil {
+		respondVenueLookupError(ctx, err)
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Venue retrieved successfully", toVenueResponse(entity))
+}
+
+// UpdateVenue handles PUT /api/lecturer/venues/{venue_id}.
+// Only the lecturer who registered the venue may update it.
+func (vs *VenueSvc) UpdateVenue(ctx *gin.Context) {
+	entity, ok := vs.loadOwnedVenue(ctx)
+	if !ok {
+		return
+	}
+
+	var req venue.UpdateVenueDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		responses.ApiFailure(ctx, "Unable to bind request body", http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if existing, err := vs.venueRepo.GetVenueByName(name); err == nil && existing.ID != entity.ID {
+			responses.ApiFailure(ctx, fmt.Sprintf("Venue %s already exists", name), http.StatusConflict, nil)
+			return
+		}
+		entity.Name = name
+	}
+	if req.Building != nil {
+		entity.Building = *req.Building
+	}
+	if req.Latitude != nil {
+		entity.Latitude = *req.Latitude
+	}
+	if req.Longitude != nil {
+		entity.Longitude = *req.Longitude
+	}
+	if req.RadiusMeters != nil {
+		entity.RadiusMeters = *req.RadiusMeters
+	}
+
+	if err := vs.venueRepo.UpdateVenue(entity); err != nil {
+		responses.ApiFailure(ctx, "Failed to update venue", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Venue updated successfully", toVenueResponse(entity))
+}
+
+// DeleteVenue handles DELETE /api/lecturer/venues/{venue_id}.
+// Only the lecturer who registered the venue may delete it.
+func (vs *VenueSvc) DeleteVenue(ctx *gin.Context) {
+	entity, ok := vs.loadOwnedVenue(ctx)
+	if !ok {
+		return
+	}
+
+	if err := vs.venueRepo.DeleteVenue(int(entity.ID)); err != nil {
+		responses.ApiFailure(ctx, "Failed to delete venue", http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	responses.ApiSuccess(ctx, http.StatusOK, "Venue deleted successfully", nil)
+}
+
+// loadOwnedVenue loads the venue in the URL and verifies the caller registered it.
+// It writes the failure response itself and returns false when the request should stop.
+func (vs *VenueSvc) loadOwnedVenue(ctx *gin.Context) (*entities.Venue, bool) {
+	lecturerID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		responses.ApiFailure(ctx, "User not found in context", http.StatusUnauthorized, nil)
+		return nil, false
+	}
+
+	venueID, err := strconv.Atoi(ctx.Param("venue_id"))
+	if err != nil {
+		responses.ApiFailure(ctx, "Invalid venue ID", http.StatusBadRequest, err.Error())
+		return nil, false
+	}
+
+	entity, err := vs.venueRepo.GetVenueByID(venueID)
+	if err != nil {
+		respondVenueLookupError(ctx, err)
+		return nil, false
+	}
+
+	if entity.CreatedBy == nil || *entity.CreatedBy != lecturerID {
+		responses.ApiFailure(ctx, "Only the lecturer who registered this venue can manage it", http.StatusForbidden, nil)
+		return nil, false
+	}
+
+	return entity, true
+}
+
+// respondVenueLookupError maps venue lookup errors to HTTP responses.
+func respondVenueLookupError(ctx *gin.Context, err error) {
+	if errors.Is(err, repository.ErrVenueNotFound) {
+		responses.ApiFailure(ctx, err.Error(), http.StatusNotFound, nil)
+		return
+	}
+	responses.ApiFailure(ctx, "Failed to retrieve venue", http.StatusInternalServerError, err.Error())
+}
+
+// toVenueResponse maps a venue entity to its response DTO.
+func toVenueResponse(v *entities.Venue) venue.VenueResponse {
+	return venue.VenueResponse{
+		ID:           int(v.ID),
+		Name:         v.Name,
+		Building:     v.Building,
+		Latitude:     v.Latitude,
+		Longitude:    v.Longitude,
+		RadiusMeters: v.RadiusMeters,
+		CreatedAt:    v.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:    v.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -36,3 +36,11 @@ ON user_attendances(student_id);
 
 CREATE INDEX IF NOT EXISTS idx_user_attendances_attendance_id 
 ON user_attendances(attendance_id);
+
+-- Enrollment-based attendance rates expand enrollments into expected sessions
+-- by course, so events are looked up by course and start time.
+CREATE INDEX IF NOT EXISTS idx_events_course_start_time
+ON events(course_id, start_time);
+
+CREATE INDEX IF NOT EXISTS idx_user_attendances_attendance_student
+ON user_attendances(attendance_id, student_id);
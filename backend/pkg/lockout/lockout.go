@@ -0,0 +1,208 @@
+// Package lockout slows down password guessing. Failed logins are counted per
+// account and per client IP; once a count passes its threshold the key is
+// locked, and each further failure doubles the lockout up to a maximum.
+package lockout
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+)
+
+// State is the failure history of one key.
+type State struct {
+	Failures    int
+	LastFailure time.Time
+	LockedUntil time.Time // Zero when the key has never been locked
+}
+
+// Store persists failure counts. Implementations must be safe for concurrent
+// use; use a shared store such as Postgres when running several replicas.
+type Store interface {
+	// Get returns the state of key, or a zero State if it has none.
+	Get(ctx context.Context, key string) (State, error)
+	// Fail records a failure at now and returns the new state. Earlier
+	// failures are forgotten first when the last one was before since and any
+	// lock also ended before since, so a key that keeps failing across its
+	// lockouts keeps counting up.
+	Fail(ctx context.Context, key string, now, since time.Time) (State, error)
+	// Lock locks key until the given time.
+	Lock(ctx context.Context, key string, until time.Time) error
+	// Reset forgets every failure of key and lifts any lock.
+	Reset(ctx context.Context, key string) error
+	// Purge deletes keys whose last failure and lock are both before the
+	// given time and returns how many were deleted.
+	Purge(ctx context.Context, before time.Time) (int, error)
+}
+
+// Policy controls when keys are locked and for how long.
+type Policy struct {
+	AccountThreshold int           // Failures for one account before it is locked
+	IPThreshold      int           // Failures from one IP, across accounts, before it is locked
+	BaseLockout      time.Duration // First lockout; doubled for every further failure
+	MaxLockout       time.Duration
+	Window           time.Duration // Failures are forgotten this long after the last failure or lockout, whichever ends later
+}
+
+// Guard applies a Policy to login attempts.
+type Guard struct {
+	store  Store
+	policy Policy
+}
+
+// NewGuard returns a new Guard backed by store.
+func NewGuard(store Store, policy Policy) *Guard {
+	return &Guard{
+		store:  store,
+		policy: policy,
+	}
+}
+
+// AccountKey identifies an account by role and login email. Unknown emails are
+// tracked like real ones so lockouts do not reveal which accounts exist.
+// Students and lecturers share one identity per email, so they share a key;
+// otherwise the unified login and the per-role aliases would count separately.
+func AccountKey(role, email string) string {
+	if role == "student" || role == "lecturer" {
+		role = "user"
+	}
+	return "account:" + role + ":" + strings.ToLower(strings.TrimSpace(email))
+}
+
+// IPKey identifies a client IP.
+func IPKey(ip string) string {
+	return "ip:" + ip
+}
+
+// Check returns how long the caller must wait before trying account from ip
+// again, or zero if the attempt may go ahead.
+func (g *Guard) Check(ctx context.Context, account, ip string) (time.Duration, error) {
+	now := time.Now()
+	var wait time.Duration
+	for _, key := range []string{account, IPKey(ip)} {
+		state, err := g.store.Get(ctx, key)
+		if err != nil {
+			return 0, err
+		}
+		if remaining := state.LockedUntil.Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+	return wait, nil
+}
+
+// Fail records a failed attempt on account from ip, locking either key once it
+// passes its threshold, and returns the account's failure count.
+func (g *Guard) Fail(ctx context.Context, account, ip string) (int, error) {
+	now := time.Now()
+	since := now.Add(-g.policy.Window)
+
+	state, err := g.store.Fail(ctx, account, now, since)
+	if err != nil {
+		return 0, err
+	}
+	if err := g.lockIfNeeded(ctx, account, state, g.policy.AccountThreshold, now); err != nil {
+		return 0, err
+	}
+
+	ipKey := IPKey(ip)
+	ipState, err := g.store.Fail(ctx, ipKey, now, since)
+	if err != nil {
+		return 0, err
+	}
+	if err := g.lockIfNeeded(ctx, ipKey, ipState, g.policy.IPThreshold, now); err != nil {
+		return 0, err
+	}
+
+	return state.Failures, nil
+}
+
+// Succeed clears the account's failures after a successful login. The IP's
+// failures are kept so a valid login cannot be used to keep guessing others.
+func (g *Guard) Succeed(ctx context.Context, account string) error {
+	return g.store.Reset(ctx, account)
+}
+
+// Unlock clears a key's failures and lifts its lock.
+func (g *Guard) Unlock(ctx context.Context, key string) error {
+	return g.store.Reset(ctx, key)
+}
+
+// Status returns the state of a key.
+func (g *Guard) Status(ctx context.Context, key string) (State, error) {
+	return g.store.Get(ctx, key)
+}
+
+// lockIfNeeded locks key when its failures reach threshold. The lockout starts
+// at BaseLockout and doubles with every failure past the threshold.
+func (g *Guard) lockIfNeeded(ctx context.Context, key string, state State, threshold int, now time.Time) error {
+	if threshold <= 0 || state.Failures < threshold {
+		return nil
+	}
+
+	duration := g.policy.BaseLockout
+	for i := threshold; i < state.Failures && duration < g.policy.MaxLockout; i++ {
+		duration *= 2
+	}
+	if duration > g.policy.MaxLockout {
+		duration = g.policy.MaxLockout
+	}
+
+	until := now.Add(duration)
+	if err := g.store.Lock(ctx, key, until); err != nil {
+		return err
+	}
+	logger.Security("login_lockout", map[string]interface{}{
+		"key":      key,
+		"failures": state.Failures,
+		"until":    until.Format(time.RFC3339),
+	})
+	return nil
+}
+
+// Janitor is a background job that deletes keys with no recent failures and
+// no active lock.
+type Janitor struct {
+	guard    *Guard
+	interval time.Duration
+}
+
+// NewJanitor returns a new Janitor that runs every interval.
+func NewJanitor(guard *Guard, interval time.Duration) *Janitor {
+	return &Janitor{
+		guard:    guard,
+		interval: interval,
+	}
+}
+
+// Run purges stale keys immediately and then on every tick until ctx is cancelled.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if removed, err := j.guard.store.Purge(ctx, time.Now().Add(-j.guard.policy.Window)); err != nil {
+			logger.Errorf("lockout janitor failed: %v", err)
+		} else if removed > 0 {
+			logger.Infof("lockout janitor removed %d stale key(s)", removed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// FormatWait renders a wait as whole seconds for messages, rounding up.
+func FormatWait(wait time.Duration) string {
+	seconds := int((wait + time.Second - 1) / time.Second)
+	if seconds == 1 {
+		return "1 second"
+	}
+	return fmt.Sprintf("%d seconds", seconds)
+}
@@ -0,0 +1,184 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryStoreFailWindow(t *testing.T) {
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+	window := 15 * time.Minute
+	since := now.Add(-window)
+
+	tests := []struct {
+		name  string
+		prior State
+		want  int
+	}{
+		{"first failure", State{}, 1},
+		{"recent failure", State{Failures: 3, LastFailure: now.Add(-time.Minute)}, 4},
+		{"stale failure", State{Failures: 3, LastFailure: now.Add(-time.Hour)}, 1},
+		{"lock still active", State{Failures: 5, LastFailure: now.Add(-time.Hour), LockedUntil: now.Add(time.Hour)}, 6},
+		{"lock ended within window", State{Failures: 5, LastFailure: now.Add(-time.Hour), LockedUntil: now.Add(-time.Minute)}, 6},
+		{"lock ended before window", State{Failures: 5, LastFailure: now.Add(-2 * time.Hour), LockedUntil: now.Add(-time.Hour)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewMemoryStore()
+			store.states["k"] = tt.prior
+
+			state, err := store.Fail(context.Background(), "k", now, since)
+			if err != nil {
+				t.Fatalf("Fail: %v", err)
+			}
+			if state.Failures != tt.want {
+				t.Errorf("Failures = %d, want %d", state.Failures, tt.want)
+			}
+			if !state.LastFailure.Equal(now) {
+				t.Errorf("LastFailure = %v, want %v", state.LastFailure, now)
+			}
+		})
+	}
+}
+
+func TestMemoryStorePurge(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.states["stale"] = State{Failures: 1, LastFailure: now.Add(-time.Hour)}
+	store.states["recent"] = State{Failures: 1, LastFailure: now}
+	store.states["locked"] = State{Failures: 5, LastFailure: now.Add(-time.Hour), LockedUntil: now.Add(time.Hour)}
+
+	removed, err := store.Purge(context.Background(), now.Add(-time.Minute))
+	if err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if removed != 1 {
+		t.Errorf("Purge removed %d key(s), want 1", removed)
+	}
+	if _, ok := store.states["stale"]; ok {
+		t.Error("stale key was kept")
+	}
+}
+
+func TestGuardLockout(t *testing.T) {
+	policy := Policy{
+		AccountThreshold: 3,
+		IPThreshold:      10,
+		BaseLockout:      time.Minute,
+		MaxLockout:       5 * time.Minute,
+		Window:           time.Hour,
+	}
+
+	tests := []struct {
+		failures int
+		wantLock time.Duration // Zero when the account should not be locked
+	}{
+		{1, 0},
+		{2, 0},
+		{3, time.Minute},
+		{4, 2 * time.Minute},
+		{5, 4 * time.Minute},
+		{6, 5 * time.Minute},
+		{10, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		store := NewMemoryStore()
+		guard := NewGuard(store, policy)
+		ctx := context.Background()
+		account := AccountKey("student", "ada@uni.edu")
+
+		var count int
+		var err error
+		for i := 0; i < tt.failures; i++ {
+			if count, err = guard.Fail(ctx, account, "10.0.0.1"); err != nil {
+				t.Fatalf("Fail: %v", err)
+			}
+		}
+		if count != tt.failures {
+			t.Errorf("after %d failures: count = %d", tt.failures, count)
+		}
+
+		wait, err := guard.Check(ctx, account, "10.0.0.1")
+		if err != nil {
+			t.Fatalf("Check: %v", err)
+		}
+		if tt.wantLock == 0 {
+			if wait != 0 {
+				t.Errorf("after %d failures: wait = %v, want none", tt.failures, wait)
+			}
+			continue
+		}
+		if wait <= tt.wantLock-time.Second || wait > tt.wantLock {
+			t.Errorf("after %d failures: wait = %v, want about %v", tt.failures, wait, tt.wantLock)
+		}
+	}
+}
+
+func TestGuardSucceedKeepsIPFailures(t *testing.T) {
+	store := NewMemoryStore()
+	guard := NewGuard(store, Policy{AccountThreshold: 5, IPThreshold: 2, BaseLockout: time.Minute, MaxLockout: time.Hour, Window: time.Hour})
+	ctx := context.Background()
+
+	if _, err := guard.Fail(ctx, AccountKey("student", "a@uni.edu"), "10.0.0.1"); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+	if err := guard.Succeed(ctx, AccountKey("student", "a@uni.edu")); err != nil {
+		t.Fatalf("Succeed: %v", err)
+	}
+	if _, err := guard.Fail(ctx, AccountKey("student", "b@uni.edu"), "10.0.0.1"); err != nil {
+		t.Fatalf("Fail: %v", err)
+	}
+
+	wait, err := guard.Check(ctx, AccountKey("student", "c@uni.edu"), "10.0.0.1")
+	if err != nil {
+		t.Fatalf("Check: %v", err)
+	}
+	if wait == 0 {
+		t.Error("IP was not locked after failures on two accounts")
+	}
+	state, err := guard.Status(ctx, AccountKey("student", "a@uni.edu"))
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if state.Failures != 0 {
+		t.Errorf("account failures after success = %d, want 0", state.Failures)
+	}
+}
+
+func TestAccountKey(t *testing.T) {
+	tests := []struct {
+		role, email string
+		want        string
+	}{
+		{"student", "Ada@Uni.edu", "account:user:ada@uni.edu"},
+		{"lecturer", " ada@uni.edu ", "account:user:ada@uni.edu"},
+		{"admin", "root@uni.edu", "account:admin:root@uni.edu"},
+	}
+
+	for _, tt := range tests {
+		if got := AccountKey(tt.role, tt.email); got != tt.want {
+			t.Errorf("AccountKey(%q, %q) = %q, want %q", tt.role, tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestFormatWait(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want string
+	}{
+		{time.Second, "1 second"},
+		{500 * time.Millisecond, "1 second"},
+		{1500 * time.Millisecond, "2 seconds"},
+		{time.Minute, "60 seconds"},
+	}
+
+	for _, tt := range tests {
+		if got := FormatWait(tt.wait); got != tt.want {
+			t.Errorf("FormatWait(%v) = %q, want %q", tt.wait, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps failure counts in process memory. It is suitable for a
+// single instance; counts are lost on restart and not shared between replicas.
+type MemoryStore struct {
+	mu     sync.Mutex
+	states map[string]State
+}
+
+// NewMemoryStore returns a new, empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		states: map[string]State{},
+	}
+}
+
+// Get returns the state of key.
+func (ms *MemoryStore) Get(ctx context.Context, key string) (State, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	return ms.states[key], nil
+}
+
+// Fail records a failure at now, first forgetting earlier failures when the
+// last failure and any lock both ended before since.
+func (ms *MemoryStore) Fail(ctx context.Context, key string, now, since time.Time) (State, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	state := ms.states[key]
+	if state.LastFailure.Before(since) && state.LockedUntil.Before(since) {
+		state.Failures = 0
+	}
+	state.Failures++
+	state.LastFailure = now
+	ms.states[key] = state
+	return state, nil
+}
+
+// Lock locks key until the given time.
+func (ms *MemoryStore) Lock(ctx context.Context, key string, until time.Time) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	state := ms.states[key]
+	if until.After(state.LockedUntil) {
+		state.LockedUntil = until
+	}
+	ms.states[key] = state
+	return nil
+}
+
+// Reset forgets key.
+func (ms *MemoryStore) Reset(ctx context.Context, key string) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	delete(ms.states, key)
+	return nil
+}
+
+// Purge deletes keys whose last failure and lock are both before the given time.
+func (ms *MemoryStore) Purge(ctx context.Context, before time.Time) (int, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	removed := 0
+	for key, state := range ms.states {
+		if state.LastFailure.Before(before) && state.LockedUntil.Before(before) {
+			delete(ms.states, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
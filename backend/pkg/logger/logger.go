@@ -69,6 +69,19 @@ func Errorf(format string, args ...interface{}) {
 	Log.Errorf(format, args...)
 }
 
+// Security logs a security event, such as a failed login, at warning level.
+// The details are written as structured fields so they can be searched and
+// alerted on.
+func Security(event string, fields map[string]interface{}) {
+	if Log == nil {
+		return
+	}
+	Log.WithFields(logrus.Fields(fields)).
+		WithField("category", "security").
+		WithField("event", event).
+		Warn("security event: " + event)
+}
+
 // LogrusLevel returns the default logging level used by the project.
 // Exported so callers can pass a level without depending on logrus directly.
 func LogrusLevel() logrus.Level {
@@ -0,0 +1,87 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+)
+
+// Message is a plain-text email.
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers email. Implementations must be safe for concurrent use.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// New returns the mailer selected by kind: "log" records that a message was
+// sent without its content and "file" writes each message to a file in dir.
+func New(kind, dir string) (Mailer, error) {
+	switch strings.ToLower(strings.TrimSpace(kind)) {
+	case "":
+		return nil, fmt.Errorf("no mailer selected")
+	case "log":
+		return NewLogMailer(), nil
+	case "file":
+		return NewFileMailer(dir)
+	default:
+		return nil, fmt.Errorf("unknown mailer %q", kind)
+	}
+}
+
+// LogMailer logs the recipient and subject of each message instead of sending
+// it. Bodies are never logged, since they carry password reset and email
+// verification tokens; use FileMailer to read them during development.
+type LogMailer struct{}
+
+// NewLogMailer returns a new LogMailer.
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send logs the message's recipient and subject.
+func (lm *LogMailer) Send(ctx context.Context, msg Message) error {
+	logger.Infof("mail to=%s subject=%q (body not logged)", msg.To, msg.Subject)
+	return nil
+}
+
+// FileMailer writes each message to its own file so tests and developers can
+// read what would have been sent.
+type FileMailer struct {
+	dir string
+}
+
+// NewFileMailer returns a FileMailer that writes to dir, creating it if needed.
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if dir == "" {
+		dir = "mail"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create mail directory: %w", err)
+	}
+	return &FileMailer{dir: dir}, nil
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// Send writes the message to <dir>/<timestamp>-<recipient>.eml.
+func (fm *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now().UTC()
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+
+	content := fmt.Sprintf("Date: %s\r\nTo: %s\r\nSubject: %s\r\n\r\n%s\r\n", now.Format(time.RFC1123Z), msg.To, msg.Subject, msg.Body)
+	if err := os.WriteFile(filepath.Join(fm.dir, name), []byte(content), 0o600); err != nil {
+		return fmt.Errorf("failed to write mail: %w", err)
+	}
+	return nil
+}
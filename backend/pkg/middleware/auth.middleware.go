@@ -3,6 +3,7 @@ package middleware
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Dom-HTG/attendance-management-system/pkg/utils"
 	"github.com/gin-gonic/gin"
@@ -15,6 +16,20 @@ type JWTClaims struct {
 	Role  string `json:"role"`
 }
 
+// RevocationStore reports whether an access token has been revoked by its jti.
+type RevocationStore interface {
+	IsAccessTokenRevoked(jti string) (bool, error)
+}
+
+// revocationStore is consulted by AuthMiddleware when set; see UseRevocationStore.
+var revocationStore RevocationStore
+
+// UseRevocationStore makes AuthMiddleware reject access tokens on the store's
+// denylist. It should be called once at startup before routes are served.
+func UseRevocationStore(store RevocationStore) {
+	revocationStore = store
+}
+
 // AuthMiddleware validates the JWT token in the Authorization header and extracts user information
 func AuthMiddleware() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
@@ -54,17 +69,42 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Reject tokens revoked by logout before they expire
+		if revocationStore != nil && claims.RegisteredClaims.ID != "" {
+			revoked, err := revocationStore.IsAccessTokenRevoked(claims.RegisteredClaims.ID)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, gin.H{
+					"error":   "failed to check token status",
+					"details": err.Error(),
+				})
+				ctx.Abort()
+				return
+			}
+			if revoked {
+				ctx.JSON(http.StatusUnauthorized, gin.H{
+					"error": "token has been revoked. please login again",
+				})
+				ctx.Abort()
+				return
+			}
+		}
+
 		// Store user information in the context for later use
 		ctx.Set("user_id", claims.ID)
 		ctx.Set("user_email", claims.Email)
 		ctx.Set("user_role", claims.Role)
+		ctx.Set("token_id", claims.RegisteredClaims.ID)
+		if claims.ExpiresAt != nil {
+			ctx.Set("token_expires_at", claims.ExpiresAt.Time)
+		}
 
 		ctx.Next()
 	}
 }
 
-// RoleMiddleware checks if the user has a specific role
+// RoleMiddleware checks if the user has a specific account role
 // Usage: RoleMiddleware("lecturer") or RoleMiddleware("student")
+// Routes should prefer RequirePermission, which also honours assigned roles.
 func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		// Get the user role from the context (set by AuthMiddleware)
@@ -116,3 +156,21 @@ func GetUserEmailFromContext(ctx *gin.Context) (string, bool) {
 	}
 	return userEmail.(string), true
 }
+
+// GetTokenIDFromContext retrieves the access token's ID (jti) from the context
+func GetTokenIDFromContext(ctx *gin.Context) (string, bool) {
+	tokenID, exists := ctx.Get("token_id")
+	if !exists || tokenID.(string) == "" {
+		return "", false
+	}
+	return tokenID.(string), true
+}
+
+// GetTokenExpiryFromContext retrieves the access token's expiry time from the context
+func GetTokenExpiryFromContext(ctx *gin.Context) (time.Time, bool) {
+	expiresAt, exists := ctx.Get("token_expires_at")
+	if !exists {
+		return time.Time{}, false
+	}
+	return expiresAt.(time.Time), true
+}
@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Dom-HTG/attendance-management-system/pkg/rbac"
+	"github.com/gin-gonic/gin"
+)
+
+// GrantStore loads the roles an admin has assigned to an account, on top of the
+// role carried in its access token.
+type GrantStore interface {
+	GrantsFor(accountRole string, userID int) ([]rbac.Grant, error)
+}
+
+// grantStore is consulted by RequirePermission when set; see UseGrantStore.
+var grantStore GrantStore
+
+// UseGrantStore makes permission checks include assigned roles. It should be
+// called once at startup before routes are served.
+func UseGrantStore(store GrantStore) {
+	grantStore = store
+}
+
+// ScopeResolver extracts the scope a request targets, such as the department
+// named in a URL parameter. It returns false when the request names none.
+type ScopeResolver func(ctx *gin.Context) (rbac.Scope, bool)
+
+// DepartmentParam resolves the department named by a URL parameter.
+func DepartmentParam(name string) ScopeResolver {
+	return func(ctx *gin.Context) (rbac.Scope, bool) {
+		value := strings.TrimSpace(ctx.Param(name))
+		return rbac.Department(value), value != ""
+	}
+}
+
+// CourseParam resolves the course code named by a URL parameter.
+func CourseParam(name string) ScopeResolver {
+	return func(ctx *gin.Context) (rbac.Scope, bool) {
+		value := strings.TrimSpace(ctx.Param(name))
+		return rbac.Course(value), value != ""
+	}
+}
+
+// RequirePermission admits callers holding perm. Without resolvers any grant of
+// perm is enough, including one limited to the caller's own resources, and the
+// service narrows access further. With resolvers, a grant must cover one of the
+// scopes they resolve. It must run after AuthMiddleware.
+func RequirePermission(perm rbac.Permission, resolvers ...ScopeResolver) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		grants, err := loadGrants(ctx)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, gin.H{
+				"error":   "failed to load permissions",
+				"details": err.Error(),
+			})
+			ctx.Abort()
+			return
+		}
+		if grants == nil {
+			ctx.JSON(http.StatusUnauthorized, gin.H{
+				"error": "user role not found in context",
+			})
+			ctx.Abort()
+			return
+		}
+
+		var allowed bool
+		if len(resolvers) == 0 {
+			allowed = rbac.AllowsAny(grants, perm)
+		} else {
+			var targets []rbac.Scope
+			for _, resolve := range resolvers {
+				if scope, ok := resolve(ctx); ok {
+					targets = append(targets, scope)
+				}
+			}
+			allowed = rbac.Allows(grants, perm, targets...)
+		}
+
+		if !allowed {
+			ctx.JSON(http.StatusForbidden, gin.H{
+				"error": "access denied. missing permission " + string(perm),
+			})
+			ctx.Abort()
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// OwnershipCheck reports whether the caller owns the resource a request
+// targets, such as a student reading their own record.
+type OwnershipCheck func(ctx *gin.Context) bool
+
+// RequireOwnerOrPermission admits callers who own the resource a request
+// targets and otherwise requires perm as RequirePermission does. It must run
+// after AuthMiddleware.
+func RequireOwnerOrPermission(owns OwnershipCheck, perm rbac.Permission, resolvers ...ScopeResolver) gin.HandlerFunc {
+	require := RequirePermission(perm, resolvers...)
+	return func(ctx *gin.Context) {
+		if owns(ctx) {
+			ctx.Next()
+			return
+		}
+		require(ctx)
+	}
+}
+
+// HasPermission reports whether the caller holds perm on one of targets through
+// a global, department or course grant. Services use it to admit callers who do
+// not own a resource, such as a head of department reading a colleague's event.
+func HasPermission(ctx *gin.Context, perm rbac.Permission, targets ...rbac.Scope) bool {
+	grants, err := loadGrants(ctx)
+	if err != nil {
+		return false
+	}
+	return rbac.Allows(grants, perm, targets...)
+}
+
+// GetGrantsFromContext retrieves the caller's grants loaded by RequirePermission
+// or HasPermission.
+func GetGrantsFromContext(ctx *gin.Context) ([]rbac.Grant, bool) {
+	grants, exists := ctx.Get("grants")
+	if !exists {
+		return nil, false
+	}
+	return grants.([]rbac.Grant), true
+}
+
+// loadGrants returns the caller's account grant plus any assigned roles, loading
+// them once per request. It returns nil when the caller is not authenticated.
+func loadGrants(ctx *gin.Context) ([]rbac.Grant, error) {
+	if grants, ok := GetGrantsFromContext(ctx); ok {
+		return grants, nil
+	}
+
+	userID, ok := GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	role, ok := GetUserRoleFromContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	grants := []rbac.Grant{}
+	if grant, ok := rbac.AccountGrant(role); ok {
+		grants = append(grants, grant)
+	}
+	if grantStore != nil {
+		assigned, err := grantStore.GrantsFor(role, userID)
+		if err != nil {
+			return nil, err
+		}
+		grants = append(grants, assigned...)
+	}
+
+	ctx.Set("grants", grants)
+	return grants, nil
+}
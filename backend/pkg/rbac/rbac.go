@@ -0,0 +1,214 @@
+package rbac
+
+import "strings"
+
+// Permission names an action. Routes require permissions rather than roles.
+type Permission string
+
+const (
+	PermAttendanceCheckIn        Permission = "attendance:check_in"
+	PermAttendanceReadOwn        Permission = "attendance:read:own"
+	PermCoursesReadEnrolled      Permission = "courses:read:enrolled"
+	PermEventsManage             Permission = "events:manage"
+	PermAttendanceRead           Permission = "attendance:read"
+	PermAttendanceOverride       Permission = "attendance:override"
+	PermRostersRead              Permission = "rosters:read"
+	PermCoursesManage            Permission = "courses:manage"
+	PermVenuesManage             Permission = "venues:manage"
+	PermAnalyticsCourseRead      Permission = "analytics:course:read"
+	PermAnalyticsDepartmentRead  Permission = "analytics:department:read"
+	PermAnalyticsInstitutionRead Permission = "analytics:institution:read"
+	PermUsersManage              Permission = "users:manage"
+	PermRolesManage              Permission = "roles:manage"
+	PermCalendarManage           Permission = "calendar:manage"
+)
+
+// Account roles are the roles carried in access tokens. Every account has
+// exactly one.
+const (
+	RoleStudent  = "student"
+	RoleLecturer = "lecturer"
+	RoleAdmin    = "admin"
+)
+
+// Assignable roles are granted to lecturer accounts by an admin, on top of
+// their account role.
+const (
+	RoleHeadOfDepartment  = "hod"
+	RoleDean              = "dean"
+	RoleTeachingAssistant = "teaching_assistant"
+	RoleRegistry          = "registry"
+)
+
+// ScopeType says which resources a grant covers.
+type ScopeType string
+
+const (
+	// ScopeOwn covers only resources the user owns, such as a lecturer's own
+	// courses and events. The service enforces ownership.
+	ScopeOwn        ScopeType = "own"
+	ScopeGlobal     ScopeType = "global"
+	ScopeDepartment ScopeType = "department" // Value is a department name
+	ScopeCourse     ScopeType = "course"     // Value is a course code
+)
+
+// Scope is a set of resources: everything, a department, a course or the
+// user's own resources.
+type Scope struct {
+	Type  ScopeType `json:"type"`
+	Value string    `json:"value,omitempty"`
+}
+
+// Department returns the scope of a department.
+func Department(name string) Scope {
+	return Scope{Type: ScopeDepartment, Value: name}
+}
+
+// Course returns the scope of a course.
+func Course(code string) Scope {
+	return Scope{Type: ScopeCourse, Value: code}
+}
+
+// Grant gives the permissions of a role within a scope.
+type Grant struct {
+	Role  string `json:"role"`
+	Scope Scope  `json:"scope"`
+}
+
+// RoleDefinition lists a role's permissions and the scopes it may be granted at.
+type RoleDefinition struct {
+	Name        string       `json:"name"`
+	Assignable  bool         `json:"assignable"`
+	Permissions []Permission `json:"permissions"`
+	ScopeTypes  []ScopeType  `json:"scope_types"`
+}
+
+var roles = map[string]RoleDefinition{
+	RoleStudent: {
+		Name:        RoleStudent,
+		Permissions: []Permission{PermAttendanceCheckIn, PermAttendanceReadOwn, PermCoursesReadEnrolled},
+		ScopeTypes:  []ScopeType{ScopeOwn},
+	},
+	RoleLecturer: {
+		Name: RoleLecturer,
+		Permissions: []Permission{
+			PermEventsManage, PermAttendanceRead, PermAttendanceOverride, PermRostersRead,
+			PermCoursesManage, PermVenuesManage, PermAnalyticsCourseRead,
+		},
+		ScopeTypes: []ScopeType{ScopeOwn},
+	},
+	RoleAdmin: {
+		Name: RoleAdmin,
+		Permissions: []Permission{
+			PermUsersManage, PermRolesManage, PermCalendarManage,
+			PermAnalyticsDepartmentRead, PermAnalyticsInstitutionRead,
+		},
+		ScopeTypes: []ScopeType{ScopeGlobal},
+	},
+	RoleHeadOfDepartment: {
+		Name:        RoleHeadOfDepartment,
+		Assignable:  true,
+		Permissions: []Permission{PermAttendanceRead, PermRostersRead, PermAnalyticsDepartmentRead},
+		ScopeTypes:  []ScopeType{ScopeDepartment},
+	},
+	RoleDean: {
+		Name:        RoleDean,
+		Assignable:  true,
+		Permissions: []Permission{PermAttendanceRead, PermRostersRead, PermAnalyticsDepartmentRead, PermAnalyticsInstitutionRead},
+		ScopeTypes:  []ScopeType{ScopeGlobal, ScopeDepartment},
+	},
+	RoleTeachingAssistant: {
+		Name:        RoleTeachingAssistant,
+		Assignable:  true,
+		Permissions: []Permission{PermAttendanceRead, PermAttendanceOverride, PermRostersRead},
+		ScopeTypes:  []ScopeType{ScopeCourse},
+	},
+	RoleRegistry: {
+		Name:        RoleRegistry,
+		Assignable:  true,
+		Permissions: []Permission{PermAttendanceRead, PermRostersRead, PermAnalyticsDepartmentRead, PermAnalyticsInstitutionRead, PermCalendarManage},
+		ScopeTypes:  []ScopeType{ScopeGlobal},
+	},
+}
+
+// Roles returns every role definition in a stable order.
+func Roles() []RoleDefinition {
+	order := []string{
+		RoleStudent, RoleLecturer, RoleAdmin,
+		RoleHeadOfDepartment, RoleDean, RoleTeachingAssistant, RoleRegistry,
+	}
+	result := make([]RoleDefinition, 0, len(order))
+	for _, name := range order {
+		result = append(result, roles[name])
+	}
+	return result
+}
+
+// LookupRole returns the definition of a role.
+func LookupRole(name string) (RoleDefinition, bool) {
+	def, ok := roles[name]
+	return def, ok
+}
+
+// AccountGrant returns the grant every account of the given account role has.
+func AccountGrant(accountRole string) (Grant, bool) {
+	def, ok := roles[accountRole]
+	if !ok || def.Assignable {
+		return Grant{}, false
+	}
+	return Grant{Role: accountRole, Scope: Scope{Type: def.ScopeTypes[0]}}, true
+}
+
+// HasPermission reports whether the grant's role includes perm, at any scope.
+func (g Grant) HasPermission(perm Permission) bool {
+	def, ok := roles[g.Role]
+	if !ok {
+		return false
+	}
+	for _, p := range def.Permissions {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// Covers reports whether the grant's scope includes target. Own-scoped grants
+// never cover a named scope; ownership is checked by the service instead.
+func (g Grant) Covers(target Scope) bool {
+	switch g.Scope.Type {
+	case ScopeGlobal:
+		return true
+	case ScopeDepartment, ScopeCourse:
+		return target.Type == g.Scope.Type && strings.EqualFold(strings.TrimSpace(target.Value), strings.TrimSpace(g.Scope.Value))
+	default:
+		return false
+	}
+}
+
+// Allows reports whether the grants give perm on at least one of targets.
+func Allows(grants []Grant, perm Permission, targets ...Scope) bool {
+	for _, g := range grants {
+		if !g.HasPermission(perm) {
+			continue
+		}
+		for _, target := range targets {
+			if g.Covers(target) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AllowsAny reports whether the grants give perm at any scope, including the
+// user's own resources. Routes use it as a first gate before the service
+// narrows access to specific resources.
+func AllowsAny(grants []Grant, perm Permission) bool {
+	for _, g := range grants {
+		if g.HasPermission(perm) {
+			return true
+		}
+	}
+	return false
+}
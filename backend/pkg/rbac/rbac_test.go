@@ -0,0 +1,105 @@
+package rbac
+
+import "testing"
+
+func TestGrantCovers(t *testing.T) {
+	tests := []struct {
+		name   string
+		grant  Grant
+		target Scope
+		want   bool
+	}{
+		{"global covers a department", Grant{Role: RoleAdmin, Scope: Scope{Type: ScopeGlobal}}, Department("Physics"), true},
+		{"global covers a course", Grant{Role: RoleDean, Scope: Scope{Type: ScopeGlobal}}, Course("CSC101"), true},
+		{"global covers global", Grant{Role: RoleRegistry, Scope: Scope{Type: ScopeGlobal}}, Scope{Type: ScopeGlobal}, true},
+		{"same department", Grant{Role: RoleHeadOfDepartment, Scope: Department("Computer Science")}, Department("Computer Science"), true},
+		{"department ignores case and spaces", Grant{Role: RoleHeadOfDepartment, Scope: Department(" computer science")}, Department("Computer Science "), true},
+		{"other department", Grant{Role: RoleHeadOfDepartment, Scope: Department("Computer Science")}, Department("Physics"), false},
+		{"department does not cover a course", Grant{Role: RoleHeadOfDepartment, Scope: Department("CSC101")}, Course("CSC101"), false},
+		{"department does not cover global", Grant{Role: RoleDean, Scope: Department("Physics")}, Scope{Type: ScopeGlobal}, false},
+		{"same course", Grant{Role: RoleTeachingAssistant, Scope: Course("CSC101")}, Course("csc101"), true},
+		{"other course", Grant{Role: RoleTeachingAssistant, Scope: Course("CSC101")}, Course("CSC102"), false},
+		{"own never covers a department", Grant{Role: RoleLecturer, Scope: Scope{Type: ScopeOwn}}, Department("Physics"), false},
+		{"own never covers a course", Grant{Role: RoleLecturer, Scope: Scope{Type: ScopeOwn}}, Course("CSC101"), false},
+		{"own never covers own", Grant{Role: RoleStudent, Scope: Scope{Type: ScopeOwn}}, Scope{Type: ScopeOwn}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.grant.Covers(tt.target); got != tt.want {
+				t.Errorf("Covers(%+v) = %v, want %v", tt.target, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllows(t *testing.T) {
+	lecturer, _ := AccountGrant(RoleLecturer)
+	admin, _ := AccountGrant(RoleAdmin)
+	hod := Grant{Role: RoleHeadOfDepartment, Scope: Department("Computer Science")}
+	ta := Grant{Role: RoleTeachingAssistant, Scope: Course("CSC101")}
+
+	tests := []struct {
+		name    string
+		grants  []Grant
+		perm    Permission
+		targets []Scope
+		want    bool
+	}{
+		{"no grants", nil, PermAttendanceRead, []Scope{Department("Computer Science")}, false},
+		{"no targets", []Grant{admin}, PermUsersManage, nil, false},
+		{"admin at global scope", []Grant{admin}, PermUsersManage, []Scope{{Type: ScopeGlobal}}, true},
+		{"admin lacks the permission", []Grant{admin}, PermEventsManage, []Scope{{Type: ScopeGlobal}}, false},
+		{"hod in their department", []Grant{hod}, PermAnalyticsDepartmentRead, []Scope{Department("Computer Science")}, true},
+		{"hod in another department", []Grant{hod}, PermAnalyticsDepartmentRead, []Scope{Department("Physics")}, false},
+		{"hod lacks the permission", []Grant{hod}, PermAnalyticsInstitutionRead, []Scope{Department("Computer Science")}, false},
+		{"any matching target is enough", []Grant{hod}, PermRostersRead, []Scope{Course("CSC101"), Department("Computer Science")}, true},
+		{"teaching assistant on their course", []Grant{ta}, PermAttendanceOverride, []Scope{Course("CSC101")}, true},
+		{"teaching assistant on another course", []Grant{ta}, PermAttendanceOverride, []Scope{Course("CSC102")}, false},
+		{"own grant never allows a named scope", []Grant{lecturer}, PermAttendanceRead, []Scope{Course("CSC101")}, false},
+		{"permission from one grant, scope from another is not enough", []Grant{lecturer, hod}, PermEventsManage, []Scope{Department("Computer Science")}, false},
+		{"second grant allows", []Grant{lecturer, hod}, PermAttendanceRead, []Scope{Department("Computer Science")}, true},
+		{"unknown role", []Grant{{Role: "janitor", Scope: Scope{Type: ScopeGlobal}}}, PermUsersManage, []Scope{{Type: ScopeGlobal}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Allows(tt.grants, tt.perm, tt.targets...); got != tt.want {
+				t.Errorf("Allows = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAllowsAny(t *testing.T) {
+	lecturer, _ := AccountGrant(RoleLecturer)
+	student, _ := AccountGrant(RoleStudent)
+
+	if !AllowsAny([]Grant{lecturer}, PermEventsManage) {
+		t.Error("lecturer cannot manage their own events")
+	}
+	if AllowsAny([]Grant{student}, PermEventsManage) {
+		t.Error("student can manage events")
+	}
+}
+
+func TestAccountGrant(t *testing.T) {
+	tests := []struct {
+		role   string
+		want   Scope
+		wantOK bool
+	}{
+		{RoleStudent, Scope{Type: ScopeOwn}, true},
+		{RoleLecturer, Scope{Type: ScopeOwn}, true},
+		{RoleAdmin, Scope{Type: ScopeGlobal}, true},
+		{RoleHeadOfDepartment, Scope{}, false},
+		{"unknown", Scope{}, false},
+	}
+
+	for _, tt := range tests {
+		grant, ok := AccountGrant(tt.role)
+		if ok != tt.wantOK || grant.Scope != tt.want {
+			t.Errorf("AccountGrant(%q) = (%+v, %v), want scope %+v, %v", tt.role, grant, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"sort"
+)
+
+// JWK is a public key in JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`   // RSA modulus
+	E         string `json:"e,omitempty"`   // RSA exponent
+	Curve     string `json:"crv,omitempty"` // OKP curve
+	X         string `json:"x,omitempty"`   // OKP public key
+}
+
+// JWKSet is the document served to clients that verify tokens themselves.
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys that currently verify tokens, newest first.
+// HS256 keys are secret and never included.
+func (m *Manager) JWKS() JWKSet {
+	m.mu.RLock()
+	keys := make([]*key, 0, len(m.keys))
+	for _, k := range m.keys {
+		keys = append(keys, k)
+	}
+	m.mu.RUnlock()
+	sort.Slice(keys, func(i, j int) bool { return keys[i].createdAt.After(keys[j].createdAt) })
+
+	set := JWKSet{Keys: []JWK{}}
+	for _, k := range keys {
+		switch pub := k.verifier.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     k.id,
+				Use:       "sig",
+				Algorithm: k.algorithm,
+				N:         base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     k.id,
+				Use:       "sig",
+				Algorithm: k.algorithm,
+				Curve:     "Ed25519",
+				X:         base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+	return set
+}
+
+// encodeKeyPair returns a private key as PKCS#8 PEM and its public key as PKIX PEM.
+func encodeKeyPair(signer crypto.Signer) ([]byte, string, error) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(signer)
+	if err != nil {
+		return nil, "", err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(signer.Public())
+	if err != nil {
+		return nil, "", err
+	}
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	return privatePEM, string(publicPEM), nil
+}
+
+// decodePrivateKey parses a PKCS#8 PEM private key.
+func decodePrivateKey(privatePEM []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		return nil, errors.New("malformed private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch priv := parsed.(type) {
+	case *rsa.PrivateKey:
+		return priv, nil
+	case ed25519.PrivateKey:
+		return priv, nil
+	}
+	return nil, errors.New("unsupported private key type")
+}
+
+// decodePublicKey parses a PKIX PEM public key.
+func decodePublicKey(publicPEM string) (crypto.PublicKey, error) {
+	block, _ := pem.Decode([]byte(publicPEM))
+	if block == nil {
+		return nil, errors.New("malformed public key")
+	}
+	parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	switch pub := parsed.(type) {
+	case *rsa.PublicKey:
+		return pub, nil
+	case ed25519.PublicKey:
+		return pub, nil
+	}
+	return nil, errors.New("unsupported public key type")
+}
+
+// seal encrypts a private key for storage with AES-GCM under a key derived
+// from secret.
+func seal(secret string, plain []byte) (string, error) {
+	gcm, err := keyCipher(secret)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, plain, nil)), nil
+}
+
+// open reverses seal.
+func open(secret, sealed string) ([]byte, error) {
+	gcm, err := keyCipher(secret)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil || len(raw) < gcm.NonceSize() {
+		return nil, errors.New("malformed private key")
+	}
+	plain, err := gcm.Open(nil, raw[:gcm.NonceSize()], raw[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.New("failed to decrypt private key; was the secret changed?")
+	}
+	return plain, nil
+}
+
+// keyCipher returns the AES-GCM cipher for stored private keys.
+func keyCipher(secret string) (cipher.AEAD, error) {
+	sum := sha256.Sum256([]byte("signing-keys:" + secret))
+	block, err := aes.NewCipher(sum[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,456 @@
+// Package signing manages the keys that sign and verify JWTs. Tokens carry the
+// ID of their key in the "kid" header, so several keys can be accepted at once
+// while a new key takes over from an old one.
+//
+// HS256 keys come from configured secrets. RS256 and EdDSA keys are generated,
+// kept in a Store shared by every replica and rotated on a schedule; their
+// public halves are published as a JWKS so other services can verify tokens.
+package signing
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Dom-HTG/attendance-management-system/pkg/logger"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Supported signing algorithms.
+const (
+	AlgHS256 = "HS256"
+	AlgRS256 = "RS256"
+	AlgEdDSA = "EdDSA"
+)
+
+// rsaKeyBits is the size of generated RSA keys.
+const rsaKeyBits = 2048
+
+// reloadCooldown limits how often an unknown kid triggers a reload from the store.
+const reloadCooldown = 10 * time.Second
+
+// ErrUnknownKey is returned when a token names a key that is not known.
+var ErrUnknownKey = errors.New("token signed with an unknown key")
+
+// StoredKey is a generated key as kept in a Store. The private key is a
+// PKCS#8 PEM block encrypted with the configured secret; the public key is a
+// plain PKIX PEM block.
+type StoredKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey string
+	PublicKey  string
+	CreatedAt  time.Time
+}
+
+// Store persists generated keys. Implementations must be safe for concurrent use.
+type Store interface {
+	// ListKeys returns every stored key.
+	ListKeys(ctx context.Context) ([]StoredKey, error)
+	// CreateKey stores a new key.
+	CreateKey(ctx context.Context, key StoredKey) error
+	// PurgeKeys deletes keys that were replaced by a newer key created before
+	// the given time, and returns how many were deleted.
+	PurgeKeys(ctx context.Context, before time.Time) (int, error)
+}
+
+// Config controls how tokens are signed and verified.
+type Config struct {
+	Algorithm        string        // AlgHS256, AlgRS256 or AlgEdDSA
+	Secret           string        // HS256 signing key; also encrypts stored private keys
+	PreviousSecrets  []string      // Earlier HS256 secrets, still accepted for verification
+	Issuer           string        // Set as "iss" and required on every token
+	Audience         string        // Set as "aud" and required on every token
+	RotationInterval time.Duration // Age at which a generated key is replaced
+	Retention        time.Duration // How long a replaced key still verifies tokens
+}
+
+// key is a signing key in memory. signer is nil for keys that can only verify.
+type key struct {
+	id        string
+	algorithm string
+	createdAt time.Time
+	signer    interface{} // []byte, *rsa.PrivateKey or ed25519.PrivateKey
+	verifier  interface{} // []byte, *rsa.PublicKey or ed25519.PublicKey
+}
+
+// Manager signs tokens with the current key and verifies them with any key
+// that is still accepted.
+type Manager struct {
+	config Config
+	store  Store
+
+	mu         sync.RWMutex
+	keys       map[string]*key
+	current    *key
+	lastReload time.Time
+}
+
+// NewManager returns a Manager for config. RS256 and EdDSA need a store, and a
+// first key is generated if it has no usable one; HS256 ignores the store.
+func NewManager(ctx context.Context, config Config, store Store) (*Manager, error) {
+	if config.Secret == "" {
+		return nil, errors.New("a signing secret is required")
+	}
+
+	m := &Manager{
+		config: config,
+		store:  store,
+		keys:   map[string]*key{},
+	}
+
+	switch config.Algorithm {
+	case AlgHS256:
+		// HS256 keys come from configuration only; the store is not used
+		m.store = nil
+		for _, secret := range config.PreviousSecrets {
+			k := hmacKey(secret)
+			m.keys[k.id] = k
+		}
+		m.current = hmacKey(config.Secret)
+		m.keys[m.current.id] = m.current
+		return m, nil
+	case AlgRS256, AlgEdDSA:
+		if store == nil {
+			return nil, fmt.Errorf("%s signing needs a key store", config.Algorithm)
+		}
+		if err := m.Reload(ctx); err != nil {
+			return nil, err
+		}
+		if m.current == nil {
+			if err := m.Rotate(ctx); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q; use %s, %s or %s", config.Algorithm, AlgHS256, AlgRS256, AlgEdDSA)
+	}
+}
+
+// Issuer returns the issuer set on tokens.
+func (m *Manager) Issuer() string {
+	return m.config.Issuer
+}
+
+// Audience returns the audience set on tokens.
+func (m *Manager) Audience() string {
+	return m.config.Audience
+}
+
+// Rotates reports whether keys are generated and rotated, which needs a Rotator.
+func (m *Manager) Rotates() bool {
+	return m.config.Algorithm != AlgHS256
+}
+
+// Sign signs claims with the current key and names it in the kid header.
+func (m *Manager) Sign(claims jwt.Claims) (string, error) {
+	m.mu.RLock()
+	current := m.current
+	m.mu.RUnlock()
+	if current == nil {
+		return "", errors.New("no signing key available")
+	}
+
+	token := jwt.NewWithClaims(signingMethod(current.algorithm), claims)
+	token.Header["kid"] = current.id
+	return token.SignedString(current.signer)
+}
+
+// Parse verifies a token's signature, expiry, issuer and audience and decodes
+// it into claims.
+func (m *Manager) Parse(tokenString string, claims jwt.Claims) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, m.keyFor,
+		jwt.WithIssuer(m.config.Issuer),
+		jwt.WithAudience(m.config.Audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return err
+	}
+	if !token.Valid {
+		return errors.New("invalid token")
+	}
+	return nil
+}
+
+// keyFor returns the verification key named by a token's kid header. An
+// unknown kid makes the manager reload the store once, in case another
+// replica has just rotated.
+func (m *Manager) keyFor(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no key ID")
+	}
+
+	k := m.lookup(kid)
+	if k == nil && m.store != nil && m.reloadDue() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		if err := m.Reload(ctx); err != nil {
+			logger.Errorf("failed to reload signing keys: %v", err)
+		}
+		k = m.lookup(kid)
+	}
+	if k == nil {
+		return nil, ErrUnknownKey
+	}
+	if token.Method.Alg() != k.algorithm {
+		return nil, errors.New("unexpected signing method")
+	}
+	return k.verifier, nil
+}
+
+// lookup returns the key with the given ID if it still verifies tokens.
+func (m *Manager) lookup(kid string) *key {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.keys[kid]
+}
+
+// reloadDue reports whether enough time has passed since the last reload.
+func (m *Manager) reloadDue() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return time.Since(m.lastReload) >= reloadCooldown
+}
+
+// Reload replaces the generated keys with those in the store. The newest key
+// of the configured algorithm whose private half can be decrypted becomes the
+// current key; replaced keys are accepted until Retention has passed.
+func (m *Manager) Reload(ctx context.Context) error {
+	if m.store == nil {
+		return nil
+	}
+	stored, err := m.store.ListKeys(ctx)
+	if err != nil {
+		return err
+	}
+	sort.Slice(stored, func(i, j int) bool { return stored[i].CreatedAt.Before(stored[j].CreatedAt) })
+
+	now := time.Now()
+	keys := map[string]*key{}
+	var current *key
+	for i, sk := range stored {
+		// A key replaced longer ago than Retention can no longer have live tokens
+		if i+1 < len(stored) && stored[i+1].CreatedAt.Add(m.config.Retention).Before(now) {
+			continue
+		}
+
+		k, err := m.decode(sk)
+		if err != nil {
+			logger.Errorf("skipping signing key %s: %v", sk.ID, err)
+			continue
+		}
+		keys[k.id] = k
+		if k.signer != nil && k.algorithm == m.config.Algorithm {
+			current = k
+		}
+	}
+
+	m.mu.Lock()
+	m.keys = keys
+	m.current = current
+	m.lastReload = now
+	m.mu.Unlock()
+	return nil
+}
+
+// Rotate generates a new key, stores it and makes it the current key. The
+// previous key keeps verifying tokens for Retention.
+func (m *Manager) Rotate(ctx context.Context) error {
+	if m.store == nil {
+		return errors.New(m.config.Algorithm + " keys are configured, not generated")
+	}
+
+	k, stored, err := m.generate()
+	if err != nil {
+		return err
+	}
+	if err := m.store.CreateKey(ctx, stored); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.keys[k.id] = k
+	m.current = k
+	m.mu.Unlock()
+
+	logger.Security("signing_key_rotated", map[string]interface{}{
+		"kid":       k.id,
+		"algorithm": k.algorithm,
+	})
+	return nil
+}
+
+// rotationDue reports whether the current key is older than RotationInterval.
+func (m *Manager) rotationDue(now time.Time) bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current == nil || (m.config.RotationInterval > 0 && now.Sub(m.current.createdAt) >= m.config.RotationInterval)
+}
+
+// generate creates a key of the configured algorithm.
+func (m *Manager) generate() (*key, StoredKey, error) {
+	var signer crypto.Signer
+	switch m.config.Algorithm {
+	case AlgRS256:
+		priv, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return nil, StoredKey{}, err
+		}
+		signer = priv
+	case AlgEdDSA:
+		_, priv, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, StoredKey{}, err
+		}
+		signer = priv
+	default:
+		return nil, StoredKey{}, fmt.Errorf("cannot generate %s keys", m.config.Algorithm)
+	}
+
+	id, err := newKeyID()
+	if err != nil {
+		return nil, StoredKey{}, err
+	}
+	privatePEM, publicPEM, err := encodeKeyPair(signer)
+	if err != nil {
+		return nil, StoredKey{}, err
+	}
+	sealed, err := seal(m.config.Secret, privatePEM)
+	if err != nil {
+		return nil, StoredKey{}, err
+	}
+
+	now := time.Now()
+	return &key{
+		id:        id,
+		algorithm: m.config.Algorithm,
+		createdAt: now,
+		signer:    signer,
+		verifier:  signer.Public(),
+	}, StoredKey{
+		ID:         id,
+		Algorithm:  m.config.Algorithm,
+		PrivateKey: sealed,
+		PublicKey:  publicPEM,
+		CreatedAt:  now,
+	}, nil
+}
+
+// decode turns a stored key back into a key. A private key that cannot be
+// decrypted, for example after the secret changed, leaves a key that can
+// still verify but no longer sign.
+func (m *Manager) decode(sk StoredKey) (*key, error) {
+	verifier, err := decodePublicKey(sk.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	k := &key{
+		id:        sk.ID,
+		algorithm: sk.Algorithm,
+		createdAt: sk.CreatedAt,
+		verifier:  verifier,
+	}
+
+	privatePEM, err := open(m.config.Secret, sk.PrivateKey)
+	if err != nil {
+		logger.Errorf("signing key %s can only verify: %v", sk.ID, err)
+		return k, nil
+	}
+	signer, err := decodePrivateKey(privatePEM)
+	if err != nil {
+		return nil, err
+	}
+	k.signer = signer
+	return k, nil
+}
+
+// hmacKey returns the HS256 key for a secret. Its ID is derived from the
+// secret so every replica with the same secret agrees on it.
+func hmacKey(secret string) *key {
+	sum := sha256.Sum256([]byte("kid:" + secret))
+	return &key{
+		id:        "hs-" + hex.EncodeToString(sum[:8]),
+		algorithm: AlgHS256,
+		signer:    []byte(secret),
+		verifier:  []byte(secret),
+	}
+}
+
+// newKeyID returns a random key ID.
+func newKeyID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// signingMethod maps an algorithm name to its jwt signing method.
+func signingMethod(algorithm string) jwt.SigningMethod {
+	switch algorithm {
+	case AlgRS256:
+		return jwt.SigningMethodRS256
+	case AlgEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+// Rotator is a background job that picks up keys created by other replicas,
+// rotates the current key once it reaches RotationInterval and deletes keys
+// past their retention.
+type Rotator struct {
+	manager  *Manager
+	interval time.Duration
+}
+
+// NewRotator returns a new Rotator that checks the keys every interval.
+func NewRotator(manager *Manager, interval time.Duration) *Rotator {
+	return &Rotator{
+		manager:  manager,
+		interval: interval,
+	}
+}
+
+// Run checks the keys on every tick until ctx is cancelled.
+func (r *Rotator) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := r.manager.Reload(ctx); err != nil {
+			logger.Errorf("failed to reload signing keys: %v", err)
+			continue
+		}
+		now := time.Now()
+		if r.manager.rotationDue(now) {
+			if err := r.manager.Rotate(ctx); err != nil {
+				logger.Errorf("signing key rotation failed: %v", err)
+			}
+		}
+		if removed, err := r.manager.store.PurgeKeys(ctx, now.Add(-r.manager.config.Retention)); err != nil {
+			logger.Errorf("failed to purge signing keys: %v", err)
+		} else if removed > 0 {
+			logger.Infof("removed %d retired signing key(s)", removed)
+		}
+	}
+}
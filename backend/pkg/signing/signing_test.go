@@ -0,0 +1,260 @@
+package signing
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memoryStore is an in-memory Store.
+type memoryStore struct {
+	mu   sync.Mutex
+	keys []StoredKey
+}
+
+func (s *memoryStore) ListKeys(ctx context.Context) ([]StoredKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]StoredKey(nil), s.keys...), nil
+}
+
+func (s *memoryStore) CreateKey(ctx context.Context, key StoredKey) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *memoryStore) PurgeKeys(ctx context.Context, before time.Time) (int, error) {
+	return 0, nil
+}
+
+// age moves every stored key's creation back by d.
+func (s *memoryStore) age(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.keys {
+		s.keys[i].CreatedAt = s.keys[i].CreatedAt.Add(-d)
+	}
+}
+
+func testConfig(algorithm, secret string) Config {
+	return Config{
+		Algorithm:        algorithm,
+		Secret:           secret,
+		Issuer:           "attendance-api",
+		Audience:         "attendance-clients",
+		RotationInterval: 24 * time.Hour,
+		Retention:        time.Hour,
+	}
+}
+
+func testClaims(issuer, audience string, expires time.Duration) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "1",
+		Issuer:    issuer,
+		Audience:  jwt.ClaimStrings{audience},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(expires)),
+	}
+}
+
+func newTestManager(t *testing.T, config Config, store Store) *Manager {
+	t.Helper()
+	m, err := NewManager(context.Background(), config, store)
+	if err != nil {
+		t.Fatalf("NewManager: %v", err)
+	}
+	return m
+}
+
+func sign(t *testing.T, m *Manager, claims jwt.Claims) string {
+	t.Helper()
+	token, err := m.Sign(claims)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	return token
+}
+
+// kidOf returns the kid header of a token without verifying it.
+func kidOf(t *testing.T, token string) string {
+	t.Helper()
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &jwt.RegisteredClaims{})
+	if err != nil {
+		t.Fatalf("ParseUnverified: %v", err)
+	}
+	kid, _ := parsed.Header["kid"].(string)
+	return kid
+}
+
+func TestHMACKeyID(t *testing.T) {
+	a := hmacKey("secret-one")
+	b := hmacKey("secret-one")
+	c := hmacKey("secret-two")
+
+	if a.id != b.id {
+		t.Errorf("kid for the same secret differs: %s, %s", a.id, b.id)
+	}
+	if a.id == c.id {
+		t.Errorf("different secrets share kid %s", a.id)
+	}
+	if len(a.id) != len("hs-")+16 || a.id[:3] != "hs-" {
+		t.Errorf("kid %q is not hs- followed by 16 hex digits", a.id)
+	}
+}
+
+func TestHS256Verify(t *testing.T) {
+	current := newTestManager(t, testConfig(AlgHS256, "current-secret"), nil)
+	previous := newTestManager(t, testConfig(AlgHS256, "previous-secret"), nil)
+	rotated := newTestManager(t, Config{
+		Algorithm:       AlgHS256,
+		Secret:          "current-secret",
+		PreviousSecrets: []string{"previous-secret"},
+		Issuer:          "attendance-api",
+		Audience:        "attendance-clients",
+	}, nil)
+
+	valid := testClaims("attendance-api", "attendance-clients", time.Hour)
+	tests := []struct {
+		name    string
+		signer  *Manager
+		claims  jwt.Claims
+		verify  *Manager
+		wantErr bool
+	}{
+		{"same secret", current, valid, current, false},
+		{"previous secret still accepted", previous, valid, rotated, false},
+		{"previous secret not configured", previous, valid, current, true},
+		{"wrong issuer", current, testClaims("someone-else", "attendance-clients", time.Hour), current, true},
+		{"wrong audience", current, testClaims("attendance-api", "someone-else", time.Hour), current, true},
+		{"expired", current, testClaims("attendance-api", "attendance-clients", -time.Minute), current, true},
+		{"no expiry", current, jwt.RegisteredClaims{Issuer: "attendance-api", Audience: jwt.ClaimStrings{"attendance-clients"}}, current, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token := sign(t, tt.signer, tt.claims)
+			err := tt.verify.Parse(token, &jwt.RegisteredClaims{})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHS256UnknownKey(t *testing.T) {
+	m := newTestManager(t, testConfig(AlgHS256, "current-secret"), nil)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, testClaims("attendance-api", "attendance-clients", time.Hour))
+	token.Header["kid"] = "hs-0000000000000000"
+	signed, err := token.SignedString([]byte("current-secret"))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	if err := m.Parse(signed, &jwt.RegisteredClaims{}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("Parse error = %v, want %v", err, ErrUnknownKey)
+	}
+
+	delete(token.Header, "kid")
+	signed, err = token.SignedString([]byte("current-secret"))
+	if err != nil {
+		t.Fatalf("SignedString: %v", err)
+	}
+	if err := m.Parse(signed, &jwt.RegisteredClaims{}); err == nil {
+		t.Error("Parse accepted a token without a kid")
+	}
+}
+
+func TestRotation(t *testing.T) {
+	for _, algorithm := range []string{AlgEdDSA, AlgRS256} {
+		t.Run(algorithm, func(t *testing.T) {
+			ctx := context.Background()
+			store := &memoryStore{}
+			config := testConfig(algorithm, "store-secret")
+			m := newTestManager(t, config, store)
+			replica := newTestManager(t, config, store)
+			claims := testClaims(config.Issuer, config.Audience, time.Hour)
+
+			old := sign(t, m, claims)
+			if got := kidOf(t, sign(t, replica, claims)); got != kidOf(t, old) {
+				t.Fatalf("replica signs with kid %s, want the stored key %s", got, kidOf(t, old))
+			}
+
+			if err := m.Rotate(ctx); err != nil {
+				t.Fatalf("Rotate: %v", err)
+			}
+			fresh := sign(t, m, claims)
+			if kidOf(t, fresh) == kidOf(t, old) {
+				t.Fatal("Rotate kept the same kid")
+			}
+			for name, token := range map[string]string{"old": old, "fresh": fresh} {
+				if err := m.Parse(token, &jwt.RegisteredClaims{}); err != nil {
+					t.Errorf("%s token rejected after rotation: %v", name, err)
+				}
+			}
+
+			if err := replica.Reload(ctx); err != nil {
+				t.Fatalf("Reload: %v", err)
+			}
+			if err := replica.Parse(fresh, &jwt.RegisteredClaims{}); err != nil {
+				t.Errorf("replica rejected the rotated key: %v", err)
+			}
+			if got := kidOf(t, sign(t, replica, claims)); got != kidOf(t, fresh) {
+				t.Errorf("replica signs with kid %s after reload, want %s", got, kidOf(t, fresh))
+			}
+
+			// Once the old key was replaced longer ago than Retention it is dropped
+			store.age(2 * config.Retention)
+			if err := m.Reload(ctx); err != nil {
+				t.Fatalf("Reload: %v", err)
+			}
+			if err := m.Parse(old, &jwt.RegisteredClaims{}); !errors.Is(err, ErrUnknownKey) {
+				t.Errorf("old token after retention: error = %v, want %v", err, ErrUnknownKey)
+			}
+			if err := m.Parse(fresh, &jwt.RegisteredClaims{}); err != nil {
+				t.Errorf("current token rejected after retention: %v", err)
+			}
+		})
+	}
+}
+
+func TestStoredKeyWithAnotherSecretOnlyVerifies(t *testing.T) {
+	store := &memoryStore{}
+	original := newTestManager(t, testConfig(AlgEdDSA, "first-secret"), store)
+	claims := testClaims("attendance-api", "attendance-clients", time.Hour)
+	token := sign(t, original, claims)
+
+	// The new manager cannot decrypt the stored key, so it generates its own
+	changed := newTestManager(t, testConfig(AlgEdDSA, "second-secret"), store)
+	if err := changed.Parse(token, &jwt.RegisteredClaims{}); err != nil {
+		t.Errorf("token signed before the secret changed was rejected: %v", err)
+	}
+	if kidOf(t, sign(t, changed, claims)) == kidOf(t, token) {
+		t.Error("manager signs with a key it cannot decrypt")
+	}
+	if len(store.keys) != 2 {
+		t.Errorf("store has %d keys, want 2", len(store.keys))
+	}
+}
+
+func TestNewManagerConfig(t *testing.T) {
+	tests := []struct {
+		name   string
+		config Config
+		store  Store
+	}{
+		{"missing secret", Config{Algorithm: AlgHS256}, nil},
+		{"generated keys without a store", Config{Algorithm: AlgEdDSA, Secret: "s"}, nil},
+		{"unsupported algorithm", Config{Algorithm: "none", Secret: "s"}, nil},
+	}
+
+	for _, tt := range tests {
+		if _, err := NewManager(context.Background(), tt.config, tt.store); err == nil {
+			t.Errorf("%s: NewManager succeeded", tt.name)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+package utils
+
+import "math"
+
+// earthRadiusMeters is the mean radius of the Earth used for distance calculations.
+const earthRadiusMeters = 6371000.0
+
+// HaversineMeters returns the great-circle distance in meters between two
+// points given in decimal degrees.
+func HaversineMeters(lat1, lng1, lat2, lng2 float64) float64 {
+	toRad := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRad(lat2 - lat1)
+	dLng := toRad(lng2 - lng1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRad(lat1))*math.Cos(toRad(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return earthRadiusMeters * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestHaversineMeters(t *testing.T) {
+	tests := []struct {
+		name                   string
+		lat1, lng1, lat2, lng2 float64
+		want                   float64
+	}{
+		{"same point", 5.5663, 5.7926, 5.5663, 5.7926, 0},
+		{"thousandth of a degree of latitude", 5.5663, 5.7926, 5.5673, 5.7926, 111.19},
+		{"one degree of longitude on the equator", 0, 0, 0, 1, 111194.93},
+		{"antipodes", 0, 0, 0, 180, 20015086.80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := HaversineMeters(tt.lat1, tt.lng1, tt.lat2, tt.lng2)
+			if math.Abs(got-tt.want) > 0.01 {
+				t.Errorf("HaversineMeters = %.2f, want %.2f", got, tt.want)
+			}
+			if back := HaversineMeters(tt.lat2, tt.lng2, tt.lat1, tt.lng1); math.Abs(back-got) > 1e-6 {
+				t.Errorf("distance is not symmetric: %.6f and %.6f", got, back)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// icalTimeLayout formats UTC date-times in iCalendar (RFC 5545) form.
+const icalTimeLayout = "20060102T150405Z"
+
+// icalLineLimit is the longest content line, in octets, before it is folded.
+const icalLineLimit = 75
+
+// ICalEvent is one VEVENT of an iCalendar feed.
+type ICalEvent struct {
+	UID          string // Globally unique and stable across feed refreshes
+	Summary      string
+	Description  string
+	Location     string
+	Latitude     *float64 // Exported as GEO when both are set
+	Longitude    *float64
+	Start        time.Time
+	End          time.Time
+	Created      time.Time
+	LastModified time.Time
+	Cancelled    bool // Exported as STATUS:CANCELLED so subscribed calendars strike the event out
+}
+
+// ICalendar renders events as an RFC 5545 calendar named name, suitable for
+// calendar apps that subscribe to the feed and refresh it every refresh.
+func ICalendar(name string, events []ICalEvent, refresh time.Duration, now time.Time) []byte {
+	var b strings.Builder
+	line := func(format string, args ...interface{}) {
+		writeICalLine(&b, fmt.Sprintf(format, args...))
+	}
+
+	ttl := icalDuration(refresh)
+	line("BEGIN:VCALENDAR")
+	line("VERSION:2.0")
+	line("PRODID:-//Attendance Management System//Calendar Feed//EN")
+	line("CALSCALE:GREGORIAN")
+	line("METHOD:PUBLISH")
+	line("X-WR-CALNAME:%s", escapeICalText(name))
+	line("X-PUBLISHED-TTL:%s", ttl)
+	line("REFRESH-INTERVAL;VALUE=DURATION:%s", ttl)
+
+	stamp := now.UTC().Format(icalTimeLayout)
+	for _, event := range events {
+		line("BEGIN:VEVENT")
+		line("UID:%s", event.UID)
+		line("DTSTAMP:%s", stamp)
+		line("DTSTART:%s", event.Start.UTC().Format(icalTimeLayout))
+		line("DTEND:%s", event.End.UTC().Format(icalTimeLayout))
+		line("SUMMARY:%s", escapeICalText(event.Summary))
+		if event.Location != "" {
+			line("LOCATION:%s", escapeICalText(event.Location))
+		}
+		if event.Latitude != nil && event.Longitude != nil {
+			line("GEO:%.6f;%.6f", *event.Latitude, *event.Longitude)
+		}
+		if event.Description != "" {
+			line("DESCRIPTION:%s", escapeICalText(event.Description))
+		}
+		if !event.Created.IsZero() {
+			line("CREATED:%s", event.Created.UTC().Format(icalTimeLayout))
+		}
+		if !event.LastModified.IsZero() {
+			line("LAST-MODIFIED:%s", event.LastModified.UTC().Format(icalTimeLayout))
+		}
+		if event.Cancelled {
+			line("STATUS:CANCELLED")
+		} else {
+			line("STATUS:CONFIRMED")
+		}
+		line("TRANSP:OPAQUE")
+		line("END:VEVENT")
+	}
+
+	line("END:VCALENDAR")
+	return []byte(b.String())
+}
+
+// escapeICalText escapes a TEXT value: backslashes, semicolons, commas and
+// newlines.
+func escapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`, "\r", `\n`).Replace(s)
+}
+
+// writeICalLine writes a content line ending in CRLF, folding it onto
+// continuation lines that start with a space once it exceeds 75 octets. Folds
+// never split a UTF-8 sequence.
+func writeICalLine(b *strings.Builder, line string) {
+	limit := icalLineLimit
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		limit = icalLineLimit - 1 // The leading space counts towards the limit
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+}
+
+// icalDuration formats a duration as an RFC 5545 DURATION such as PT1H.
+func icalDuration(d time.Duration) string {
+	if d < time.Minute {
+		d = time.Minute
+	}
+	hours := int(d / time.Hour)
+	minutes := int(d % time.Hour / time.Minute)
+	switch {
+	case minutes == 0:
+		return fmt.Sprintf("PT%dH", hours)
+	case hours == 0:
+		return fmt.Sprintf("PT%dM", minutes)
+	default:
+		return fmt.Sprintf("PT%dH%dM", hours, minutes)
+	}
+}
@@ -0,0 +1,79 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Intro to Programming", "Intro to Programming"},
+		{"Room 1; Block A", `Room 1\; Block A`},
+		{"Lagos, Nigeria", `Lagos\, Nigeria`},
+		{`C:\path`, `C:\\path`},
+		{"line one\nline two", `line one\nline two`},
+		{"line one\r\nline two", `line one\nline two`},
+		{"line one\rline two", `line one\nline two`},
+		{`a\;b`, `a\\\;b`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeICalText(tt.in); got != tt.want {
+			t.Errorf("escapeICalText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestWriteICalLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:CSC 101"},
+		{"exactly the limit", "SUMMARY:" + strings.Repeat("a", icalLineLimit-len("SUMMARY:"))},
+		{"one over the limit", "SUMMARY:" + strings.Repeat("a", icalLineLimit-len("SUMMARY:")+1)},
+		{"several folds", "DESCRIPTION:" + strings.Repeat("abcdefghij", 30)},
+		{"multi-byte runes", "LOCATION:" + strings.Repeat("é", 100)},
+		{"four-byte runes", "SUMMARY:" + strings.Repeat("😀", 60)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b strings.Builder
+			writeICalLine(&b, tt.line)
+			out := b.String()
+
+			if !strings.HasSuffix(out, "\r\n") {
+				t.Fatalf("output %q does not end in CRLF", out)
+			}
+			physical := strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n")
+
+			var unfolded strings.Builder
+			for i, part := range physical {
+				if len(part) > icalLineLimit {
+					t.Errorf("line %d is %d octets, over the %d limit", i, len(part), icalLineLimit)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(part, " ") {
+						t.Fatalf("continuation line %d %q does not start with a space", i, part)
+					}
+					part = part[1:]
+				}
+				if !utf8.ValidString(part) {
+					t.Errorf("line %d %q splits a UTF-8 sequence", i, part)
+				}
+				unfolded.WriteString(part)
+			}
+			if unfolded.String() != tt.line {
+				t.Errorf("unfolded line = %q, want %q", unfolded.String(), tt.line)
+			}
+			if len(tt.line) <= icalLineLimit && len(physical) != 1 {
+				t.Errorf("line of %d octets was folded", len(tt.line))
+			}
+		})
+	}
+}
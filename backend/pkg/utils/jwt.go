@@ -2,69 +2,112 @@ package utils
 
 import (
 	"errors"
-	"os"
 	"time"
 
+	"github.com/Dom-HTG/attendance-management-system/pkg/signing"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// signingKeys signs and verifies every JWT; see UseSigningKeys.
+var signingKeys *signing.Manager
+
+// UseSigningKeys sets the key manager used to sign and verify tokens. It must
+// be called once at startup before any token is issued.
+func UseSigningKeys(manager *signing.Manager) {
+	signingKeys = manager
+}
+
+// Challenge token purposes. Access tokens have no purpose; a challenge token is
+// only accepted by the login step for its purpose and never as an access token.
+const (
+	PurposeTwoFactor      = "2fa"       // Password accepted; a TOTP or recovery code is needed
+	PurposeTwoFactorSetup = "2fa_setup" // Password accepted; the role requires enrolling first
 )
 
 type Claims struct {
-	ID    int    `json:"id"`
-	Email string `json:"email"`
-	Role  string `json:"role"`
+	ID      int    `json:"id"`
+	Email   string `json:"email"`
+	Role    string `json:"role"`
+	Purpose string `json:"purpose,omitempty"` // Set on challenge tokens only
 	jwt.RegisteredClaims
 }
 
-// GenerateToken generates a JWT token with the given user data
-func GenerateToken(userID int, email, role string, expirationMinutes int) (string, error) {
-	// Get JWT secret from environment or use default
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-super-secret-key-change-in-production"
+// GenerateToken generates a JWT token with the given user data.
+// Every token carries a random ID (jti) so it can be revoked before it expires.
+func GenerateToken(userID int, email, role string, ttl time.Duration) (string, *Claims, error) {
+	return signToken(userID, email, role, "", ttl)
+}
+
+// GenerateChallengeToken issues a short-lived token that carries a user from
+// the password step of login to the step named by purpose.
+func GenerateChallengeToken(userID int, email, role, purpose string, ttl time.Duration) (string, *Claims, error) {
+	return signToken(userID, email, role, purpose, ttl)
+}
+
+// signToken signs a token with the given claims using the current signing key.
+func signToken(userID int, email, role, purpose string, ttl time.Duration) (string, *Claims, error) {
+	if signingKeys == nil {
+		return "", nil, errors.New("signing keys are not configured")
 	}
 
-	expirationTime := time.Now().Add(time.Duration(expirationMinutes) * time.Minute)
+	now := time.Now()
 	claims := &Claims{
-		ID:    userID,
-		Email: email,
-		Role:  role,
+		ID:      userID,
+		Email:   email,
+		Role:    role,
+		Purpose: purpose,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ID:        uuid.New().String(),
+			Issuer:    signingKeys.Issuer(),
+			Audience:  jwt.ClaimStrings{signingKeys.Audience()},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
+	tokenString, err := signingKeys.Sign(claims)
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return tokenString, nil
+	return tokenString, claims, nil
 }
 
-// ValidateToken validates and parses the JWT token
+// ValidateToken validates and parses an access token. Challenge tokens are rejected.
 func ValidateToken(tokenString string) (*Claims, error) {
-	// Get JWT secret from environment or use default
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "your-super-secret-key-change-in-production"
+	claims, err := parseToken(tokenString)
+	if err != nil {
+		return nil, err
 	}
+	if claims.Purpose != "" {
+		return nil, errors.New("token cannot be used for API access")
+	}
+	return claims, nil
+}
 
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
-
+// ValidateChallengeToken validates a challenge token issued for purpose.
+func ValidateChallengeToken(tokenString, purpose string) (*Claims, error) {
+	claims, err := parseToken(tokenString)
 	if err != nil {
 		return nil, err
 	}
+	if claims.Purpose != purpose {
+		return nil, errors.New("token was not issued for this step")
+	}
+	return claims, nil
+}
+
+// parseToken verifies a token's signature, expiry, issuer and audience and
+// returns its claims.
+func parseToken(tokenString string) (*Claims, error) {
+	if signingKeys == nil {
+		return nil, errors.New("signing keys are not configured")
+	}
 
-	if !token.Valid {
-		return nil, errors.New("invalid token")
+	claims := &Claims{}
+	if err := signingKeys.Parse(tokenString, claims); err != nil {
+		return nil, err
 	}
 
 	return claims, nil
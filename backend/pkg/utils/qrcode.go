@@ -61,8 +61,8 @@ func GenerateQRCodePNGWithLevel(data string, level qrcode.RecoveryLevel, size in
 	return base64String, nil
 }
 
-// ValidateQRCodeToken checks if the QR code token is valid (you can add custom logic here)
-// For now, it simply checks if the token is not empty
+// ValidateQRCodeToken performs a cheap shape check on a scanned token before any lookup.
+// Signed rotating tokens are verified separately with ParseRotatingQRToken.
 func ValidateQRCodeToken(token string) bool {
 	return token != ""
 }
@@ -0,0 +1,90 @@
+package utils
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// rotatingQRTokenPrefix marks tokens issued for rotating-QR events.
+// Static events still use a plain UUID.
+const rotatingQRTokenPrefix = "v1."
+
+// qrTokenKey signs rotating QR tokens; see UseQRTokenSecret.
+var qrTokenKey []byte
+
+// UseQRTokenSecret sets the key rotating QR tokens are signed with. It must be
+// called once at startup before any token is issued.
+func UseQRTokenSecret(secret string) {
+	qrTokenKey = []byte(secret)
+}
+
+// RotatingQRToken is the decoded payload of a signed rotating QR token.
+type RotatingQRToken struct {
+	EventID int
+	Window  int64 // Index of the rotation window the token was issued for
+}
+
+// IsRotatingQRToken reports whether the token looks like a signed rotating token.
+func IsRotatingQRToken(token string) bool {
+	return strings.HasPrefix(token, rotatingQRTokenPrefix)
+}
+
+// GenerateRotatingQRToken issues a signed token for the rotation window that
+// contains now. It returns the token and the time the window closes.
+// Tokens have the form v1.<event_id>.<window>.<signature>.
+func GenerateRotatingQRToken(eventID int, period time.Duration, now time.Time) (string, time.Time) {
+	window := now.Unix() / int64(period.Seconds())
+	payload := fmt.Sprintf("%s%d.%d", rotatingQRTokenPrefix, eventID, window)
+	expiresAt := time.Unix((window+1)*int64(period.Seconds()), 0)
+	return payload + "." + signQRPayload(payload), expiresAt
+}
+
+// ParseRotatingQRToken verifies the token signature and returns its payload.
+// It does not check whether the window is still current; see ValidAt.
+func ParseRotatingQRToken(token string) (*RotatingQRToken, error) {
+	if !IsRotatingQRToken(token) {
+		return nil, errors.New("not a rotating QR token")
+	}
+
+	parts := strings.Split(strings.TrimPrefix(token, rotatingQRTokenPrefix), ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed QR token")
+	}
+
+	payload := rotatingQRTokenPrefix + parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(parts[2]), []byte(signQRPayload(payload))) {
+		return nil, errors.New("invalid QR token signature")
+	}
+
+	eventID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, errors.New("malformed QR token")
+	}
+	window, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, errors.New("malformed QR token")
+	}
+
+	return &RotatingQRToken{EventID: eventID, Window: window}, nil
+}
+
+// ValidAt reports whether the token belongs to the current rotation window or
+// the one just before it. Accepting the previous window covers a code that
+// rotated while the student's phone was still submitting it.
+func (t *RotatingQRToken) ValidAt(period time.Duration, now time.Time) bool {
+	current := now.Unix() / int64(period.Seconds())
+	return t.Window == current || t.Window == current-1
+}
+
+// signQRPayload returns the base64url HMAC-SHA256 signature of payload.
+func signQRPayload(payload string) string {
+	mac := hmac.New(sha256.New, qrTokenKey)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,20 @@
+package utils
+
+import (
+	"regexp"
+	"strconv"
+)
+
+var academicSessionPattern = regexp.MustCompile(`^(\d{4})/(\d{4})$`)
+
+// ValidAcademicSession reports whether name is an academic session such as
+// "2024/2025", whose second year follows the first.
+func ValidAcademicSession(name string) bool {
+	m := academicSessionPattern.FindStringSubmatch(name)
+	if m == nil {
+		return false
+	}
+	start, _ := strconv.Atoi(m[1])
+	end, _ := strconv.Atoi(m[2])
+	return end == start+1
+}
@@ -0,0 +1,21 @@
+package utils
+
+import "testing"
+
+func TestValidAcademicSession(t *testing.T) {
+	for name, want := range map[string]bool{
+		"2024/2025":  true,
+		"1999/2000":  true,
+		"2024/2024":  false,
+		"2024/2026":  false,
+		"2025/2024":  false,
+		"2024-2025":  false,
+		"24/25":      false,
+		"2024/2025 ": false,
+		"":           false,
+	} {
+		if got := ValidAcademicSession(name); got != want {
+			t.Errorf("ValidAcademicSession(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters (RFC 6238). These are the defaults every authenticator app
+// supports, so they are not configurable.
+const (
+	totpDigits = 6
+	totpPeriod = 30 // Seconds per time step
+	totpSkew   = 1  // Steps accepted either side of the current one, for clock drift
+)
+
+// recoveryCodeAlphabet leaves out characters that are easy to misread.
+const recoveryCodeAlphabet = "abcdefghjkmnpqrstuvwxyz23456789"
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// totpKey encrypts TOTP secrets at rest; see UseTOTPEncryptionKey.
+var totpKey []byte
+
+// GenerateTOTPSecret returns a random 160-bit secret, base32 encoded as
+// authenticator apps expect.
+func GenerateTOTPSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TOTPURI returns the otpauth:// URI that authenticator apps read from a QR code.
+func TOTPURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+	label := url.PathEscape(issuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// TOTPCode returns the code for a secret at the given time step.
+func TOTPCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", errors.New("invalid TOTP secret")
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000), nil
+}
+
+// VerifyTOTP checks code against the steps around now and returns the step it
+// matched. Callers should reject steps at or before the last one accepted so a
+// code cannot be replayed.
+func VerifyTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != totpDigits {
+		return 0, false
+	}
+
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		expected, err := TOTPCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateRecoveryCode returns a random single-use recovery code of the form
+// xxxxx-xxxxx.
+func GenerateRecoveryCode() (string, error) {
+	// Bytes at or above limit are discarded so every character is equally likely
+	limit := byte(256 - 256%len(recoveryCodeAlphabet))
+	code := make([]byte, 0, 11)
+	buf := make([]byte, 16)
+	for len(code) < 11 {
+		if _, err := rand.Read(buf); err != nil {
+			return "", err
+		}
+		for _, b := range buf {
+			if len(code) == 11 {
+				break
+			}
+			if b >= limit {
+				continue
+			}
+			if len(code) == 5 {
+				code = append(code, '-')
+			}
+			code = append(code, recoveryCodeAlphabet[int(b)%len(recoveryCodeAlphabet)])
+		}
+	}
+	return string(code), nil
+}
+
+// NormalizeRecoveryCode lowercases a recovery code and drops separators so
+// codes can be typed with or without the dash.
+func NormalizeRecoveryCode(code string) string {
+	return strings.NewReplacer("-", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(code)))
+}
+
+// EncryptTOTPSecret encrypts a TOTP secret for storage with AES-GCM.
+func EncryptTOTPSecret(secret string) (string, error) {
+	gcm, err := totpCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(secret), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// DecryptTOTPSecret reverses EncryptTOTPSecret.
+func DecryptTOTPSecret(encrypted string) (string, error) {
+	gcm, err := totpCipher()
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", errors.New("malformed TOTP secret")
+	}
+	plain, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt TOTP secret")
+	}
+	return string(plain), nil
+}
+
+// UseTOTPEncryptionKey sets the key TOTP secrets are encrypted with at rest.
+// It must be called once at startup before any two-factor request is served.
+func UseTOTPEncryptionKey(secret string) {
+	key := sha256.Sum256([]byte(secret))
+	totpKey = key[:]
+}
+
+// totpCipher returns the AES-GCM cipher for TOTP secrets.
+func totpCipher() (cipher.AEAD, error) {
+	if totpKey == nil {
+		return nil, errors.New("TOTP encryption key is not configured")
+	}
+	block, err := aes.NewCipher(totpKey)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed from RFC 6238 appendix B, "12345678901234567890",
+// base32 encoded.
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238Vectors(t *testing.T) {
+	// RFC 6238 lists 8-digit codes; a 6-digit code is their last six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, tt.unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", tt.unix, err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestTOTPCodeInvalidSecret(t *testing.T) {
+	if _, err := TOTPCode("not base32!", 1); err == nil {
+		t.Error("TOTPCode accepted an invalid secret")
+	}
+}
+
+func TestVerifyTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / totpPeriod
+	code := func(step int64) string {
+		c, err := TOTPCode(rfc6238Secret, step)
+		if err != nil {
+			t.Fatalf("TOTPCode(%d): %v", step, err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", code(current), current, true},
+		{"previous step within skew", code(current - 1), current - 1, true},
+		{"next step within skew", code(current + 1), current + 1, true},
+		{"two steps old", code(current - 2), 0, false},
+		{"two steps ahead", code(current + 2), 0, false},
+		{"spaces are ignored", " " + code(current)[:3] + " " + code(current)[3:] + " ", current, true},
+		{"too short", code(current)[:5], 0, false},
+		{"too long", code(current) + "0", 0, false},
+		{"wrong code", "000000", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := VerifyTOTP(rfc6238Secret, tt.code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("VerifyTOTP(%q) = (%d, %v), want (%d, %v)", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTOTPSecretEncryption(t *testing.T) {
+	previous := totpKey
+	t.Cleanup(func() { totpKey = previous })
+
+	totpKey = nil
+	if _, err := EncryptTOTPSecret(rfc6238Secret); err == nil {
+		t.Fatal("EncryptTOTPSecret succeeded without a key")
+	}
+
+	UseTOTPEncryptionKey("first key")
+	sealed, err := EncryptTOTPSecret(rfc6238Secret)
+	if err != nil {
+		t.Fatalf("EncryptTOTPSecret: %v", err)
+	}
+	opened, err := DecryptTOTPSecret(sealed)
+	if err != nil {
+		t.Fatalf("DecryptTOTPSecret: %v", err)
+	}
+	if opened != rfc6238Secret {
+		t.Errorf("DecryptTOTPSecret = %q, want %q", opened, rfc6238Secret)
+	}
+
+	UseTOTPEncryptionKey("second key")
+	if _, err := DecryptTOTPSecret(sealed); err == nil {
+		t.Error("DecryptTOTPSecret opened a secret sealed with another key")
+	}
+}